@@ -0,0 +1,48 @@
+#!/bin/bash
+set -e
+
+# Report how many files, and how many bytes, fall into each age bracket
+# yupsh equivalent: See main.go
+
+# Parse the directory argument, and check it, since find only warns
+# yupsh: dir := opts.ArgOr(0, "."); os.Stat(dir)
+DIR=${1:-.}
+if [[ ! -d ${DIR} ]]; then
+  echo "age-report: ${DIR}: not a directory" >&2
+  exit 2
+fi
+
+# Measure every age from the same instant
+# yupsh: now := time.Now()
+NOW=$(date +%s)
+
+# Each file's mtime and size, then count and sum per bracket, oldest first
+# yupsh: find.Find(...), While(ageAndSize(now), ...), awk.Awk(newAgeProgram(), ...)
+find "${DIR}" -type f -printf '%T@\t%s\n' \
+| awk -F'\t' -v now="${NOW}" '
+  function human(n,   units, u) {
+    if (n < 1024) return n "B"
+    split("K M G T P E", units, " ")
+    for (u = 0; n >= 1024 && u < 6; u++) n /= 1024
+    return sprintf("%.1f%s", n, units[u])
+  }
+  BEGIN {
+    day = 86400
+    split("1 7 30 182 365", under, " ")
+    label[1] = "under 1 day"; label[2] = "1 day to 1 week"
+    label[3] = "1 week to 1 month"; label[4] = "1 to 6 months"
+    label[5] = "6 months to 1 year"; label[6] = "older than 1 year"
+  }
+  {
+    age = now - $1
+    for (i = 1; i <= 5 && age >= under[i] * day; i++) ;
+    n[i]++; bytes[i] += $2
+    total_n++; total_bytes += $2
+  }
+  END {
+    for (i = 6; i >= 1; i--)
+      printf "%s\t%s\t%d file(s)\t%d%%\n", label[i], human(bytes[i] + 0), n[i],
+        total_bytes ? int(bytes[i] * 100 / total_bytes) : 0
+    printf "total\t%s\t%d file(s)\n", human(total_bytes + 0), total_n
+  }' \
+| column -t -s $'\t'
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	awk `github.com/yupsh/awk`
+	echo `github.com/yupsh/echo`
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	size `github.com/yupsh/script-examples/internal/size`
+	status `github.com/yupsh/script-examples/internal/status`
+	table `github.com/yupsh/script-examples/internal/table`
+	. `github.com/yupsh/while`
+)
+
+// Report how many files, and how many bytes, fall into each age bracket,
+// to decide what to archive
+// Shell equivalent: See age-report.sh
+//
+//   age-report ~/projects
+//   older than 1 year   4.2G    312 file(s)  61%
+//   6 months to 1 year  1.1G    95 file(s)   16%
+//   ...
+//   under 1 day         2.0M    4 file(s)    0%
+//   total               6.9G    580 file(s)
+//
+// A file's age is the time since it was last modified. The brackets are
+// fixed, and every one is printed, oldest first, empty ones included, so
+// reports of different trees line up. The last column is each bracket's
+// share of the total size. A file modified in the future counts as under
+// a day old.
+//
+// Usage: age-report [directory]
+func main() {
+	// Shell: DIR=${1:-.}
+	opts := flags.New("age-report", "[directory]")
+	opts.Parse()
+	dir := opts.ArgOr(0, ".")
+
+	// find only warns about a missing directory, so check it up front
+	if err := input.Dir(dir); err != nil {
+		status.Result{Err: err}.Exit("age-report")
+	}
+
+	// Measure every age from the same instant
+	// Shell: NOW=$(date +%s)
+	now := time.Now()
+
+	err := gloo.Run(pipe.Pipeline(
+		// Find all regular files
+		// Shell: find "${DIR}" -type f
+		find.Find(find.Dir(dir), find.FileType),
+
+		// Each file's bracket and size: "bracket\tsize"
+		// Shell: -printf '%T@\t%s\n', then the bracket in awk
+		While(ageAndSize(now), input.WholeLine),
+
+		// Count and sum per bracket, then print the brackets in order
+		// Shell: awk -F'\t' '{n[$1]++; bytes[$1] += $2} END {...}'
+		awk.Awk(newAgeProgram(), awk.FieldSeparator("\t")),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "age-report: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// day is the unit of the brackets; time has no constant for it because
+// days aren't always 24 hours, but for bucketing ages they are close enough
+const day = 24 * time.Hour
+
+// bracket is an age range: the files younger than under and at least as
+// old as the previous bracket's limit
+type bracket struct {
+	label string
+	under time.Duration // 0: no upper limit
+}
+
+// brackets are the age ranges, newest first; the index of a file's bracket
+// is what flows down the pipeline
+var brackets = []bracket{
+	{"under 1 day", day},
+	{"1 day to 1 week", 7 * day},
+	{"1 week to 1 month", 30 * day},
+	{"1 to 6 months", 182 * day},
+	{"6 months to 1 year", 365 * day},
+	{"older than 1 year", 0},
+}
+
+// bracketOf returns the index of the bracket for a file of the given age
+func bracketOf(age time.Duration) int {
+	for i, b := range brackets {
+		if b.under == 0 || age < b.under {
+			return i
+		}
+	}
+	return len(brackets) - 1
+}
+
+// ageAndSize returns a While() callback that emits "bracket\tsize" for each
+// file, with its age measured from now
+//
+// Shell equivalent:
+//   find -printf '%T@\t%s\n'
+func ageAndSize(now time.Time) Body {
+	return func(args ...any) gloo.Command {
+		path := args[0].(string)
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil // Skip files we can't access
+		}
+
+		i := bracketOf(now.Sub(info.ModTime()))
+		return echo.Echo(fmt.Sprintf("%d\t%d", i, info.Size()))
+	}
+}
+
+// ageProgram is a custom awk program accumulating two values per key: the
+// number of files in each bracket, and their total size
+//
+// Shell awk pattern:
+//   {n[$1]++; bytes[$1] += $2}                    - Action: count and sum by bracket
+//   END {for (i = last; i >= 0; i--) print ...}   - End: one row per bracket, oldest first
+type ageProgram struct {
+	awk.SimpleProgram
+	files []int   // bracket index -> files in it
+	bytes []int64 // bracket index -> their total size
+}
+
+func newAgeProgram() *ageProgram {
+	return &ageProgram{
+		files: make([]int, len(brackets)),
+		bytes: make([]int64, len(brackets)),
+	}
+}
+
+// Action adds the file to its bracket
+// Shell: {n[$1]++; bytes[$1] += $2}
+func (p *ageProgram) Action(ctx *awk.Context) (string, bool) {
+	// With FieldSeparator("\t"): $1 = bracket index, $2 = size
+	i, err := strconv.Atoi(ctx.Field(1))
+	if err != nil || i < 0 || i >= len(brackets) {
+		return "", false
+	}
+	n, _ := strconv.ParseInt(ctx.Field(2), 10, 64)
+
+	p.files[i]++
+	p.bytes[i] += n
+	return "", false
+}
+
+// End prints every bracket from the oldest to the newest, then the totals
+// Shell: END {for (i = last; i >= 0; i--) printf "%s\t%s\t%d file(s)\t%d%%\n", ...}
+func (p *ageProgram) End(ctx *awk.Context) (string, error) {
+	var totalFiles int
+	var totalBytes int64
+	for i := range brackets {
+		totalFiles += p.files[i]
+		totalBytes += p.bytes[i]
+	}
+
+	var rows [][]string
+	for i := len(brackets) - 1; i >= 0; i-- {
+		rows = append(rows, []string{
+			brackets[i].label,
+			size.Format(p.bytes[i]),
+			fmt.Sprintf("%d file(s)", p.files[i]),
+			share(p.bytes[i], totalBytes),
+		})
+	}
+	rows = append(rows, []string{"total", size.Format(totalBytes), fmt.Sprintf("%d file(s)", totalFiles)})
+
+	return strings.Join(table.Align(rows), "\n"), nil
+}
+
+// share returns n as a whole percentage of total
+func share(n, total int64) string {
+	if total == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%d%%", n*100/total)
+}
@@ -0,0 +1,114 @@
+#!/bin/bash
+set -e
+
+# Replace the IP addresses in logs with consistent pseudonyms
+# yupsh equivalent: See main.go
+#
+# Addresses are checked by their shape here, and parsed in the Go version,
+# which also compares them as addresses: only the Go version knows that
+# 2001:DB8:0:0::7 and 2001:db8::7 are the same address. Addresses written in
+# their usual short form get the same pseudonym from both.
+
+# Parse flags (--key SECRET), then the files
+# yupsh: key := opts.String("key", "", ...)
+KEY=
+while [[ $1 == --* ]]; do
+  case $1 in
+    --key) KEY=$2; shift 2 ;;
+    *) echo "Usage: $0 [--key SECRET] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Without --key, a random key for this run
+# yupsh: secret = make([]byte, sha256.Size); rand.Read(secret)
+if [[ -z ${KEY} ]]; then
+  KEY=$(openssl rand -hex 32)
+fi
+export KEY
+
+# Replace each address found in every line
+# yupsh: While(a.line, input.WholeLine) with candidate.ReplaceAllStringFunc(line, a.replace)
+cat "$@" | awk '
+  # yupsh: netip.ParseAddr(s), for IPv4: four numbers up to 255, no leading zeros
+  function v4(s,   n, p, i) {
+    if (s !~ /^[0-9]+\.[0-9]+\.[0-9]+\.[0-9]+$/) return 0
+    n = split(s, p, ".")
+    for (i = 1; i <= n; i++)
+      if (length(p[i]) > 3 || p[i] + 0 > 255 || p[i] ~ /^0[0-9]/) return 0
+    return 1
+  }
+
+  # yupsh: address(s), for IPv6: eight groups of up to four hex digits, or
+  # fewer with one "::", and the last two may be an IPv4 address; and a
+  # digit somewhere, so Foo::Bad and dead::beef are not addresses
+  function v6(s,   t, doubles, n, g, i, groups) {
+    if (s !~ /[0-9]/) return 0
+    t = s; doubles = gsub(/::/, "", t)
+    if (doubles > 1 || s ~ /:::/) return 0
+    if (s ~ /^:[^:]/ || s ~ /[^:]:$/) return 0
+    n = split(s, g, ":")
+    groups = 0
+    for (i = 1; i <= n; i++) {
+      if (g[i] == "") continue
+      if (i == n && g[i] ~ /\./) {
+        if (!v4(g[i])) return 0
+        groups += 2
+      } else if (g[i] ~ /^[0-9A-Fa-f]+$/ && length(g[i]) <= 4) {
+        groups++
+      } else {
+        return 0
+      }
+    }
+    return doubles ? groups < 8 : groups == 8
+  }
+
+  # yupsh: a.token(addr), with addr.Unmap() and the HMAC of its text
+  function token(addr,   cmd, line, f, n) {
+    addr = tolower(addr)
+    if (addr ~ /^::ffff:[0-9.]+$/) addr = substr(addr, 8)
+    if (addr in tokens) return tokens[addr]
+    cmd = "printf %s " addr " | openssl dgst -sha256 -hmac \"${KEY}\""
+    cmd | getline line
+    close(cmd)
+    n = split(line, f, " ")
+    return tokens[addr] = "ip-" substr(f[n], 1, 12)
+  }
+
+  # yupsh: a.replace(run)
+  function replace(m,   body, label, out, rest, hit, before, after) {
+    if (v4(m) || v6(m)) return token(m)
+    # A sentence or a label can end right after an address: "from ::1."
+    if (match(m, /[.:]+$/) && RSTART > 1)
+      return replace(substr(m, 1, RSTART - 1)) substr(m, RSTART)
+    # A label such as "ip:" runs into the address after it
+    if (match(m, /^[^:]+:/)) {
+      label = substr(m, 1, RLENGTH); rest = substr(m, RLENGTH + 1)
+      if (v4(rest) || v6(rest)) return label token(rest)
+    }
+    # An IPv4 address can still be part of a longer run; like \b, the
+    # match must not be part of a longer word or number
+    out = ""; rest = m
+    while (match(rest, /[0-9]+\.[0-9]+\.[0-9]+\.[0-9]+/)) {
+      hit = substr(rest, RSTART, RLENGTH)
+      before = out substr(rest, 1, RSTART - 1)
+      after = substr(rest, RSTART + RLENGTH)
+      if (v4(hit) && before !~ /[[:alnum:]_]$/ && after !~ /^[[:alnum:]_]/) hit = token(hit)
+      out = before hit
+      rest = after
+    }
+    return out rest
+  }
+
+  # yupsh: candidate.ReplaceAllStringFunc(line, a.replace); a run of word
+  # characters, colons and dots is matched whole, so never from the middle
+  # of a word
+  {
+    out = ""; rest = $0
+    while (match(rest, /[[:alnum:]_:.]*[:.][[:alnum:]_:.]*/)) {
+      hit = substr(rest, RSTART, RLENGTH)
+      out = out substr(rest, 1, RSTART - 1)
+      rest = substr(rest, RSTART + RLENGTH)
+      out = out replace(hit)
+    }
+    print out rest
+  }'
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"os"
+	"regexp"
+	"strings"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// candidate matches a run of word characters, colons and dots with a colon
+// or a dot in it: an address, perhaps with a label run into it
+// ("ip:203.0.113.5") or a full stop after it, or a clock time, version
+// number or name such as core::fmt::write. A run is matched whole, so an
+// address is never taken out of the middle of a word; replace decides which
+// runs are, or hold, an address.
+var candidate = regexp.MustCompile(`[\w:.]*[:.][\w:.]*`)
+
+// ipv4 matches a dotted IPv4 address inside a run that isn't one address,
+// such as "10:00:192.0.2.1"
+var ipv4 = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}\b`)
+
+// tokenLen is the number of hex digits of the HMAC kept in a pseudonym: 48
+// bits, so even a million distinct addresses are unlikely to share one
+const tokenLen = 12
+
+// Replace the IP addresses in logs with consistent pseudonyms, so logs can
+// be shared without the real addresses
+// Shell equivalent: See anon-ip.sh
+//
+//   anon-ip --key s3cret access.log
+//   2026-03-01 10:02:11 192.0.2.10 GET /index.html 200
+//   ->  2026-03-01 10:02:11 ip-046fffb79a24 GET /index.html 200
+//
+// Each IPv4 or IPv6 address becomes "ip-" and the first 12 hex digits of its
+// HMAC-SHA256 under --key, so the same address always gets the same
+// pseudonym, and different addresses different ones, while the address
+// can't be recovered without the key. (A plain hash would not do: there are
+// few enough IPv4 addresses to hash them all and look the result up.)
+// Addresses are compared as addresses, not text, so 2001:DB8:0:0::7 and
+// 2001:db8::7 are the same, and so are ::ffff:192.0.2.1 and 192.0.2.1.
+//
+// Without --key, a random key is made for the run: pseudonyms are
+// consistent within the run but not across runs. Give the same --key to
+// anonymize several logs so that they can still be correlated.
+//
+// Usage: anon-ip [--key SECRET] [file...]
+func main() {
+	opts := flags.New("anon-ip", "[file...]")
+	key := opts.String("key", "", "secret `key` for the pseudonyms (default: random for each run)")
+	opts.Parse()
+
+	secret := []byte(*key)
+	if len(secret) == 0 {
+		secret = make([]byte, sha256.Size)
+		rand.Read(secret)
+	}
+	a := &anonymizer{key: secret, tokens: map[netip.Addr]string{}}
+
+	// Shell: sed -E 's/ADDRESS/ip-TOKEN/g' "$@"
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		While(a.line, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "anon-ip: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// anonymizer holds the key, and the pseudonym of each address seen so far
+type anonymizer struct {
+	key    []byte
+	tokens map[netip.Addr]string
+}
+
+// line is the While() callback: it echoes the line with every address
+// replaced by its pseudonym
+// Shell: awk '{ while (match(rest, ADDRESS)) ... }'
+func (a *anonymizer) line(args ...any) gloo.Command {
+	return echo.Echo(candidate.ReplaceAllStringFunc(args[0].(string), a.replace))
+}
+
+// replace returns a candidate run with the address in it replaced, or
+// unchanged if it holds none
+func (a *anonymizer) replace(run string) string {
+	if addr, ok := address(run); ok {
+		return a.token(addr)
+	}
+
+	// A sentence or a label can end right after an address: "from ::1."
+	if body := strings.TrimRight(run, ".:"); body != run && body != "" {
+		return a.replace(body) + run[len(body):]
+	}
+
+	// A label such as "ip:" runs into the address after it
+	if label, rest, ok := strings.Cut(run, ":"); ok && label != "" {
+		if addr, ok := address(rest); ok {
+			return label + ":" + a.token(addr)
+		}
+	}
+
+	// Otherwise an IPv4 address can still be part of a longer run, such as
+	// "10:00:192.0.2.1"
+	return ipv4.ReplaceAllStringFunc(run, func(match string) string {
+		if addr, ok := address(match); ok {
+			return a.token(addr)
+		}
+		return match
+	})
+}
+
+// address parses s as an IP address. An IPv6 address must have a digit in
+// it: ::1 and 2001:db8::7 are addresses, but names such as Foo::Bad and
+// dead::beef, which netip.ParseAddr accepts too, are left alone.
+func address(s string) (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(s)
+	if err != nil || addr.Is6() && !strings.ContainsAny(s, "0123456789") {
+		return netip.Addr{}, false
+	}
+	return addr, true
+}
+
+// token returns the pseudonym of addr: "ip-" and the start of the HMAC of
+// its canonical form
+//
+// Shell equivalent:
+//   printf '%s' "${addr}" | openssl dgst -sha256 -hmac "${KEY}" | cut -c1-12
+func (a *anonymizer) token(addr netip.Addr) string {
+	addr = addr.Unmap() // ::ffff:192.0.2.1 is 192.0.2.1
+	if t, ok := a.tokens[addr]; ok {
+		return t
+	}
+
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(addr.String()))
+	t := "ip-" + hex.EncodeToString(mac.Sum(nil))[:tokenLen]
+	a.tokens[addr] = t
+	return t
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/netip"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	pipe `github.com/gloo-foo/pipe`
+	input `github.com/yupsh/script-examples/internal/input`
+	. `github.com/yupsh/while`
+)
+
+// anonymize runs text through the pipeline main() runs, with key
+func anonymize(t *testing.T, key, text string) string {
+	t.Helper()
+	a := &anonymizer{key: []byte(key), tokens: map[netip.Addr]string{}}
+	var stdout, stderr bytes.Buffer
+	err := pipe.Pipeline(
+		input.Source(),
+		While(a.line, input.WholeLine),
+	).Executor()(context.Background(), strings.NewReader(text), &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("anon-ip: %v\n%s", err, stderr.String())
+	}
+	return stdout.String()
+}
+
+// token matches a pseudonym
+var token = regexp.MustCompile(`ip-[0-9a-f]{12}`)
+
+// TestSameAddress checks that an address gets the same pseudonym on every
+// line, however it is spelled
+func TestSameAddress(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+	}{
+		{"IPv4", []string{"from 192.0.2.10 GET /", "GET / (forwarded for 192.0.2.10)", "upstream ip:192.0.2.10."}},
+		{"IPv6", []string{"from 2001:db8::7 GET /", "client=[2001:DB8:0:0::7]:51344", "at 2001:db8:0:0:0:0:0:7"}},
+		{"mapped", []string{"peer ::ffff:198.51.100.23 retried", "peer 198.51.100.23 retried"}},
+		{"loopback", []string{"listening on ::1", "connect from ::1."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Split(strings.TrimSuffix(anonymize(t, "k", strings.Join(tt.lines, "\n")+"\n"), "\n"), "\n")
+			var want string
+			for i, line := range got {
+				tokens := token.FindAllString(line, -1)
+				if len(tokens) != 1 {
+					t.Fatalf("line %d %q has %d pseudonyms, want 1", i+1, line, len(tokens))
+				}
+				if want == "" {
+					want = tokens[0]
+				}
+				if tokens[0] != want {
+					t.Errorf("line %d %q has %s, want %s as on line 1", i+1, line, tokens[0], want)
+				}
+			}
+		})
+	}
+}
+
+// TestDifferentAddresses checks that different addresses get different
+// pseudonyms, and that the key decides them
+func TestDifferentAddresses(t *testing.T) {
+	addrs := []string{"192.0.2.10", "192.0.2.11", "198.51.100.23", "2001:db8::7", "2001:db8::8", "::1", "10.0.0.1"}
+	got := token.FindAllString(anonymize(t, "k", strings.Join(addrs, "\n")+"\n"), -1)
+	if len(got) != len(addrs) {
+		t.Fatalf("got %d pseudonyms %q for %d addresses", len(got), got, len(addrs))
+	}
+	seen := map[string]string{}
+	for i, tok := range got {
+		if other, ok := seen[tok]; ok {
+			t.Errorf("%s and %s both became %s", other, addrs[i], tok)
+		}
+		seen[tok] = addrs[i]
+	}
+
+	if a, b := anonymize(t, "k", "192.0.2.10\n"), anonymize(t, "other", "192.0.2.10\n"); a == b {
+		t.Errorf("keys k and other both give %q", a)
+	}
+}
+
+// TestNotAddresses checks that text that isn't an address is left as it
+// is, names with "::" in them included
+func TestNotAddresses(t *testing.T) {
+	lines := []string{
+		"panic at core::fmt::write",
+		"Foo::Bad called",
+		"std::io::Error in dead::beef",
+		"e::f and a::b",
+		"at 10:02:11.123 on 2026-03-01",
+		"from mac 00:1a:2b:3c:4d:5e",
+		"version 1.2.3 on 999.1.1.1",
+		"build v1.2.3.4 and x10.0.0.1",
+		"ratio 3:2, time 12:30:",
+		"::",
+	}
+	text := strings.Join(lines, "\n") + "\n"
+	if got := anonymize(t, "k", text); got != text {
+		t.Errorf("anon-ip changed lines without addresses:\n%s\nwant\n%s", got, text)
+	}
+}
+
+// TestAccessLog checks the README's example
+func TestAccessLog(t *testing.T) {
+	log, err := os.ReadFile("access.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `2026-03-01 10:02:11 ip-046fffb79a24 GET /index.html 200
+2026-03-01 10:02:12 ip-743f60033acb GET /about.html 200
+2026-03-01 10:02:15 ip-046fffb79a24 GET /login 302
+2026-03-01 10:02:40 ip-fc4fade71f1e POST /login 401 (forwarded for ip-046fffb79a24)
+2026-03-01 10:03:05 client=[ip-743f60033acb]:51344 GET /index.html 200
+2026-03-01 10:03:30 peer ip-fc4fade71f1e retried, upstream ip:ip-3114912d6bae
+2026-03-01 10:04:00 version 1.2.3 on 999.1.1.1 from mac 00:1a:2b:3c:4d:5e ok
+`
+	if got := anonymize(t, "s3cret", string(log)); got != want {
+		t.Errorf("anon-ip --key s3cret access.log:\n%s\nwant\n%s", got, want)
+	}
+}
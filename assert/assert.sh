@@ -0,0 +1,77 @@
+#!/bin/bash
+
+# Check the output of a pipeline, and fail if it isn't what was expected
+# yupsh equivalent: See main.go
+#
+# The patterns are POSIX extended regexps here (awk); the Go version takes
+# RE2, which is the same for everyday patterns.
+
+# Parse flags (--match RE, --no-match RE, --expect-lines N, --quiet), then
+# the files
+# yupsh: match := opts.List("match", ...); noMatch := opts.List("no-match", ...); ...
+MATCH=
+NO_MATCH=
+LINES=-1
+QUIET=0
+usage() {
+  echo "Usage: $0 [--match RE]... [--no-match RE]... [--expect-lines N] [--quiet] [file...]" >&2
+  exit 2
+}
+while [[ $1 == --* ]]; do
+  case $1 in
+    --match) MATCH+=$2$'\n'; shift 2 ;;
+    --no-match) NO_MATCH+=$2$'\n'; shift 2 ;;
+    --expect-lines) LINES=$2; shift 2 ;;
+    --quiet) QUIET=1; shift ;;
+    *) usage ;;
+  esac
+done
+if [[ ! ${LINES} =~ ^(-1|[0-9]+)$ ]]; then
+  usage
+fi
+
+# Copy the input through, noting what each line satisfies, and check once
+# it ends; the patterns come through the environment, which, unlike awk -v,
+# leaves their backslashes alone
+# yupsh: c.check(), then c.failures()
+cat "$@" | MATCH=${MATCH} NO_MATCH=${NO_MATCH} awk -v lines="${LINES}" -v quiet="${QUIET}" '
+  BEGIN {
+    # Each pattern ends with a newline, so the last field is always empty
+    nmatch = split(ENVIRON["MATCH"], match_re, "\n") - 1
+    nnomatch = split(ENVIRON["NO_MATCH"], no_match_re, "\n") - 1
+    if (nmatch < 0) nmatch = 0
+    if (nnomatch < 0) nnomatch = 0
+  }
+  {
+    if (!quiet) print
+    for (i = 1; i <= nmatch; i++)
+      if ($0 ~ match_re[i]) matched[i] = 1
+    for (i = 1; i <= nnomatch; i++)
+      if (!(i in hit) && $0 ~ no_match_re[i]) { hit[i] = $0; hit_at[i] = NR }
+  }
+  END {
+    failed = 0
+    if (nmatch == 0 && nnomatch == 0 && lines < 0 && NR == 0) {
+      print "assert: the input is empty" > "/dev/stderr"; failed = 1
+    }
+    for (i = 1; i <= nmatch; i++)
+      if (!(i in matched)) {
+        print "assert: no line matches \"" match_re[i] "\"" > "/dev/stderr"; failed = 1
+      }
+    for (i = 1; i <= nnomatch; i++)
+      if (i in hit) {
+        print "assert: line " hit_at[i] " matches \"" no_match_re[i] "\": " hit[i] > "/dev/stderr"; failed = 1
+      }
+    if (lines >= 0 && NR != lines) {
+      print "assert: expected " lines " line(s), got " NR > "/dev/stderr"; failed = 1
+    }
+    exit failed
+  }'
+
+# A file cat couldn't read is an error, not a failed check
+# yupsh: os.Exit(2) on a pipeline error, os.Exit(1) on a failed check
+status=("${PIPESTATUS[@]}")
+if (( status[0] != 0 )); then
+  exit 2
+fi
+exit "${status[1]}"
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Check the output of a pipeline, and fail if it isn't what was expected
+// Shell equivalent: See assert.sh
+//
+//   assert --expect-lines 4 --no-match degraded health.txt
+//   web     ok      12ms
+//   ...
+//   assert: line 3 matches "degraded": cache   degraded 250ms
+//
+// The input is copied to stdout as it is read (or dropped, with --quiet),
+// so assert can sit at the end of a pipeline or in the middle of one; the
+// checks are made once it ends:
+//   --match RE         some line matches RE (grep -q RE)
+//   --no-match RE      no line does (! grep -q RE)
+//   --expect-lines N   there are exactly N lines (wc -l)
+// --match and --no-match may be given more than once, and every check given
+// is made. With none of them, the check is that the input isn't empty.
+//
+// The exit status is 0 when every check passes, 1 when one fails, after a
+// message on stderr for each failure, and 2 on an error, so a CI script can
+// guard a step with it:
+//   make report | assert --match '^total' > report.txt || exit 1
+//
+// Usage: assert [--match RE]... [--no-match RE]... [--expect-lines N] [--quiet] [file...]
+func main() {
+	opts := flags.New("assert", "[file...]")
+	match := opts.List("match", "fail unless some line matches `regexp`; repeatable")
+	noMatch := opts.List("no-match", "fail if any line matches `regexp`; repeatable")
+	lines := opts.Int("expect-lines", -1, "fail unless there are exactly `N` lines (-1: any number)")
+	quiet := opts.Bool("quiet", false, "don't copy the input to stdout")
+	opts.Parse()
+	if *lines < -1 {
+		opts.Fail("--expect-lines must not be negative")
+	}
+
+	c := &checker{lines: *lines, quiet: *quiet}
+	for _, p := range *match {
+		c.match = append(c.match, mustCompile(opts, p))
+	}
+	for _, p := range *noMatch {
+		c.noMatch = append(c.noMatch, mustCompile(opts, p))
+	}
+
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		c.check(),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "assert: %s\n", status.Message(err))
+		os.Exit(2)
+	}
+
+	failures := c.failures()
+	for _, f := range failures {
+		fmt.Fprintf(os.Stderr, "assert: %s\n", f)
+	}
+	if len(failures) > 0 {
+		os.Exit(1)
+	}
+}
+
+// mustCompile compiles a --match or --no-match pattern, or fails with a
+// usage error
+func mustCompile(opts *flags.Set, pattern string) *regexp.Regexp {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		opts.Fail("invalid pattern: %v", err)
+	}
+	return re
+}
+
+// checker holds the checks, and what the input showed about them
+type checker struct {
+	match   []*regexp.Regexp
+	noMatch []*regexp.Regexp
+	lines   int // -1: not checked
+	quiet   bool
+
+	n       int          // lines read
+	matched map[int]bool // the --match patterns some line matched, by index
+	hits    map[int]hit  // the first line to match each --no-match pattern
+}
+
+// hit is a line that matched a --no-match pattern
+type hit struct {
+	n    int
+	line string
+}
+
+// check returns the command that copies stdin to stdout, or not with
+// --quiet, and notes what each line satisfies
+//
+// Shell equivalent:
+//   awk '{print; n++} $0 ~ re {matched = 1} END {...}'
+func (c *checker) check() gloo.Command {
+	c.matched = map[int]bool{}
+	c.hits = map[int]hit{}
+
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		if c.quiet {
+			stdout = io.Discard
+		}
+		out := bufio.NewWriter(stdout)
+		scanner := bufio.NewScanner(stdin)
+		scanner.Buffer(nil, 1<<30)
+		for scanner.Scan() {
+			line := scanner.Text()
+			c.n++
+			for i, re := range c.match {
+				if !c.matched[i] && re.MatchString(line) {
+					c.matched[i] = true
+				}
+			}
+			for i, re := range c.noMatch {
+				if _, seen := c.hits[i]; !seen && re.MatchString(line) {
+					c.hits[i] = hit{c.n, line}
+				}
+			}
+			if _, err := fmt.Fprintln(out, line); err != nil {
+				return err
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return out.Flush()
+	})
+}
+
+// failures returns a message for each check that failed
+func (c *checker) failures() []string {
+	var failures []string
+	if len(c.match) == 0 && len(c.noMatch) == 0 && c.lines < 0 && c.n == 0 {
+		// Shell: [[ -n $(cat) ]]
+		failures = append(failures, "the input is empty")
+	}
+	for i, re := range c.match {
+		if !c.matched[i] {
+			failures = append(failures, fmt.Sprintf("no line matches \"%s\"", re))
+		}
+	}
+	for i, re := range c.noMatch {
+		if h, ok := c.hits[i]; ok {
+			failures = append(failures, fmt.Sprintf("line %d matches \"%s\": %s", h.n, re, h.line))
+		}
+	}
+	if c.lines >= 0 && c.n != c.lines {
+		failures = append(failures, fmt.Sprintf("expected %d line(s), got %d", c.lines, c.n))
+	}
+	return failures
+}
@@ -0,0 +1,24 @@
+#!/bin/bash
+set -e -o pipefail
+
+# Base64-encode or decode a stream
+# yupsh equivalent: See main.go
+
+# Parse flags (--decode, --wrap N), then the file arguments (stdin when none)
+# yupsh: decodeMode := opts.Bool("decode", ...); wrap := opts.Int("wrap", 76, ...)
+DECODE=
+WRAP=76
+while [[ $1 == --* ]]; do
+  case $1 in
+    --decode) DECODE=1; shift ;;
+    --wrap) WRAP=$2; shift 2 ;;
+    *) echo "Usage: $0 [--decode] [--wrap 76] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# yupsh: input.Source(opts.Args()...), then encode(*wrap) or decode()
+if [[ -n ${DECODE} ]]; then
+  cat "$@" | base64 --decode
+else
+  cat "$@" | base64 -w "${WRAP}"
+fi
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Base64-encode or decode a stream
+// Shell equivalent: See base64.sh
+//
+// Unlike the other examples, this data is not line-oriented: the input may be
+// binary, and newlines in it are just bytes. So instead of While() or a line
+// transform, the work is done by custom gloo.Commands that stream bytes
+// through encoding/base64's Encoder and Decoder with io.Copy. Nothing is
+// buffered beyond io.Copy's 32KB chunks, so input size doesn't matter.
+//
+// Usage: base64 [--decode] [--wrap 76] [file...]
+//   --wrap 0 disables line wrapping when encoding
+func main() {
+	opts := flags.New("base64", "[file...]")
+	decodeMode := opts.Bool("decode", false, "decode base64 input instead of encoding")
+	wrap := opts.Int("wrap", 76, "wrap encoded lines after `N` characters (0 = no wrapping)")
+	opts.Parse()
+
+	// Pick the transform
+	// Shell: base64 -w "${WRAP}"  or  base64 --decode
+	transform := encode(*wrap)
+	if *decodeMode {
+		transform = decode()
+	}
+
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+
+		// Read the named files, or stdin when there are none
+		// Shell: cat "$@"
+		input.Source(opts.Args()...),
+
+		transform,
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "base64: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// encode returns a command that base64-encodes stdin, wrapping the output
+// every width characters
+//
+// Shell equivalent:
+//   base64 -w 76
+//
+// The encoder writes through a wrapWriter, which inserts the newlines, so
+// wrapping also happens in a streaming fashion.
+func encode(width int) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		w := &wrapWriter{w: stdout, width: width}
+		enc := base64.NewEncoder(base64.StdEncoding, w)
+
+		if _, err := io.Copy(enc, stdin); err != nil {
+			return err
+		}
+
+		// Close flushes the final partial block (and its "=" padding)
+		if err := enc.Close(); err != nil {
+			return err
+		}
+
+		// Like base64(1), end the last wrapped line with a newline
+		// (with --wrap 0 the output is a single unterminated line)
+		if w.width > 0 && w.col > 0 {
+			_, err := io.WriteString(stdout, "\n")
+			return err
+		}
+		return nil
+	})
+}
+
+// decode returns a command that decodes base64 from stdin
+//
+// Shell equivalent:
+//   base64 --decode
+//
+// base64.NewDecoder ignores "\r" and "\n", so wrapped input from encode (or
+// any other tool) is accepted as-is.
+func decode() gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		_, err := io.Copy(stdout, base64.NewDecoder(base64.StdEncoding, stdin))
+		return err
+	})
+}
+
+// wrapWriter inserts a newline after every width bytes written through it
+//
+// Shell equivalent:
+//   fold -w 76
+type wrapWriter struct {
+	w     io.Writer
+	width int // 0 disables wrapping
+	col   int // Bytes written on the current line
+}
+
+// Write copies p to the underlying writer, splitting it at line boundaries
+func (ww *wrapWriter) Write(p []byte) (int, error) {
+	if ww.width <= 0 {
+		n, err := ww.w.Write(p)
+		ww.col += n
+		return n, err
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// Fill the rest of the current line
+		chunk := min(ww.width-ww.col, len(p))
+		n, err := ww.w.Write(p[:chunk])
+		written += n
+		ww.col += n
+		if err != nil {
+			return written, err
+		}
+		p = p[chunk:]
+
+		// Line is full: start a new one
+		if ww.col == ww.width {
+			if _, err := io.WriteString(ww.w, "\n"); err != nil {
+				return written, err
+			}
+			ww.col = 0
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"math/rand/v2"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+
+	gloo `github.com/gloo-foo/framework`
+)
+
+// run runs cmd over stdin and returns what it wrote
+func run(t *testing.T, cmd gloo.Command, stdin []byte) []byte {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	if err := cmd.Executor()(context.Background(), bytes.NewReader(stdin), &stdout, &stderr); err != nil {
+		t.Fatalf("run: %v\n%s", err, stderr.String())
+	}
+	return stdout.Bytes()
+}
+
+// samples are the inputs of the round trips: every length around the
+// 3-byte blocks and the line widths, every byte value, and a large input
+// that spans several of io.Copy's chunks
+func samples() [][]byte {
+	rng := rand.New(rand.NewPCG(597, 1))
+	random := func(n int) []byte {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = byte(rng.UintN(256))
+		}
+		return b
+	}
+
+	var all [][]byte
+	for n := range 120 {
+		all = append(all, random(n))
+	}
+	every := make([]byte, 256)
+	for i := range every {
+		every[i] = byte(i)
+	}
+	return append(all,
+		every,
+		[]byte("line one\nline two\r\n\x00\xff"),
+		bytes.Repeat([]byte{0}, 1000),
+		random(100_003),
+	)
+}
+
+// TestRoundTrip checks that decoding what encode wrote gives back the input
+// exactly, for each --wrap
+func TestRoundTrip(t *testing.T) {
+	for _, width := range []int{0, 1, 3, 4, 76, 80} {
+		t.Run("wrap="+strconv.Itoa(width), func(t *testing.T) {
+			for _, in := range samples() {
+				encoded := run(t, encode(width), in)
+				if back := run(t, decode(), encoded); !bytes.Equal(back, in) {
+					t.Fatalf("%d bytes came back as %d different ones", len(in), len(back))
+				}
+				checkWrapped(t, encoded, in, width)
+			}
+		})
+	}
+}
+
+// checkWrapped checks the encoded text is the standard encoding of in,
+// split into lines of width characters, the last one shorter, each ending
+// in a newline; or, for width 0, one line without a newline
+func checkWrapped(t *testing.T, encoded, in []byte, width int) {
+	t.Helper()
+	want := base64.StdEncoding.EncodeToString(in)
+	if width == 0 {
+		if string(encoded) != want {
+			t.Errorf("--wrap 0 of %d bytes: %q, want %q", len(in), encoded, want)
+		}
+		return
+	}
+
+	var b strings.Builder
+	for len(want) > 0 {
+		n := min(width, len(want))
+		b.WriteString(want[:n] + "\n")
+		want = want[n:]
+	}
+	if string(encoded) != b.String() {
+		t.Errorf("--wrap %d of %d bytes:\n%q\nwant\n%q", width, len(in), encoded, b.String())
+	}
+}
+
+// TestDecodeWrapped checks that decode accepts CRLF line endings and other
+// tools' wrapping, and refuses text that isn't base64
+func TestDecodeWrapped(t *testing.T) {
+	in := []byte("hello, world\n")
+	for _, encoded := range []string{
+		"aGVsbG8sIHdvcmxkCg==",
+		"aGVsbG8sIHdvcmxkCg==\n",
+		"aGVs\nbG8s\nIHdv\ncmxk\nCg==\n",
+		"aGVsbG8sIHdv\r\ncmxkCg==\r\n",
+	} {
+		if got := run(t, decode(), []byte(encoded)); !bytes.Equal(got, in) {
+			t.Errorf("decode(%q) = %q, want %q", encoded, got, in)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := decode().Executor()(context.Background(), strings.NewReader("not base64!"), &stdout, &stderr)
+	if err == nil {
+		t.Errorf("decode of invalid input succeeded: %q", stdout.String())
+	}
+}
+
+// TestMatchesCoreutils checks the output is byte for byte what base64(1)
+// prints, where it is installed
+func TestMatchesCoreutils(t *testing.T) {
+	if _, err := exec.LookPath("base64"); err != nil {
+		t.Skip("no base64(1) to compare with")
+	}
+	for _, width := range []int{0, 76, 5} {
+		for _, in := range samples()[:40] {
+			cmd := exec.Command("base64", "-w", strconv.Itoa(width))
+			cmd.Stdin = bytes.NewReader(in)
+			want, err := cmd.Output()
+			if err != nil {
+				t.Skipf("base64 -w: %v", err)
+			}
+			if got := run(t, encode(width), in); !bytes.Equal(got, want) {
+				t.Errorf("--wrap %d of %d bytes: %q, base64(1) printed %q", width, len(in), got, want)
+			}
+		}
+	}
+}
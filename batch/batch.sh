@@ -0,0 +1,62 @@
+#!/bin/bash
+
+# Group lines into batches of N, one batch per output record, for bulk APIs
+# yupsh equivalent: See main.go
+
+# Parse flags (--size N, --format json|lines, --separator TEXT), then the
+# files
+# yupsh: size := opts.Int("size", 100, ...); format := opts.Format("json", "lines"); ...
+SIZE=100
+FORMAT=json
+SEPARATOR=---
+usage() {
+  echo "Usage: $0 [--size N] [--format json|lines] [--separator TEXT] [file...]" >&2
+  exit 2
+}
+while [[ $1 == --* ]]; do
+  case $1 in
+    --size) SIZE=$2; shift 2 ;;
+    --format) FORMAT=$2; shift 2 ;;
+    --separator) SEPARATOR=$2; shift 2 ;;
+    *) usage ;;
+  esac
+done
+if [[ ! ${SIZE} =~ ^[0-9]+$ ]] || (( SIZE < 1 )); then
+  usage
+fi
+if [[ ${FORMAT} != json && ${FORMAT} != lines ]]; then
+  usage
+fi
+
+# Flush every N lines, and once more at the end for the remainder; END
+# flushes only when lines are left, so there is never an empty batch
+# yupsh: batch(*size, write)
+set -o pipefail
+cat "$@" | SEPARATOR=${SEPARATOR} awk -v size="${SIZE}" -v format="${FORMAT}" '
+  function quote(s,   n, parts, i, t) {
+    # Double each backslash; gsub replacement escapes differ between awks
+    n = split(s, parts, /\\/)
+    t = parts[1]
+    for (i = 2; i <= n; i++) t = t "\\\\" parts[i]
+    s = t
+    gsub(/"/, "\\\"", s)
+    gsub(/\t/, "\\t", s)
+    gsub(/\r/, "\\r", s)
+    return "\"" s "\""
+  }
+  function flush(   i) {
+    if (format == "json") {
+      printf "["
+      for (i = 1; i <= c; i++) printf "%s%s", (i > 1 ? "," : ""), quote(b[i])
+      print "]"
+    } else {
+      if (batches > 0) print ENVIRON["SEPARATOR"]
+      for (i = 1; i <= c; i++) print b[i]
+    }
+    batches++
+    c = 0
+    fflush()
+  }
+  { b[++c] = $0 }
+  c == size { flush() }
+  END { if (c > 0) flush() }'
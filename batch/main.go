@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Group lines into batches of N, one batch per output record, for bulk APIs
+// Shell equivalent: See batch.sh
+//
+//   batch --size 3 ids.txt
+//   ["a1","a2","a3"]
+//   ["a4","a5","a6"]
+//   ["a7"]
+//
+// A batch is written as soon as it holds --size lines, and once more when
+// the input ends, with whatever is left: 7 lines in batches of 3 make two
+// full batches and one of 1. Empty input makes no batches at all, never an
+// empty one. Only the current batch is held in memory.
+//
+// --format json writes each batch as a JSON array of strings, one array per
+// line, ready for `curl --data @-` or a JSON Lines reader. --format lines
+// writes the batch's lines unchanged, with a --separator line between
+// batches.
+//
+// Usage: batch [--size N] [--format json|lines] [--separator TEXT] [file...]
+func main() {
+	opts := flags.New("batch", "[file...]")
+	size := opts.Int("size", 100, "put `N` lines in each batch")
+	format := opts.Format("json", "lines")
+	separator := opts.String("separator", "---", "line between batches with --format lines")
+	opts.Parse()
+	if *size < 1 {
+		opts.Fail("--size must be at least 1")
+	}
+
+	write := writeJSON
+	if *format == "lines" {
+		write = writeLines(*separator)
+	}
+
+	err := gloo.Run(pipe.Pipeline(
+		// Report a missing file instead of silently producing nothing
+		// Shell: set -o pipefail
+		pipe.PipeFail,
+
+		// Read the named files, or stdin when there are none
+		// Shell: cat "$@"
+		input.Source(opts.Args()...),
+
+		// Flush every N lines, and the remainder at the end
+		// Shell: awk 'NR % N == 0 {flush()} END {flush()}'
+		batch(*size, write),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "batch: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// batchWriter writes batch number n (counting from 0) to out
+type batchWriter func(out *bufio.Writer, n int, lines []string) error
+
+// batch returns the command that collects stdin's lines into batches of
+// size and writes each full batch with write, then the partial one left at
+// EOF, if any
+//
+// Shell equivalent:
+//   awk -v n=N '{b[++c] = $0} c == n {flush()} END {if (c) flush()}'
+func batch(size int, write batchWriter) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		out := bufio.NewWriter(stdout)
+		scanner := bufio.NewScanner(stdin)
+		scanner.Buffer(nil, 1<<30)
+
+		lines := make([]string, 0, size)
+		n := 0
+		flush := func() error {
+			if err := write(out, n, lines); err != nil {
+				return err
+			}
+			n++
+			lines = lines[:0]
+			// Each batch goes out whole, as soon as it is complete, so a
+			// slow producer doesn't hold back the batches already made
+			return out.Flush()
+		}
+
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+			if len(lines) == size {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+
+		// The remainder: fewer than size lines, but never none
+		if len(lines) > 0 {
+			return flush()
+		}
+		return nil
+	})
+}
+
+// writeJSON writes a batch as a JSON array of strings on one line
+// Shell: jq -R . | jq -s -c .
+func writeJSON(out *bufio.Writer, n int, lines []string) error {
+	data, err := json.Marshal(lines)
+	if err != nil {
+		return err
+	}
+	out.Write(data)
+	return out.WriteByte('\n')
+}
+
+// writeLines returns the batchWriter that writes a batch's lines as they
+// are, after a separator line when it isn't the first batch
+// Shell: split -l N --filter 'cat; echo ---'
+func writeLines(separator string) batchWriter {
+	return func(out *bufio.Writer, n int, lines []string) error {
+		if n > 0 {
+			out.WriteString(separator + "\n")
+		}
+		for _, line := range lines {
+			out.WriteString(line + "\n")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,48 @@
+#!/bin/bash
+set -e
+
+# Print the lines between a start marker and an end marker
+# yupsh equivalent: See main.go
+#
+# The patterns are POSIX extended regexps here (awk); the Go version takes
+# RE2, which is the same for everyday patterns.
+
+# Parse flags (--inclusive, --all), then the two patterns and the files
+# yupsh: inclusive := opts.Bool("inclusive", ...); all := opts.Bool("all", ...)
+INCLUSIVE=0
+ALL=0
+usage() {
+  echo "Usage: $0 [--inclusive] [--all] start end [file...]" >&2
+  exit 2
+}
+while [[ $1 == --* ]]; do
+  case $1 in
+    --inclusive) INCLUSIVE=1; shift ;;
+    --all) ALL=1; shift ;;
+    *) usage ;;
+  esac
+done
+if (( $# < 2 )); then
+  usage
+fi
+START=$1
+END=$2
+shift 2
+
+# Track whether we're inside a range; the end is only looked for from the
+# line after the start. sed -n '/start/,/end/p' is the --inclusive --all
+# case; the patterns come through the environment, which, unlike awk -v,
+# leaves their backslashes alone
+# yupsh: While(r.line, input.WholeLine) with the ranger state
+cat "$@" | START=${START} END=${END} awk -v inclusive="${INCLUSIVE}" -v all="${ALL}" '
+  inside && $0 ~ ENVIRON["END"] {
+    inside = 0
+    if (!all) done = 1
+    if (inclusive) print
+    next
+  }
+  inside { print; next }
+  !done && $0 ~ ENVIRON["START"] {
+    inside = 1
+    if (inclusive) print
+  }'
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Print the lines between a start marker and an end marker, like
+// sed -n '/start/,/end/p'
+// Shell equivalent: See between.sh
+//
+//   between '# BEGIN upstreams' '# END upstreams' nginx.conf
+//
+// A range opens at the first line matching start, and closes at the next
+// line after it matching end; the end pattern is only looked for from the
+// line after the start, so one line can't open and close a range. A range
+// that is never closed runs to the end of the input, as with sed.
+//
+// By default only the lines inside the range are printed; --inclusive
+// prints the two marker lines too, as sed does. Only the first range is
+// printed unless --all is given, in which case every range is, the input
+// being searched for a new start after each end.
+//
+// Usage: between [--inclusive] [--all] start end [file...]
+func main() {
+	opts := flags.New("between", "start end [file...]")
+	inclusive := opts.Bool("inclusive", false, "print the start and end lines too")
+	all := opts.Bool("all", false, "print every range, not just the first")
+	opts.Parse()
+	if opts.NArg() < 2 {
+		opts.Fail("missing start or end pattern")
+	}
+
+	r := &ranger{
+		start:     mustCompile(opts, opts.Arg(0)),
+		end:       mustCompile(opts, opts.Arg(1)),
+		inclusive: *inclusive,
+		all:       *all,
+	}
+
+	// Shell: sed -n '/start/,/end/p' "$@"
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()[2:]...),
+		While(r.line, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "between: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// mustCompile compiles a marker pattern, or fails with a usage error
+func mustCompile(opts *flags.Set, pattern string) *regexp.Regexp {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		opts.Fail("invalid pattern: %v", err)
+	}
+	return re
+}
+
+// ranger is the state the While() callback carries from line to line
+//
+// Shell equivalent:
+//   awk 'inside && /end/ {inside = 0; done = 1; next}
+//        inside {print}
+//        !done && /start/ {inside = 1}'
+type ranger struct {
+	start, end     *regexp.Regexp
+	inclusive, all bool
+
+	inside bool // a start line has been read, and its end line not yet
+	done   bool // the first range has closed, and --all is off
+}
+
+// line is the While() callback: it echoes the lines inside a range, and
+// the markers with --inclusive
+func (r *ranger) line(args ...any) gloo.Command {
+	line := args[0].(string)
+
+	switch {
+	case r.inside && r.end.MatchString(line):
+		// The end marker closes the range
+		r.inside = false
+		r.done = !r.all
+		return r.marker(line)
+	case r.inside:
+		return echo.Echo(line)
+	case !r.done && r.start.MatchString(line):
+		// The start marker opens one; the end is looked for from the next
+		// line, as with sed's /start/,/end/
+		r.inside = true
+		return r.marker(line)
+	}
+	return nil
+}
+
+// marker echoes a start or end line with --inclusive, and drops it without
+func (r *ranger) marker(line string) gloo.Command {
+	if !r.inclusive {
+		return nil
+	}
+	return echo.Echo(line)
+}
@@ -0,0 +1,34 @@
+#!/bin/bash
+set -e
+
+# Find files larger than a threshold, largest first
+# yupsh equivalent: See main.go
+
+# Parse flags (--min-size SIZE, --human), then the directory argument
+# yupsh: minSize := opts.Size("min-size", 100<<20, ...); human := opts.Human()
+MIN_SIZE=100M
+HUMAN=
+while [[ $1 == --* ]]; do
+  case $1 in
+    --min-size) MIN_SIZE=$2; shift 2 ;;
+    --human) HUMAN=1; shift ;;
+    *) echo "Usage: $0 [--min-size 100MB] [--human] [directory]" >&2; exit 2 ;;
+  esac
+done
+DIR=${1:-.}
+
+# Convert the human size to bytes
+# yupsh: size.Parse(text)
+MIN_BYTES=$(numfmt --from=iec "${MIN_SIZE%B}")
+
+# Find files over the threshold, largest first
+# yupsh: find.Find(...), While(largerThan(*minSize), input.WholeLine), sort.Sort(...)
+find "${DIR}" -type f -size +"${MIN_BYTES}"c -printf '%s\t%p\n' \
+| sort -t$'\t' -k1,1 -nr \
+| while IFS=$'\t' read -r size path; do
+    # yupsh: While(formatRow(*human), FieldSeparator("\t"))
+    if [[ -n ${HUMAN} ]]; then
+      size=$(numfmt --to=iec --format=%.1f "${size}")
+    fi
+    printf '%10s  %s\n' "${size}" "${path}"
+  done
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	echo `github.com/yupsh/echo`
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	size `github.com/yupsh/script-examples/internal/size`
+	status `github.com/yupsh/script-examples/internal/status`
+	sort `github.com/yupsh/sort`
+	. `github.com/yupsh/while`
+)
+
+// Find files larger than a threshold, largest first
+// Shell equivalent: See bigfiles.sh
+//
+// The ops task of hunting down disk hogs. The threshold is given as a human
+// size (--min-size 100MB, 1.5G, 512K), parsed by the shared size.Parse().
+//
+// Usage: bigfiles [--min-size 100MB] [--human] [directory]
+func main() {
+	// Shell: MIN_SIZE=100M; DIR=${1:-.}
+	opts := flags.New("bigfiles", "[directory]")
+	minSize := opts.Size("min-size", 100<<20, "list files larger than `size` (K, M, G suffixes)")
+	human := opts.Human()
+	opts.Parse()
+	dir := opts.ArgOr(0, ".")
+
+	err := gloo.Run(pipe.Pipeline(
+		// Find all regular files
+		// Shell: find "${DIR}" -type f
+		find.Find(find.Dir(dir), find.FileType),
+
+		// Keep files over the threshold: "size\tpath"
+		// Shell: -size +"${MIN_SIZE}" -printf '%s\t%p\n'
+		While(largerThan(*minSize), input.WholeLine),
+
+		// Largest first
+		// Shell: sort -t$'\t' -k1,1 -nr
+		sort.Sort(sort.Field(1), sort.Delimiter("\t"), sort.Numeric, sort.Reverse),
+
+		// Align the size column (human-readable with --human)
+		// Shell: numfmt --to=iec --field=1
+		While(formatRow(*human), FieldSeparator("\t")),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bigfiles: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// largerThan returns a While() callback that emits "size\tpath" for files
+// bigger than limit bytes
+//
+// Shell equivalent:
+//   find -size +100M -printf '%s\t%p\n' 2>&1 >/dev/null | sed 's/^/warning: /' >&2
+//
+// Files that can't be stat'ed are skipped with a warning on stderr rather
+// than aborting the walk, like find's "Permission denied" messages.
+func largerThan(limit int64) Body {
+	return func(args ...any) gloo.Command {
+		path := args[0].(string)
+
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bigfiles: warning: %v\n", err)
+			return nil
+		}
+
+		if info.Size() <= limit {
+			return nil
+		}
+		return echo.Echo(fmt.Sprintf("%d\t%s", info.Size(), path))
+	}
+}
+
+// formatRow returns a While() callback that prints "size  path" with the size
+// right-aligned, in human-readable units when human is set
+// Shell: printf '%10s  %s\n' "$size" "$path"
+func formatRow(human bool) Body {
+	return func(args ...any) gloo.Command {
+		if len(args) < 2 {
+			return nil
+		}
+
+		sizeCol := args[0].(string)
+		if human {
+			var n int64
+			fmt.Sscanf(sizeCol, "%d", &n)
+			sizeCol = size.Format(n)
+		}
+		return echo.Echo(fmt.Sprintf("%10s  %s", sizeCol, args[1].(string)))
+	}
+}
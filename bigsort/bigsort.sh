@@ -0,0 +1,37 @@
+#!/bin/bash
+set -e
+
+# Sort input too large to fit in memory (external merge sort)
+# yupsh equivalent: See main.go
+
+# Parse flags (--chunk-lines N, --numeric), then the files
+# yupsh: chunkLines := opts.Int("chunk-lines", 100000, ...); numeric := opts.Bool(...)
+CHUNK_LINES=100000
+NUMERIC=
+while [[ $1 == --* ]]; do
+  case $1 in
+    --chunk-lines) CHUNK_LINES=$2; shift 2 ;;
+    --numeric) NUMERIC=-n; shift ;;
+    *) echo "Usage: $0 [--chunk-lines N] [--numeric] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Temp directory for the sorted chunks, removed on exit
+# yupsh: dir, err := os.MkdirTemp("", "bigsort-"); defer os.RemoveAll(dir)
+DIR=$(mktemp -d -t bigsort-XXXXXX)
+trap 'rm -rf "${DIR}"' EXIT
+
+# Split the input into chunks of CHUNK_LINES lines
+# yupsh: readChunk(scanner, chunkLines)
+cat "$@" | split -l "${CHUNK_LINES}" - "${DIR}/chunk."
+
+# Sort each chunk on its own
+# yupsh: writeSortedChunk(ctx, chunk, lines, sortOpts, stderr)
+for chunk in "${DIR}"/chunk.*; do
+  [[ -e ${chunk} ]] || exit 0  # empty input
+  LC_ALL=C sort ${NUMERIC} "${chunk}" -o "${chunk}"
+done
+
+# Merge the sorted chunks
+# yupsh: merge.Files(less, chunks...)
+LC_ALL=C sort -m ${NUMERIC} "${DIR}"/chunk.*
@@ -0,0 +1,14 @@
+module github.com/yupsh/script-examples/bigsort
+
+go 1.25
+
+require (
+	github.com/gloo-foo/framework v0.0.3
+	github.com/gloo-foo/pipe v0.0.3
+	github.com/yupsh/script-examples/internal v0.0.0
+	github.com/yupsh/sort v0.0.3
+)
+
+require github.com/yupsh/while v0.0.4 // indirect
+
+replace github.com/yupsh/script-examples/internal => ../internal
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	merge `github.com/yupsh/script-examples/internal/merge`
+	status `github.com/yupsh/script-examples/internal/status`
+	sort `github.com/yupsh/sort`
+)
+
+// Sort input too large to fit in memory (external merge sort)
+// Shell equivalent: See bigsort.sh
+//
+// sort.Sort() holds its whole input in memory. bigsort instead:
+//  1. splits the input into chunks of --chunk-lines lines,
+//  2. sorts each chunk with sort.Sort() into a temp file,
+//  3. k-way merges the sorted temp files (internal/merge, as in merge).
+//
+// At most one chunk is in memory at a time, plus one line per temp file
+// during the merge. Input that fits in a single chunk is sorted directly,
+// without temp files. The temp directory is removed on exit, including on
+// Ctrl-C, and when the reader goes away early (bigsort | head).
+//
+// Usage: bigsort [--chunk-lines N] [--numeric] [file...]
+func main() {
+	opts := flags.New("bigsort", "[file...]")
+	chunkLines := opts.Int("chunk-lines", 100000, "sort `N` lines at a time in memory")
+	numeric := opts.Bool("numeric", false, "compare lines numerically")
+	opts.Parse()
+	if *chunkLines < 1 {
+		opts.Fail("--chunk-lines must be at least 1")
+	}
+
+	// Cancel on Ctrl-C so the deferred cleanup still runs
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// A write to a closed stdout would otherwise kill the process with
+	// SIGPIPE, before the cleanup; ignored, the write fails with EPIPE
+	// Shell: trap 'rm -rf "${DIR}"' EXIT runs when sort -m is killed too
+	signal.Ignore(syscall.SIGPIPE)
+
+	// Shell: cat "$@" | split -l N - chunk. ; sort each chunk ; sort -m chunk.*
+	err := gloo.RunWithContext(ctx, pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		externalSort(*chunkLines, *numeric),
+	))
+	if errors.Is(err, syscall.EPIPE) {
+		// The reader has all it wanted: nothing to report
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bigsort: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// externalSort sorts stdin to stdout, chunkLines lines at a time
+func externalSort(chunkLines int, numeric bool) gloo.Command {
+	sortOpts := []any{}
+	less := merge.Lexical
+	if numeric {
+		sortOpts = append(sortOpts, sort.Numeric)
+		less = merge.Numeric
+	}
+
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		dir, err := os.MkdirTemp("", "bigsort-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+
+		var chunks []string
+		scanner := bufio.NewScanner(stdin)
+		for {
+			lines, err := readChunk(scanner, chunkLines)
+			if err != nil {
+				return err
+			}
+			if len(lines) == 0 {
+				break
+			}
+
+			// The whole input fit in one chunk: no merge needed
+			if len(chunks) == 0 && len(lines) < chunkLines {
+				return sortChunk(ctx, lines, sortOpts, stdout, stderr)
+			}
+
+			// Shell: sort chunk.N > chunk.N.sorted
+			chunk := filepath.Join(dir, fmt.Sprintf("chunk.%d", len(chunks)))
+			if err := writeSortedChunk(ctx, chunk, lines, sortOpts, stderr); err != nil {
+				return err
+			}
+			chunks = append(chunks, chunk)
+		}
+		if len(chunks) == 0 {
+			return nil // empty input
+		}
+
+		// Shell: sort -m chunk.*.sorted
+		return merge.Files(less, chunks...).Executor()(ctx, stdin, stdout, stderr)
+	})
+}
+
+// readChunk reads up to n lines; an empty result means the input is done
+func readChunk(scanner *bufio.Scanner, n int) ([]string, error) {
+	var lines []string
+	for len(lines) < n && scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// writeSortedChunk sorts lines into a new file at path
+func writeSortedChunk(ctx context.Context, path string, lines []string, sortOpts []any, stderr io.Writer) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(f)
+	if err := sortChunk(ctx, lines, sortOpts, out, stderr); err != nil {
+		f.Close()
+		return err
+	}
+	if err := out.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// sortChunk runs sort.Sort() over one in-memory chunk
+// Shell: sort [-n] < chunk
+func sortChunk(ctx context.Context, lines []string, sortOpts []any, stdout, stderr io.Writer) error {
+	chunk := strings.NewReader(strings.Join(lines, "\n") + "\n")
+	return sort.Sort(sortOpts...).Executor()(ctx, chunk, stdout, stderr)
+}
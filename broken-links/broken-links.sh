@@ -0,0 +1,52 @@
+#!/bin/bash
+
+# Find symlinks whose targets don't exist
+# yupsh equivalent: See main.go
+
+# Parse flags (--delete, --force), then the directory
+# yupsh: del := opts.Bool("delete", ...); force := opts.Bool("force", ...)
+DELETE=0
+FORCE=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --delete) DELETE=1; shift ;;
+    --force) FORCE=1; shift ;;
+    *) echo "Usage: $0 [--delete [--force]] [directory]" >&2; exit 2 ;;
+  esac
+done
+if (( FORCE && !DELETE )); then
+  echo "broken-links: --force only applies with --delete" >&2
+  exit 2
+fi
+DIR=${1:-.}
+
+# Read confirmations from the original stdin, not from find's output
+# yupsh: answers: bufio.NewReader(os.Stdin)
+exec 3<&0
+
+FAILED=0
+# yupsh: find.Find(find.Dir(dir), find.LinkType) | While(c.link, input.WholeLine)
+while IFS= read -r -d '' link; do
+  # test -e follows the link, like os.Stat()
+  [[ -e ${link} ]] && continue
+
+  # A link that resolves to itself (directly or through others) is a loop
+  # yupsh: errors.Is(err, syscall.ELOOP)
+  why=missing
+  if ! readlink -e -- "${link}" > /dev/null && [[ $(stat -L -- "${link}" 2>&1) == *"Too many levels"* ]]; then
+    why=loop
+  fi
+  printf '%s -> %s (%s)\n' "${link}" "$(readlink -- "${link}")" "${why}"
+
+  if (( DELETE )); then
+    if (( !FORCE )); then
+      # yupsh: c.confirm(path)
+      printf 'remove %s? [y/N] ' "${link}" >&2
+      read -r answer <&3 || { echo >&2; answer=; }
+      [[ ${answer,,} == y* ]] || continue
+    fi
+    rm -- "${link}" || FAILED=1
+  fi
+done < <(find "${DIR}" -type l -print0)
+
+exit "${FAILED}"
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"syscall"
+
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Find symlinks whose targets don't exist
+// Shell equivalent: See broken-links.sh
+//
+//   broken-links ~/bin
+//   /home/me/bin/deploy -> /opt/tools/deploy (missing)
+//   /home/me/bin/self -> self (loop)
+//
+// Every symlink under the directory is checked by following it: os.Lstat()
+// describes the link itself, while os.Stat() describes what it points to,
+// and fails when that is missing (or when the links go round in a loop).
+// A link to another link that resolves is fine.
+//
+// With --delete, each broken link is removed after a y/N confirmation read
+// from stdin, or without asking with --force. Only the links are removed,
+// never their targets.
+//
+// Usage: broken-links [--delete [--force]] [directory]
+func main() {
+	opts := flags.New("broken-links", "[directory]")
+	del := opts.Bool("delete", false, "remove each broken link, after confirming")
+	force := opts.Bool("force", false, "with --delete, remove without asking")
+	opts.Parse()
+	if *force && !*del {
+		opts.Fail("--force only applies with --delete")
+	}
+	dir := opts.ArgOr(0, ".")
+
+	c := &checker{remove: *del, force: *force, answers: bufio.NewReader(os.Stdin)}
+
+	err := gloo.Run(pipe.Pipeline(
+		// List the symlinks; find itself never follows them
+		// Shell: find "${DIR}" -type l
+		find.Find(find.Dir(dir), find.LinkType),
+
+		// Keep the ones whose target can't be reached
+		// Shell: ! test -e "$link"
+		While(c.link, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "broken-links: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+	if c.failed {
+		os.Exit(1)
+	}
+}
+
+// checker reports (and optionally removes) broken links
+type checker struct {
+	remove, force bool
+	answers       *bufio.Reader // confirmations, read from stdin
+	failed        bool
+}
+
+// link is the While() callback: it checks one symlink
+func (c *checker) link(args ...any) gloo.Command {
+	path := args[0].(string)
+
+	// Following the link succeeds: it isn't broken
+	_, err := os.Stat(path)
+	if err == nil {
+		return nil
+	}
+
+	var why string
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		why = "missing"
+	case errors.Is(err, syscall.ELOOP):
+		why = "loop"
+	default:
+		// e.g. permission denied on a directory along the target's path:
+		// the target may well exist, so don't call it broken
+		fmt.Fprintf(os.Stderr, "broken-links: %v\n", err)
+		c.failed = true
+		return nil
+	}
+
+	// Shell: printf '%s -> %s (missing)\n' "$link" "$(readlink "$link")"
+	target, err := os.Readlink(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "broken-links: %v\n", err)
+		c.failed = true
+		return nil
+	}
+	fmt.Printf("%s -> %s (%s)\n", path, target, why)
+
+	if c.remove && (c.force || c.confirm(path)) {
+		// Shell: rm -- "$link"   (rm never follows a symlink)
+		if err := os.Remove(path); err != nil {
+			fmt.Fprintf(os.Stderr, "broken-links: %v\n", err)
+			c.failed = true
+		}
+	}
+	return nil
+}
+
+// confirm asks on stderr whether to remove path; anything but an answer
+// starting with y (including end of input) means no
+// Shell: read -r -p "remove $link? [y/N] " answer < /dev/tty
+func (c *checker) confirm(path string) bool {
+	fmt.Fprintf(os.Stderr, "remove %s? [y/N] ", path)
+	answer, err := c.answers.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(os.Stderr)
+		return false
+	}
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "y")
+}
@@ -0,0 +1,55 @@
+#!/bin/bash
+
+# Concatenate files with a banner naming each one
+# yupsh equivalent: See main.go
+
+# Parse flags (--no-banner), then the files
+# yupsh: noBanner := opts.Bool("no-banner", false, ...)
+NO_BANNER=
+while [[ $1 == --* ]]; do
+  case $1 in
+    --no-banner) NO_BANNER=1; shift ;;
+    *) echo "Usage: $0 [--no-banner] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Stdin alone gets no banner
+# yupsh: files = []string{input.Stdin}; *noBanner = true
+if (( $# == 0 )); then
+  exec cat
+fi
+
+FAILED=
+FIRST=1
+for file in "$@"; do
+  # yupsh: os.Open(file) check before cat.Cat(file)
+  if [[ ${file} != - && ! -r ${file} ]]; then
+    echo "catsep: ${file}: cannot open" >&2
+    FAILED=1
+    continue
+  fi
+
+  if [[ -z ${NO_BANNER} ]]; then
+    # End an unterminated last line, then a blank line between files
+    # yupsh: lineEndWriter tracks whether the output ends in a newline
+    if [[ -z ${FIRST} ]]; then
+      [[ -n ${LAST_CHAR} ]] && echo
+      echo
+    fi
+    label=${file}
+    [[ ${file} == - ]] && label="(standard input)"
+    echo "===== ${label} ====="
+  fi
+  FIRST=
+
+  # yupsh: run(catFile(file), out)
+  if [[ ${file} == - ]]; then
+    cat
+    LAST_CHAR=  # assume stdin ends with a newline
+  else
+    cat "${file}"
+    LAST_CHAR=$(tail -c 1 "${file}")
+  fi
+done
+
+[[ -z ${FAILED} ]]
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	cat `github.com/yupsh/cat`
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+)
+
+// Concatenate files with a banner naming each one, for reviewing many small
+// files at once
+// Shell equivalent: See catsep.sh
+//
+//   catsep go.mod main.go
+//   ===== go.mod =====
+//   module example
+//   ...
+//
+//   ===== main.go =====
+//   package main
+//   ...
+//
+// With --no-banner, or with stdin alone, the output is exactly `cat`'s. A file that doesn't end in
+// a newline gets one before the next banner, so a banner always starts on
+// its own line.
+//
+// cat.Cat() runs once per file inside a plain Go loop that prints the banners
+// in between. cat.Cat() skips files it can't open (and would fall back to
+// stdin), so each file is checked first; an unreadable one is reported and
+// skipped, and the exit status is 1 at the end, like cat.
+//
+// Usage: catsep [--no-banner] [file...]
+func main() {
+	opts := flags.New("catsep", "[file...]")
+	noBanner := opts.Bool("no-banner", false, "print the files only, like cat")
+	opts.Parse()
+
+	// Stdin alone gets no banner
+	// Shell: cat
+	files := opts.Args()
+	if len(files) == 0 {
+		files = []string{input.Stdin}
+		*noBanner = true
+	}
+
+	out := &lineEndWriter{w: os.Stdout, atLineStart: true}
+	failed := false
+	first := true
+	for _, file := range files {
+		// Shell: cat: nope: No such file or directory
+		if file != input.Stdin {
+			f, err := os.Open(file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "catsep: %v\n", err)
+				failed = true
+				continue
+			}
+			f.Close()
+		}
+
+		if !*noBanner {
+			// End an unterminated last line, then a blank line between files
+			// Shell: echo; echo "===== ${file} ====="
+			var banner strings.Builder
+			if !out.atLineStart {
+				banner.WriteString("\n")
+			}
+			if !first {
+				banner.WriteString("\n")
+			}
+			fmt.Fprintf(&banner, "===== %s =====", label(file))
+			if err := run(echo.Echo(banner.String()), out); err != nil {
+				fmt.Fprintf(os.Stderr, "catsep: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		first = false
+
+		// Shell: cat "${file}"
+		if err := run(catFile(file), out); err != nil {
+			fmt.Fprintf(os.Stderr, "catsep: %s: %v\n", file, err)
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// catFile returns cat.Cat() for a file, or for stdin given input.Stdin
+func catFile(file string) gloo.Command {
+	if file == input.Stdin {
+		return cat.Cat()
+	}
+	return cat.Cat(file)
+}
+
+// label names a file in its banner
+func label(file string) string {
+	if file == input.Stdin {
+		return "(standard input)"
+	}
+	return file
+}
+
+// run executes cmd with its output going to w
+func run(cmd gloo.Command, w io.Writer) error {
+	return cmd.Executor()(context.Background(), os.Stdin, w, os.Stderr)
+}
+
+// lineEndWriter passes writes through, remembering whether the output so far
+// ends at the start of a line
+type lineEndWriter struct {
+	w           io.Writer
+	atLineStart bool
+}
+
+func (l *lineEndWriter) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		l.atLineStart = p[len(p)-1] == '\n'
+	}
+	return l.w.Write(p)
+}
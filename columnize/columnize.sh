@@ -0,0 +1,27 @@
+#!/bin/bash
+set -e
+
+# Align delimited rows into padded columns
+# yupsh equivalent: See main.go
+
+# Parse flags (--delim SEP), then the files
+# yupsh: delim := opts.Delim("\t")
+DELIM=$'\t'
+while [[ $1 == --* ]]; do
+  case $1 in
+    --delim) DELIM=$2; shift 2 ;;
+    *) echo "Usage: $0 [--delim TAB] [file...]" >&2; exit 2 ;;
+  esac
+done
+# --delim '\t' means a tab, as in the yupsh version
+[[ ${DELIM} == '\t' ]] && DELIM=$'\t'
+
+# Buffer the rows, then print them padded to the widest cell per column
+# (column merges adjacent separators, so an empty cell shifts the rest of the
+# row left; main.go keeps empty cells in place)
+# yupsh: awk.Awk(&columnizeProgram{}, awk.FieldSeparator(*delim))
+if [[ ${DELIM} == " " ]]; then
+  cat "$@" | column -t
+else
+  cat "$@" | column -t -s "${DELIM}"
+fi
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	awk `github.com/yupsh/awk`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	table `github.com/yupsh/script-examples/internal/table`
+)
+
+// Align delimited rows into padded columns, like `column -t`
+// Shell equivalent: See columnize.sh
+//
+//   printf 'name\tsize\nmain.go\t4120\nREADME.md\t880\n' | columnize
+//   name       size
+//   main.go    4120
+//   README.md  880
+//
+// No line can be printed until the widest cell of every column is known, so
+// this is a two-pass, buffering command: awk's Action() collects the rows,
+// and End() prints them aligned. Empty lines are dropped, like `column -t`.
+//
+// Usage: columnize [--delim TAB] [file...]
+func main() {
+	opts := flags.New("columnize", "[file...]")
+	delim := opts.Delim("\t")
+	opts.Parse()
+
+	// Shell: column -t -s $'\t' "$@"
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		awk.Awk(&columnizeProgram{}, awk.FieldSeparator(*delim)),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "columnize: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// columnizeProgram is a custom awk program that buffers every row
+//
+// Shell awk pattern:
+//   {row[NR] = $0}               - Action: pass 1, collect the rows
+//   END {for (...) printf ...}   - End: pass 2, print them padded
+type columnizeProgram struct {
+	awk.SimpleProgram
+	rows [][]string
+}
+
+// Condition skips empty lines
+// Shell: NF > 0
+func (p *columnizeProgram) Condition(ctx *awk.Context) bool {
+	return ctx.Field(0) != ""
+}
+
+// Action collects the row's cells
+// Shell: {row[NR] = $0}
+func (p *columnizeProgram) Action(ctx *awk.Context) (string, bool) {
+	// ctx.Fields[0] is the whole line ($0); the cells follow it
+	p.rows = append(p.rows, append([]string(nil), ctx.Fields[1:]...))
+	return "", false
+}
+
+// End prints every row with each column padded to its widest cell
+// Shell: END {for (i = 1; i <= NR; i++) printf "%-*s  ...", width[1], ...}
+func (p *columnizeProgram) End(ctx *awk.Context) (string, error) {
+	return strings.Join(table.Align(p.rows), "\n"), nil
+}
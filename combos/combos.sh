@@ -0,0 +1,53 @@
+#!/bin/bash
+set -e
+
+# Print every combination of values from two or more lists (cartesian product)
+# yupsh equivalent: See main.go
+
+# Parse flags (--sep, --head), then the lists
+# yupsh: sep := opts.String("sep", "\t", ...); limit := opts.Int("head", 0, ...)
+SEP=$'\t'
+HEAD=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --sep) SEP=$2; shift 2 ;;
+    --head) HEAD=$2; shift 2 ;;
+    *) echo "Usage: $0 [--sep TAB] [--head N] list list..." >&2; exit 2 ;;
+  esac
+done
+if (( $# < 2 )); then
+  echo "Usage: $0 [--sep TAB] [--head N] list list..." >&2
+  exit 2
+fi
+
+# Each list is a file of values, or comma-separated values
+# yupsh: readList(arg)
+read_list() {
+  if [[ -f $1 ]]; then
+    grep -v '^$' "$1"
+  else
+    tr , '\n' <<<"$1"
+  fi
+}
+
+# Print PREFIX followed by every combination of the remaining lists
+# yupsh: product(lists, *sep) - an odometer instead of recursion
+product() {
+  local prefix=$1 list=$2
+  shift 2
+  local value
+  while IFS= read -r value; do
+    if (( $# == 0 )); then
+      echo "${prefix}${value}"
+    else
+      product "${prefix}${value}${SEP}" "$@"
+    fi
+  done < <(read_list "${list}")
+}
+
+# yupsh: pipe.Pipeline(product(lists, *sep), head.Head(head.LineCount(*limit)))
+if (( HEAD > 0 )); then
+  product "" "$@" | head -n "${HEAD}"
+else
+  product "" "$@"
+fi
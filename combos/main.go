@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	head `github.com/yupsh/head`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Print every combination of values from two or more lists (cartesian product)
+// Shell equivalent: See combos.sh
+//
+// Each list is either a file with one value per line, or comma-separated
+// values on the command line:
+//   combos linux,darwin amd64,arm64
+//   linux	amd64
+//   linux	arm64
+//   darwin	amd64
+//   darwin	arm64
+//
+// The product grows fast (four lists of 100 values is 100 million lines), so
+// the generator stops as soon as the downstream command closes the pipe, just
+// like seq and yes in pipe-closure. `--head N` demonstrates that: only N
+// combinations are ever generated.
+//
+// Usage: combos [--sep TAB] [--head N] list list...
+func main() {
+	opts := flags.New("combos", "list list...")
+	sep := opts.String("sep", "\t", "`separator` between the values of a combination")
+	limit := opts.Int("head", 0, "stop after `N` combinations (0 = all)")
+	opts.Parse()
+	if opts.NArg() < 2 {
+		opts.Fail("need at least two lists")
+	}
+
+	lists := make([][]string, opts.NArg())
+	for i, arg := range opts.Args() {
+		values, err := readList(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "combos: %v\n", err)
+			os.Exit(1)
+		}
+		lists[i] = values
+	}
+
+	// Shell: product linux,darwin amd64,arm64 | head -n 5
+	stages := []any{product(lists, *sep)}
+	if *limit > 0 {
+		stages = append(stages, head.Head(head.LineCount(*limit)))
+	}
+
+	if err := gloo.Run(pipe.Pipeline(stages...)); err != nil {
+		fmt.Fprintf(os.Stderr, "combos: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// readList returns the values of one list argument: the non-empty lines of
+// the file it names, or else its comma-separated values
+//
+// Shell equivalent:
+//   if [ -f "$arg" ]; then grep -v '^$' "$arg"; else tr , '\n' <<<"$arg"; fi
+func readList(arg string) ([]string, error) {
+	f, err := os.Open(arg)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return strings.Split(arg, ","), nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var values []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			values = append(values, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", arg, err)
+	}
+	return values, nil
+}
+
+// product generates the cartesian product of lists, one combination per line
+//
+// Shell equivalent (for two lists; combos.sh recurses for more):
+//   for a in "${first[@]}"; do
+//     for b in "${second[@]}"; do
+//       echo "$a	$b"
+//     done
+//   done
+//
+// The nested loops are written as an odometer over one index per list, so
+// any number of lists works: the last index turns fastest, and a wrap-around
+// carries into the index before it.
+func product(lists [][]string, sep string) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		for _, values := range lists {
+			if len(values) == 0 {
+				return nil // the product with an empty list is empty
+			}
+		}
+
+		index := make([]int, len(lists))
+		combo := make([]string, len(lists))
+		for {
+			for i, values := range lists {
+				combo[i] = values[index[i]]
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			// A write error means the reader closed the pipe: stop generating
+			if _, err := fmt.Fprintln(stdout, strings.Join(combo, sep)); err != nil {
+				return err
+			}
+
+			// Advance the odometer; done once the first index wraps
+			i := len(index) - 1
+			for ; i >= 0; i-- {
+				index[i]++
+				if index[i] < len(lists[i]) {
+					break
+				}
+				index[i] = 0
+			}
+			if i < 0 {
+				return nil
+			}
+		}
+	})
+}
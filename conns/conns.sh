@@ -0,0 +1,31 @@
+#!/bin/bash
+set -e
+set -o pipefail
+
+# Count TCP connections per remote host, busiest hosts first
+# yupsh equivalent: See main.go
+
+# Parse flags (--top N, --field N), then the files
+# yupsh: top := opts.Top(); field := opts.Int("field", 5, ...)
+TOP=10
+FIELD=5
+while [[ $1 == --* ]]; do
+  case $1 in
+    --top) TOP=$2; shift 2 ;;
+    --field) FIELD=$2; shift 2 ;;
+    *) echo "Usage: $0 [--top N] [--field N] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Take the remote address column, keep the lines ending in a numeric port,
+# strip the port and the brackets, and unmap IPv4-mapped IPv6 addresses;
+# then count the hosts, busiest first
+# yupsh: While(remoteHost(*field)), sort.Sort(), uniq.Uniq(uniq.Count), sort.Sort(...), head.Head(...)
+cat "$@" \
+| awk -v f="${FIELD}" '$f ~ /:[0-9]+$/ {print $f}' \
+| sed -E -e 's/:[0-9]+$//' -e 's/^\[(.*)\]$/\1/' -e 's/^::ffff:([0-9.]+)$/\1/I' \
+| grep . \
+| sort \
+| uniq -c \
+| sort -nr \
+| head -n "${TOP}"
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	head `github.com/yupsh/head`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	sort `github.com/yupsh/sort`
+	uniq `github.com/yupsh/uniq`
+	. `github.com/yupsh/while`
+)
+
+// Count TCP connections per remote host, busiest hosts first
+// Shell equivalent: See conns.sh
+//
+//   ss -tn | conns --top 3
+//         3 203.0.113.7
+//         3 10.0.0.12
+//         2 2001:db8:1::42
+//
+// The input is the output of `ss -tn`, or of `netstat -tn`: both print the
+// remote address:port in the fifth column (--field). The port is stripped
+// and the hosts counted with the file-stats idiom, sort | uniq -c | sort -nr.
+// It reads the files named, or stdin, so a captured listing works as well as
+// a live one.
+//
+// Addresses are IPv4 (203.0.113.7:51022) or IPv6, bracketed as ss prints
+// them ([2001:db8::1]:443) or not, as netstat does (2001:db8::1:443). An
+// IPv4 address mapped into IPv6 ([::ffff:203.0.113.7]) counts as the IPv4
+// one. Lines whose field isn't an address with a numeric port, such as the
+// header or a listening socket's *:*, are skipped; the numbers must not be
+// resolved to names (-n).
+//
+// Usage: conns [--top N] [--field N] [file...]
+func main() {
+	opts := flags.New("conns", "[file...]")
+	top := opts.Top()
+	field := opts.Int("field", 5, "the remote address is column `N` (ss -tn and netstat -tn: 5)")
+	opts.Parse()
+	if *field < 1 {
+		opts.Fail("--field must be at least 1")
+	}
+
+	err := gloo.Run(pipe.Pipeline(
+		// Report a missing file instead of silently counting nothing
+		// Shell: set -o pipefail
+		pipe.PipeFail,
+
+		// Read the named files, or stdin when there are none
+		// Shell: ss -tn, or cat "$@"
+		input.Source(opts.Args()...),
+
+		// The remote host of each connection, without its port
+		// Shell: awk '{print $5}' | sed 's/:[0-9]*$//'
+		// No FieldSeparator: While() splits on runs of blanks, as awk does,
+		// which is how ss and netstat pad their columns
+		While(remoteHost(*field)),
+
+		// Group identical hosts together, then count them
+		// Shell: sort | uniq -c
+		// Output format: "      4 203.0.113.7" (count followed by host)
+		sort.Sort(),
+		uniq.Uniq(uniq.Count),
+
+		// Most connections first
+		// Shell: sort -nr
+		sort.Sort(sort.Numeric, sort.Reverse),
+
+		// Shell: head -n "${TOP}"
+		head.Head(head.LineCount(*top)),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "conns: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// remoteHost returns a While() callback that emits the host of the address
+// in column field (counting from 1), and skips lines without one
+//
+// Shell equivalent:
+//   awk '{print $5}' | sed -e 's/:[0-9]*$//' -e 's/^\[//' -e 's/\]$//'
+func remoteHost(field int) Body {
+	return func(args ...any) gloo.Command {
+		if len(args) < field {
+			return nil
+		}
+		host, ok := hostOf(args[field-1].(string))
+		if !ok {
+			return nil
+		}
+		return echo.Echo(host)
+	}
+}
+
+// hostOf splits "host:port" and returns the host, without brackets. The
+// port is the part after the last colon, so an unbracketed IPv6 address
+// keeps the rest of its colons. It reports false when there is no port, or
+// it isn't a number (the "*" of a listening socket, or a header).
+func hostOf(addr string) (string, bool) {
+	i := strings.LastIndex(addr, ":")
+	if i < 0 {
+		return "", false
+	}
+	host, port := addr[:i], addr[i+1:]
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return "", false
+	}
+
+	// Shell: sed -e 's/^\[//' -e 's/\]$//'
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		host = host[1 : len(host)-1]
+	}
+	if host == "" {
+		return "", false
+	}
+
+	// Count ::ffff:203.0.113.7 as 203.0.113.7, the same client over IPv4
+	// and a dual-stack socket; anything that isn't an IP is kept as it is
+	if ip, err := netip.ParseAddr(host); err == nil {
+		host = ip.Unmap().String()
+	}
+	return host, true
+}
@@ -0,0 +1,25 @@
+#!/bin/bash
+set -e
+
+# Count files per directory, busiest directories first
+# yupsh equivalent: See main.go
+
+# Parse --top N, then the directory argument
+# yupsh: top := opts.Top(); dir := opts.ArgOr(0, ".")
+TOP=10
+if [[ $1 == --top ]]; then
+  TOP=$2
+  shift 2
+fi
+DIR=${1:-.}
+
+# Find files, map each to its directory, count per directory
+# yupsh: find.Find(...), While(parentDir, input.WholeLine), sort, uniq -c, sort -nr, head
+find "${DIR}" -type f \
+| while IFS= read -r file; do
+    dirname "${file}"
+  done \
+| sort \
+| uniq -c \
+| sort -nr \
+| head -n "${TOP}"
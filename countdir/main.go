@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	echo `github.com/yupsh/echo`
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	head `github.com/yupsh/head`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	sort `github.com/yupsh/sort`
+	uniq `github.com/yupsh/uniq`
+	. `github.com/yupsh/while`
+)
+
+// Count files per directory, busiest directories first
+// Shell equivalent: See countdir.sh
+//
+// This is "group by a derived key": the While() callback turns each file
+// path into its parent directory, and sort | uniq -c counts how often each
+// directory appears. It is the same shape as file-stats' extension histogram,
+// with the directory instead of the extension as the key.
+//
+// Only files directly inside a directory are counted for it; files in its
+// subdirectories count towards those subdirectories.
+//
+// Usage: countdir [--top N] [directory]
+func main() {
+	opts := flags.New("countdir", "[directory]")
+	top := opts.Top()
+	opts.Parse()
+	dir := opts.ArgOr(0, ".")
+
+	err := gloo.Run(pipe.Pipeline(
+		// Find all regular files
+		// Shell: find "${DIR}" -type f
+		find.Find(find.Dir(dir), find.FileType),
+
+		// Replace each path with its parent directory
+		// Shell: while IFS= read -r file; do dirname "$file"; done
+		While(parentDir, input.WholeLine),
+
+		// Group identical directories together, then count them
+		// Shell: sort | uniq -c
+		// Output format: "      5 ./src" (count followed by directory)
+		sort.Sort(),
+		uniq.Uniq(uniq.Count),
+
+		// Most files first
+		// Shell: sort -nr
+		sort.Sort(sort.Numeric, sort.Reverse),
+
+		// Shell: head -n "${TOP}"
+		head.Head(head.LineCount(*top)),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "countdir: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// parentDir emits the directory containing a file
+//
+// Shell equivalent:
+//   dirname "$file"
+//
+// filepath.Dir() is a pure string operation, so no extra system call is
+// made per file.
+func parentDir(args ...any) gloo.Command {
+	return echo.Echo(filepath.Dir(args[0].(string)))
+}
@@ -0,0 +1,98 @@
+#!/bin/bash
+set -e
+
+# Check that every row of a CSV file has the same number of fields, and
+# report the rows that don't
+# yupsh equivalent: See main.go
+
+# Parse flags (--expect N, --delim SEP), then the files
+# yupsh: expect := opts.Int("expect", 0, ...); delim := opts.Delim(",")
+EXPECT=0
+DELIM=,
+while [[ $1 == --* ]]; do
+  case $1 in
+    --expect) EXPECT=$2; shift 2 ;;
+    --delim) DELIM=$2; shift 2 ;;
+    *) echo "Usage: $0 [--expect N] [--delim ,] [file...]" >&2; exit 2 ;;
+  esac
+done
+if [[ ${DELIM} == '\t' ]]; then
+  DELIM=$'\t'
+fi
+if [[ ${#DELIM} -ne 1 || ${DELIM} == " " ]]; then
+  echo "Usage: $0 [--expect N] [--delim ,] [file...]" >&2
+  exit 2
+fi
+for file in "$@"; do
+  if [[ ${file} != - && ! -r ${file} ]]; then
+    echo "csv-lint: ${file}: cannot read" >&2
+    exit 2
+  fi
+done
+
+# Scan each row character by character, as encoding/csv does: a field
+# starting with a quote runs to the closing quote, across separators and
+# line breaks, and "" inside it is a quote
+# yupsh: l.check(), with csv.NewReader(stdin) and r.FieldsPerRecord = -1
+cat "$@" | awk -v want="${EXPECT}" -v d="${DELIM}" '
+  # yupsh: fmt.Fprintf(out, "line %d, column %d: %v\n", perr.Line, perr.Column, perr.Err)
+  function problem(line, column, msg) {
+    rows++; bad++; quoted = 0
+    printf "line %d, column %d: %s\n", line, column, msg
+  }
+
+  {
+    sub(/\r$/, "")
+    if (!quoted) {
+      # A new row; blank lines are skipped
+      if ($0 == "") next
+      start = NR; fields = 1; fieldStart = 1
+    }
+
+    for (i = 1; i <= length($0); i++) {
+      c = substr($0, i, 1)
+      if (quoted) {
+        if (c != "\"") continue
+        if (substr($0, i + 1, 1) == "\"") { i++; continue }
+        # The closing quote must end the field
+        quoted = 0
+        next_c = substr($0, i + 1, 1)
+        if (next_c != "" && next_c != d) {
+          problem(NR, i, "extraneous or missing \" in quoted-field")
+          next
+        }
+      } else if (c == d) {
+        fields++; fieldStart = 1
+      } else if (c == "\"") {
+        if (!fieldStart) {
+          problem(NR, i, "bare \" in non-quoted-field")
+          next
+        }
+        quoted = 1; fieldStart = 0
+      } else {
+        fieldStart = 0
+      }
+    }
+    # Still inside quotes: the row goes on on the next line
+    if (quoted) next
+
+    rows++
+    if (want == 0) {
+      # The header sets the count
+      # yupsh: l.want = len(record)
+      want = fields
+    } else if (fields != want) {
+      bad++
+      printf "line %d: %d fields, want %d\n", start, fields, want
+    }
+  }
+
+  # yupsh: if l.bad > 0 { ...; os.Exit(1) }
+  END {
+    if (quoted) problem(NR, length($0) + 2, "extraneous or missing \" in quoted-field")
+    if (bad) {
+      fflush()
+      printf "csv-lint: %d of %d rows have problems\n", bad, rows > "/dev/stderr"
+      exit 1
+    }
+  }'
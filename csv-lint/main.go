@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Check that every row of a CSV file has the same number of fields, and
+// report the rows that don't
+// Shell equivalent: See csv-lint.sh
+//
+//   csv-lint orders.csv
+//   line 4: 2 fields, want 3
+//   line 5: 4 fields, want 3
+//   line 8, column 11: extraneous or missing " in quoted-field
+//   csv-lint: 3 of 8 rows have problems
+//
+// The header (the first row) sets the number of fields every row must have;
+// --expect N sets it instead, and the header is then checked like any row.
+// Rows that can't be parsed at all, such as a stray quote, are reported too,
+// with the column where parsing failed.
+//
+// The input is read with encoding/csv, as one stream rather than line by
+// line, so quoted fields may contain separators, quotes ("") and newlines,
+// and line numbers are those of the file: a row spanning two lines is
+// reported at the line it starts on. Blank lines are skipped.
+//
+// As a data-quality gate in a pipeline, the exit status is 0 if every row
+// is well-formed, 1 if some aren't and 2 on trouble, as with diff.
+//
+// Usage: csv-lint [--expect N] [--delim ,] [file...]
+func main() {
+	opts := flags.New("csv-lint", "[file...]")
+	expect := opts.Int("expect", 0, "require `N` fields in every row, the header included (default: the header's count)")
+	delim := opts.Delim(",")
+	opts.Parse()
+
+	if *expect < 0 {
+		opts.Fail("--expect must not be negative")
+	}
+	comma, _ := utf8.DecodeRuneInString(*delim)
+	if *delim == " " {
+		opts.Fail("--delim: CSV fields are separated by each separator, so \" \" is not supported")
+	}
+
+	l := &linter{want: *expect, comma: comma}
+
+	// Shell: awk -F, 'NR == 1 {want = NF} NF != want {print "line " NR ": " NF " fields, want " want}'
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		l.check(),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "csv-lint: %s\n", status.Message(err))
+		os.Exit(2)
+	}
+
+	if l.bad > 0 {
+		fmt.Fprintf(os.Stderr, "csv-lint: %d of %d rows have problems\n", l.bad, l.rows)
+		os.Exit(1)
+	}
+}
+
+// linter counts the rows it checks, and the ones with problems
+type linter struct {
+	want  int // fields each row must have; 0 until the header sets it
+	comma rune
+	rows  int
+	bad   int
+}
+
+// check returns the command that reads CSV from stdin and prints a line for
+// each row with the wrong number of fields, or that isn't valid CSV
+//
+// Shell equivalent:
+//   awk -F, 'NR == 1 {want = NF} NF != want {print "line " NR ": ..."}'
+func (l *linter) check() gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		out := bufio.NewWriter(stdout)
+		defer out.Flush()
+
+		r := csv.NewReader(stdin)
+		r.Comma = l.comma
+		r.FieldsPerRecord = -1 // counted here, to report them all
+
+		for {
+			record, err := r.Read()
+			if err == io.EOF {
+				return nil
+			}
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				// Not CSV: report it, and go on with the next row
+				// Shell: (awk has no notion of quoting)
+				l.rows++
+				l.bad++
+				fmt.Fprintf(out, "line %d, column %d: %v\n", perr.Line, perr.Column, perr.Err)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			l.rows++
+			if l.want == 0 {
+				// The header sets the count
+				// Shell: NR == 1 {want = NF}
+				l.want = len(record)
+				continue
+			}
+			if len(record) != l.want {
+				// Shell: NF != want {print "line " NR ": " NF " fields, want " want}
+				line, _ := r.FieldPos(0)
+				l.bad++
+				fmt.Fprintf(out, "line %d: %d fields, want %d\n", line, len(record), l.want)
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+	})
+}
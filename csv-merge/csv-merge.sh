@@ -0,0 +1,68 @@
+#!/bin/bash
+
+# Concatenate CSV files that share a header, printing the header once
+# yupsh equivalent: See main.go
+#
+# Headers are compared as lines of text, after dropping a byte order mark
+# and a \r, and rows are copied as they are: awk has no notion of CSV
+# quoting, so this can't tell two spellings of one header apart, nor check
+# the rows. The Go version parses both with encoding/csv.
+
+# Parse flags (--strict, --delim SEP), then the files
+# yupsh: strict := opts.Bool("strict", ...); delim := opts.Delim(",")
+STRICT=0
+DELIM=,
+usage() {
+  echo "Usage: $0 [--strict] [--delim ,] file..." >&2
+  exit 2
+}
+while [[ $1 == --* ]]; do
+  case $1 in
+    --strict) STRICT=1; shift ;;
+    --delim) DELIM=$2; shift 2 ;;
+    *) usage ;;
+  esac
+done
+if [[ $# -eq 0 || ${#DELIM} -ne 1 && ${DELIM} != '\t' || ${DELIM} == " " ]]; then
+  usage
+fi
+
+# The first line of a file, without a byte order mark or \r
+# yupsh: m.readHeader(name)
+header() {
+  head -n 1 -- "$1" | sed -e $'1s/^\xef\xbb\xbf//' -e 's/\r$//'
+}
+
+# Check every header before printing anything
+# yupsh: m.check(opts.Args())
+for file in "$@"; do
+  if [[ ! -r ${file} ]]; then
+    echo "csv-merge: open ${file}: no such file or directory" >&2
+    exit 2
+  elif [[ ! -s ${file} ]]; then
+    echo "csv-merge: ${file}: no header" >&2
+    exit 2
+  fi
+done
+HEADER=$(header "$1")
+FILES=()
+for file in "$@"; do
+  h=$(header "${file}")
+  if [[ ${h} != "${HEADER}" ]]; then
+    echo "csv-merge: ${file}: header \"${h}\" does not match \"${HEADER}\", skipped" >&2
+    continue
+  fi
+  FILES+=("${file}")
+done
+if (( STRICT && ${#FILES[@]} < $# )); then
+  echo "csv-merge: --strict: $(( $# - ${#FILES[@]} )) file(s) don't match $1, nothing merged" >&2
+  exit 2
+fi
+
+# The header once, then every file's rows after its own header
+# yupsh: m.merge(files)
+printf '%s\n' "${HEADER}"
+awk 'FNR > 1 {sub(/\r$/, ""); print}' "${FILES[@]}"
+
+# yupsh: if len(files) < opts.NArg() { os.Exit(1) }
+(( ${#FILES[@]} == $# )) || exit 1
@@ -0,0 +1,11 @@
+module github.com/yupsh/script-examples/csv-merge
+
+go 1.25
+
+require (
+	github.com/gloo-foo/framework v0.0.3
+	github.com/gloo-foo/pipe v0.0.3
+	github.com/yupsh/script-examples/internal v0.0.0
+)
+
+replace github.com/yupsh/script-examples/internal => ../internal
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// bom is the byte order mark some spreadsheet exports start with
+const bom = "\ufeff"
+
+// Concatenate CSV files that share a header, printing the header once
+// Shell equivalent: See csv-merge.sh
+//
+//   csv-merge jan.csv feb.csv mar.csv
+//   csv-merge: mar.csv: header "date,amount,region" does not match "date,region,amount", skipped
+//   date,region,amount
+//   2026-01-03,north,120
+//   ...
+//
+// The first file's header is the schema; every other file must have the
+// same column names in the same order, or it is reported and skipped. With
+// --strict a mismatch is an error instead, and nothing is printed. Either
+// way, every header is checked before any row is printed, so the output is
+// never a partial merge.
+//
+// `cat` can't do this: it repeats each file's header as a row, and it joins
+// files whose columns differ without a word. The files are read and the
+// merge written with encoding/csv, so quoted fields with separators or
+// newlines in them are carried over whole, and a row with the wrong number
+// of fields stops the merge (see csv-lint for finding those). A byte order
+// mark before the header is ignored when comparing it.
+//
+// The exit status is 0 if every file was merged, 1 if some were skipped and
+// 2 on trouble, including a mismatch with --strict.
+//
+// Usage: csv-merge [--strict] [--delim ,] file...
+func main() {
+	opts := flags.New("csv-merge", "file...")
+	strict := opts.Bool("strict", false, "fail on a mismatched header instead of skipping the file")
+	delim := opts.Delim(",")
+	opts.Parse()
+	if opts.NArg() == 0 {
+		opts.Fail("no CSV files given")
+	}
+	comma, _ := utf8.DecodeRuneInString(*delim)
+	if *delim == " " {
+		opts.Fail("--delim: CSV fields are separated by each separator, so \" \" is not supported")
+	}
+
+	m := &merger{comma: comma}
+
+	// Shell: head -n 1 "${file}" for every file, before printing anything
+	files, err := m.check(opts.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "csv-merge: %v\n", err)
+		os.Exit(2)
+	}
+	if *strict && len(files) < opts.NArg() {
+		fmt.Fprintf(os.Stderr, "csv-merge: --strict: %d file(s) don't match %s, nothing merged\n", opts.NArg()-len(files), opts.Arg(0))
+		os.Exit(2)
+	}
+
+	// Shell: awk 'FNR == 1 && NR != 1 {next} {print}' "${files[@]}"
+	if err := gloo.Run(pipe.Pipeline(m.merge(files))); err != nil {
+		fmt.Fprintf(os.Stderr, "csv-merge: %s\n", status.Message(err))
+		os.Exit(2)
+	}
+	if len(files) < opts.NArg() {
+		os.Exit(1)
+	}
+}
+
+// merger holds the schema the files must match
+type merger struct {
+	comma  rune
+	header []string // the first file's header
+}
+
+// check reads the header of each file, sets the schema from the first, and
+// returns the files whose header matches it, reporting the others
+//
+// Shell equivalent:
+//   [[ $(head -n 1 "${file}") == "${HEADER}" ]] || echo "... skipped" >&2
+func (m *merger) check(names []string) ([]string, error) {
+	var files []string
+	for i, name := range names {
+		header, err := m.readHeader(name)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			m.header = header
+		}
+		if !slices.Equal(header, m.header) {
+			fmt.Fprintf(os.Stderr, "csv-merge: %s: header %q does not match %q, skipped\n",
+				name, m.join(header), m.join(m.header))
+			continue
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
+
+// readHeader returns the first record of the named file, without a byte
+// order mark
+func (m *merger) readHeader(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header, err := m.reader(f).Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%s: no header", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	header[0] = strings.TrimPrefix(header[0], bom)
+	return header, nil
+}
+
+// merge returns the command that writes the header, then the rows of each
+// file after its own header
+func (m *merger) merge(files []string) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		out := bufio.NewWriter(stdout)
+		w := csv.NewWriter(out)
+		w.Comma = m.comma
+
+		// Shell: NR == 1 {print}
+		if err := w.Write(m.header); err != nil {
+			return err
+		}
+		for _, name := range files {
+			if err := m.copyRows(ctx, name, w); err != nil {
+				return err
+			}
+		}
+
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+		return out.Flush()
+	})
+}
+
+// copyRows writes every row of the named file but its header to w
+//
+// Shell equivalent:
+//   FNR > 1 {print}
+func (m *merger) copyRows(ctx context.Context, name string, w *csv.Writer) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := m.reader(f)
+	r.FieldsPerRecord = len(m.header)
+	if _, err := r.Read(); err != nil { // the header, checked already
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+	}
+}
+
+// reader returns a CSV reader of r with the --delim separator
+func (m *merger) reader(r io.Reader) *csv.Reader {
+	cr := csv.NewReader(r)
+	cr.Comma = m.comma
+	return cr
+}
+
+// join formats a header for a message, as the line it came from
+func (m *merger) join(header []string) string {
+	return strings.Join(header, string(m.comma))
+}
@@ -0,0 +1,48 @@
+#!/bin/bash
+set -e
+
+# Print the CSV rows matching a minimal SQL-like WHERE clause
+# yupsh equivalent: See main.go
+
+# Parse flags (--where EXPR, --header, --comma SEP), then the files
+# yupsh: where := opts.String("where", "", ...); header := opts.Bool("header", ...)
+WHERE=
+HEADER=0
+COMMA=,
+while [[ $1 == --* ]]; do
+  case $1 in
+    --where) WHERE=$2; shift 2 ;;
+    --header) HEADER=1; shift ;;
+    --comma) COMMA=$2; shift 2 ;;
+    *) echo "Usage: $0 [--where EXPR] [--header] [--comma ,] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Translate the clause into an awk condition, one space-separated token at a
+# time: col3 > 100 AND col1 = eu  ->  $3 > 100 && $1 == "eu"
+# (Unlike main.go, tokens must be separated by spaces, and quoted strings
+# can't contain them.)
+# yupsh: parseWhere(*where) - a tokenizer and recursive-descent parser
+CONDITION=
+set -f  # don't glob-expand tokens such as '*'
+for tok in ${WHERE}; do
+  case ${tok^^} in
+    AND) tok='&&' ;;
+    OR) tok='||' ;;
+    NOT) tok='!' ;;
+    '=') tok='==' ;;
+    '!='|'<'|'<='|'>'|'>='|'('|')') ;;
+    COL[0-9]*) tok="\$${tok:3}" ;;
+    *)
+      tok=${tok#[\'\"]}; tok=${tok%[\'\"]}
+      [[ ${tok} =~ ^-?[0-9.]+$ ]] || tok="\"${tok}\""
+      ;;
+  esac
+  CONDITION="${CONDITION} ${tok}"
+done
+set +f
+CONDITION=${CONDITION:-1}
+
+# Filter the rows; awk splits on every comma, even inside quotes
+# yupsh: While(q.filter, input.WholeLine) with encoding/csv per line
+cat "$@" | awk -F"${COMMA}" -v header="${HEADER}" "(header && NR == 1) || (${CONDITION})"
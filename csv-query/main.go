@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Print the CSV rows matching a minimal SQL-like WHERE clause
+// Shell equivalent: See csv-query.sh
+//
+// SQL:
+//   SELECT * FROM orders WHERE amount > 100 AND region = 'eu'
+// csv-query:
+//   csv-query --header --where "col3 > 100 AND col1 = eu" orders.csv
+//
+// The clause supports:
+//   colN              the Nth column (1-based)
+//   = != < <= > >=    comparisons: numeric when both sides are numbers,
+//                     otherwise string comparison
+//   AND OR NOT ( )    boolean logic; AND binds tighter than OR
+//   42, foo, 'a b'    literals; quote strings containing spaces
+//
+// The clause is parsed once, before any input is read, into a predicate
+// (a tree of Go closures) that the While() callback evaluates per row.
+// Matching rows are printed exactly as they were read.
+//
+// Each line is parsed with encoding/csv, so quoted fields may contain commas,
+// but not newlines.
+//
+// Usage: csv-query [--where EXPR] [--header] [--comma ,] [file...]
+func main() {
+	opts := flags.New("csv-query", "[file...]")
+	where := opts.String("where", "", "filter `expression`, e.g. \"col3 > 100 AND col1 = foo\" (default all rows)")
+	header := opts.Bool("header", false, "print the first row unfiltered")
+	comma := opts.String("comma", ",", "field `separator`")
+	opts.Parse()
+
+	if len([]rune(*comma)) != 1 {
+		opts.Fail("--comma must be a single character")
+	}
+	match, err := parseWhere(*where)
+	if err != nil {
+		opts.Fail("--where: %v", err)
+	}
+
+	q := &query{match: match, header: *header, comma: []rune(*comma)[0]}
+
+	// Shell: awk -F, 'NR == 1 || ($3 > 100 && $1 == "foo")'
+	err = gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		While(q.filter, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "csv-query: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// query applies a parsed WHERE clause to a stream of CSV lines
+type query struct {
+	match  predicate
+	header bool // the next line is the header
+	comma  rune
+	line   int
+}
+
+// filter is the While() callback: it echoes the line if the row matches
+func (q *query) filter(args ...any) gloo.Command {
+	line := args[0].(string)
+	q.line++
+
+	// Shell: NR == 1
+	if q.header {
+		q.header = false
+		return echo.Echo(line)
+	}
+
+	r := csv.NewReader(strings.NewReader(line))
+	r.Comma = q.comma
+	r.FieldsPerRecord = -1 // rows may be ragged
+	row, err := r.Read()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "csv-query: line %d: %v\n", q.line, err)
+		return nil
+	}
+
+	if !q.match(row) {
+		return nil
+	}
+	return echo.Echo(line)
+}
+
+// predicate reports whether a row matches
+type predicate func(row []string) bool
+
+// operand yields one side of a comparison for a row
+type operand func(row []string) string
+
+// parseWhere compiles a WHERE clause; an empty clause matches every row
+func parseWhere(expr string) (predicate, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return func([]string) bool { return true }, nil
+	}
+
+	p := &parser{tokens: tokens}
+	match, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	return match, nil
+}
+
+// token is one word of a WHERE clause; quoted literals are never keywords
+type token struct {
+	text   string
+	quoted bool
+}
+
+// tokenize splits a clause into words, operators, parentheses and quoted
+// strings
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, token{text: string(c)})
+			i++
+		case strings.ContainsRune("=!<>", c):
+			op := string(c)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("'!' must be followed by '='")
+			}
+			tokens = append(tokens, token{text: op})
+			i += len(op)
+		case c == '\'' || c == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != c {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string %s", string(runes[i:]))
+			}
+			tokens = append(tokens, token{text: string(runes[i+1 : end]), quoted: true})
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("()=!<>'\"", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, token{text: string(runes[i:end])})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// parser is a recursive-descent parser over the tokens:
+//   or         := and { OR and }
+//   and        := not { AND not }
+//   not        := NOT not | ( or ) | comparison
+//   comparison := operand op operand
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+// keyword consumes the next token if it is the (case-insensitive) keyword
+func (p *parser) keyword(word string) bool {
+	if t := p.peek(); !p.done() && !t.quoted && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) or() (predicate, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(row []string) bool { return l(row) || right(row) }
+	}
+	return left, nil
+}
+
+func (p *parser) and() (predicate, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(row []string) bool { return l(row) && right(row) }
+	}
+	return left, nil
+}
+
+func (p *parser) not() (predicate, error) {
+	if p.keyword("NOT") {
+		inner, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return func(row []string) bool { return !inner(row) }, nil
+	}
+
+	if t := p.peek(); !t.quoted && t.text == "(" {
+		p.pos++
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.peek(); t.quoted || t.text != ")" {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		p.pos++
+		return inner, nil
+	}
+
+	return p.comparison()
+}
+
+func (p *parser) comparison() (predicate, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+
+	op := p.peek()
+	test, ok := comparisons[op.text]
+	if op.quoted || !ok {
+		return nil, fmt.Errorf("expected a comparison after operand, got %q", op.text)
+	}
+	p.pos++
+
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	return func(row []string) bool { return test(compare(left(row), right(row))) }, nil
+}
+
+// operand parses a column reference (colN) or a literal
+func (p *parser) operand() (operand, error) {
+	if p.done() {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	t := p.tokens[p.pos]
+	if !t.quoted && strings.ContainsAny(t.text, "()=!<>") {
+		return nil, fmt.Errorf("expected an operand, got %q", t.text)
+	}
+	p.pos++
+
+	// Shell: $3
+	if n, ok := column(t); ok {
+		return func(row []string) string {
+			if n > len(row) {
+				return "" // missing columns are empty, as in awk
+			}
+			return row[n-1]
+		}, nil
+	}
+
+	return func([]string) string { return t.text }, nil
+}
+
+// column reports the 1-based column number of an unquoted colN token
+func column(t token) (int, bool) {
+	if t.quoted || len(t.text) < 4 || !strings.EqualFold(t.text[:3], "col") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(t.text[3:])
+	return n, err == nil && n >= 1
+}
+
+// comparisons maps each operator to a test on compare()'s result
+var comparisons = map[string]func(int) bool{
+	"=":  func(c int) bool { return c == 0 },
+	"!=": func(c int) bool { return c != 0 },
+	"<":  func(c int) bool { return c < 0 },
+	"<=": func(c int) bool { return c <= 0 },
+	">":  func(c int) bool { return c > 0 },
+	">=": func(c int) bool { return c >= 0 },
+}
+
+// compare orders a and b numerically if both are numbers, else as strings
+// Shell: awk compares "10" > "9" numerically, "b" > "a" as strings
+func compare(a, b string) int {
+	x, errX := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	y, errY := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if errX == nil && errY == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,150 @@
+#!/bin/bash
+set -e
+
+# Merge the CSV rows that share a key into one, summing their value columns
+# yupsh equivalent: See main.go
+#
+# awk -F, would split "south, coastal" in two, so each line is split by a
+# small CSV parser instead. It handles quoted fields, but not one that runs
+# onto the next line, which the Go version's encoding/csv does.
+
+# Parse flags (--key COL..., --sum COL..., --delim SEP), then the files
+# yupsh: keys := opts.List("key", ...); sums := opts.List("sum", ...); delim := opts.Delim(",")
+KEYS=()
+SUMS=()
+DELIM=,
+usage() {
+  echo "Usage: $0 --key COL... --sum COL... [--delim ,] [file...]" >&2
+  exit 2
+}
+while [[ $1 == --* ]]; do
+  case $1 in
+    --key) KEYS+=("$2"); shift 2 ;;
+    --sum) SUMS+=("$2"); shift 2 ;;
+    --delim) DELIM=$2; shift 2 ;;
+    *) usage ;;
+  esac
+done
+[[ ${DELIM} == '\t' ]] && DELIM=$'\t'
+if [[ ${#KEYS[@]} -eq 0 || ${#SUMS[@]} -eq 0 || ${#DELIM} -ne 1 || ${DELIM} == " " ]]; then
+  usage
+fi
+
+# The column names go to awk one per line, since a name may hold a comma
+# yupsh: r.run(), with r.columns(header), r.add(record, ...) and r.write(stdout)
+cat "$@" | awk -v d="${DELIM}" -v keynames="$(printf '%s\n' "${KEYS[@]}")" \
+    -v sumnames="$(printf '%s\n' "${SUMS[@]}")" '
+  # Split a line into f[1..n] at unquoted separators; "" in quotes is a quote
+  function parse(line,    n, i, c, field, quoted) {
+    n = 1; field = ""; quoted = 0
+    for (i = 1; i <= length(line); i++) {
+      c = substr(line, i, 1)
+      if (quoted) {
+        if (c != "\"") { field = field c; continue }
+        if (substr(line, i + 1, 1) == "\"") { field = field c; i++; continue }
+        quoted = 0
+      } else if (c == "\"" && field == "") {
+        quoted = 1
+      } else if (c == d) {
+        f[n++] = field; field = ""
+      } else {
+        field = field c
+      }
+    }
+    f[n] = field
+    return n
+  }
+
+  # Quote a field as encoding/csv does: when it holds the separator, a quote
+  # or a line break, or starts with a blank
+  function quote(s) {
+    if (index(s, d) || index(s, "\"") || s ~ /\r/ || s ~ /^[ \t]/) {
+      gsub(/"/, "\"\"", s)
+      return "\"" s "\""
+    }
+    return s
+  }
+
+  function trim(s) {
+    sub(/^[ \t]+/, "", s); sub(/[ \t]+$/, "", s)
+    return s
+  }
+
+  BEGIN {
+    nkeys = split(keynames, keyname, "\n")
+    nsums = split(sumnames, sumname, "\n")
+  }
+
+  { sub(/\r$/, "") }
+
+  # yupsh: r.columns(header)
+  NR == 1 {
+    sub(/^\xef\xbb\xbf/, "")
+    header = $0
+    n = parse($0)
+    for (i = 1; i <= n; i++) if (!(f[i] in column)) column[f[i]] = i
+    for (i = 1; i <= nkeys; i++) {
+      if (!(keyname[i] in column)) {
+        printf "csv-rollup: no column \"%s\" in the header \"%s\"\n", keyname[i], header > "/dev/stderr"
+        bad = 1; exit 1
+      }
+      keycol[i] = column[keyname[i]]
+    }
+    for (i = 1; i <= nsums; i++) {
+      if (!(sumname[i] in column)) {
+        printf "csv-rollup: no column \"%s\" in the header \"%s\"\n", sumname[i], header > "/dev/stderr"
+        bad = 1; exit 1
+      }
+      sumcol[i] = column[sumname[i]]
+    }
+    next
+  }
+
+  # yupsh: slices.Equal(record, r.header) -> skip
+  $0 == header || $0 == "" { next }
+
+  # yupsh: r.add(record, line, stderr)
+  {
+    parse($0)
+    k = ""
+    for (i = 1; i <= nkeys; i++) k = k (i > 1 ? SUBSEP : "") f[keycol[i]]
+    if (!(k in seen)) {
+      seen[k] = 1
+      order[++groups] = k
+      row = ""
+      for (i = 1; i <= nkeys; i++) row = row (i > 1 ? d : "") quote(f[keycol[i]])
+      keyrow[k] = row
+    }
+    for (i = 1; i <= nsums; i++) {
+      v = trim(f[sumcol[i]])
+      if (v == "") continue
+      if (v !~ /^[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?$/) {
+        printf "csv-rollup: line %d: %s: not a number: \"%s\"\n", NR, sumname[i], f[sumcol[i]] > "/dev/stderr"
+        continue
+      }
+      total[k, i] += v
+      # yupsh: precision(r.places[i], text)
+      if (v ~ /[eE]/) places[i] = -1
+      else if (places[i] >= 0 && index(v, ".") && length(v) - index(v, ".") > places[i]) places[i] = length(v) - index(v, ".")
+    }
+  }
+
+  # yupsh: r.write(stdout)
+  END {
+    if (bad) exit 1
+    if (NR == 0) { print "csv-rollup: no header" > "/dev/stderr"; exit 1 }
+    row = ""
+    for (i = 1; i <= nkeys; i++) row = row (i > 1 ? d : "") quote(keyname[i])
+    for (i = 1; i <= nsums; i++) row = row d quote(sumname[i])
+    print row
+    for (g = 1; g <= groups; g++) {
+      k = order[g]
+      row = keyrow[k]
+      for (i = 1; i <= nsums; i++) {
+        # An exponent: as many places as it takes, near enough
+        fmt = places[i] < 0 ? "%.15g" : "%." (places[i] + 0) "f"
+        row = row d sprintf(fmt, total[k, i])
+      }
+      print row
+    }
+  }'
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// bom is the byte order mark some spreadsheet exports start with
+const bom = "\ufeff"
+
+// Merge the CSV rows that share a key into one, summing their value columns
+// Shell equivalent: See csv-rollup.sh
+//
+// SQL:
+//   SELECT region, product, sum(units), sum(revenue) FROM sales GROUP BY region, product
+// csv-rollup:
+//   csv-rollup --key region --key product --sum units --sum revenue sales.csv
+//   region,product,units,revenue
+//   north,widget,15,149.85
+//   "south, coastal",gadget,4,99.80
+//
+// This is groupby for CSV: the columns are named by the header, the key may
+// be several columns, and the output is CSV again, with a header, so it can
+// go on to a spreadsheet or to csv-query. It has the --key columns, then the
+// --sum columns, in the order given; the other columns are dropped. Rows are
+// merged in the order their key first appears.
+//
+// The input is read and the output written with encoding/csv, so a quoted
+// field with a comma in it is one field, and is quoted again on the way
+// out. A sum has as many decimal places as the most precise of its values,
+// so money adds up to money: 0.10 + 0.20 is 0.30, not 0.30000000000000004.
+// A blank value adds nothing; one that isn't a number is left out with a
+// warning.
+//
+// A row that repeats the header is skipped, so files exported with the same
+// header can be rolled up together:
+//   csv-rollup --key region --sum revenue jan.csv feb.csv mar.csv
+//
+// Usage: csv-rollup --key COL... --sum COL... [--delim ,] [file...]
+func main() {
+	opts := flags.New("csv-rollup", "--key COL... --sum COL... [file...]")
+	keys := opts.List("key", "group by the `column` with this header name; repeatable")
+	sums := opts.List("sum", "sum the `column` with this header name; repeatable")
+	delim := opts.Delim(",")
+	opts.Parse()
+	if len(*keys) == 0 {
+		opts.Fail("missing --key: name the column(s) to group by")
+	}
+	if len(*sums) == 0 {
+		opts.Fail("missing --sum: name the column(s) to add up")
+	}
+	if *delim == " " {
+		opts.Fail("--delim: CSV fields are separated by each separator, so \" \" is not supported")
+	}
+	for _, name := range *sums {
+		if slices.Contains(*keys, name) {
+			opts.Fail("%q can't be both a --key and a --sum", name)
+		}
+	}
+	comma, _ := utf8.DecodeRuneInString(*delim)
+
+	r := &rollup{keyNames: *keys, sumNames: *sums, comma: comma, groups: map[string]*group{}}
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+
+		// Read the named files, or stdin
+		// Shell: cat "$@"
+		input.Source(opts.Args()...),
+
+		// Add each row into its key's totals, then print the totals
+		// Shell: awk -F, '{units[$2 FS $3] += $4} END {for (k in units) print k, units[k]}'
+		r.run(),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "csv-rollup: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// rollup merges the rows of a CSV stream by key
+type rollup struct {
+	keyNames, sumNames []string // the --key and --sum columns, by name
+	comma              rune
+
+	header     []string
+	keys, sums []int // the columns' indexes in the header
+	places     []int // decimal places of each sum; -1 for as many as it takes
+
+	groups map[string]*group // by composite key
+	order  []string          // keys in first-seen order
+}
+
+// group is the merged row of one key
+type group struct {
+	key    []string  // the values of the --key columns
+	totals []float64 // one per --sum column
+}
+
+// run returns the command that reads CSV from stdin and writes one row per
+// key, after a header
+func (r *rollup) run() gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		cr := csv.NewReader(bufio.NewReader(stdin))
+		cr.Comma = r.comma
+
+		header, err := cr.Read()
+		if err == io.EOF {
+			return fmt.Errorf("no header")
+		}
+		if err != nil {
+			return err
+		}
+		header[0] = strings.TrimPrefix(header[0], bom)
+		if err := r.columns(header); err != nil {
+			return err
+		}
+
+		for {
+			record, err := cr.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			line, _ := cr.FieldPos(0)
+			r.add(record, line, stderr)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+		return r.write(stdout)
+	})
+}
+
+// columns finds the --key and --sum columns in the header
+//
+// Shell equivalent:
+//   NR == 1 {for (i = 1; i <= NF; i++) column[$i] = i}
+func (r *rollup) columns(header []string) error {
+	index := func(name string) (int, error) {
+		i := slices.Index(header, name)
+		if i < 0 {
+			return 0, fmt.Errorf("no column %q in the header %q", name, strings.Join(header, string(r.comma)))
+		}
+		return i, nil
+	}
+
+	r.header = header
+	for _, name := range r.keyNames {
+		i, err := index(name)
+		if err != nil {
+			return err
+		}
+		r.keys = append(r.keys, i)
+	}
+	for _, name := range r.sumNames {
+		i, err := index(name)
+		if err != nil {
+			return err
+		}
+		r.sums = append(r.sums, i)
+		r.places = append(r.places, 0)
+	}
+	return nil
+}
+
+// add folds one row into its key's group, warning about values that are
+// not numbers
+//
+// Shell equivalent:
+//   {k = $2 SUBSEP $3; if (!(k in units)) order[++n] = k; units[k] += $4}
+func (r *rollup) add(record []string, line int, stderr io.Writer) {
+	// Shell: $0 == header {next}
+	if slices.Equal(record, r.header) {
+		return
+	}
+
+	key := make([]string, len(r.keys))
+	for i, col := range r.keys {
+		key[i] = record[col]
+	}
+	// A NUL can't be typed into a spreadsheet cell, so it can't make two
+	// keys run together
+	id := strings.Join(key, "\x00")
+	g, ok := r.groups[id]
+	if !ok {
+		g = &group{key: key, totals: make([]float64, len(r.sums))}
+		r.groups[id] = g
+		r.order = append(r.order, id)
+	}
+
+	for i, col := range r.sums {
+		text := strings.TrimSpace(record[col])
+		if text == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			fmt.Fprintf(stderr, "csv-rollup: line %d: %s: not a number: %q\n", line, r.sumNames[i], record[col])
+			continue
+		}
+		g.totals[i] += v
+		r.places[i] = precision(r.places[i], text)
+	}
+}
+
+// precision returns the decimal places a sum needs to show text exactly as
+// well as the values before it, which needed places
+//
+// Shell equivalent:
+//   d = index($4, ".") ? length($4) - index($4, ".") : 0; if (d > p) p = d
+func precision(places int, text string) int {
+	if places < 0 || strings.ContainsAny(text, "eE") {
+		// An exponent: 1e-9 is too small to count the places of
+		return -1
+	}
+	if _, frac, ok := strings.Cut(text, "."); ok {
+		return max(places, len(frac))
+	}
+	return places
+}
+
+// write prints the header of the --key and --sum columns, then the merged
+// row of each key, in the order the keys first appeared
+//
+// Shell equivalent:
+//   END {for (i = 1; i <= n; i++) printf "%s,%.2f\n", order[i], units[order[i]]}
+func (r *rollup) write(stdout io.Writer) error {
+	out := bufio.NewWriter(stdout)
+	w := csv.NewWriter(out)
+	w.Comma = r.comma
+
+	if err := w.Write(append(slices.Clone(r.keyNames), r.sumNames...)); err != nil {
+		return err
+	}
+	for _, id := range r.order {
+		g := r.groups[id]
+		row := slices.Clone(g.key)
+		for i, total := range g.totals {
+			row = append(row, strconv.FormatFloat(total, 'f', r.places[i], 64))
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return out.Flush()
+}
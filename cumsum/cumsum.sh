@@ -0,0 +1,37 @@
+#!/bin/bash
+set -e
+
+# Print each line followed by the running total of a numeric column
+# yupsh equivalent: See main.go
+
+# Parse flags (--field N, --delim SEP, --reset-on-blank), then the files
+# yupsh: field := opts.Int("field", 1, ...); delim := ...; reset := ...
+FIELD=1
+DELIM=$'\t'
+RESET=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --field) FIELD=$2; shift 2 ;;
+    --delim) DELIM=$2; shift 2 ;;
+    --reset-on-blank) RESET=1; shift ;;
+    *) echo "Usage: $0 [--field N] [--delim TAB] [--reset-on-blank] [file...]" >&2; exit 2 ;;
+  esac
+done
+# --delim '\t' means a tab, as in the yupsh version
+# yupsh: delim := opts.Delim("\t")
+[[ ${DELIM} == '\t' ]] && DELIM=$'\t'
+
+# Carry the total from line to line
+# yupsh: While(s.line, input.WholeLine) with the total in a summer struct
+cat "$@" | awk -F"${DELIM}" -v OFS="${DELIM}" -v field="${FIELD}" -v reset="${RESET}" '
+  # yupsh: blank lines pass through, and may end a group
+  $0 ~ /^[[:space:]]*$/ { if (reset) total = 0; print; next }
+
+  # yupsh: strconv.ParseFloat(...) fails -> warn and print unchanged
+  $field !~ /^[[:space:]]*[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?[[:space:]]*$/ {
+    printf "cumsum: line %d: column %d is not a number\n", NR, field > "/dev/stderr"
+    print; next
+  }
+
+  # yupsh: s.total += value; echo.Echo(line + s.delim + formatNumber(s.total))
+  { total += $field; print $0 OFS total }'
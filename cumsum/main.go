@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	num `github.com/yupsh/script-examples/internal/num`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Print each line followed by the running total of a numeric column
+// Shell equivalent: See cumsum.sh
+//
+//   cumsum --field 2 sales.tsv
+//   mon	12    ->    mon	12	12
+//   tue	9           tue	9	21
+//   wed	14          wed	14	35
+//
+// The total is carried from one line to the next in the callback's state.
+// With --reset-on-blank, a blank line starts a new total, so blank-separated
+// groups get totals of their own. Lines whose field is not a number are
+// printed unchanged, with a warning, and leave the total as it was.
+//
+// Usage: cumsum [--field N] [--delim TAB] [--reset-on-blank] [file...]
+func main() {
+	opts := flags.New("cumsum", "[file...]")
+	field := opts.Int("field", 1, "`column` to add up")
+	delim := opts.Delim("\t")
+	reset := opts.Bool("reset-on-blank", false, "start a new total after each blank line")
+	opts.Parse()
+	if *field < 1 {
+		opts.Fail("--field must be at least 1")
+	}
+
+	s := &summer{field: *field, delim: *delim, reset: *reset}
+
+	// Shell: awk -F'\t' '{total += $2; print $0 "\t" total}'
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		While(s.line, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cumsum: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// summer carries the running total from line to line
+type summer struct {
+	field int
+	delim string
+	reset bool
+
+	total float64
+	lines int // lines read so far, for warnings
+}
+
+// line is the While() callback: it appends the updated total to each line
+// Shell: {total += $N; print $0 FS total}
+func (s *summer) line(args ...any) gloo.Command {
+	line := args[0].(string)
+	s.lines++
+
+	// Blank lines pass through, and may end a group
+	// Shell: NF == 0 {if (reset) total = 0; print; next}
+	if strings.TrimSpace(line) == "" {
+		if s.reset {
+			s.total = 0
+		}
+		return echo.Echo(line)
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(s.column(line)), 64)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cumsum: line %d: column %d is not a number\n", s.lines, s.field)
+		return echo.Echo(line)
+	}
+	s.total += value
+
+	// The total is joined with the separator itself; for whitespace-split
+	// input that is a single space, as awk's default OFS
+	return echo.Echo(line + s.delim + num.Format(s.total))
+}
+
+// column returns field s.field of line, or "" if the line is too short
+func (s *summer) column(line string) string {
+	var fields []string
+	if s.delim == " " {
+		fields = strings.Fields(line)
+	} else {
+		fields = strings.Split(line, s.delim)
+	}
+	if s.field > len(fields) {
+		return ""
+	}
+	return fields[s.field-1]
+}
@@ -0,0 +1,140 @@
+#!/bin/bash
+set -o pipefail
+
+# Find the files in a tree with the same content, keep the first of each
+# set and replace the others with hard links to it, or delete them
+# yupsh equivalent: See main.go
+#
+# The listing is tab-separated, so file names with a tab or a newline in
+# them are not supported here.
+
+# Parse flags (--apply, --dry-run, --delete, --algo A, --min-size SIZE,
+# --human), then the directory; --dry-run wins over --apply
+# yupsh: apply := opts.Bool("apply", ...); dryRun := opts.DryRun(); ...
+APPLY=0
+DRY_RUN=0
+DELETE=0
+ALGO=sha256
+MIN_SIZE=1
+HUMAN=0
+usage() {
+  echo "Usage: $0 [--apply] [--dry-run] [--delete] [--algo sha256|sha1|md5] [--min-size SIZE] [--human] [directory]" >&2
+  exit 2
+}
+while [[ $1 == --* ]]; do
+  case $1 in
+    --apply) APPLY=1; shift ;;
+    --dry-run) DRY_RUN=1; shift ;;
+    --delete) DELETE=1; shift ;;
+    --algo) ALGO=$2; shift 2 ;;
+    --min-size) MIN_SIZE=$2; shift 2 ;;
+    --human) HUMAN=1; shift ;;
+    *) usage ;;
+  esac
+done
+case ${ALGO} in
+  sha256|sha1|md5) ;;
+  *) usage ;;
+esac
+MIN_BYTES=$(numfmt --from=iec "${MIN_SIZE%B}") || usage
+if (( MIN_BYTES < 1 )); then
+  usage
+fi
+if (( DRY_RUN )); then
+  APPLY=0
+fi
+DIR=${1:-.}
+if [[ ! -e ${DIR} ]]; then
+  echo "dedup-files: stat ${DIR}: no such file or directory" >&2
+  exit 2
+elif [[ ! -d ${DIR} ]]; then
+  echo "dedup-files: ${DIR}: not a directory" >&2
+  exit 2
+fi
+
+# "size TAB inode TAB path" for every file big enough to matter, in path order
+# yupsh: find.Find(...) | While(d.file, input.WholeLine), then slices.SortFunc()
+LIST=$(find "${DIR}" -type f -size +$(( MIN_BYTES - 1 ))c -printf '%s\t%D:%i\t%p\n' | LC_ALL=C sort -t$'\t' -k3) || exit 2
+
+# Count the inodes of each size; a size only one inode has is unique
+# yupsh: bySize[f.info.Size()] = append(inodes, f.inode)
+declare -A FIRST INODES
+while IFS=$'\t' read -r size inode path; do
+  if [[ -z ${FIRST[${inode}]} ]]; then
+    FIRST[${inode}]=${path}
+    (( INODES[${size}] += 1 ))
+  fi
+done <<< "${LIST}"
+
+# Keep the first file with each content, and replace the later ones
+# yupsh: d.hash(); d.dedup()
+declare -A SUM KEEP KEEP_INODE COUNTED
+DUPLICATES=0
+SAVED=0
+FAILED=0
+while IFS=$'\t' read -r size inode path; do
+  [[ -n ${path} ]] && (( INODES[${size}] > 1 )) || continue
+
+  # Hash each inode once, by its first path
+  # yupsh: digest.File(n.first.path, d.algo)
+  if [[ -z ${SUM[${inode}]+set} ]]; then
+    SUM[${inode}]=$("${ALGO}sum" -- "${FIRST[${inode}]}" | cut -d' ' -f1) || FAILED=$(( FAILED + 1 ))
+  fi
+  sum=${SUM[${inode}]}
+  [[ -n ${sum} ]] || continue
+
+  key=${size}:${sum}
+  if [[ -z ${KEEP[${key}]} ]]; then
+    KEEP[${key}]=${path}
+    KEEP_INODE[${key}]=${inode}
+    continue
+  fi
+  [[ ${inode} == "${KEEP_INODE[${key}]}" ]] && continue
+  keep=${KEEP[${key}]}
+
+  # Check again, then link or delete
+  # yupsh: d.replace(f, keep)
+  if ! cmp -s -- "${keep}" "${path}"; then
+    echo "dedup-files: ${path}: not the same as ${keep} after all, skipped" >&2
+    FAILED=$(( FAILED + 1 ))
+    continue
+  fi
+  if (( DELETE )); then
+    line="delete ${path} (same as ${keep})"
+  else
+    line="link ${path} -> ${keep}"
+  fi
+  if (( ! APPLY )); then
+    echo "[dry-run] ${line}"
+  else
+    echo "${line}"
+    if (( DELETE )); then
+      rm -- "${path}" || { FAILED=$(( FAILED + 1 )); continue; }
+    else
+      ln -- "${keep}" "${path}.dedup-tmp" && mv -- "${path}.dedup-tmp" "${path}" ||
+        { rm -f -- "${path}.dedup-tmp"; FAILED=$(( FAILED + 1 )); continue; }
+    fi
+  fi
+
+  # Count each inode once, with its first path replaced
+  if [[ -z ${COUNTED[${inode}]} ]]; then
+    COUNTED[${inode}]=1
+    DUPLICATES=$(( DUPLICATES + 1 ))
+    SAVED=$(( SAVED + size ))
+  fi
+done <<< "${LIST}"
+
+# yupsh: size.Format(d.saved), with --human
+saved="${SAVED} bytes"
+if (( HUMAN )); then
+  saved=$(numfmt --to=iec --round=nearest --format=%.1f "${SAVED}")
+fi
+if (( APPLY )); then
+  echo "dedup-files: ${DUPLICATES} duplicate(s), ${saved} saved" >&2
+else
+  echo "dedup-files: dry run: ${DUPLICATES} duplicate(s), ${saved} would be saved" >&2
+fi
+if (( FAILED )); then
+  echo "dedup-files: ${FAILED} file(s) could not be checked or replaced" >&2
+  exit 1
+fi
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	digest `github.com/yupsh/script-examples/internal/digest`
+	dryrun `github.com/yupsh/script-examples/internal/dryrun`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	size `github.com/yupsh/script-examples/internal/size`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Find the files in a tree with the same content, keep the first of each
+// set and replace the others with hard links to it, or delete them
+// Shell equivalent: See dedup-files.sh
+//
+//   dedup-files sample
+//   [dry-run] link sample/backup/photo.b64 -> sample/2024/photo.b64
+//   [dry-run] link sample/backup/report.txt -> sample/2024/report.txt
+//   [dry-run] link sample/misc/report (copy).txt -> sample/2024/report.txt
+//   dedup-files: dry run: 3 duplicate(s), 5620 bytes would be saved
+//
+// Without --apply, nothing is changed: each change is printed marked
+// "[dry-run]" (the examples' dry-run convention, see internal/dryrun), so
+// the default run is the one that can't lose data. --dry-run is accepted as
+// in the other examples, and wins over --apply. With --apply, each
+// duplicate is replaced by a hard link to the file kept, which is the first
+// of its set in path order; with --delete it is removed instead.
+//
+// Only files of the same size can have the same content, so the files are
+// grouped by size first, and only the sizes shared by two files or more are
+// hashed. The paths that are already hard links to one file count as one
+// copy: they are hashed once, and never replaced. Empty files, and any
+// smaller than --min-size, are left alone.
+//
+// Before a duplicate is replaced, it is checked again: it must still be the
+// file that was hashed, and compare equal to the kept file byte by byte.
+// A link is made under a temporary name next to the duplicate, then renamed
+// over it, so the duplicate's path never goes missing. A duplicate shares
+// its kept file's owner, mode and times once linked.
+//
+// The exit status is 1 if a file couldn't be read or replaced, and 2 if the
+// tree couldn't be walked at all (see internal/status).
+//
+// Usage: dedup-files [--apply] [--dry-run] [--delete] [--algo sha256|sha1|md5] [--min-size SIZE] [--human] [directory]
+func main() {
+	opts := flags.New("dedup-files", "[directory]")
+	apply := opts.Bool("apply", false, "make the changes; without it, only print them, as --dry-run does")
+	dryRun := opts.DryRun()
+	remove := opts.Bool("delete", false, "delete the duplicates, instead of linking them to the file kept")
+	algo := opts.Choice("algo", "hash algorithm", digest.Algorithms...)
+	minSize := opts.Size("min-size", 1, "leave alone the files smaller than `SIZE`")
+	human := opts.Human()
+	opts.Parse()
+	if *minSize < 1 {
+		opts.Fail("--min-size must be at least 1")
+	}
+	dir := opts.ArgOr(0, ".")
+
+	// find only warns about a missing directory, so check it up front
+	if err := input.Dir(dir); err != nil {
+		status.Result{Err: err}.Exit("dedup-files")
+	}
+
+	d := &deduper{algo: *algo, minSize: *minSize, remove: *remove, dryRun: *dryRun || !*apply}
+
+	err := gloo.Run(pipe.Pipeline(
+		// Shell: find "${DIR}" -type f -size +$((MIN_BYTES - 1))c -printf '%s\t%D:%i\t%p\n'
+		find.Find(find.Dir(dir), find.FileType),
+		While(d.file, input.WholeLine),
+	))
+	if err != nil {
+		status.Result{Err: err}.Exit("dedup-files")
+	}
+
+	d.hash()
+	d.dedup()
+
+	saved := fmt.Sprintf("%d bytes", d.saved)
+	if *human {
+		saved = size.Format(d.saved)
+	}
+	if d.dryRun {
+		fmt.Fprintf(os.Stderr, "dedup-files: dry run: %d duplicate(s), %s would be saved\n", d.duplicates, saved)
+	} else {
+		fmt.Fprintf(os.Stderr, "dedup-files: %d duplicate(s), %s saved\n", d.duplicates, saved)
+	}
+	if d.failed > 0 {
+		fmt.Fprintf(os.Stderr, "dedup-files: %d file(s) could not be checked or replaced\n", d.failed)
+		os.Exit(1)
+	}
+}
+
+// file is a path found in the tree
+type file struct {
+	path  string
+	info  fs.FileInfo
+	inode *inode
+}
+
+// inode is one file on disk, which the paths that are hard links to it
+// share: one copy of its content
+type inode struct {
+	first *file  // the first of its paths: hashed for them all
+	sum   string // its digest, or "" if it wasn't hashed
+	paths int    // how many of the files are links to it
+	done  int    // how many of them were replaced
+}
+
+// deduper finds the duplicates and replaces them
+type deduper struct {
+	algo    string
+	minSize int64
+	remove  bool
+	dryRun  bool
+
+	files      []*file
+	duplicates int   // inodes replaced (or, with the dry run, that would be)
+	saved      int64 // bytes they took up
+	failed     int
+}
+
+// file is the While() callback: it notes the size and identity of each file
+// big enough to matter
+func (d *deduper) file(args ...any) gloo.Command {
+	path := args[0].(string)
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		d.fail(err)
+		return nil
+	}
+	if info.Size() >= d.minSize {
+		d.files = append(d.files, &file{path: path, info: info})
+	}
+	return nil
+}
+
+// hash works out which files are links to the same inode, and hashes one
+// path of each inode whose size another inode has too
+//
+// Shell equivalent:
+//   "${ALGO}sum" -- "${path}"
+func (d *deduper) hash() {
+	// Shell: LC_ALL=C sort -t$'\t' -k3
+	slices.SortFunc(d.files, func(a, b *file) int { return cmp.Compare(a.path, b.path) })
+
+	bySize := map[int64][]*inode{}
+	for _, f := range d.files {
+		inodes := bySize[f.info.Size()]
+		i := slices.IndexFunc(inodes, func(n *inode) bool { return os.SameFile(n.first.info, f.info) })
+		if i >= 0 {
+			f.inode = inodes[i]
+		} else {
+			f.inode = &inode{first: f}
+			bySize[f.info.Size()] = append(inodes, f.inode)
+		}
+		f.inode.paths++
+	}
+
+	for _, inodes := range bySize {
+		if len(inodes) < 2 {
+			continue // a size no other inode has: unique
+		}
+		for _, n := range inodes {
+			sum, err := digest.File(n.first.path, d.algo)
+			if err != nil {
+				d.fail(err)
+				continue // never a duplicate, nor kept
+			}
+			n.sum = sum
+		}
+	}
+}
+
+// dedup goes through the files in path order: the first file with some
+// content is kept, and every later one that isn't a link to it is replaced
+func (d *deduper) dedup() {
+	type content struct {
+		size int64
+		sum  string
+	}
+	kept := map[content]*file{}
+
+	for _, f := range d.files {
+		if f.inode.sum == "" {
+			continue // unique, or unreadable
+		}
+		key := content{f.info.Size(), f.inode.sum}
+		keep, ok := kept[key]
+		if !ok {
+			kept[key] = f
+			continue
+		}
+		if f.inode == keep.inode {
+			continue // already a link to the file kept
+		}
+
+		if err := d.replace(f, keep); err != nil {
+			d.fail(err)
+			continue
+		}
+		f.inode.done++
+		if f.inode.done == f.inode.paths {
+			// The last path to this inode is gone: its space is free
+			d.duplicates++
+			d.saved += f.info.Size()
+		}
+	}
+}
+
+// replace links dup to keep, or deletes it, after checking that it is still
+// the file that was hashed and the same as keep; with the dry run it only
+// prints what it would do
+//
+// Shell equivalent:
+//   cmp -s -- "${keep}" "${dup}" && ln -- "${keep}" "${dup}.dedup-tmp" && mv -- "${dup}.dedup-tmp" "${dup}"
+func (d *deduper) replace(dup, keep *file) error {
+	now, err := os.Lstat(dup.path)
+	if err != nil {
+		return err
+	}
+	if !os.SameFile(now, dup.info) || now.Size() != dup.info.Size() || !now.ModTime().Equal(dup.info.ModTime()) {
+		return fmt.Errorf("%s: changed since it was hashed, skipped", dup.path)
+	}
+	same, err := identical(keep.path, dup.path)
+	if err != nil {
+		return err
+	}
+	if !same {
+		return fmt.Errorf("%s: not the same as %s after all, skipped", dup.path, keep.path)
+	}
+
+	if d.remove {
+		fmt.Println(dryrun.Line(d.dryRun, fmt.Sprintf("delete %s (same as %s)", dup.path, keep.path)))
+		if d.dryRun {
+			return nil
+		}
+		return os.Remove(dup.path)
+	}
+
+	fmt.Println(dryrun.Line(d.dryRun, fmt.Sprintf("link %s -> %s", dup.path, keep.path)))
+	if d.dryRun {
+		return nil
+	}
+	// A link can't replace a file in one step: link under a new name, which
+	// fails rather than overwrite if it exists, then rename it over dup
+	tmp := dup.path + ".dedup-tmp"
+	if err := os.Link(keep.path, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, dup.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// identical reports whether two files have the same bytes
+// Shell: cmp -s -- "$a" "$b"
+func identical(a, b string) (bool, error) {
+	fa, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fa.Close()
+	fb, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fb.Close()
+
+	bufA := make([]byte, 64*1024)
+	bufB := make([]byte, len(bufA))
+	for {
+		na, errA := io.ReadFull(fa, bufA)
+		nb, errB := io.ReadFull(fb, bufB)
+		if !bytes.Equal(bufA[:na], bufB[:nb]) {
+			return false, nil
+		}
+		endA := errors.Is(errA, io.EOF) || errors.Is(errA, io.ErrUnexpectedEOF)
+		endB := errors.Is(errB, io.EOF) || errors.Is(errB, io.ErrUnexpectedEOF)
+		switch {
+		case errA != nil && !endA:
+			return false, fmt.Errorf("%s: %w", a, errA)
+		case errB != nil && !endB:
+			return false, fmt.Errorf("%s: %w", b, errB)
+		case endA || endB:
+			return endA && endB, nil
+		}
+	}
+}
+
+// fail reports a file that couldn't be checked or replaced
+func (d *deduper) fail(err error) {
+	fmt.Fprintf(os.Stderr, "dedup-files: %v\n", err)
+	d.failed++
+}
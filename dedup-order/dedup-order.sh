@@ -0,0 +1,9 @@
+#!/bin/bash
+set -e
+
+# Drop duplicate lines, keeping the first occurrence in its original place
+# yupsh equivalent: See main.go
+
+# Print a line only the first time it is seen
+# yupsh: While(d.line, input.WholeLine) with a seen-set map
+cat "$@" | awk '!seen[$0]++'
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Drop duplicate lines, keeping the first occurrence in its original place
+// Shell equivalent: See dedup-order.sh
+//
+// uniq only drops *adjacent* duplicates, so the usual idiom sorts first:
+//   sort | uniq          b a b c a  ->  a b c        (order lost)
+// dedup-order keeps first-seen order instead:
+//   dedup-order          b a b c a  ->  b a c
+//
+// Memory tradeoff: uniq needs only the previous line, but here every
+// distinct line must be remembered until the end of the input, since a
+// duplicate may turn up at any point. Memory grows with the number (and
+// length) of distinct lines, not with the input size: a huge log with few
+// distinct lines is cheap, a huge file of unique lines costs about as much
+// as holding it all. sort | uniq has the same cost, as sort buffers, and
+// also loses the order.
+//
+// Usage: dedup-order [file...]
+func main() {
+	opts := flags.New("dedup-order", "[file...]")
+	opts.Parse()
+
+	d := &deduper{seen: make(map[string]struct{})}
+
+	// Shell: awk '!seen[$0]++' "$@"
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		While(d.line, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dedup-order: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// deduper remembers every distinct line seen so far
+type deduper struct {
+	seen map[string]struct{}
+}
+
+// line is the While() callback: it echoes a line the first time it appears
+// Shell: !seen[$0]++
+func (d *deduper) line(args ...any) gloo.Command {
+	line := args[0].(string)
+	if _, dup := d.seen[line]; dup {
+		return nil
+	}
+	d.seen[line] = struct{}{}
+	return echo.Echo(line)
+}
@@ -0,0 +1,50 @@
+#!/bin/bash
+set -e
+
+# Report the text encoding of every file under a directory
+# yupsh equivalent: See main.go
+
+# Get directory from argument or use current directory
+# yupsh: dir := opts.ArgOr(0, ".")
+DIR=${1:-.}
+
+# Number of bytes in a byte stream after deleting some byte ranges
+# yupsh: the byte loop in detect()
+count_without() {
+  LC_ALL=C tr -d "$1" | wc -c
+}
+
+# Find every file, inspect the first 8KB, print "path: encoding"
+# yupsh: find.Find(...), sort.Sort(), While(detectFile, input.WholeLine)
+find "${DIR}" -type f | sort | while IFS= read -r file; do
+  # yupsh: chunk := make([]byte, sniffSize); io.ReadFull(f, chunk)
+  chunk_size=$(head -c 8192 "${file}" | wc -c)
+  bom=$(head -c 3 "${file}" | od -An -tx1 | tr -d ' \n')
+
+  # yupsh: switch on len(b) and bytes.HasPrefix(...)
+  if (( chunk_size == 0 )); then
+    encoding=empty
+  elif [[ ${bom} == efbbbf ]]; then
+    encoding="UTF-8 (BOM)"
+  elif [[ ${bom} == fffe* ]]; then
+    encoding=UTF-16LE
+  elif [[ ${bom} == feff* ]]; then
+    encoding=UTF-16BE
+  # Control bytes other than \t \n \v \f \r and ESC
+  # yupsh: (c < 0x20 && !isTextControl(c)) || c == 0x7F
+  elif (( $(head -c 8192 "${file}" | count_without '\000-\010\016-\032\034-\037\177') != chunk_size )); then
+    encoding=binary
+  # No bytes with the high bit set
+  # yupsh: ascii stays true
+  elif (( $(head -c 8192 "${file}" | count_without '\200-\377') == chunk_size )); then
+    encoding=ASCII
+  # (iconv rejects a character split at the 8KB mark; main.go trims it first)
+  # yupsh: utf8.Valid(trimPartialRune(b))
+  elif head -c 8192 "${file}" | iconv -f UTF-8 -t UTF-8 >/dev/null 2>&1; then
+    encoding=UTF-8
+  else
+    encoding=Latin-1
+  fi
+
+  echo "${file}: ${encoding}"
+done
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	echo `github.com/yupsh/echo`
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	sort `github.com/yupsh/sort`
+	. `github.com/yupsh/while`
+)
+
+// Report the text encoding of every file under a directory
+// Shell equivalent: See detect-encoding.sh
+//
+// Prints "path: encoding" for each file, where encoding is one of:
+//   empty                 no bytes at all
+//   UTF-8 (BOM)           starts with EF BB BF
+//   UTF-16LE / UTF-16BE   starts with FF FE / FE FF
+//   binary                contains NUL or other non-text control bytes
+//   ASCII                 7-bit text (also valid UTF-8)
+//   UTF-8                 valid UTF-8 with multi-byte characters
+//   Latin-1               8-bit text that isn't valid UTF-8
+//
+// Only the first 8KB of each file is inspected, like `file --mime-encoding`,
+// so a large file is classified as quickly as a small one. Run it before bulk
+// processing to find the files that need iconv first; see samples/ for one
+// file of each kind.
+//
+// Usage: detect-encoding [directory]
+func main() {
+	opts := flags.New("detect-encoding", "[directory]")
+	opts.Parse()
+	dir := opts.ArgOr(0, ".")
+
+	// Shell: find "${DIR}" -type f | sort | while read -r f; do file --mime-encoding "$f"; done
+	err := gloo.Run(pipe.Pipeline(
+		find.Find(find.Dir(dir), find.FileType),
+		sort.Sort(),
+		While(detectFile, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "detect-encoding: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// sniffSize is how much of each file is inspected
+const sniffSize = 8 << 10
+
+// detectFile reads the start of one file and prints its encoding
+// Shell: file --brief --mime-encoding "$f"
+func detectFile(args ...any) gloo.Command {
+	path := args[0].(string)
+
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "detect-encoding: warning: %v\n", err)
+		return nil
+	}
+	defer f.Close()
+
+	// Shell: head -c 8192 "$f"
+	chunk := make([]byte, sniffSize)
+	n, err := io.ReadFull(f, chunk)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		fmt.Fprintf(os.Stderr, "detect-encoding: warning: %s: %v\n", path, err)
+		return nil
+	}
+
+	// A short read means the chunk is the whole file
+	return echo.Echo(fmt.Sprintf("%s: %s", path, detect(chunk[:n], n == sniffSize)))
+}
+
+// detect classifies the leading bytes of a file; truncated tells whether
+// the file goes on past them
+func detect(b []byte, truncated bool) string {
+	switch {
+	case len(b) == 0:
+		return "empty"
+	case bytes.HasPrefix(b, []byte{0xEF, 0xBB, 0xBF}):
+		return "UTF-8 (BOM)"
+	case bytes.HasPrefix(b, []byte{0xFF, 0xFE}):
+		return "UTF-16LE"
+	case bytes.HasPrefix(b, []byte{0xFE, 0xFF}):
+		return "UTF-16BE"
+	}
+
+	ascii := true
+	for _, c := range b {
+		if (c < 0x20 && !isTextControl(c)) || c == 0x7F {
+			return "binary"
+		}
+		if c >= 0x80 {
+			ascii = false
+		}
+	}
+	if ascii {
+		return "ASCII"
+	}
+
+	// The chunk may end partway through a multi-byte character
+	if truncated {
+		b = trimPartialRune(b)
+	}
+	if utf8.Valid(b) {
+		return "UTF-8"
+	}
+	return "Latin-1"
+}
+
+// isTextControl reports whether a control byte is common in text files:
+// tab, newline, vertical tab, form feed, carriage return, or escape
+func isTextControl(c byte) bool {
+	return c == '\t' || c == '\n' || c == '\v' || c == '\f' || c == '\r' || c == 0x1B
+}
+
+// trimPartialRune drops an incomplete UTF-8 sequence at the end of b
+func trimPartialRune(b []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(b); i++ {
+		if utf8.RuneStart(b[len(b)-i]) {
+			if !utf8.FullRune(b[len(b)-i:]) {
+				return b[:len(b)-i]
+			}
+			break
+		}
+	}
+	return b
+}
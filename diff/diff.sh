@@ -0,0 +1,22 @@
+#!/bin/bash
+
+# Compare two files line by line and print the differences as a unified diff
+# yupsh equivalent: See main.go
+
+# Parse flags (--context N), then the two files
+# yupsh: context := opts.Int("context", 3, ...)
+CONTEXT=3
+while [[ $1 == --* ]]; do
+  case $1 in
+    --context) CONTEXT=$2; shift 2 ;;
+    *) echo "Usage: $0 [--context N] old new" >&2; exit 2 ;;
+  esac
+done
+if (( $# != 2 )); then
+  echo "Usage: $0 [--context N] old new" >&2
+  exit 2
+fi
+
+# --label keeps the file names in the header without diff's timestamps
+# yupsh: compare(old, new, *context) — LCS edits grouped by hunks()
+diff -U "${CONTEXT}" --label "$1" --label "$2" "$1" "$2"
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+)
+
+// maxCells bounds the LCS table (rows x columns, after the common prefix and
+// suffix are trimmed), so two large, very different files fail cleanly
+// instead of exhausting memory
+const maxCells = 25_000_000
+
+// Compare two files line by line and print the differences as a unified diff
+// Shell equivalent: See diff.sh
+//
+//   diff --context 1 old.conf new.conf
+//   --- old.conf
+//   +++ new.conf
+//   @@ -2,4 +2,5 @@
+//    listen = 8080
+//   -workers = 4
+//   +workers = 8
+//    timeout = 30
+//    log = info
+//   +cache = on
+//
+// The changes come from a longest common subsequence (LCS) of the two files'
+// lines: lines in the LCS are unchanged, the rest of the old file is
+// removed (-) and the rest of the new file is added (+). Changes are shown in
+// hunks with --context unchanged lines around them, as diff -u does, and a
+// file's last line is followed by "\ No newline at end of file" when the
+// file doesn't end in one, so that patch can put it back as it was.
+//
+// As with diff, the exit status is 0 if the files are the same, 1 if they
+// differ and 2 on trouble.
+//
+// Usage: diff [--context N] old new
+func main() {
+	opts := flags.New("diff", "old new")
+	context := opts.Int("context", 3, "show `N` unchanged lines around each change")
+	opts.Parse()
+	if opts.NArg() != 2 {
+		opts.Fail("want two files to compare")
+	}
+	if *context < 0 {
+		opts.Fail("--context must not be negative")
+	}
+
+	differ, err := compare(opts.Arg(0), opts.Arg(1), *context)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "diff: %v\n", err)
+		os.Exit(2)
+	}
+	if differ {
+		os.Exit(1)
+	}
+}
+
+// compare prints the unified diff of two files and reports whether they
+// differ
+func compare(oldName, newName string, context int) (bool, error) {
+	a, err := readLines(oldName)
+	if err != nil {
+		return false, err
+	}
+	b, err := readLines(newName)
+	if err != nil {
+		return false, err
+	}
+	edits, err := diffLines(a, b)
+	if err != nil || !changed(edits) {
+		return false, err
+	}
+
+	// Shell: diff -u old new
+	gloo.MustRun(echo.Echo("--- " + oldName + "\n+++ " + newName))
+	for _, h := range hunks(edits, context) {
+		gloo.MustRun(echo.Echo(h.String()))
+	}
+	return true, nil
+}
+
+// readLines returns the lines of a file, or of stdin for "-", each ending in
+// "\n", except the last line of a file that doesn't end in a newline
+//
+// Keeping the newline in the line makes a last line without one differ from
+// the same text with one, as it does for diff, so a change to just the end
+// of the file still shows. A "\r" before the newline is dropped, as
+// bufio.ScanLines does.
+func readLines(name string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if name != input.Stdin {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var lines []string
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			text, eol := strings.CutSuffix(line, "\n")
+			text = strings.TrimSuffix(text, "\r")
+			if eol {
+				text += "\n"
+			}
+			lines = append(lines, text)
+		}
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+}
+
+// edit is one line of the diff: kept (' '), removed ('-') or added ('+')
+type edit struct {
+	op   byte
+	text string // with its "\n", if it has one
+}
+
+// noNewline follows a line of a hunk that has no newline at the end of its
+// file, as diff -u marks it
+// Shell: printf 'last' > new.txt; diff -u old.txt new.txt
+const noNewline = `\ No newline at end of file`
+
+// diffLines returns the edits that turn a into b
+func diffLines(a, b []string) ([]edit, error) {
+	// Lines shared at the start and end are unchanged whatever the LCS, and
+	// trimming them keeps the table small for typical, local edits
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if (len(midA)+1)*(len(midB)+1) > maxCells {
+		return nil, fmt.Errorf("files differ in too many lines to compare (%d x %d)", len(midA), len(midB))
+	}
+
+	edits := make([]edit, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		edits = append(edits, edit{' ', line})
+	}
+	edits = append(edits, lcsEdits(midA, midB)...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, edit{' ', line})
+	}
+	return edits, nil
+}
+
+// lcsEdits diffs a and b through their longest common subsequence
+//
+// lcs[i][j] is the length of the LCS of a[i:] and b[j:], filled from the
+// end. Walking forward from lcs[0][0], equal lines are kept, and otherwise
+// the step that keeps the longer LCS is taken, preferring removals, so each
+// change lists its removed lines before its added ones.
+func lcsEdits(a, b []string) []edit {
+	width := len(b) + 1
+	lcs := make([]int32, (len(a)+1)*width)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			default:
+				lcs[i*width+j] = max(lcs[(i+1)*width+j], lcs[i*width+j+1])
+			}
+		}
+	}
+
+	var edits []edit
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i]})
+			i, j = i+1, j+1
+		case j == len(b) || (i < len(a) && lcs[(i+1)*width+j] >= lcs[i*width+j+1]):
+			edits = append(edits, edit{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j]})
+			j++
+		}
+	}
+	return edits
+}
+
+// changed reports whether any edit removes or adds a line
+func changed(edits []edit) bool {
+	for _, e := range edits {
+		if e.op != ' ' {
+			return true
+		}
+	}
+	return false
+}
+
+// hunk is a run of edits with its position in both files
+type hunk struct {
+	oldStart, newStart int // 0-based index of the hunk's first line in each file
+	edits              []edit
+}
+
+// hunks groups the changes with context unchanged lines on either side.
+// Changes separated by at most 2*context unchanged lines share a hunk, since
+// their context would otherwise overlap.
+func hunks(edits []edit, context int) []hunk {
+	var out []hunk
+	first, last := -1, -1 // edits of the open hunk's first line and last change
+	var cur hunk
+
+	// flush closes the open hunk after the trailing context of its last change
+	flush := func() {
+		cur.edits = edits[first:min(len(edits), last+1+context)]
+		out = append(out, cur)
+		first = -1
+	}
+
+	oldLine, newLine := 0, 0 // lines of each file before edits[i]
+	for i, e := range edits {
+		if e.op != ' ' {
+			if first >= 0 && i-last-1 > 2*context {
+				flush()
+			}
+			if first < 0 {
+				// The leading context is all unchanged lines: either the
+				// start of the files or the gap after the previous hunk
+				first = max(0, i-context)
+				cur = hunk{oldStart: oldLine - (i - first), newStart: newLine - (i - first)}
+			}
+			last = i
+		}
+
+		switch e.op {
+		case ' ':
+			oldLine, newLine = oldLine+1, newLine+1
+		case '-':
+			oldLine++
+		case '+':
+			newLine++
+		}
+	}
+	if first >= 0 {
+		flush()
+	}
+	return out
+}
+
+// String formats the hunk as diff -u does: a "@@ -old +new @@" header, then
+// one line per edit prefixed by ' ', '-' or '+', each followed by noNewline
+// if its file ends there without a newline
+func (h hunk) String() string {
+	oldCount, newCount := 0, 0
+	lines := make([]string, 0, len(h.edits)+1)
+	for _, e := range h.edits {
+		if e.op != '+' {
+			oldCount++
+		}
+		if e.op != '-' {
+			newCount++
+		}
+		text, eol := strings.CutSuffix(e.text, "\n")
+		lines = append(lines, string(e.op)+text)
+		if !eol {
+			lines = append(lines, noNewline)
+		}
+	}
+
+	header := fmt.Sprintf("@@ -%s +%s @@", unifiedRange(h.oldStart, oldCount), unifiedRange(h.newStart, newCount))
+	return strings.Join(append([]string{header}, lines...), "\n")
+}
+
+// unifiedRange formats a hunk's line range in one file: "start,count" with a
+// 1-based start, where a count of 1 is left out and an empty range names
+// the line before it
+func unifiedRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprint(start + 1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
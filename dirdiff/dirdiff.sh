@@ -0,0 +1,85 @@
+#!/bin/bash
+set -eo pipefail
+
+# Compare two snapshots of a directory tree and print the files added,
+# removed and changed, grouped, in color
+# yupsh equivalent: See main.go
+#
+# The listings are tab-separated lines and manifests are split on commas,
+# so paths with a tab, a comma, a quote or a newline are not supported here.
+
+usage() {
+  echo "Usage: $0 [--color auto|always|never] old new" >&2
+  exit 2
+}
+
+# Parse flags (--color WHEN), then the two snapshots
+# yupsh: color := opts.Choice("color", ..., "auto", "always", "never")
+COLOR=auto
+while [[ $1 == --* ]]; do
+  case $1 in
+    --color) COLOR=$2; shift 2 ;;
+    *) usage ;;
+  esac
+done
+[[ ${COLOR} =~ ^(auto|always|never)$ ]] || usage
+(( $# == 2 )) || usage
+
+# yupsh: colored := *color == "always" || (*color == "auto" && term.IsTerminal(os.Stdout))
+COLORED=0
+if [[ ${COLOR} == always || ( ${COLOR} == auto && -t 1 ) ]]; then
+  COLORED=1
+fi
+
+# A snapshot as "path TAB size TAB mtime TAB sha256" lines, from a
+# directory (no sha256) or an index manifest, with times in UTC
+# yupsh: load(path), with scan(root) or readManifest(f)
+listing() {
+  if [[ -d $1 ]]; then
+    (cd "$1" && TZ=UTC find . -type f -printf '%P\t%s\t%TY-%Tm-%TdT%TH:%TM:%TS\t\n') \
+      | awk -F'\t' -v OFS='\t' '{ sub(/\.[0-9]+$/, "", $3); $3 = $3 "Z"; print }'
+  elif [[ -f $1 ]]; then
+    awk -F, -v OFS='\t' -v name="$1" '
+      NR == 1 {
+        for (i = 1; i <= NF; i++) col[$i] = i
+        if (!("path" in col) || !("size" in col) || !("mtime" in col)) {
+          printf "dirdiff: %s: not an index manifest\n", name > "/dev/stderr"
+          exit 2
+        }
+        next
+      }
+      { print $col["path"], $col["size"], $col["mtime"], ("sha256" in col) ? $col["sha256"] : "" }' "$1"
+  else
+    echo "dirdiff: stat $1: no such file or directory" >&2
+    exit 2
+  fi
+}
+OLD_LIST=$(mktemp)
+NEW_LIST=$(mktemp)
+trap 'rm -f "${OLD_LIST}" "${NEW_LIST}"' EXIT
+listing "$1" | LC_ALL=C sort -t$'\t' -k1,1 > "${OLD_LIST}"
+listing "$2" | LC_ALL=C sort -t$'\t' -k1,1 > "${NEW_LIST}"
+
+# Categorize every path, then print the categories in turn
+# yupsh: compare(old, cur), then d.write(os.Stdout, colored)
+awk -F'\t' -v colored="${COLORED}" '
+  function paint(color, text) { return colored ? color text "\033[m" : text }
+
+  FNR == NR { paths[++n] = $1; size[$1] = $2; mtime[$1] = $3; sum[$1] = $4; next }
+
+  # yupsh: the switch in compare()
+  !($1 in size) { added[++na] = $1; next }
+  $2 != size[$1] { changed[++nc] = $1 " (" size[$1] " -> " $2 " bytes)" }
+  $2 == size[$1] && $4 != "" && sum[$1] != "" && $4 != sum[$1] { changed[++nc] = $1 " (content)" }
+  $2 == size[$1] && ($4 == "" || sum[$1] == "") && $3 != mtime[$1] { changed[++nc] = $1 " (time)" }
+  { delete size[$1] }
+
+  END {
+    for (i = 1; i <= n; i++) if (paths[i] in size) removed[++nr] = paths[i]
+    if (na + nr + nc == 0) exit 0
+    if (na) { print paint("\033[1m", "Added"); for (i = 1; i <= na; i++) print "  " paint("\033[32m", "+ " added[i]) }
+    if (nr) { print paint("\033[1m", "Removed"); for (i = 1; i <= nr; i++) print "  " paint("\033[31m", "- " removed[i]) }
+    if (nc) { print paint("\033[1m", "Changed"); for (i = 1; i <= nc; i++) print "  " paint("\033[33m", "~ " changed[i]) }
+    printf "\n%d added, %d removed, %d changed\n", na, nr, nc
+    exit 1
+  }' "${OLD_LIST}" "${NEW_LIST}"
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	term `github.com/yupsh/script-examples/internal/term`
+	. `github.com/yupsh/while`
+)
+
+// Compare two snapshots of a directory tree and print the files added,
+// removed and changed, grouped, in color
+// Shell equivalent: See dirdiff.sh
+//
+//   dirdiff before.csv after.csv
+//   Added
+//     + posts/new.md
+//   Removed
+//     - old.html
+//   Changed
+//     ~ css/style.css (content)
+//     ~ posts/hello.md (21 -> 63 bytes)
+//
+//   1 added, 1 removed, 2 changed
+//
+// A snapshot is a directory, read as it is now, or a manifest written
+// earlier by the index example, so a tree can be compared with itself last
+// week:
+//   index --hash site > site.csv    # ... later ...
+//   dirdiff site.csv site
+//
+// A file has changed when its size has, or, when both snapshots have its
+// sha256, its content has; otherwise, when its modification time has, as
+// in sync-check. So with two hashed manifests, a file that was only touched
+// isn't listed.
+//
+// --color decides when the +, - and ~ lines are green, red and yellow:
+//   auto     only when stdout is a terminal (the default)
+//   always   even into a pipe, e.g. for `less -R`
+//   never    plain text
+//
+// As with diff, the exit status is 0 if the snapshots match (and nothing
+// is printed), 1 if they differ and 2 on trouble.
+//
+// Usage: dirdiff [--color auto|always|never] old new
+func main() {
+	opts := flags.New("dirdiff", "old new")
+	color := opts.Choice("color", "when to color the output", "auto", "always", "never")
+	opts.Parse()
+	if opts.NArg() != 2 {
+		opts.Fail("want two snapshots: directories or index manifests")
+	}
+
+	old, err := load(opts.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dirdiff: %v\n", err)
+		os.Exit(2)
+	}
+	cur, err := load(opts.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dirdiff: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Shell: [ -t 1 ] && COLOR=always || COLOR=never
+	colored := *color == "always" || (*color == "auto" && term.IsTerminal(os.Stdout))
+
+	d := compare(old, cur)
+	if err := d.write(os.Stdout, colored); err != nil {
+		fmt.Fprintf(os.Stderr, "dirdiff: %v\n", err)
+		os.Exit(2)
+	}
+	if d.len() > 0 {
+		os.Exit(1)
+	}
+}
+
+// entry is what a snapshot records about a file
+type entry struct {
+	size  int64
+	mtime time.Time
+	sum   string // the sha256, if the snapshot has one
+}
+
+// snapshot is a tree's regular files, by their slash-separated path
+// relative to its root
+type snapshot map[string]entry
+
+// load reads the snapshot at path: a directory, or an index manifest
+func load(path string) (snapshot, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return scan(path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s, err := readManifest(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// scan walks the tree under root into a snapshot, as sync-check does
+//
+// Shell equivalent:
+//   (cd "${root}" && find . -type f -printf '%P\t%s\t%Ts\n')
+func scan(root string) (snapshot, error) {
+	s := snapshot{}
+	var walkErr error
+	err := gloo.Run(pipe.Pipeline(
+		find.Find(find.Dir(root), find.FileType),
+		While(func(args ...any) gloo.Command {
+			path := args[0].(string)
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				walkErr = err
+				return nil
+			}
+			info, err := os.Lstat(path)
+			if err != nil {
+				walkErr = err
+				return nil
+			}
+			s[filepath.ToSlash(rel)] = entry{size: info.Size(), mtime: info.ModTime()}
+			return nil
+		}, input.WholeLine),
+	))
+	if err == nil {
+		err = walkErr
+	}
+	return s, err
+}
+
+// readManifest reads an index manifest into a snapshot. The columns are
+// found by name, so the mode and sha256 columns may be missing.
+//
+// Shell equivalent:
+//   awk -F, 'NR > 1 {print $1 "\t" $2 "\t" $3 "\t" $5}' manifest.csv
+func readManifest(r io.Reader) (snapshot, error) {
+	cr := csv.NewReader(bufio.NewReader(r))
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading the header: %w", err)
+	}
+	column := map[string]int{}
+	for i, name := range header {
+		column[name] = i
+	}
+	for _, name := range []string{"path", "size", "mtime"} {
+		if _, ok := column[name]; !ok {
+			return nil, fmt.Errorf("not an index manifest: no %q column", name)
+		}
+	}
+	sumColumn, hasSum := column["sha256"]
+
+	s := snapshot{}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return s, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		size, err := strconv.ParseInt(record[column["size"]], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: size %q is not a number", line, record[column["size"]])
+		}
+		mtime, err := time.Parse(time.RFC3339, record[column["mtime"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: mtime %q is not an RFC 3339 time", line, record[column["mtime"]])
+		}
+		e := entry{size: size, mtime: mtime}
+		if hasSum {
+			e.sum = record[sumColumn]
+		}
+		s[record[column["path"]]] = e
+	}
+}
+
+// change is a file that differs between the snapshots, with why
+type change struct {
+	path string
+	why  string // for a changed file: what changed
+}
+
+// diff is the files that differ, by category, each ordered by path
+type diff struct {
+	added, removed, changed []change
+}
+
+// len returns the number of files that differ
+func (d *diff) len() int {
+	return len(d.added) + len(d.removed) + len(d.changed)
+}
+
+// compare returns the differences from old to cur
+//
+// Shell equivalent:
+//   awk -F'\t' 'FNR == NR {size[$1] = $2; ...; next} ...' old.list new.list
+func compare(old, cur snapshot) *diff {
+	paths := slices.Collect(maps.Keys(old))
+	for path := range cur {
+		if _, ok := old[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	// Shell: LC_ALL=C sort
+	slices.Sort(paths)
+
+	d := &diff{}
+	for _, path := range paths {
+		a, inOld := old[path]
+		b, inCur := cur[path]
+		switch {
+		case !inOld:
+			d.added = append(d.added, change{path: path})
+		case !inCur:
+			d.removed = append(d.removed, change{path: path})
+		case a.size != b.size:
+			d.changed = append(d.changed, change{path, fmt.Sprintf("%d -> %d bytes", a.size, b.size)})
+		case a.sum != "" && b.sum != "":
+			// The content decides; the time doesn't matter
+			if a.sum != b.sum {
+				d.changed = append(d.changed, change{path, "content"})
+			}
+		case a.mtime.Unix() != b.mtime.Unix():
+			d.changed = append(d.changed, change{path, "time"})
+		}
+	}
+	return d
+}
+
+// The escape codes for each category: green, red and yellow, then reset,
+// as git diff colors added and removed lines
+const (
+	green  = "\x1b[32m"
+	red    = "\x1b[31m"
+	yellow = "\x1b[33m"
+	bold   = "\x1b[1m"
+	reset  = "\x1b[m"
+)
+
+// group is one category of the report
+type group struct {
+	title   string
+	mark    string
+	color   string
+	changes []change
+}
+
+// write prints the differences grouped by category, skipping the empty
+// ones, then a count of each; nothing at all if there are none
+//
+// Shell equivalent:
+//   printf '%s\n' Added; sed 's/^/  + /' added.txt; ...
+func (d *diff) write(w io.Writer, colored bool) error {
+	if d.len() == 0 {
+		return nil
+	}
+	paint := func(color, text string) string {
+		if !colored {
+			return text
+		}
+		return color + text + reset
+	}
+
+	out := bufio.NewWriter(w)
+	for _, g := range []group{
+		{"Added", "+", green, d.added},
+		{"Removed", "-", red, d.removed},
+		{"Changed", "~", yellow, d.changed},
+	} {
+		if len(g.changes) == 0 {
+			continue
+		}
+		fmt.Fprintln(out, paint(bold, g.title))
+		for _, c := range g.changes {
+			line := g.mark + " " + c.path
+			if c.why != "" {
+				line += " (" + c.why + ")"
+			}
+			fmt.Fprintf(out, "  %s\n", paint(g.color, line))
+		}
+	}
+	fmt.Fprintf(out, "\n%d added, %d removed, %d changed\n", len(d.added), len(d.removed), len(d.changed))
+	return out.Flush()
+}
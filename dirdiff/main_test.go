@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// golden is the report on the example's before.csv and after.csv, without
+// color, as the doc comment and README show it
+const golden = `Added
+  + posts/new.md
+Removed
+  - old.html
+Changed
+  ~ css/style.css (content)
+  ~ posts/hello.md (21 -> 63 bytes)
+
+1 added, 1 removed, 2 changed
+`
+
+// report loads the snapshots at old and cur and returns what write() prints
+func report(t *testing.T, old, cur string, colored bool) string {
+	t.Helper()
+	a, err := load(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := load(cur)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := compare(a, b).write(&out, colored); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+// TestGolden checks the --color never report on the example manifests
+func TestGolden(t *testing.T) {
+	if got := report(t, "before.csv", "after.csv", false); got != golden {
+		t.Errorf("report:\n%s\nwant:\n%s", got, golden)
+	}
+}
+
+// TestGoldenColored checks that color adds only the escape codes: without
+// them the report is the golden one
+func TestGoldenColored(t *testing.T) {
+	got := report(t, "before.csv", "after.csv", true)
+	if !strings.Contains(got, green+"+ posts/new.md"+reset) || !strings.Contains(got, bold+"Added"+reset) {
+		t.Errorf("no color in:\n%q", got)
+	}
+	plain := got
+	for _, code := range []string{green, red, yellow, bold, reset} {
+		plain = strings.ReplaceAll(plain, code, "")
+	}
+	if plain != golden {
+		t.Errorf("report without its colors:\n%s\nwant:\n%s", plain, golden)
+	}
+}
+
+// TestSame checks that a snapshot compared with itself prints nothing
+func TestSame(t *testing.T) {
+	if got := report(t, "before.csv", "before.csv", false); got != "" {
+		t.Errorf("printed %q", got)
+	}
+}
+
+// TestDirectoryAgainstManifest checks a directory read as it is now against
+// a manifest: without a sha256 on both sides, the time decides
+func TestDirectoryAgainstManifest(t *testing.T) {
+	dir := t.TempDir()
+	when := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	for name, text := range map[string]string{"same.txt": "same\n", "touched.txt": "touched\n", "sub/new.txt": "new\n"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, when, when); err != nil {
+			t.Fatal(err)
+		}
+	}
+	later := when.Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "touched.txt"), later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := filepath.Join(t.TempDir(), "old.csv")
+	err := os.WriteFile(manifest, []byte("path,size,mtime\n"+
+		"same.txt,5,2026-10-01T09:00:00Z\n"+
+		"touched.txt,8,2026-10-01T09:00:00Z\n"+
+		"gone.txt,3,2026-10-01T09:00:00Z\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `Added
+  + sub/new.txt
+Removed
+  - gone.txt
+Changed
+  ~ touched.txt (time)
+
+1 added, 1 removed, 1 changed
+`
+	if got := report(t, manifest, dir, false); got != want {
+		t.Errorf("report:\n%s\nwant:\n%s", got, want)
+	}
+}
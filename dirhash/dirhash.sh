@@ -0,0 +1,36 @@
+#!/bin/bash
+set -eo pipefail
+
+# Print one digest that fingerprints the whole content of a directory tree
+# yupsh equivalent: See main.go
+
+# Parse flags (--algo A, --verbose), then the directory
+# yupsh: algo := opts.Choice("algo", ...); verbose := opts.Bool("verbose", ...)
+ALGO=sha256
+VERBOSE=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --algo) ALGO=$2; shift 2 ;;
+    --verbose) VERBOSE=1; shift ;;
+    *) echo "Usage: $0 [--algo sha256|sha1|md5] [--verbose] [directory]" >&2; exit 2 ;;
+  esac
+done
+case ${ALGO} in
+  sha256|sha1|md5) ;;
+  *) echo "Usage: $0 [--algo sha256|sha1|md5] [--verbose] [directory]" >&2; exit 2 ;;
+esac
+cd "${1:-.}"
+
+# The manifest: relative paths, sorted byte by byte, each with its digest
+# yupsh: find.Find(...) | While(m.relative) | sort.Sort() | While(m.file)
+manifest() {
+  find . -type f -printf '%P\n' | LC_ALL=C sort | xargs -r -d '\n' "${ALGO}sum" --
+}
+
+# The fingerprint is the manifest's own digest
+# yupsh: io.WriteString(m.total, line+"\n"); total.Sum(nil)
+if (( VERBOSE )); then
+  manifest | tee /dev/stderr | "${ALGO}sum" | cut -d' ' -f1
+else
+  manifest | "${ALGO}sum" | cut -d' ' -f1
+fi
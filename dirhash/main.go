@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+
+	echo `github.com/yupsh/echo`
+	find `github.com/yupsh/find`
+	sort `github.com/yupsh/sort`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	digest `github.com/yupsh/script-examples/internal/digest`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Print one digest that fingerprints the whole content of a directory tree
+// Shell equivalent: See dirhash.sh
+//
+//   dirhash src
+//   3f1c0a...e29b
+//
+// The fingerprint is the digest of a manifest: one sha256sum-style line
+// ("digest  path") per regular file, with paths relative to the directory,
+// sorted byte by byte. It changes when any file's content changes, or a
+// file is added, removed or renamed, and not otherwise: it is the same
+// wherever the tree lives, whatever order the directory lists its entries,
+// and whatever the files' times and permissions. With --verbose the manifest
+// itself is printed before the fingerprint.
+//
+// Usage: dirhash [--algo sha256|sha1|md5] [--verbose] [directory]
+func main() {
+	opts := flags.New("dirhash", "[directory]")
+	algo := opts.Choice("algo", "hash algorithm", digest.Algorithms...)
+	verbose := opts.Bool("verbose", false, "print each file's digest before the fingerprint")
+	opts.Parse()
+	dir := opts.ArgOr(0, ".")
+
+	// find only warns about a missing directory, so check it up front
+	if err := input.Dir(dir); err != nil {
+		status.Result{Err: err}.Exit("dirhash")
+	}
+
+	total, err := digest.New(*algo)
+	if err != nil {
+		opts.Fail("%v", err)
+	}
+	m := &manifest{dir: dir, algo: *algo, verbose: *verbose, total: total}
+
+	err = gloo.Run(pipe.Pipeline(
+		// Shell: cd "${DIR}" && find . -type f -printf '%P\n'
+		find.Find(find.Dir(dir), find.FileType),
+		While(m.relative, input.WholeLine),
+
+		// Sort the paths, so the walk order can't change the result
+		// Shell: LC_ALL=C sort
+		sort.Sort(),
+
+		// Hash each file, and the resulting manifest line
+		// Shell: xargs -d '\n' sha256sum | sha256sum
+		While(m.file, input.WholeLine),
+	))
+	if err == nil {
+		err = m.err
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dirhash: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(hex.EncodeToString(total.Sum(nil)))
+}
+
+// manifest hashes the files one by one into the fingerprint
+type manifest struct {
+	dir     string
+	algo    string
+	verbose bool
+
+	total hash.Hash // the fingerprint, fed every manifest line
+	err   error     // the first file that could not be hashed
+}
+
+// relative is the first While() callback: it echoes each path relative to
+// the directory, as the manifest records it
+func (m *manifest) relative(args ...any) gloo.Command {
+	rel, err := filepath.Rel(m.dir, args[0].(string))
+	if err != nil {
+		m.fail(err)
+		return nil
+	}
+	return echo.Echo(filepath.ToSlash(rel))
+}
+
+// file is the second While() callback: it writes the file's manifest line
+// into the fingerprint, and echoes it with --verbose
+func (m *manifest) file(args ...any) gloo.Command {
+	rel := args[0].(string)
+
+	sum, err := digest.File(filepath.Join(m.dir, rel), m.algo)
+	if err != nil {
+		// A fingerprint that skipped a file would be wrong, so this fails
+		// the run; the other files are still checked, to report them all
+		m.fail(err)
+		return nil
+	}
+
+	line := digest.Line(sum, rel)
+	io.WriteString(m.total, line+"\n")
+	if m.verbose {
+		return echo.Echo(line)
+	}
+	return nil
+}
+
+// fail reports a file that can't be part of the fingerprint
+func (m *manifest) fail(err error) {
+	fmt.Fprintf(os.Stderr, "dirhash: %v\n", err)
+	if m.err == nil {
+		m.err = fmt.Errorf("%s: not every file could be hashed", m.dir)
+	}
+}
@@ -0,0 +1,93 @@
+#!/bin/bash
+set -o pipefail
+
+# Find the directories in a tree whose whole content is identical, and print
+# them in groups
+# yupsh equivalent: See main.go
+#
+# Each directory is fingerprinted on its own, as dirhash.sh does, so a file
+# is hashed once for every directory above it; the Go version hashes each
+# file once.
+
+# Parse flags (--algo A, --all), then the directory
+# yupsh: algo := opts.Choice("algo", ...); all := opts.Bool("all", ...)
+ALGO=sha256
+ALL=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --algo) ALGO=$2; shift 2 ;;
+    --all) ALL=1; shift ;;
+    *) echo "Usage: $0 [--algo sha256|sha1|md5] [--all] [directory]" >&2; exit 2 ;;
+  esac
+done
+case ${ALGO} in
+  sha256|sha1|md5) ;;
+  *) echo "Usage: $0 [--algo sha256|sha1|md5] [--all] [directory]" >&2; exit 2 ;;
+esac
+ROOT=${1:-.}
+if [[ ! -d ${ROOT} ]]; then
+  echo "dup-dirs: ${ROOT}: not a directory" >&2
+  exit 2
+fi
+
+# The fingerprint of one directory: its dirhash
+# yupsh: t.file(), writing digest.Line(sum, rel) into t.sums[dir]
+fingerprint() {
+  (cd "$1" && find . -type f -printf '%P\n' | LC_ALL=C sort \
+    | xargs -r -d '\n' "${ALGO}sum" -- | "${ALGO}sum" | cut -d' ' -f1)
+}
+
+# "fingerprint  path" for every directory with files in it; a directory
+# with a file that can't be read is left out
+# yupsh: t.sums, without the directories in t.bad
+FAILED=0
+LINES=$(mktemp)
+trap 'rm -f "${LINES}"' EXIT
+while IFS= read -r rel; do
+  dir=${ROOT%/}/${rel}
+  [[ ${ROOT} == . ]] && dir=${rel}
+  [[ -n $(find "${dir}" -type f -print -quit) ]] || continue
+  if sum=$(fingerprint "${dir}"); then
+    printf '%s  %s\n' "${sum}" "${dir}"
+  else
+    FAILED=1
+  fi
+done < <(find "${ROOT}" -mindepth 1 -type d -printf '%P\n') > "${LINES}"
+
+# Group them; unless --all, leave out a group whose directories are all
+# inside duplicate directories
+# yupsh: t.groups(*all), t.print(...)
+LC_ALL=C sort "${LINES}" | awk -v all="${ALL}" '
+  {
+    sum = substr($0, 1, index($0, "  ") - 1)
+    dir = substr($0, index($0, "  ") + 2)
+    n++; sums[n] = sum; dirs[n] = dir
+    sumOf[dir] = sum; count[sum]++
+  }
+
+  # yupsh: parent(rel)
+  function parent(dir) {
+    if (dir !~ /\//) return ""
+    sub(/\/[^\/]*$/, "", dir)
+    return dir
+  }
+
+  END {
+    # A group is kept if one of its directories is not inside a duplicate
+    for (i = 1; i <= n; i++) {
+      p = parent(dirs[i])
+      if (all || !(p in sumOf) || count[sumOf[p]] < 2) keep[sums[i]] = 1
+    }
+    for (i = 1; i <= n; i++) {
+      if (count[sums[i]] < 2 || !(sums[i] in keep)) continue
+      if (sums[i] != last && found++) print ""
+      print sums[i] "  " dirs[i]
+      last = sums[i]
+    }
+    exit found ? 0 : 1
+  }'
+status=$?
+if (( FAILED )); then
+  exit 2
+fi
+exit "${status}"
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"cmp"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	echo `github.com/yupsh/echo`
+	find `github.com/yupsh/find`
+	sort `github.com/yupsh/sort`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	digest `github.com/yupsh/script-examples/internal/digest`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Find the directories in a tree whose whole content is identical, and print
+// them in groups
+// Shell equivalent: See dup-dirs.sh
+//
+//   dup-dirs sample
+//   30deb0...  sample/backup/photos-2024
+//   30deb0...  sample/photos/2024
+//
+//   46f755...  sample/notes (copy)/ideas
+//   46f755...  sample/notes/ideas
+//   46f755...  sample/old/ideas
+//   ...
+//
+// Each directory is fingerprinted as the dirhash example does: the digest
+// of a manifest of its regular files, with paths relative to it, so two
+// directories match when they hold the same files under the same names,
+// wherever they are. Every file is hashed once; its manifest line is then
+// fed to the fingerprint of each directory above it. Each line of a group
+// is "fingerprint  path", so a group can be checked with `dirhash`, and
+// groups are separated by blank lines, as `uniq --all-repeated=separate`
+// prints them.
+//
+// When two directories match, so do their subdirectories; those groups
+// repeat what the parents' group already says and are left out, unless one
+// of the directories is a copy on its own, somewhere else. --all prints
+// every group. Directories with no files are never reported.
+//
+// Exit status follows the examples' convention (see internal/status): 0 when
+// duplicates were found, 1 when there are none, and 2 on an error, including
+// a file that couldn't be read (the directories above it are left out).
+//
+// Usage: dup-dirs [--algo sha256|sha1|md5] [--all] [directory]
+func main() {
+	opts := flags.New("dup-dirs", "[directory]")
+	algo := opts.Choice("algo", "hash algorithm", digest.Algorithms...)
+	all := opts.Bool("all", false, "also print groups inside directories that are duplicates")
+	opts.Parse()
+	root := opts.ArgOr(0, ".")
+
+	// find only warns about a missing directory, so check it up front
+	if err := input.Dir(root); err != nil {
+		status.Result{Err: err}.Exit("dup-dirs")
+	}
+
+	t := &tree{root: root, algo: *algo, sums: map[string]hash.Hash{}, bad: map[string]bool{}}
+
+	err := gloo.Run(pipe.Pipeline(
+		// Shell: find "${ROOT}" -type f -printf '%P\n'
+		find.Find(find.Dir(root), find.FileType),
+		While(t.relative, input.WholeLine),
+
+		// Sort the paths, so each directory's manifest is in dirhash's order
+		// Shell: LC_ALL=C sort
+		sort.Sort(),
+
+		// Hash each file into every directory above it
+		// Shell: (cd "${dir}" && find . -type f ... | sha256sum), for each directory
+		While(t.file, input.WholeLine),
+	))
+	if err == nil {
+		err = t.err
+	}
+
+	// Shell: sort | uniq -w64 --all-repeated=separate
+	found := t.print(t.groups(*all))
+	status.Result{Count: found, Err: err}.Exit("dup-dirs")
+}
+
+// tree holds the fingerprint of every directory under root, as its files
+// are hashed
+type tree struct {
+	root string
+	algo string
+
+	sums map[string]hash.Hash // directory (relative to root) -> its fingerprint so far
+	bad  map[string]bool      // directories with a file that couldn't be hashed
+	err  error
+}
+
+// relative is the first While() callback: it echoes each path relative to
+// the root, as the manifests record it
+func (t *tree) relative(args ...any) gloo.Command {
+	rel, err := filepath.Rel(t.root, args[0].(string))
+	if err != nil {
+		t.fail(err)
+		return nil
+	}
+	return echo.Echo(filepath.ToSlash(rel))
+}
+
+// file is the second While() callback: it hashes the file and writes its
+// manifest line, with the path relative to each directory above it, into
+// that directory's fingerprint
+func (t *tree) file(args ...any) gloo.Command {
+	rel := args[0].(string)
+
+	sum, err := digest.File(filepath.Join(t.root, rel), t.algo)
+	for dir := parent(rel); dir != "."; dir = parent(dir) {
+		if err != nil {
+			// A fingerprint that skipped a file would be wrong
+			t.bad[dir] = true
+			continue
+		}
+		h, ok := t.sums[dir]
+		if !ok {
+			h, _ = digest.New(t.algo) // the algorithm was checked by --algo
+			t.sums[dir] = h
+		}
+		io.WriteString(h, digest.Line(sum, rel[len(dir)+1:])+"\n")
+	}
+	if err != nil {
+		t.fail(err)
+	}
+	return nil
+}
+
+// fail reports a file that can't be part of the fingerprints
+func (t *tree) fail(err error) {
+	fmt.Fprintf(os.Stderr, "dup-dirs: %v\n", err)
+	if t.err == nil {
+		t.err = fmt.Errorf("%s: not every file could be hashed", t.root)
+	}
+}
+
+// group is a set of directories with the same fingerprint
+type group struct {
+	sum  string
+	dirs []string // relative to the root, sorted
+}
+
+// groups returns the groups of two or more directories with the same
+// fingerprint, ordered by fingerprint; unless all is set, a group is left
+// out when every directory in it is inside a duplicate directory
+func (t *tree) groups(all bool) []group {
+	bySum := map[string][]string{}
+	sumOf := map[string]string{}
+	for dir, h := range t.sums {
+		if t.bad[dir] {
+			continue
+		}
+		sum := hex.EncodeToString(h.Sum(nil))
+		bySum[sum] = append(bySum[sum], dir)
+		sumOf[dir] = sum
+	}
+
+	// duplicate reports whether dir has the same fingerprint as another
+	duplicate := func(dir string) bool {
+		sum, ok := sumOf[dir]
+		return ok && len(bySum[sum]) > 1
+	}
+
+	var groups []group
+	for sum, dirs := range bySum {
+		if len(dirs) < 2 {
+			continue
+		}
+		if !all && !slices.ContainsFunc(dirs, func(dir string) bool { return !duplicate(parent(dir)) }) {
+			continue
+		}
+		slices.Sort(dirs)
+		groups = append(groups, group{sum: sum, dirs: dirs})
+	}
+	slices.SortFunc(groups, func(a, b group) int { return cmp.Compare(a.sum, b.sum) })
+	return groups
+}
+
+// print writes the groups, one "fingerprint  path" line per directory and a
+// blank line between groups, and returns how many there were
+func (t *tree) print(groups []group) int {
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Println()
+		}
+		for _, dir := range g.dirs {
+			fmt.Println(digest.Line(g.sum, filepath.Join(t.root, dir)))
+		}
+	}
+	return len(groups)
+}
+
+// parent returns the directory holding rel, a slash-separated path relative
+// to the root, or "." for the root itself
+func parent(rel string) string {
+	dir, _ := filepath.Split(rel)
+	if dir == "" {
+		return "."
+	}
+	return dir[:len(dir)-1]
+}
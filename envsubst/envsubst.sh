@@ -0,0 +1,46 @@
+#!/bin/bash
+set -e
+
+# Replace ${VAR} and $VAR in the input with values from the environment
+# yupsh equivalent: See main.go
+#
+# Uses GNU envsubst (from gettext), whose optional SHELL-FORMAT argument
+# lists the variables to replace: every variable mentioned in it is
+# substituted, and all other references are left alone.
+
+# Parse flags (--only VAR,..., --keep-undefined), then the files
+# yupsh: only := opts.String("only", "", ...); keep := opts.Bool("keep-undefined", false, ...)
+ONLY=
+KEEP=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --only) ONLY=$2; shift 2 ;;
+    --keep-undefined) KEEP=1; shift ;;
+    *) echo "Usage: $0 [--only VAR,...] [--keep-undefined] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Restrict to the --only list, and (with --keep-undefined) to variables that
+# are actually set
+# yupsh: substituter.expand() checks s.only, then os.LookupEnv()
+VARS=()
+if [[ -n ${ONLY} ]]; then
+  IFS=, read -ra VARS <<< "${ONLY}"
+elif (( KEEP )); then
+  mapfile -t VARS < <(compgen -e)
+fi
+if (( KEEP )) && [[ -n ${ONLY} ]]; then
+  SET=()
+  for name in "${VARS[@]}"; do
+    printenv "${name}" > /dev/null && SET+=("${name}")
+  done
+  VARS=("${SET[@]}")
+fi
+
+# yupsh: While(s.line, input.WholeLine) with reference.ReplaceAllStringFunc()
+if [[ -n ${ONLY} ]] || (( KEEP )); then
+  # With no names left the format is just "${}", which replaces nothing
+  cat "$@" | envsubst "$(printf '${%s} ' "${VARS[@]}")"
+else
+  cat "$@" | envsubst
+fi
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// reference matches ${NAME} or $NAME; a "$" not followed by a valid name,
+// as in "$5" or "${", is not a reference and is left alone
+var reference = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// validName matches a variable name accepted by --only
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Replace ${VAR} and $VAR in the input with values from the environment
+// Shell equivalent: See envsubst.sh
+//
+//   $ HOST=db.local PORT=5432 envsubst < app.conf.tmpl
+//   url = postgres://${HOST}:$PORT/app    ->    url = postgres://db.local:5432/app
+//
+// As with GNU envsubst, an undefined variable expands to nothing. With
+// --keep-undefined its reference is left as written instead, and with
+// --only just the listed variables are replaced, so templates containing
+// unrelated dollar signs (shell snippets, prices) survive untouched.
+//
+// Usage: envsubst [--only VAR,...] [--keep-undefined] [file...]
+func main() {
+	opts := flags.New("envsubst", "[file...]")
+	only := opts.String("only", "", "replace just these comma-separated `variables`")
+	keep := opts.Bool("keep-undefined", false, "leave references to undefined variables as they are")
+	opts.Parse()
+
+	s := &substituter{keepUndefined: *keep}
+	if *only != "" {
+		s.only = make(map[string]bool)
+		for _, name := range strings.Split(*only, ",") {
+			if !validName.MatchString(name) {
+				opts.Fail("--only: %q is not a variable name", name)
+			}
+			s.only[name] = true
+		}
+	}
+
+	// Shell: envsubst '${HOST} ${PORT}' < file
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		While(s.line, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "envsubst: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// substituter expands variable references, line by line
+type substituter struct {
+	only          map[string]bool // nil means every variable
+	keepUndefined bool
+}
+
+// line is the While() callback: it echoes the line with references expanded
+func (s *substituter) line(args ...any) gloo.Command {
+	return echo.Echo(reference.ReplaceAllStringFunc(args[0].(string), s.expand))
+}
+
+// expand returns the value for one reference, or the reference itself when
+// it is to be left alone
+func (s *substituter) expand(ref string) string {
+	m := reference.FindStringSubmatch(ref)
+	name := m[1] + m[2] // one of the groups is empty
+
+	if s.only != nil && !s.only[name] {
+		return ref
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok && s.keepUndefined {
+		return ref
+	}
+	return value
+}
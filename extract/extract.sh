@@ -0,0 +1,109 @@
+#!/bin/bash
+
+# Pull fields out of each line with a regexp's named capture groups, and
+# print the chosen ones joined by a separator
+# yupsh equivalent: See main.go
+#
+# bash's =~ takes a POSIX extended regexp, with glibc's \w, \s and \S but
+# not \d or (?:...), and its groups are only numbered, so the named groups
+# are rewritten first.
+
+# Parse flags (--regex, --fields, --sep, --count-skipped), then the files
+# yupsh: pattern := opts.String("regex", ...); fields := opts.String("fields", ...); ...
+PATTERN=
+FIELDS=
+SEP=$'\t'
+COUNT_SKIPPED=0
+usage() {
+  echo "Usage: $0 --regex REGEXP [--fields NAME,...] [--sep TAB] [--count-skipped] [file...]" >&2
+  exit 2
+}
+while [[ $1 == --* ]]; do
+  case $1 in
+    --regex) PATTERN=$2; shift 2 ;;
+    --fields) FIELDS=$2; shift 2 ;;
+    --sep) SEP=$2; shift 2 ;;
+    --count-skipped) COUNT_SKIPPED=1; shift ;;
+    *) usage ;;
+  esac
+done
+if [[ -z ${PATTERN} ]]; then
+  usage
+fi
+
+# Turn each (?P<name> or (?<name> into a plain (, and note the number of
+# its group; escaped characters and bracket expressions hold no groups
+# yupsh: re.SubexpNames(), re.SubexpIndex(name)
+REGEX=
+NAMES=()
+declare -A GROUP
+n=0
+in_class=0
+for (( i = 0; i < ${#PATTERN}; i++ )); do
+  c=${PATTERN:i:1}
+  if [[ ${c} == '\' ]]; then
+    REGEX+=${PATTERN:i:2}
+    (( i += 1 ))
+  elif (( in_class )); then
+    [[ ${c} == ']' ]] && in_class=0
+    REGEX+=${c}
+  elif [[ ${c} == '[' ]]; then
+    # A ] straight after [ or [^ is a literal
+    in_class=1
+    REGEX+=${c}
+    [[ ${PATTERN:i+1:1} == '^' ]] && { REGEX+='^'; (( i += 1 )); }
+    [[ ${PATTERN:i+1:1} == ']' ]] && { REGEX+=']'; (( i += 1 )); }
+  elif [[ ${c} == '(' ]]; then
+    (( n += 1 ))
+    if [[ ${PATTERN:i} =~ ^\(\?P?\<([A-Za-z_][A-Za-z0-9_]*)\> ]]; then
+      GROUP[${BASH_REMATCH[1]}]=${n}
+      NAMES+=("${BASH_REMATCH[1]}")
+      (( i += ${#BASH_REMATCH[0]} - 1 ))
+    fi
+    REGEX+=${c}
+  else
+    REGEX+=${c}
+  fi
+done
+
+# The group number of each field, in output order
+# yupsh: x.groups = append(x.groups, re.SubexpIndex(name))
+if [[ -n ${FIELDS} ]]; then
+  IFS=, read -r -a NAMES <<< "${FIELDS}"
+elif (( ${#NAMES[@]} == 0 )); then
+  echo "extract: --regex has no named groups, such as (?P<ip>\S+)" >&2
+  exit 2
+fi
+INDEXES=()
+for name in "${NAMES[@]}"; do
+  if [[ -z ${GROUP[${name}]} ]]; then
+    echo "extract: --fields: no group named \"${name}\" in --regex" >&2
+    exit 2
+  fi
+  INDEXES+=("${GROUP[${name}]}")
+done
+
+# Print the fields of the lines that match, and count the others
+# yupsh: While(x.line, input.WholeLine)
+MATCHED=0
+SKIPPED=0
+while IFS= read -r line || [[ -n ${line} ]]; do
+  if [[ ${line} =~ ${REGEX} ]]; then
+    out=
+    for (( k = 0; k < ${#INDEXES[@]}; k++ )); do
+      (( k > 0 )) && out+=${SEP}
+      out+=${BASH_REMATCH[INDEXES[k]]}
+    done
+    printf '%s\n' "${out}"
+    (( MATCHED += 1 ))
+  else
+    (( SKIPPED += 1 ))
+  fi
+done < <(cat -- "$@")
+wait $! || exit 2
+
+# yupsh: fmt.Fprintf(os.Stderr, "extract: %d line(s) didn't match\n", x.skipped)
+if (( COUNT_SKIPPED )); then
+  echo "extract: ${SKIPPED} line(s) didn't match" >&2
+fi
+(( MATCHED > 0 ))
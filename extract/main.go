@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Pull fields out of each line with a regexp's named capture groups, and
+// print the chosen ones joined by a separator
+// Shell equivalent: See extract.sh
+//
+//   extract --regex '^(?P<ip>\S+) .* "(?P<method>\w+) (?P<path>\S+)' --fields ip,method access.log
+//   10.0.0.5	GET
+//   192.168.1.20	GET
+//   10.0.0.5	POST
+//   172.16.0.3	DELETE
+//
+// Where splitting on blanks only works when every field is a word in a fixed
+// column, a regexp can anchor on the text around a field (the quote before a
+// request, the brackets around a date) and take the field whatever its
+// column. --fields picks the groups by name, in any order, and may use one
+// more than once; without it, every named group is printed, in the order of
+// the regexp. A group that took no part in the match is printed empty.
+//
+// Lines that don't match are skipped; --count-skipped reports how many on
+// stderr. As with grep, the exit status is 0 if any line matched, 1 if none
+// did and 2 on an error (see internal/status).
+//
+// Usage: extract --regex REGEXP [--fields NAME,...] [--sep TAB] [--count-skipped] [file...]
+func main() {
+	opts := flags.New("extract", "--regex REGEXP [file...]")
+	pattern := opts.String("regex", "", "`regexp` with named groups, such as (?P<ip>\\S+)")
+	fields := opts.String("fields", "", "comma-separated group `names` to print (default: every named group)")
+	sep := opts.String("sep", "\t", "`separator` between the fields")
+	countSkipped := opts.Bool("count-skipped", false, "report the number of lines that didn't match on stderr")
+	opts.Parse()
+	if *pattern == "" {
+		opts.Fail("missing --regex")
+	}
+	re, err := regexp.Compile(*pattern)
+	if err != nil {
+		opts.Fail("invalid --regex: %v", err)
+	}
+
+	var names []string
+	if *fields != "" {
+		names = strings.Split(*fields, ",")
+	} else {
+		for _, name := range re.SubexpNames() {
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			opts.Fail("--regex has no named groups, such as (?P<ip>\\S+)")
+		}
+	}
+	x := &extractor{re: re, sep: *sep}
+	for _, name := range names {
+		i := re.SubexpIndex(name)
+		if i < 0 {
+			opts.Fail("--fields: no group named %q in --regex", name)
+		}
+		x.groups = append(x.groups, i)
+	}
+
+	// Shell: while read -r line; do [[ ${line} =~ ${REGEX} ]] && ...; done
+	err = gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		While(x.line, input.WholeLine),
+	))
+	if *countSkipped {
+		fmt.Fprintf(os.Stderr, "extract: %d line(s) didn't match\n", x.skipped)
+	}
+	status.Result{Count: x.matched, Err: err}.Exit("extract")
+}
+
+// extractor prints the chosen groups of every line that matches
+type extractor struct {
+	re     *regexp.Regexp
+	groups []int // the index of each field's group, in output order
+	sep    string
+
+	matched int
+	skipped int
+}
+
+// line is the While() callback: it echoes the line's fields, or skips the
+// line if it doesn't match
+//
+// Shell equivalent:
+//   [[ ${line} =~ ${REGEX} ]] && printf '%s\t%s\n' "${BASH_REMATCH[1]}" "${BASH_REMATCH[2]}"
+func (x *extractor) line(args ...any) gloo.Command {
+	m := x.re.FindStringSubmatch(args[0].(string))
+	if m == nil {
+		x.skipped++
+		return nil
+	}
+	x.matched++
+
+	values := make([]string, len(x.groups))
+	for i, g := range x.groups {
+		values[i] = m[g]
+	}
+	return echo.Echo(strings.Join(values, x.sep))
+}
@@ -0,0 +1,82 @@
+#!/bin/bash
+set -eo pipefail
+
+# Download a list of URLs, several at a time, and run each body through a
+# transform: grep for a pattern, or count the matches
+# yupsh equivalent: See main.go
+#
+# xargs -P runs the downloads in parallel, so each URL's output goes to a
+# numbered file, and the files are printed in order once all are done; the
+# Go version prints each URL's lines as soon as the URLs before it are done.
+
+# Parse flags (--jobs N, --timeout SECONDS, --grep P, --ignore-case,
+# --count), then the files
+# yupsh: opts.Int("jobs", 8, ...); opts.Duration("timeout", 10*time.Second, ...); opts.String("grep", ...); ...
+JOBS=8
+TIMEOUT=10
+PATTERN=
+GREP_OPTS=
+while [[ $1 == --* ]]; do
+  case $1 in
+    --jobs) JOBS=$2; shift 2 ;;
+    --timeout) TIMEOUT=${2%s}; shift 2 ;;
+    --grep) PATTERN=$2; shift 2 ;;
+    --ignore-case) GREP_OPTS+=" -i"; shift ;;
+    --count) GREP_OPTS+=" -c"; shift ;;
+    *) echo "Usage: $0 [--jobs N] [--timeout SECONDS] [--grep P [--ignore-case] [--count]] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+WORK=$(mktemp -d)
+trap 'rm -rf "${WORK}"' EXIT
+export WORK TIMEOUT PATTERN GREP_OPTS
+
+# Fetch one numbered URL, "n<TAB>url", into ${WORK}/n.out, or its error
+# into ${WORK}/n.err
+# yupsh: f.fetch(ctx, url), in a worker
+fetch() {
+  local n=${1%%$'\t'*} url=${1#*$'\t'}
+  # -f fails on an HTTP error status; -sS is quiet but for the error
+  # yupsh: http.NewRequestWithContext(ctx, ...) with context.WithTimeout
+  if ! curl -fsS --max-time "${TIMEOUT}" "${url}" > "${WORK}/${n}.body" 2> "${WORK}/${n}.curl"; then
+    echo "${url}: $(< "${WORK}/${n}.curl")" > "${WORK}/${n}.err"
+    return 0
+  fi
+  # grep exits 1 when nothing matches; that's not a failure here
+  # yupsh: f.transform.Executor()(ctx, resp.Body, &out, io.Discard)
+  if [[ -n ${PATTERN} ]]; then
+    # shellcheck disable=SC2086
+    grep -E ${GREP_OPTS} -e "${PATTERN}" "${WORK}/${n}.body" || true
+  else
+    cat "${WORK}/${n}.body"
+  fi | sed "s|^|${url}\t|" > "${WORK}/${n}.out"
+  rm "${WORK}/${n}.body"
+}
+export -f fetch
+
+# Number the URLs, skipping blank lines and comments, and fetch them --jobs
+# at a time
+# yupsh: f.fetchAll(), the reader and workers
+cat "$@" \
+  | awk -v OFS='\t' '$1 != "" && $1 !~ /^#/ {print ++n, $1}' \
+  | tr '\n' '\0' \
+  | xargs -0 -r -P "${JOBS}" -n 1 bash -c 'fetch "$1"' _
+
+# Print the results in order, and count the failures
+# yupsh: the printer, emitting ordered.Map's results in order
+urls=0
+failed=0
+for ((n = 1; ; n++)); do
+  if [[ -f ${WORK}/${n}.err ]]; then
+    echo "fetch-process: $(< "${WORK}/${n}.err")" >&2
+    failed=$((failed + 1))
+  elif [[ -f ${WORK}/${n}.out ]]; then
+    cat "${WORK}/${n}.out"
+  else
+    break
+  fi
+  urls=$((urls + 1))
+done
+
+echo "fetch-process: $((urls - failed)) of ${urls} URLs fetched, ${failed} failed" >&2
+((failed == 0)) || exit 1
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	grep `github.com/yupsh/grep`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	ordered `github.com/yupsh/script-examples/internal/ordered`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Download a list of URLs, several at a time, and run each body through a
+// transform: grep for a pattern, or count the matches
+// Shell equivalent: See fetch-process.sh
+//
+//   fetch-process --grep '<title>' urls.txt
+//   http://localhost:8000/index.html	<title>Home</title>
+//   http://localhost:8000/about.html	<title>About us</title>
+//   fetch-process: http://localhost:8000/gone.html: 404 Not Found
+//   fetch-process: 2 of 3 URLs fetched, 1 failed
+//
+// Each line of the input is a URL; blank lines and # comments are skipped.
+// Every line of the transform's output is printed after its URL and a tab,
+// so the results of all the pages can go on through sort, cut or awk
+// -F'\t' together:
+//   --grep P        the lines of the body matching the regexp P
+//   --count         with --grep, the number of matching lines instead
+//   (neither)       every line of the body
+//
+// --jobs downloads run at once, each with its own --timeout, which covers
+// reading the body too. The results still come out in the list's order,
+// whichever downloads finish first, as in rdns.
+//
+// A URL that fails, whether the server can't be reached, answers with an
+// HTTP error status (4xx or 5xx, as curl -f treats them) or takes too long,
+// is reported on stderr and the batch goes on. At the end a summary goes to
+// stderr, and fetch-process exits 1 if any URL failed; 2 on other trouble.
+//
+// Usage: fetch-process [--jobs N] [--timeout DURATION] [--grep P [--ignore-case] [--count]] [file...]
+func main() {
+	opts := flags.New("fetch-process", "[file...]")
+	jobs := opts.Int("jobs", 8, "download `N` URLs at a time")
+	timeout := opts.Duration("timeout", 10*time.Second, "give up on a URL after `duration`")
+	pattern := opts.String("grep", "", "keep the lines of each body matching `regexp`")
+	ignoreCase := opts.Bool("ignore-case", false, "with --grep, match case-insensitively")
+	count := opts.Bool("count", false, "with --grep, print the number of matching lines")
+	opts.Parse()
+	if *jobs < 1 {
+		opts.Fail("--jobs must be at least 1")
+	}
+	if *timeout <= 0 {
+		opts.Fail("--timeout must be positive")
+	}
+	if *pattern == "" && (*ignoreCase || *count) {
+		opts.Fail("--ignore-case and --count only apply with --grep")
+	}
+	// grep.Grep() matches nothing with a bad pattern, silently; say why
+	if _, err := regexp.Compile(*pattern); err != nil {
+		opts.Fail("invalid pattern: %v", err)
+	}
+
+	f := &fetcher{
+		client:    http.DefaultClient,
+		timeout:   *timeout,
+		transform: transform(*pattern, *ignoreCase, *count),
+		jobs:      *jobs,
+	}
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+
+		// Read the URLs from the named files, or stdin
+		// Shell: grep -v '^#' "$@"
+		input.Source(opts.Args()...),
+
+		// Download and transform --jobs of them at a time, in order
+		// Shell: xargs -P "${JOBS}" curl -fsS --max-time 10 | grep P
+		f.fetchAll(),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fetch-process: %s\n", status.Message(err))
+		os.Exit(2)
+	}
+
+	fmt.Fprintf(os.Stderr, "fetch-process: %d of %d URLs fetched, %d failed\n", f.urls-f.failed, f.urls, f.failed)
+	if f.failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// transform returns the command each body is run through
+//
+// Shell equivalent:
+//   grep [-i] [-c] P    or    cat
+func transform(pattern string, ignoreCase, count bool) gloo.Command {
+	if pattern == "" {
+		// Shell: cat
+		return gloo.LineTransform(func(line string) (string, bool) { return line, true })
+	}
+	var params []any
+	if ignoreCase {
+		params = append(params, grep.IgnoreCase)
+	}
+	cmd := grep.Grep(grep.Pattern(pattern), params...)
+	if !count {
+		return cmd
+	}
+	// grep.Grep() accepts grep.Count, but does not count (see grep-count)
+	// Shell: grep -c P
+	return pipe.Pipeline(cmd, gloo.AccumulateAndProcess(func(lines []string) []string {
+		return []string{fmt.Sprint(len(lines))}
+	}))
+}
+
+// fetcher downloads URLs with a pool of workers, and prints what the
+// transform makes of each body in the order the URLs arrived
+type fetcher struct {
+	client    *http.Client
+	timeout   time.Duration // per URL (--timeout)
+	transform gloo.Command  // run on each body
+	jobs      int           // number of workers (--jobs)
+	urls      int           // URLs in the list
+	failed    int           // URLs that could not be fetched
+}
+
+// result is the transform's output for one URL, or the error that stopped
+// it; the zero result stands for a blank line or a comment
+type result struct {
+	url string
+	out []byte
+	err error
+}
+
+// fetchAll returns the command that reads one URL per line from stdin and
+// prints each one's results, every line prefixed with the URL and a tab
+//
+// Shell equivalent:
+//   xargs -P "${JOBS}" -n 1 fetch | sort -n | cut -f2-
+//
+// As in index and rdns, the URLs go through ordered.Map, --jobs at a time,
+// and the results come back in the order of the list. A worker holds the
+// transform's output for its URL, not the body, so a grep of a large page
+// keeps only the lines it matched.
+func (f *fetcher) fetchAll() gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		// Shell: xargs -P "${JOBS}"
+		return ordered.Map(ctx, stdin, f.jobs, func(ctx context.Context, line string) result {
+			url := strings.TrimSpace(line)
+			if url == "" || strings.HasPrefix(url, "#") {
+				return result{}
+			}
+			out, err := f.fetch(ctx, url)
+			return result{url: url, out: out, err: err}
+		}, func(r result) error {
+			// Printer: each URL's lines, in order, or its error; nothing
+			// for a blank line or a comment
+			if r.url == "" {
+				return nil
+			}
+			f.urls++
+			if r.err != nil {
+				// Shell: curl: (22) The requested URL returned error: 404
+				fmt.Fprintf(stderr, "fetch-process: %s: %v\n", r.url, r.err)
+				f.failed++
+				return nil
+			}
+			// Shell: sed "s|^|${url}\t|"
+			lines := bufio.NewScanner(bytes.NewReader(r.out))
+			for lines.Scan() {
+				if _, err := fmt.Fprintf(stdout, "%s\t%s\n", r.url, lines.Text()); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// fetch downloads url and returns what the transform writes for its body
+//
+// Shell equivalent:
+//   curl -fsS --max-time "${TIMEOUT}" "$url" | grep P
+//
+// The body is streamed into the transform, not read into memory first. It
+// runs in a worker, so it only reads f.
+func (f *fetcher) fetch(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// An error page has a body too, but it isn't the page asked for
+	// Shell: curl -f
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+
+	var out bytes.Buffer
+	if err := f.transform.Executor()(ctx, resp.Body, &out, io.Discard); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -4,51 +4,202 @@ set -e
 # Analyze files in a directory and generate statistics
 # yupsh equivalent: See main.go
 
+# Parse flags (--top N, --human, --null, --sort-by count|name, --stream,
+# --lines), then the directory (or file) argument
+# yupsh: opts := flags.New("file-stats", "[directory | file]"); opts.Top(); opts.Human(); opts.Null(); opts.Choice("sort-by", ...); opts.Bool("stream", ...); opts.Bool("lines", ...); opts.Parse()
+TOP=10
+HUMAN=
+NULL=
+SORT_BY=count
+STREAM=
+LINES=
+while [[ $1 == -* ]]; do
+  case $1 in
+    --top) TOP=$2; shift 2 ;;
+    --human) HUMAN=1; shift ;;
+    --null|-0) NULL=1; shift ;;
+    --sort-by) SORT_BY=$2; shift 2 ;;
+    --stream) STREAM=1; shift ;;
+    --lines) LINES=1; shift ;;
+    *) echo "Usage: $0 [--top N] [--human] [--null] [--sort-by count|name] [--stream] [--lines] [directory | file]" >&2; exit 2 ;;
+  esac
+done
+case ${SORT_BY} in
+  count|name) ;;
+  *) echo "Usage: $0 [--top N] [--human] [--null] [--sort-by count|name] [--stream] [--lines] [directory | file]" >&2; exit 2 ;;
+esac
+
 # Get directory from command line, default to current directory
-# yupsh: dir := "."; if len(os.Args) > 1 { dir = os.Args[1] }
+# yupsh: dir := opts.ArgOr(0, ".")
 DIR=${1:-.}
 echo "Analyzing files in: ${DIR}"
+# find only warns about a missing directory, so check it up front
+# yupsh: if err := input.Dir(dir); err != nil { status.Result{Err: err}.Exit(...) }
+if [[ ! -f ${DIR} && ! -d ${DIR} ]]; then
+  echo "file-stats: ${DIR}: not a directory" >&2
+  exit 2
+fi
+
+# List regular files, one per line, or NUL-terminated with --null
+# yupsh: listFiles(dir, pattern, *useNull)
+list_files() {
+  # A file argument, or a symlink to one, is analyzed on its own
+  # yupsh: if info, err := os.Stat(dir); ... info.Mode().IsRegular() { t.collect(dir) }
+  if [[ -f ${DIR} ]]; then
+    if [[ -n ${NULL} ]]; then printf '%s\0' "${DIR}"; else printf '%s\n' "${DIR}"; fi
+  elif [[ -n ${NULL} ]]; then
+    find "${DIR}" -type f -print0
+  else
+    find "${DIR}" -type f
+  fi
+}
+
+# Read one name from list_files, whole: IFS= keeps spaces, -d '' reads up
+# to a NUL instead of a newline
+# yupsh: eachFile(body, *useNull)
+read_file() {
+  if [[ -n ${NULL} ]]; then
+    IFS= read -r -d '' file
+  else
+    IFS= read -r file
+  fi
+}
+
+# Print a name, quoted if it has a control character such as a newline
+# (bash quotes it as $'a\nb', where Go prints "a\nb")
+# yupsh: null.Quote(name)
+quote() {
+  if [[ $1 == *[[:cntrl:]]* ]]; then
+    printf '%q' "$1"
+  else
+    printf '%s' "$1"
+  fi
+}
+
+# === Walk the directory, once ===
+# Stat every file as find lists it, keeping "size TAB ext TAB mtime TAB name
+# TAB lines" lines in a temporary file that the reports then read
+# yupsh: var t tree; listFiles(dir, *useNull), eachFile(t.collect, *useNull)
+FILES=$(mktemp)
+trap 'rm -f "${FILES}"' EXIT
+# With --stream, also count the types as the files are found, and print the
+# most common so far to stderr each second
+# yupsh: t.live = newHistogram(*top); t.live.add(ext) in t.collect
+declare -A count=()
+found=0
+last=${SECONDS}
+list_files |
+  while read_file; do
+    # Size and modification time, skipping files that can't be read
+    # yupsh: os.Stat(filename), which follows symlinks, as -L does
+    read -r size mtime < <(stat -L -c '%s %Y' -- "${file}" 2> /dev/null) || continue
+    # Extension (everything after last dot), or none
+    # yupsh: strings.TrimPrefix(filepath.Ext(filename), ".")
+    ext=
+    [[ ${file##*/} == *.* ]] && ext=${file##*.}
+    # With --lines, count the file's lines; awk counts a last line without a
+    # newline, as wc -l doesn't, but holds each line in memory whole
+    # yupsh: f.lines = countLines(filename), with count.Lines
+    lines=0
+    if [[ -n ${LINES} ]]; then
+      lines=$(awk 'END {print NR}' < "${file}" 2> /dev/null) || lines=0
+    fi
+    printf '%s\t%s\t%s\t%s\t%s\n' "${size}" "$(quote "${ext}")" "${mtime}" "$(quote "${file}")" "${lines}"
+    if [[ -n ${STREAM} ]]; then
+      found=$((found + 1))
+      [[ -n ${ext} ]] && count[${ext}]=$((${count[${ext}]:-0} + 1))
+      if ((SECONDS > last)); then
+        # yupsh: h.running() - an approximate snapshot; the walk goes on
+        top=$(for e in "${!count[@]}"; do printf '%s %s\n' "${count[${e}]}" "${e}"; done |
+          sort -k1,1nr -k2,2r | head -n "${TOP}" | awk '{printf "%s%s %s", sep, $2, $1; sep = ", "}')
+        echo "file-stats: ${found} files so far: ${top}" >&2
+        last=${SECONDS}
+      fi
+    fi
+  done > "${FILES}"
 
 # === File Count by Type ===
 echo "=== File Count by Type ==="
-# Find all files with extensions, extract extension, count occurrences
-# yupsh: Pipeline with find, While(extractExtension), sort, uniq, sort
-find "${DIR}" -type f -name "*.*" \
-| while read file; do
-    # Extract extension (everything after last dot)
-    # yupsh: filepath.Ext(filename) then strings.TrimPrefix(ext, ".")
-    echo "${file##*.}"
-done \
-| sort \              # yupsh: sort.Sort()
-| uniq -c \           # yupsh: uniq.Uniq(uniq.Count) - counts and shows count
-| sort -nr            # yupsh: sort.Sort(sort.Numeric, sort.Reverse)
+# Count the files with each extension. --stream counted them already, but in
+# a subshell of the pipeline above, so they are counted again here; the
+# output is the same either way
+# yupsh: if *stream { fmt.Print(t.live.final(*sortBy == "name")) }
+# yupsh: Pipeline with t.lines(extension), sort, uniq, sort
+# yupsh: t.lines(extension) - files without an extension are left out
+cut -f2 "${FILES}" | grep . |
+  # yupsh: sort.Sort()
+  sort |
+  # yupsh: uniq.Uniq(uniq.Count) - counts and shows count
+  uniq -c |
+  # Most common first, or keep the alphabetical order with --sort-by name
+  # yupsh: sort.Sort(sort.Numeric, sort.Reverse), appended only for "count"
+  if [[ ${SORT_BY} == count ]]; then sort -nr; else cat; fi
 
 echo ""
 # === Largest Files ===
 echo "=== Largest Files ==="
-# Find all files, get size info, sort by size, show top 10
-# yupsh: Pipeline with find, While(getFileSize), sort, head
-find "${DIR}" -type f -exec ls -la {} \; \
-| awk '{print $5 "\t" $9}' \    # Extract size (field 5) and name (field 9)
-                                # yupsh: os.Stat(filename) then fmt.Sprintf("%d\t%s", info.Size(), filename)
-| sort -nr \                    # Sort numerically, descending
-                                # yupsh: sort.Sort(sort.Numeric, sort.Reverse)
-| head -10                      # Take top 10
-                                # yupsh: head.Head(head.LineCount(10))
+# Sort the files by size, show top 10
+# yupsh: Pipeline with t.lines(sizeAndName), sort, head
+# yupsh: t.lines(sizeAndName)
+cut -f1,4 "${FILES}" |
+  # Sort numerically on the size column, descending
+  # yupsh: sort.Sort(sort.Field(1), sort.Delimiter("\t"), sort.Numeric, sort.Reverse)
+  sort -t$'\t' -k1,1 -nr |
+  # Take top N (--top, default 10)
+  # yupsh: head.Head(head.LineCount(*top))
+  head -n "${TOP}" |
+  # yupsh: While(formatSize(*human), FieldSeparator("\t"))
+  if [[ -n ${HUMAN} ]]; then numfmt --to=iec --field=1; else cat; fi
 
 echo ""
 # === Total Size ===
 echo "=== Total Size ==="
-# Find all files, extract sizes, sum them up
-# yupsh: Pipeline with find, While(getFileSizeOnly), awk.Awk(&totalSizeProgram{})
-find "${DIR}" -type f -exec ls -la {} \; \
-| awk '{sum += $5} END {print "Total: " sum " bytes"}'
+# Sum the sizes, and break the total down by extension (largest first, at
+# most --top of them) and by modification time (the newest); -F'\t' keeps
+# the empty extension field of a file without one in its place
+# yupsh: Pipeline with t.lines(sizeRecord), awk.Awk(&totalSizeProgram{}, awk.FieldSeparator(recordSeparator))
+size() {
+  if [[ -n ${HUMAN} ]]; then numfmt --to=iec "$1"; else echo "$1 bytes"; fi
+}
+read -r sum newest < <(awk -F'\t' '{sum += $1; if ($3 > newest) newest = $3}
+  END {print sum + 0, newest + 0}' "${FILES}")
+echo "Total: $(size "${sum}")"
+awk -F'\t' -v OFS='\t' '{bytes[$2] += $1} END {for (ext in bytes) print bytes[ext], ext}' "${FILES}" |
+  sort -t$'\t' -k1,1nr -k2,2 |
+  head -n "${TOP}" |
+  while IFS=$'\t' read -r bytes ext; do
+    echo "  ${ext:-(none)}: $(size "${bytes}")"
+  done
+if [[ -s ${FILES} ]]; then
+  echo "Newest: $(date -d "@${newest}" '+%F %T')"
+fi
 # yupsh: Custom awk program that accumulates sizes:
 #   func (p *totalSizeProgram) Action(ctx *awk.Context) {
-#     var size int64
-#     fmt.Sscanf(ctx.Field(1), "%d", &size)
+#     ext := ctx.Field(1)
+#     fmt.Sscanf(ctx.Field(2), "%d", &size)
 #     p.sum += size
+#     p.bytes[ext] += size
 #   }
 #   func (p *totalSizeProgram) End(ctx *awk.Context) (string, error) {
-#     return fmt.Sprintf("Total: %d bytes", p.sum), nil
+#     return fmt.Sprintf("Total: %d bytes", p.sum), nil    // and the breakdown
 #   }
+
+# === Lines by Type ===
+# With --lines, the total lines, and the extensions with the most (at most
+# --top of them)
+# yupsh: if *countLines { fmt.Print(t.lineCounts(*top)) }
+if [[ -n ${LINES} ]]; then
+  echo ""
+  echo "=== Lines by Type ==="
+  echo "Total: $(awk -F'\t' '{sum += $5} END {print sum + 0}' "${FILES}") lines"
+  awk -F'\t' -v OFS='\t' '{lines[$2] += $5} END {for (ext in lines) print lines[ext], ext}' "${FILES}" |
+    sort -t$'\t' -k1,1nr -k2,2 |
+    head -n "${TOP}" |
+    while IFS=$'\t' read -r lines ext; do
+      echo "  ${ext:-(none)}: ${lines} lines"
+    done
+fi
+
+# Exit 1 if there was nothing to analyze, like grep finding nothing
+# yupsh: status.Result{Count: len(t.files)}.Exit("file-stats")
+[[ -s ${FILES} ]] || exit 1
@@ -9,7 +9,10 @@ require (
 	github.com/yupsh/echo v0.0.3
 	github.com/yupsh/find v0.0.3
 	github.com/yupsh/head v0.0.3
+	github.com/yupsh/script-examples/internal v0.0.0
 	github.com/yupsh/sort v0.0.3
 	github.com/yupsh/uniq v0.0.3
 	github.com/yupsh/while v0.0.4
 )
+
+replace github.com/yupsh/script-examples/internal => ../internal
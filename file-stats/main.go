@@ -1,10 +1,18 @@
 package main
 
 import (
+	"bufio"
+	"cmp"
+	"context"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	awk `github.com/yupsh/awk`
 	echo `github.com/yupsh/echo`
@@ -12,6 +20,12 @@ import (
 	gloo `github.com/gloo-foo/framework`
 	head `github.com/yupsh/head`
 	pipe `github.com/gloo-foo/pipe`
+	count `github.com/yupsh/script-examples/internal/count`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	null `github.com/yupsh/script-examples/internal/null`
+	size `github.com/yupsh/script-examples/internal/size`
+	status `github.com/yupsh/script-examples/internal/status`
 	sort `github.com/yupsh/sort`
 	uniq `github.com/yupsh/uniq`
 	. `github.com/yupsh/while`
@@ -26,224 +40,561 @@ import (
 // 3. Using awk.Awk() with custom programs for aggregations
 //
 // Key advantage: Native Go file operations (os.Stat) instead of parsing ls output
+//
+// The directory is walked once: every file is stat'ed as find lists it, and
+// its name, size, extension and modification time are kept in memory. The
+// three reports are then pipelines over streams written from that list, so
+// a large tree is read once rather than once per report.
+//
+// File names pass from find to the While() callback one per line, so a name
+// with a newline in it would arrive as two names. With --null (or -0) they
+// pass NUL-terminated instead, as with `find -print0 | while read -d ''`, and
+// any name is safe; see package null. Either way, a name containing a
+// newline, tab or other control character is printed Go-quoted, so the
+// report keeps one file per line.
+//
+// The argument may also be a file, such as the one path a $(...) yields: it
+// is then analyzed on its own, its extension counted, and its size both the
+// largest and the total.
+//
+// Exit status follows the examples' convention (see internal/status): 0 when
+// files were analyzed, 1 when the directory held none, and 2 on an error,
+// a missing directory included (see input.Dir), so `file-stats dir && ...`
+// only goes on with something to show.
+//
+// The type counts are listed most common first; with --sort-by name they are
+// listed alphabetically by extension instead, so the reports of two runs
+// (nightly ones, say) line up for a plain diff.
+//
+// On a huge tree the walk takes a while, and no report prints until it is
+// done. With --stream, the type counts are kept in a map as files are
+// found, and every second the --top most common so far are printed to
+// stderr; the final counts go to stdout as usual. Those running lines are
+// snapshots of a walk in progress, in find's order, so an extension that
+// is common deep in the tree may only show up late.
+//
+// With --lines, the walk also reads each file and counts its lines, and a
+// fourth report gives the total and the --top extensions with the most
+// lines. Files are read a buffer at a time with count.Lines, so a huge file
+// is never held in memory, and there is no maximum line length: a line
+// longer than the 64 KiB buffer (count.BufferSize) is read in pieces and
+// still counts as one line. A last line without a newline counts too.
+//
+// Usage: file-stats [--top N] [--human] [--null] [--sort-by count|name] [--stream] [--lines] [directory | file]
 func main() {
-	// Get directory from command line, default to current directory
-	// Shell: DIR=${1:-.}
-	dir := "."
-	if len(os.Args) > 1 {
-		dir = os.Args[1]
-	}
+	// Parse flags, then get directory from command line, default to current directory
+	// Shell: while [[ $1 == --* ]]; do case $1 in ...; esac; done; DIR=${1:-.}
+	opts := flags.New("file-stats", "[directory | file]")
+	top := opts.Top()
+	human := opts.Human()
+	useNull := opts.Null()
+	sortBy := opts.Choice("sort-by", "order of the type counts", "count", "name")
+	stream := opts.Bool("stream", false, "count the types as files are found, printing running counts to stderr")
+	countLines := opts.Bool("lines", false, "count the lines of every file too, by extension")
+	opts.Parse()
+	dir := opts.ArgOr(0, ".")
 
 	fmt.Fprintf(os.Stderr, "Analyzing files in: %s\n", dir)
 
+	// === Walk the directory, once ===
+	// Shell: find | while read_file; do stat; done > "${FILES}"
+	t := tree{countLines: *countLines}
+	if *stream {
+		t.live = newHistogram(*top)
+	}
+	if info, err := os.Stat(dir); err == nil && info.Mode().IsRegular() {
+		// A file argument, or a symlink to one, is analyzed on its own, as
+		// a tree of one file, rather than as the root of a walk
+		// Shell: if [[ -f ${DIR} ]]; then printf '%s\n' "${DIR}"; fi
+		t.collect(dir)
+	} else if err := input.Dir(dir); err != nil {
+		// find only warns about a missing directory, so check it up front
+		// Shell: [[ -d ${DIR} ]] || exit 2
+		status.Result{Err: err}.Exit("file-stats")
+	} else if err := gloo.Run(pipe.Pipeline(
+		// Find all files
+		// Shell: find "${DIR}" -type f
+		listFiles(dir, *useNull),
+
+		// Stat each file and keep what the reports need
+		// Shell: while read_file; do printf ... "$(stat -L -c %s "${file}")" ...; done
+		// yupsh: eachFile() calls t.collect() for each name
+		eachFile(t.collect, *useNull),
+	)); err != nil {
+		status.Result{Err: err}.Exit("file-stats")
+	}
+
 	// === File Count by Type ===
-	// Shell: find | while read | sort | uniq -c | sort -nr
+	// Shell: cut -f2 | grep . | sort | uniq -c | sort -nr
 	fmt.Fprintf(os.Stderr, "\n=== File Count by Type ===\n")
-	err := gloo.Run(pipe.Pipeline(
-		// Find all files with extensions
-		// Shell: find "${DIR}" -type f -name "*.*"
-		find.Find(find.Dir(dir), find.FileType, find.Name("*.*")),
-
-		// Extract extension from each filename
-		// Shell: while read file; do echo "${file##*.}"; done
-		// yupsh: While() calls extractExtension() for each line
-		//        extractExtension uses filepath.Ext() and strings.TrimPrefix()
-		While(extractExtension),
-
-		// Sort extensions alphabetically (prepares for uniq)
-		// Shell: sort
-		sort.Sort(),
-
-		// Count occurrences of each unique extension
-		// Shell: uniq -c
-		// Output format: "  5 go" (count followed by value)
-		uniq.Uniq(uniq.Count),
-
-		// Sort by count in descending order (most common first)
-		// Shell: sort -nr (numeric, reverse)
-		sort.Sort(sort.Numeric, sort.Reverse),
-	))
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+	if *stream {
+		// Counted during the walk already: print the map, in the order the
+		// pipeline would have
+		// Shell: the same pipeline; the running counts were only progress
+		fmt.Print(t.live.final(*sortBy == "name"))
+	} else if err := typeCounts(&t, *sortBy); err != nil {
+		status.Result{Err: err}.Exit("file-stats")
 	}
 
 	// === Largest Files ===
-	// Shell: find | ls -la | awk | sort -nr | head -10
+	// Shell: cut -f1,3 | sort -nr | head -10
 	fmt.Fprintf(os.Stderr, "\n=== Largest Files ===\n")
-	err = gloo.Run(pipe.Pipeline(
-		// Find all files (no name filter this time)
-		// Shell: find "${DIR}" -type f
-		find.Find(find.Dir(dir), find.FileType),
-
-		// Get size and name for each file
-		// Shell: find -exec ls -la {} \; | awk '{print $5 "\t" $9}'
-		// yupsh: While() calls getFileSize() which uses os.Stat()
-		//        This is better than parsing ls output - native Go!
-		//        Output format: "12485\t./README.md" (size TAB filename)
-		While(getFileSize),
+	err := gloo.Run(pipe.Pipeline(
+		// Size and name for each file
+		// Shell: cut -f1,3 "${FILES}"
+		// Output format: "12485\t./README.md" (size TAB filename)
+		t.lines(sizeAndName),
 
 		// Sort by size in descending order (largest first)
-		// Shell: sort -nr (numeric, reverse)
-		// Note: sort.Numeric tells sort to compare numbers, not strings
-		sort.Sort(sort.Numeric, sort.Reverse),
-
-		// Take only the top 10 largest files
-		// Shell: head -10
-		head.Head(head.LineCount(10)),
+		// Shell: sort -t$'\t' -k1,1 -nr (numeric, reverse)
+		// Note: sort.Numeric tells sort to compare numbers, not strings.
+		//       sort.Field(1) makes it compare just the size column; the
+		//       whole "size\tname" line is not a number, so without it
+		//       sort.Numeric would fall back to comparing strings.
+		sort.Sort(sort.Field(1), sort.Delimiter("\t"), sort.Numeric, sort.Reverse),
+
+		// Take only the top N largest files (--top, default 10)
+		// Shell: head -${TOP}
+		head.Head(head.LineCount(*top)),
+
+		// Reformat the size column once sorting no longer needs raw bytes
+		// Shell: numfmt --to=iec --field=1 (only with --human)
+		// yupsh: formatSize() re-emits the line, converting field 1 if --human
+		While(formatSize(*human), FieldSeparator("\t")),
 	))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+		status.Result{Err: err}.Exit("file-stats")
 	}
 
 	// === Total Size ===
-	// Shell: find | ls -la | awk '{sum += $5} END {print "Total: " sum " bytes"}'
+	// Shell: cut -f2,1,3 | awk -F'\t' '{sum += $2; ...} END {print "Total: " sum " bytes"; ...}'
 	fmt.Fprintf(os.Stderr, "\n=== Total Size ===\n")
 	err = gloo.Run(pipe.Pipeline(
-		// Find all files
-		// Shell: find "${DIR}" -type f
-		find.Find(find.Dir(dir), find.FileType),
-
-		// Get just the file size (not the name)
-		// Shell: (implicit in awk processing)
-		// yupsh: While() calls getFileSizeOnly() which uses os.Stat()
-		//        Output format: "12485" (just the size in bytes)
-		While(getFileSizeOnly),
-
-		// Sum all sizes using a custom awk program
-		// Shell: awk '{sum += $5} END {print "Total: " sum " bytes"}'
+		// Extension, size and modification time of each file
+		// Shell: awk -F'\t' -v OFS='\t' '{print $2, $1, $3}' "${FILES}"
+		// Output format: "go\t12485\t1760468392" (ext TAB size TAB mtime)
+		t.lines(sizeRecord),
+
+		// Sum all sizes, and break the total down by the other fields,
+		// using a custom awk program reading the record's tab-separated
+		// fields; see totalSizeProgram for why the separator must be given
+		// Shell: awk -F'\t' '{sum += $2; bytes[$1] += $2} END {...}'
 		// yupsh: Custom totalSizeProgram that accumulates and formats output
-		//        Action() accumulates each size, End() prints the total
-		awk.Awk(&totalSizeProgram{}),
+		//        Action() accumulates each record, End() prints the totals
+		awk.Awk(&totalSizeProgram{human: *human, top: *top}, awk.FieldSeparator(recordSeparator)),
 	))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+		status.Result{Err: err}.Exit("file-stats")
+	}
+
+	// === Lines by Type ===
+	// Shell: awk -F'\t' '{sum += $5; lines[$2] += $5} END {...}'
+	if *countLines {
+		fmt.Fprintf(os.Stderr, "\n=== Lines by Type ===\n")
+		fmt.Print(t.lineCounts(*top))
 	}
+
+	// Exit 1 if there was nothing to analyze, like grep finding nothing
+	// Shell: [[ -s ${FILES} ]] || exit 1
+	status.Result{Count: len(t.files)}.Exit("file-stats")
 }
 
-// extractExtension extracts the file extension from a filepath
+// typeCounts prints how many files have each extension, most common first,
+// or alphabetically for sortBy "name"
 //
 // Shell equivalent:
-//   echo "${file##*.}"
-//
-// This is called by While() for each filename from find.Find().
-// It demonstrates using native Go functions instead of shell string manipulation.
+//   cut -f2 | grep . | sort | uniq -c | sort -nr
+func typeCounts(t *tree, sortBy string) error {
+	stages := []any{
+		// One extension per file that has one
+		// Shell: cut -f2 "${FILES}" | grep .
+		t.lines(extension),
+
+		// Sort extensions alphabetically (prepares for uniq)
+		// Shell: sort
+		sort.Sort(),
+
+		// Count occurrences of each unique extension
+		// Shell: uniq -c
+		// Output format: "  5 go" (count followed by value)
+		// The counts come out in the alphabetical order of the sort above
+		uniq.Uniq(uniq.Count),
+	}
+
+	// Sort by count in descending order (most common first), unless the
+	// alphabetical order is wanted
+	// Shell: sort -nr (numeric, reverse), or cat with --sort-by name
+	if sortBy == "count" {
+		stages = append(stages, sort.Sort(sort.Numeric, sort.Reverse))
+	}
+
+	return gloo.Run(pipe.Pipeline(stages...))
+}
+
+// listFiles returns the command listing the regular files under dir: one per
+// line, or NUL-terminated with --null
 //
-// Shell pattern: ${file##*.} removes everything up to last dot
-// yupsh pattern: filepath.Ext() gets extension, strings.TrimPrefix() removes dot
-func extractExtension(args ...any) gloo.Command {
-	// args[0] is the filename from find.Find() output
-	filename := args[0].(string)
+// Shell equivalent:
+//   find "${DIR}" -type f ${PRINT}    # -print or -print0
+func listFiles(dir string, useNull bool) gloo.Command {
+	if useNull {
+		return null.Files(dir, "")
+	}
+	return find.Find(find.Dir(dir), find.FileType)
+}
 
-	// Get the extension (everything after last dot)
-	// filepath.Ext() returns ".go", ".txt", etc.
-	ext := filepath.Ext(filename)
-	if ext == "" {
-		// Skip files without extensions
-		// Shell: (would still output empty line)
-		// yupsh: return nil to skip this line entirely
-		return nil
+// eachFile returns the While() that calls body with each file name from
+// listFiles(), whole: spaces in a name don't split it, and with --null
+// neither do newlines
+//
+// Shell equivalent:
+//   while read_file; do ...; done    # IFS= read -r, with -d '' for --null
+func eachFile(body Body, useNull bool) gloo.Command {
+	if useNull {
+		return null.While(body)
 	}
+	return While(body, input.WholeLine)
+}
 
-	// Remove the leading dot: ".go" -> "go"
-	// Shell: ${file##*.} doesn't include the dot
-	ext = strings.TrimPrefix(ext, ".")
+// file is what the walk keeps of each file
+type file struct {
+	name    string    // Path as find listed it
+	size    int64     // Size in bytes
+	ext     string    // Extension without the dot ("go"), or "" for none
+	modTime time.Time // Last modification
+	lines   int64     // Lines in the file, with --lines
+}
 
-	// Output just the extension
-	// Shell: echo "${file##*.}"
-	return echo.Echo(ext)
+// tree is the in-memory result of the one walk, which every report reads
+type tree struct {
+	files      []file
+	live       *histogram // the type counts kept during the walk, with --stream
+	countLines bool       // read each file and count its lines (--lines)
 }
 
-// getFileSize gets the file size and name formatted as "size\tname"
+// collect is the While() callback that stats each file from find.Find() and
+// keeps its details
 //
 // Shell equivalent:
-//   find -exec ls -la {} \; | awk '{print $5 "\t" $9}'
+//   size=$(stat -L -c %s -- "${file}") || continue
+//   printf '%s\t%s\t%s\n' "${size}" "${ext}" "${file}"
 //
-// This demonstrates a key yupsh advantage: instead of spawning ls and parsing
-// its output with awk, we use native Go's os.Stat() to get file information
-// directly. This is faster, more reliable, and cross-platform.
+// This demonstrates a key yupsh advantage: instead of spawning stat (or
+// parsing ls output with awk, which breaks on names with spaces), we use
+// native Go's os.Stat() to get file information directly. This is faster,
+// more reliable, and cross-platform.
 //
-// Shell approach: find -exec ls -la {} \; spawns ls for each file
+// Shell approach: $(stat ...) spawns stat for each file
 // yupsh approach: os.Stat() is a single function call, no subprocess
-func getFileSize(args ...any) gloo.Command {
+func (t *tree) collect(args ...any) gloo.Command {
 	// args[0] is the filename from find.Find() output
 	filename := args[0].(string)
 
 	// Get file info using native Go
-	// Shell: ls -la outputs multiple fields, awk extracts field 5 (size)
+	// Shell: stat -L -c %s prints just the size
 	// yupsh: os.Stat() gives us structured data directly
 	info, err := os.Stat(filename)
 	if err != nil {
 		// Skip files we can't access (permissions, deleted, etc.)
-		// Shell: ls would print error to stderr but continue
-		// yupsh: return nil to skip this line
+		// Shell: size=$(stat ... 2> /dev/null) || continue
 		return nil
 	}
 
-	// Format as "size\tname" for sorting
-	// Shell: awk '{print $5 "\t" $9}'
-	// Output example: "12485\t./README.md"
-	return echo.Echo(fmt.Sprintf("%d\t%s", info.Size(), filename))
+	// Get the extension (everything after last dot), without the dot
+	// Shell: ${file##*.}, for names matching *.*
+	// yupsh: filepath.Ext() returns ".go", strings.TrimPrefix() removes
+	//        the dot; it is "" for a name without one
+	ext := strings.TrimPrefix(filepath.Ext(filename), ".")
+
+	f := file{
+		name:    filename,
+		size:    info.Size(),
+		ext:     ext,
+		modTime: info.ModTime(),
+	}
+	if t.countLines {
+		// Shell: lines=$(awk 'END {print NR}' < "${file}")
+		f.lines = countLines(filename)
+	}
+	t.files = append(t.files, f)
+	if t.live != nil {
+		t.live.add(ext)
+	}
+
+	// Nothing to output: the reports read t.files
+	// yupsh: return nil to skip this line entirely
+	return nil
 }
 
-// getFileSizeOnly gets just the file size (for summing)
+// streamInterval is how often --stream prints the running type counts
+const streamInterval = time.Second
+
+// histogram is the type counts of --stream: a map updated as each file is
+// found, in place of the sort | uniq -c | sort -nr run after the walk
+type histogram struct {
+	counts map[string]int // Extension -> files with it; "" is not counted
+	files  int            // Files found so far, with an extension or not
+	top    int            // Extensions in each running line (--top)
+	next   time.Time      // When the next running line is due
+}
+
+// newHistogram returns an empty histogram whose first running line is due
+// a streamInterval from now, so a quick walk prints none
+func newHistogram(top int) *histogram {
+	return &histogram{counts: map[string]int{}, top: top, next: time.Now().Add(streamInterval)}
+}
+
+// add counts a file with extension ext, and prints the running counts to
+// stderr if they are due
 //
 // Shell equivalent:
-//   find -exec ls -la {} \; | awk '{print $5}'
+//   count[$ext]++; if (( SECONDS > last )); then ...; fi
 //
-// This is similar to getFileSize but only outputs the size,
-// not the filename. Used for the total size calculation.
-func getFileSizeOnly(args ...any) gloo.Command {
-	// args[0] is the filename from find.Find() output
-	filename := args[0].(string)
+// It is called from collect(), so the walk itself keeps time: no goroutine
+// or lock is needed, and while the walk is stuck on a slow directory, so
+// are the counts, and there is nothing new to print.
+func (h *histogram) add(ext string) {
+	h.files++
+	if ext != "" {
+		h.counts[ext]++
+	}
+	if now := time.Now(); !now.Before(h.next) {
+		fmt.Fprintf(os.Stderr, "file-stats: %d files so far: %s\n", h.files, h.running())
+		h.next = now.Add(streamInterval)
+	}
+}
 
-	// Get file info using native Go
-	info, err := os.Stat(filename)
+// running formats the --top most common extensions so far, as "go 107, md
+// 93, ..."; the order between running lines can change as the walk goes on
+func (h *histogram) running() string {
+	exts := h.sorted(false)
+	parts := make([]string, 0, h.top)
+	for _, ext := range exts[:min(len(exts), h.top)] {
+		parts = append(parts, fmt.Sprintf("%s %d", null.Quote(ext), h.counts[ext]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sorted returns the extensions most common first, or alphabetically with
+// byName. Ties go in reverse alphabetical order, as sort -nr leaves them,
+// comparing the whole "count ext" line once the counts are equal.
+func (h *histogram) sorted(byName bool) []string {
+	exts := slices.Sorted(maps.Keys(h.counts))
+	if !byName {
+		slices.SortStableFunc(exts, func(a, b string) int {
+			return cmp.Or(cmp.Compare(h.counts[b], h.counts[a]), strings.Compare(b, a))
+		})
+	}
+	return exts
+}
+
+// final formats the counts as the type-count pipeline prints them: one
+// "count ext" line per extension, the count right-aligned as uniq -c does
+func (h *histogram) final(byName bool) string {
+	var b strings.Builder
+	for _, ext := range h.sorted(byName) {
+		fmt.Fprintf(&b, "%7d %s\n", h.counts[ext], null.Quote(ext))
+	}
+	return b.String()
+}
+
+// countLines returns the number of lines in the file name, or as many as
+// could be read of it
+//
+// Shell equivalent:
+//   awk 'END {print NR}' < "${file}"
+//
+// count.Lines reads the file a buffer at a time (count.BufferSize), so
+// neither a huge file nor a huge line is ever held in memory, unlike
+// os.ReadFile or a bufio.Scanner, which stops at its buffer's size.
+func countLines(name string) int64 {
+	f, err := os.Open(name)
 	if err != nil {
-		return nil // Skip files we can't access
+		return 0 // Unreadable: counted as no lines, as the shell's awk does
+	}
+	defer f.Close()
+
+	var n int64
+	_ = count.Lines(&n).Executor()(context.Background(), f, io.Discard, io.Discard)
+	return n
+}
+
+// lineCounts formats the --lines report: the total lines, then the top
+// extensions by lines, most first, then by name, as the size breakdown
+//
+// Shell equivalent:
+//   awk -F'\t' '{sum += $5; lines[$2] += $5} END {...}' | sort -k1,1nr -k2,2 | head -n "${TOP}"
+func (t *tree) lineCounts(top int) string {
+	var sum int64
+	lines := map[string]int64{}
+	for _, f := range t.files {
+		sum += f.lines
+		lines[f.ext] += f.lines
 	}
 
-	// Return just the size in bytes
-	// Shell: awk '{print $5}' (field 5 from ls -la)
-	// Output example: "12485"
-	return echo.Echo(fmt.Sprintf("%d", info.Size()))
+	exts := slices.Collect(maps.Keys(lines))
+	slices.SortFunc(exts, func(a, b string) int {
+		return cmp.Or(cmp.Compare(lines[b], lines[a]), cmp.Compare(a, b))
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Total: %d lines\n", sum)
+	for _, ext := range exts[:min(len(exts), top)] {
+		name := null.Quote(ext)
+		if name == "" {
+			name = "(none)"
+		}
+		fmt.Fprintf(&b, "  %s: %d lines\n", name, lines[ext])
+	}
+	return b.String()
 }
 
-// totalSizeProgram is a custom awk program that sums all input numbers
+// lines returns the command that writes one line per collected file, as
+// format prints it, skipping the files format returns "" for
 //
 // Shell equivalent:
-//   awk '{sum += $1} END {print "Total: " sum " bytes"}'
+//   cut -f... "${FILES}"
+//
+// It is the first stage of each report's pipeline, in place of a second
+// walk of the directory.
+func (t *tree) lines(format func(f file) string) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		out := bufio.NewWriter(stdout)
+		for _, f := range t.files {
+			if line := format(f); line != "" {
+				out.WriteString(line)
+				out.WriteByte('\n')
+			}
+		}
+		return out.Flush()
+	})
+}
+
+// extension formats a file's extension, quoted if it has a newline in it, or
+// "" for a file without one
+//
+// Shell equivalent:
+//   cut -f2 | grep .
+func extension(f file) string {
+	return null.Quote(f.ext)
+}
+
+// sizeAndName formats a file as "size\tname", quoting a name with a newline
+// or tab in it, so the line stays one line of two fields
+//
+// Shell equivalent:
+//   cut -f1,3
+func sizeAndName(f file) string {
+	return fmt.Sprintf("%d\t%s", f.size, null.Quote(f.name))
+}
+
+// formatSize returns a While() callback that rewrites "size\tname" lines,
+// printing the size in human-readable units when human is set
+//
+// Shell equivalent:
+//   numfmt --to=iec --field=1
+//
+// The callback is built by a function so the --human setting can be captured
+// in the closure; While() itself only passes the line's fields.
+func formatSize(human bool) Body {
+	return func(args ...any) gloo.Command {
+		// With FieldSeparator("\t"): args[0] = size, args[1] = filename
+		if len(args) < 2 {
+			return nil
+		}
+		sizeField := args[0].(string)
+		filename := args[1].(string)
+
+		if human {
+			var n int64
+			fmt.Sscanf(sizeField, "%d", &n)
+			sizeField = size.Format(n)
+		}
+		return echo.Echo(fmt.Sprintf("%s\t%s", sizeField, filename))
+	}
+}
+
+// recordSeparator separates the fields of the records sizeRecord writes,
+// and is the awk.FieldSeparator totalSizeProgram reads them with
+const recordSeparator = "\t"
+
+// sizeRecord formats a file as "ext\tsize\tmtime", the mtime in Unix
+// seconds, for totalSizeProgram. The extension is quoted if it has a tab or
+// newline in it, so the record keeps its three fields; it is "" for a file
+// without one, leaving the first field empty.
+//
+// Shell equivalent:
+//   awk -F'\t' -v OFS='\t' '{print $2, $1, $3}'
+func sizeRecord(f file) string {
+	return strings.Join([]string{
+		null.Quote(f.ext),
+		strconv.FormatInt(f.size, 10),
+		strconv.FormatInt(f.modTime.Unix(), 10),
+	}, recordSeparator)
+}
+
+// totalSizeProgram is a custom awk program that sums the sizes of the files,
+// and breaks the total down by the other fields of each record: the bytes
+// per extension, and the newest modification time
+//
+// Shell equivalent:
+//   awk -F'\t' '{sum += $2; bytes[$1] += $2; if ($3 > newest) newest = $3}
+//                END {print "Total: " sum " bytes"; ...}'
 //
 // This demonstrates how to use yupsh's awk.Awk() command with a custom
 // program. The shell's awk has three sections: BEGIN, Action, and END.
 // We only need Action (process each line) and END (output final result).
 //
 // Shell awk pattern:
-//   {sum += $1}                         - Action: add field 1 to sum
-//   END {print "Total: " sum " bytes"}  - End: print final total
+//   {sum += $2; bytes[$1] += $2}        - Action: add field 2 to the totals
+//   END {print "Total: " sum " bytes"}  - End: print final totals
 //
 // yupsh pattern:
 //   Action() - called for each input line
 //   End() - called once at the end
+//
+// The records are "ext\tsize\tmtime", read with
+// awk.FieldSeparator(recordSeparator). The separator decides what
+// ctx.Field(i) returns, and the program relies on it:
+//   - With the default separator, " ", awk.Awk() splits on runs of
+//     whitespace, as the shell's awk does, and ignores leading ones. A file
+//     without an extension has an empty first field, "\t512\t1760468392",
+//     which would then be dropped: the size would move to Field(1), the
+//     mtime to Field(2), and the sums go wrong without an error.
+//   - With any other separator, the line is split on each occurrence, so an
+//     empty field stays in its place: Field(1) is "", Field(2) the size.
+// Field(0) is the whole line either way, and Field(i) is "" past the last
+// field (ctx.NF), so a short record reads as empty fields, not a panic.
+// A program reading more than one field should be given the separator its
+// records are written with, as here, even when that is a tab.
 type totalSizeProgram struct {
-	awk.SimpleProgram // Provides basic awk program structure
-	sum int64         // Accumulator for total size
+	awk.SimpleProgram                  // Provides basic awk program structure
+	sum               int64            // Accumulator for total size
+	bytes             map[string]int64 // Extension ("" for none) -> total size
+	newest            int64            // Latest modification, in Unix seconds
+	human             bool             // Print sizes in human-readable units (--human)
+	top               int              // Extensions to list in the breakdown (--top)
 }
 
 // Action is called for each input line
-// Shell: {sum += $1}
+// Shell: {sum += $2; bytes[$1] += $2; if ($3 > newest) newest = $3}
 func (p *totalSizeProgram) Action(ctx *awk.Context) (string, bool) {
-	// Parse the size from field 1 (the only field in our input)
-	// Shell: $1 (automatic in awk)
-	// yupsh: ctx.Field(1) (explicit field access)
-	var size int64
-	fmt.Sscanf(ctx.Field(1), "%d", &size)
-
-	// Add to running total
-	// Shell: sum += $1
+	// Parse the fields of the record
+	// Shell: $1, $2, $3 (split on -F'\t')
+	// yupsh: ctx.Field(1), ctx.Field(2), ctx.Field(3) (split on awk.FieldSeparator)
+	ext := ctx.Field(1)
+	var size, mtime int64
+	fmt.Sscanf(ctx.Field(2), "%d", &size)
+	fmt.Sscanf(ctx.Field(3), "%d", &mtime)
+
+	// Add to running totals
+	// Shell: sum += $2; bytes[$1] += $2
 	p.sum += size
+	if p.bytes == nil {
+		p.bytes = make(map[string]int64)
+	}
+	p.bytes[ext] += size
+	p.newest = max(p.newest, mtime)
 
 	// Don't emit anything during processing (only at the end)
 	// Shell: (no print statement in action, so nothing output)
@@ -252,10 +603,43 @@ func (p *totalSizeProgram) Action(ctx *awk.Context) (string, bool) {
 }
 
 // End is called once after all lines are processed
-// Shell: END {print "Total: " sum " bytes"}
+// Shell: END {print "Total: " sum " bytes"; for (ext in bytes) ...; print "Newest: " ...}
 func (p *totalSizeProgram) End(ctx *awk.Context) (string, error) {
 	// Format and return the total
 	// Shell: print "Total: " sum " bytes"
-	return fmt.Sprintf("Total: %d bytes", p.sum), nil
+	lines := []string{"Total: " + p.formatSize(p.sum)}
+
+	// The breakdown by extension, largest first, then by name
+	// Shell: for (ext in bytes) print ... | "sort -t: -k2,2nr | head -N"
+	exts := make([]string, 0, len(p.bytes))
+	for ext := range p.bytes {
+		exts = append(exts, ext)
+	}
+	slices.SortFunc(exts, func(a, b string) int {
+		return cmp.Or(cmp.Compare(p.bytes[b], p.bytes[a]), cmp.Compare(a, b))
+	})
+	for _, ext := range exts[:min(len(exts), p.top)] {
+		name := ext
+		if name == "" {
+			name = "(none)"
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s", name, p.formatSize(p.bytes[ext])))
+	}
+
+	// The latest modification of any file
+	// Shell: print "Newest: " strftime("%F %T", newest)
+	if ctx.NR > 0 {
+		lines = append(lines, "Newest: "+time.Unix(p.newest, 0).Format(time.DateTime))
+	}
+	return strings.Join(lines, "\n"), nil
 }
 
+// formatSize prints n as a byte count, or in human-readable units with
+// --human
+// Shell: numfmt --to=iec
+func (p *totalSizeProgram) formatSize(n int64) string {
+	if p.human {
+		return size.Format(n)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	count `github.com/yupsh/script-examples/internal/count`
+)
+
+// walk runs the walk main() does over dir, and returns the tree it collects
+func walk(t testing.TB, dir string, useNull bool) *tree {
+	t.Helper()
+	var tr tree
+	var stdout, stderr bytes.Buffer
+	err := pipe.Pipeline(
+		listFiles(dir, useNull),
+		eachFile(tr.collect, useNull),
+	).Executor()(context.Background(), strings.NewReader(""), &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("walk: %v\n%s", err, stderr.String())
+	}
+	return &tr
+}
+
+// run runs cmd with no input and returns what it wrote to stdout
+func run(t testing.TB, cmd gloo.Command) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	if err := cmd.Executor()(context.Background(), strings.NewReader(""), &stdout, &stderr); err != nil {
+		t.Fatalf("run: %v\n%s", err, stderr.String())
+	}
+	return stdout.String()
+}
+
+// TestNewlineInName checks that, with --null, a file whose name has a
+// newline in it is found and stat'ed whole, and that its report line stays
+// one line of two fields
+func TestNewlineInName(t *testing.T) {
+	dir := t.TempDir()
+	odd := filepath.Join(dir, "a\nb.txt")
+	for name, text := range map[string]string{odd: "hello", filepath.Join(dir, "plain.go"): "package x\n"} {
+		if err := os.WriteFile(name, []byte(text), 0o644); err != nil {
+			t.Skipf("can't make a file named %q here: %v", name, err)
+		}
+	}
+
+	tr := walk(t, dir, true)
+	names := make([]string, 0, len(tr.files))
+	for _, f := range tr.files {
+		names = append(names, f.name)
+	}
+	slices.Sort(names)
+	if want := []string{odd, filepath.Join(dir, "plain.go")}; !slices.Equal(names, want) {
+		t.Fatalf("walk found %q, want %q", names, want)
+	}
+
+	report := run(t, tr.lines(sizeAndName))
+	lines := strings.Split(strings.TrimSuffix(report, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("report has %d lines, want 2:\n%s", len(lines), report)
+	}
+	want := "5\t" + `"` + dir + `/a\nb.txt"`
+	if !slices.Contains(lines, want) {
+		t.Errorf("report %q has no line %q", lines, want)
+	}
+	for _, line := range lines {
+		if n := strings.Count(line, "\t"); n != 1 {
+			t.Errorf("report line %q has %d tabs, want 1", line, n)
+		}
+	}
+}
+
+// sampleTree writes files small files, spread over directories of 100, with
+// a few extensions between them, and returns the directory holding them
+func sampleTree(b *testing.B, files int) string {
+	b.Helper()
+	dir := b.TempDir()
+	exts := []string{"go", "md", "txt", "json", ""}
+	for i := range files {
+		sub := filepath.Join(dir, fmt.Sprintf("d%03d", i/100))
+		if err := os.MkdirAll(sub, 0o755); err != nil {
+			b.Fatal(err)
+		}
+		name := fmt.Sprintf("f%d", i)
+		if ext := exts[i%len(exts)]; ext != "" {
+			name += "." + ext
+		}
+		if err := os.WriteFile(filepath.Join(sub, name), bytes.Repeat([]byte("x"), i%4096), 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// reports are the formats of the three reports' first stages
+var reports = []func(file) string{extension, sizeAndName, sizeRecord}
+
+// BenchmarkWalk compares the single walk the reports share with the walk
+// per report file-stats made before, the README's benchmark on a generated
+// tree:
+//
+//   go test -bench Walk -benchmem
+//
+// Both feed the same lines to the reports; the reports' sort, uniq, head and
+// awk stages, the same either way, are left out.
+func BenchmarkWalk(b *testing.B) {
+	dir := sampleTree(b, 2000)
+	b.Run("once", func(b *testing.B) {
+		for b.Loop() {
+			t := walk(b, dir, false)
+			for _, format := range reports {
+				discard(b, t.lines(format))
+			}
+		}
+	})
+	b.Run("per-report", func(b *testing.B) {
+		for b.Loop() {
+			for _, format := range reports {
+				discard(b, walk(b, dir, false).lines(format))
+			}
+		}
+	})
+}
+
+// discard runs cmd with no input, throwing its output away
+func discard(b *testing.B, cmd gloo.Command) {
+	var stderr bytes.Buffer
+	if err := cmd.Executor()(context.Background(), strings.NewReader(""), io.Discard, &stderr); err != nil {
+		b.Fatalf("%v\n%s", err, stderr.String())
+	}
+}
+
+// build builds file-stats into a temporary directory and returns its path
+func build(t *testing.T) string {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "file-stats")
+	if out, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput(); err != nil {
+		t.Fatalf("building file-stats: %v\n%s", err, out)
+	}
+	return bin
+}
+
+// TestTopRejected checks that a --top below 1 is a usage error, exit status
+// 2 with a message, rather than a slice bounds panic in the report
+func TestTopRejected(t *testing.T) {
+	bin := build(t)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, top := range []string{"-1", "0", "ten"} {
+		var stderr bytes.Buffer
+		cmd := exec.Command(bin, "--top", top, dir)
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		var exit *exec.ExitError
+		if !errors.As(err, &exit) || exit.ExitCode() != 2 {
+			t.Errorf("--top %s: got %v, want exit status 2\n%s", top, err, stderr.String())
+		}
+		if !strings.Contains(stderr.String(), "-top") || strings.Contains(stderr.String(), "panic") {
+			t.Errorf("--top %s: stderr doesn't name the flag:\n%s", top, stderr.String())
+		}
+	}
+}
+
+// TestRunning checks the --stream running line at each --top, fewer
+// extensions than --top included, and that --stream rejects a --top below 1
+// before the walk, as the reports do
+func TestRunning(t *testing.T) {
+	h := newHistogram(1)
+	for _, ext := range []string{"go", "md", "go", "", "txt", "go", "md"} {
+		h.add(ext)
+	}
+	tests := []struct {
+		top  int
+		want string
+	}{
+		{1, "go 3"},
+		{2, "go 3, md 2"},
+		{3, "go 3, md 2, txt 1"},
+		{10, "go 3, md 2, txt 1"},
+	}
+	for _, tt := range tests {
+		h.top = tt.top
+		if got := h.running(); got != tt.want {
+			t.Errorf("running() with top %d = %q, want %q", tt.top, got, tt.want)
+		}
+	}
+	if got := newHistogram(10).running(); got != "" {
+		t.Errorf("running() with nothing counted = %q, want \"\"", got)
+	}
+
+	bin := build(t)
+	var stderr bytes.Buffer
+	cmd := exec.Command(bin, "--stream", "--top", "-1", t.TempDir())
+	cmd.Stderr = &stderr
+	var exit *exec.ExitError
+	if err := cmd.Run(); !errors.As(err, &exit) || exit.ExitCode() != 2 || strings.Contains(stderr.String(), "panic") {
+		t.Errorf("--stream --top -1: got %v, want exit status 2 without a panic\n%s", err, stderr.String())
+	}
+}
+
+// TestLineCounts checks the --lines report on files with a line longer
+// than count.BufferSize, a last line without a newline, and no lines
+func TestLineCounts(t *testing.T) {
+	dir := t.TempDir()
+	long := strings.Repeat("x", 3*count.BufferSize/2)
+	for name, text := range map[string]string{
+		"bundle.js":     long + "\n" + long + "\n",
+		"one-line.json": long,
+		"a.go":          "package a\n\nfunc A() {}\n",
+		"b.go":          "package b",
+		"empty.go":      "",
+		"README":        "one\ntwo\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tr := tree{countLines: true}
+	err := pipe.Pipeline(listFiles(dir, false), eachFile(tr.collect, false)).
+		Executor()(context.Background(), strings.NewReader(""), io.Discard, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range tr.files {
+		want := map[string]int64{"bundle.js": 2, "one-line.json": 1, "a.go": 3, "b.go": 1, "empty.go": 0, "README": 2}[filepath.Base(f.name)]
+		if f.lines != want {
+			t.Errorf("%s: %d lines, want %d", filepath.Base(f.name), f.lines, want)
+		}
+	}
+
+	// Ties go by name, and no extension sorts first, as in the size breakdown
+	want := "Total: 9 lines\n  go: 4 lines\n  (none): 2 lines\n  js: 2 lines\n"
+	if got := tr.lineCounts(3); got != want {
+		t.Errorf("lineCounts(3) =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,39 @@
+#!/bin/bash
+
+# List files and directories whose permission bits match a mode
+# yupsh equivalent: See main.go
+
+usage() {
+  echo "Usage: $0 (--mode OCTAL [--exact] | --world-writable) [directory]" >&2
+  exit 2
+}
+
+# Parse flags (--mode M, --exact, --world-writable), then the directory
+# yupsh: mode := opts.String("mode", ...); exact := opts.Bool("exact", ...)
+MODE=
+EXACT=0
+WORLD=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --mode) MODE=$2; shift 2 ;;
+    --exact) EXACT=1; shift ;;
+    --world-writable) WORLD=1; shift ;;
+    *) usage ;;
+  esac
+done
+if (( WORLD )); then
+  [[ -z ${MODE} ]] && (( !EXACT )) || usage
+  MODE=0002
+fi
+[[ ${MODE} =~ ^[0-7]{1,4}$ ]] || usage
+DIR=${1:-.}
+
+# -perm -MODE: at least these bits; -perm MODE: exactly these bits
+# yupsh: bits&m.want == m.want, or bits == m.want with --exact
+PERM=-${MODE}
+(( EXACT )) && PERM=${MODE}
+
+# Symlinks are skipped: their own mode is always 0777. Modes are padded to
+# four digits, as fmt's %04o does
+# yupsh: find.Find(find.Dir(dir)) | While(m.check, input.WholeLine)
+find "${DIR}" ! -type l -perm "${PERM}" -printf '000%m %p\n' | sed -E 's/^0*([0-7]{4}) /\1 /'
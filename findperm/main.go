@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+
+	echo `github.com/yupsh/echo`
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// worldWritable is the "other: write" bit, for --world-writable
+const worldWritable = 0o002
+
+// List files and directories whose permission bits match a mode
+// Shell equivalent: See findperm.sh
+//
+//   findperm --world-writable /srv
+//   0777 /srv/uploads
+//   0666 /srv/app/config.ini
+//
+// By default a file matches if it has *at least* the bits of --mode, whatever
+// else it has (find -perm -MODE): --mode 0002 finds everything writable by
+// others, --mode 4000 every setuid program. With --exact its permissions
+// must equal --mode exactly (find -perm MODE): --mode 0644 --exact finds the
+// files that are precisely rw-r--r--.
+//
+// Modes are octal, including the setuid (4000), setgid (2000) and sticky
+// (1000) bits. Symlinks are skipped, since their own mode is always 0777 and
+// says nothing about access. Each match is printed with its mode.
+//
+// Usage: findperm (--mode OCTAL [--exact] | --world-writable) [directory]
+func main() {
+	opts := flags.New("findperm", "[directory]")
+	mode := opts.String("mode", "", "octal permission `bits` to look for, e.g. 0777 or 4000")
+	exact := opts.Bool("exact", false, "match only files whose mode is exactly --mode")
+	world := opts.Bool("world-writable", false, "find files anyone can write to (same as --mode 0002)")
+	opts.Parse()
+
+	var want uint64
+	switch {
+	case *world && *mode != "":
+		opts.Fail("--world-writable and --mode are mutually exclusive")
+	case *world:
+		want = worldWritable
+		if *exact {
+			opts.Fail("--exact needs --mode")
+		}
+	case *mode == "":
+		opts.Fail("--mode or --world-writable is required")
+	default:
+		var err error
+		if want, err = strconv.ParseUint(*mode, 8, 32); err != nil || want > 0o7777 {
+			opts.Fail("--mode: %q is not an octal mode from 0 to 7777", *mode)
+		}
+	}
+	dir := opts.ArgOr(0, ".")
+
+	// find only warns about a missing directory, so check it up front
+	if err := input.Dir(dir); err != nil {
+		status.Result{Err: err}.Exit("findperm")
+	}
+
+	m := matcher{want: uint32(want), exact: *exact}
+
+	err := gloo.Run(pipe.Pipeline(
+		// Shell: find "${DIR}"
+		find.Find(find.Dir(dir)),
+
+		// Keep the entries whose mode matches
+		// Shell: -perm -0002 (at least) or -perm 0644 (exact)
+		While(m.check, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "findperm: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// matcher is the permission predicate
+type matcher struct {
+	want  uint32
+	exact bool
+}
+
+// check is the While() callback: it echoes the entry with its mode if the
+// mode matches
+func (m matcher) check(args ...any) gloo.Command {
+	path := args[0].(string)
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "findperm: %v\n", err)
+		return nil
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		return nil
+	}
+
+	bits := unixBits(info.Mode())
+	if m.exact && bits != m.want || !m.exact && bits&m.want != m.want {
+		return nil
+	}
+	// Shell: -printf '%#m %p\n'
+	return echo.Echo(fmt.Sprintf("%04o %s", bits, path))
+}
+
+// unixBits converts a FileMode to the octal bits chmod and ls use: Go keeps
+// setuid, setgid and sticky outside the permission bits
+func unixBits(mode fs.FileMode) uint32 {
+	bits := uint32(mode.Perm())
+	if mode&fs.ModeSetuid != 0 {
+		bits |= 0o4000
+	}
+	if mode&fs.ModeSetgid != 0 {
+		bits |= 0o2000
+	}
+	if mode&fs.ModeSticky != 0 {
+		bits |= 0o1000
+	}
+	return bits
+}
@@ -0,0 +1,32 @@
+#!/bin/bash
+
+# List the files in a tree modified after (or, with --older, not after) a
+# reference file
+# yupsh equivalent: See main.go
+
+# Parse flags (--older), then the reference and the directory
+# yupsh: older := opts.Bool("older", ...)
+OLDER=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --older) OLDER=1; shift ;;
+    *) echo "Usage: $0 [--older] reference [directory]" >&2; exit 2 ;;
+  esac
+done
+if (( $# == 0 || $# > 2 )); then
+  echo "Usage: $0 [--older] reference [directory]" >&2
+  exit 2
+fi
+REF=$1
+DIR=${2:-.}
+
+# find reads the reference's mtime once, before the walk
+# yupsh: ref, err := os.Stat(opts.Arg(0))
+[[ -e ${REF} ]] || { echo "findtime: ${REF}: No such file or directory" >&2; exit 1; }
+
+# yupsh: find.Find(find.Dir(dir), find.FileType) | While(c.check, input.WholeLine)
+if (( OLDER )); then
+  find "${DIR}" -type f ! -newer "${REF}" ! -samefile "${REF}"
+else
+  find "${DIR}" -type f -newer "${REF}"
+fi
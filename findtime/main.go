@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	echo `github.com/yupsh/echo`
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// List the files in a tree modified after (or, with --older, not after) a
+// reference file, like find -newer
+// Shell equivalent: See findtime.sh
+//
+//   touch .last-build; ...; findtime .last-build src
+//   src/parser.go
+//   src/lexer/tokens.go
+//
+// The reference is stat'ed once, up front; its modification time is the
+// anchor every file in the walk is compared against. A file is newer when
+// its mtime is strictly after the anchor. --older lists the rest: files
+// modified at or before it, as find ! -newer does, except for the reference
+// file itself.
+//
+// Usage: findtime [--older] reference [directory]
+func main() {
+	opts := flags.New("findtime", "reference [directory]")
+	older := opts.Bool("older", false, "list files not modified since the reference instead")
+	opts.Parse()
+	if opts.NArg() == 0 {
+		opts.Fail("missing reference file")
+	}
+	if opts.NArg() > 2 {
+		opts.Fail("too many arguments")
+	}
+	dir := opts.ArgOr(1, ".")
+
+	// Shell: find -newer reads the reference's mtime once
+	ref, err := os.Stat(opts.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "findtime: %v\n", err)
+		os.Exit(1)
+	}
+	// find only warns about a missing directory, so check it up front
+	if err := input.Dir(dir); err != nil {
+		status.Result{Err: err}.Exit("findtime")
+	}
+
+	c := comparer{ref: ref, anchor: ref.ModTime(), older: *older}
+
+	err = gloo.Run(pipe.Pipeline(
+		// Shell: find "${DIR}" -type f
+		find.Find(find.Dir(dir), find.FileType),
+
+		// Keep the files on the requested side of the anchor
+		// Shell: -newer "${REF}"  or  ! -newer "${REF}" ! -samefile "${REF}"
+		While(c.check, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "findtime: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// comparer holds the reference file and its modification time
+type comparer struct {
+	ref    os.FileInfo
+	anchor time.Time
+	older  bool
+}
+
+// check is the While() callback: it echoes the path if the file is on the
+// requested side of the anchor
+func (c comparer) check(args ...any) gloo.Command {
+	path := args[0].(string)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "findtime: %v\n", err)
+		return nil
+	}
+
+	newer := info.ModTime().After(c.anchor)
+	if newer == c.older || c.older && os.SameFile(info, c.ref) {
+		return nil
+	}
+	return echo.Echo(path)
+}
@@ -0,0 +1,77 @@
+#!/bin/bash
+set -e
+
+# Copy (or move) every file of a directory tree into one flat directory
+# yupsh equivalent: See main.go
+
+# Parse flags (--move, --verbose), then the source and destination
+# yupsh: move := opts.Bool("move", ...); verbose := opts.Bool("verbose", ...)
+MOVE=0
+VERBOSE=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --move) MOVE=1; shift ;;
+    --verbose) VERBOSE=1; shift ;;
+    *) echo "Usage: $0 [--move] [--verbose] source destination" >&2; exit 2 ;;
+  esac
+done
+if (( $# != 2 )); then
+  echo "Usage: $0 [--move] [--verbose] source destination" >&2
+  exit 2
+fi
+SRC=${1%/}
+DEST=${2%/}
+mkdir -p "${DEST}"
+DEST_ABS=$(cd "${DEST}" && pwd)
+
+# yupsh: newFlattener() marks the names already in the destination as taken
+declare -A TAKEN
+for existing in "${DEST}"/* "${DEST}"/.*; do
+  [[ -e ${existing} ]] && TAKEN[${existing##*/}]=1
+done
+
+DONE=0
+RENAMED=0
+FAILED=0
+while IFS= read -r -d '' file; do
+  # yupsh: skip files that are already in the destination
+  [[ $(cd "${file%/*}" && pwd) == "${DEST_ABS}" ]] && continue
+
+  # yupsh: f.freeName(rel) — base name, else the path joined with "_",
+  # else that with a counter
+  base=${file##*/}
+  rel=${file#"${SRC}"/}
+  name=${base}
+  if [[ -n ${TAKEN[${name}]} ]]; then
+    name=${rel//\//_}
+    if [[ -n ${TAKEN[${name}]} ]]; then
+      stem=${name%.*} ext=${name#"${stem}"}
+      [[ ${stem} == "${name}" ]] && ext=
+      i=2
+      while [[ -n ${TAKEN[${stem}-${i}${ext}]} ]]; do i=$((i + 1)); done
+      name=${stem}-${i}${ext}
+    fi
+  fi
+  [[ ${name} != "${base}" ]] && RENAMED=$((RENAMED + 1))
+  TAKEN[${name}]=1
+
+  # yupsh: moveFile() / copyFile(), never overwriting
+  if [[ -e ${DEST}/${name} ]]; then
+    echo "flatten: ${DEST}/${name}: already exists" >&2; FAILED=$((FAILED + 1)); continue
+  fi
+  if (( MOVE )); then
+    mv -n -- "${file}" "${DEST}/${name}" || { FAILED=$((FAILED + 1)); continue; }
+  else
+    cp -n -p -- "${file}" "${DEST}/${name}" || { FAILED=$((FAILED + 1)); continue; }
+  fi
+  DONE=$((DONE + 1))
+  (( VERBOSE )) && echo "${file} -> ${DEST}/${name}"
+done < <(find "${SRC}" -type f -print0 | LC_ALL=C sort -z)
+
+# yupsh: fmt.Fprintf(os.Stderr, "flatten: %s %d file(s), %d renamed ...")
+(( MOVE )) && VERB=moved || VERB=copied
+echo "flatten: ${VERB} ${DONE} file(s), ${RENAMED} renamed to avoid a collision" >&2
+if (( FAILED )); then
+  echo "flatten: ${FAILED} file(s) failed" >&2
+  exit 1
+fi
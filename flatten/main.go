@@ -0,0 +1,212 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Copy (or move) every file of a directory tree into one flat directory
+// Shell equivalent: See flatten.sh
+//
+//   src/2025/feb/data.csv  ->  out/data.csv
+//   src/2025/jan/data.csv  ->  out/2025_jan_data.csv   (name taken)
+//   src/report.txt         ->  out/report.txt
+//
+// A file keeps its name unless that name is already taken in the
+// destination, by an existing file or an earlier one from the tree; then it
+// is prefixed with its directory relative to the source, joined with "_".
+// If even that is taken, a counter is added (2025_jan_data-2.csv). Nothing
+// in the destination is ever overwritten.
+//
+// Files are handled in find's order (by path), so which of two equal names
+// is renamed doesn't depend on chance. They are moved with --move and copied
+// otherwise; a summary of how many were handled and renamed goes to stderr.
+//
+// Usage: flatten [--move] [--verbose] source destination
+func main() {
+	opts := flags.New("flatten", "source destination")
+	move := opts.Bool("move", false, "move the files instead of copying them")
+	verbose := opts.Bool("verbose", false, "print each file as it is handled")
+	opts.Parse()
+	if opts.NArg() != 2 {
+		opts.Fail("want a source and a destination directory")
+	}
+	src, dst := opts.Arg(0), opts.Arg(1)
+
+	// Shell: mkdir -p "$DEST"
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "flatten: %v\n", err)
+		os.Exit(1)
+	}
+	f, err := newFlattener(src, dst, *move, *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "flatten: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = gloo.Run(pipe.Pipeline(
+		// Shell: find "$SRC" -type f
+		find.Find(find.Dir(src), find.FileType),
+
+		// Shell: while IFS= read -r file; do cp -n "$file" "$DEST/$name"; done
+		While(f.file, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "flatten: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+
+	// Shell: echo "copied $n file(s), $renamed renamed" >&2
+	verb := "copied"
+	if *move {
+		verb = "moved"
+	}
+	fmt.Fprintf(os.Stderr, "flatten: %s %d file(s), %d renamed to avoid a collision\n", verb, f.done, f.renamed)
+	if f.failed > 0 {
+		fmt.Fprintf(os.Stderr, "flatten: %d file(s) failed\n", f.failed)
+		os.Exit(1)
+	}
+}
+
+// flattener places files from the source tree into the destination
+type flattener struct {
+	src, dst string
+	move     bool
+	verbose  bool
+	taken    map[string]bool // names in use in the destination
+
+	done, renamed, failed int
+}
+
+// newFlattener records the names already in the destination, so they are
+// never overwritten
+func newFlattener(src, dst string, move, verbose bool) (*flattener, error) {
+	entries, err := os.ReadDir(dst)
+	if err != nil {
+		return nil, err
+	}
+	f := &flattener{src: src, dst: dst, move: move, verbose: verbose, taken: make(map[string]bool)}
+	for _, e := range entries {
+		f.taken[e.Name()] = true
+	}
+	return f, nil
+}
+
+// file is the While() callback: it copies or moves one file into the
+// destination under a free name
+func (f *flattener) file(args ...any) gloo.Command {
+	path := args[0].(string)
+
+	// Files already in the destination (when it is inside the source) are
+	// where they belong
+	if dir, err := filepath.Abs(filepath.Dir(path)); err == nil {
+		if abs, err := filepath.Abs(f.dst); err == nil && dir == abs {
+			return nil
+		}
+	}
+
+	rel, err := filepath.Rel(f.src, path)
+	if err != nil {
+		rel = path
+	}
+	name := f.freeName(rel)
+	if name != filepath.Base(path) {
+		f.renamed++
+	}
+	f.taken[name] = true
+	target := filepath.Join(f.dst, name)
+
+	if f.move {
+		err = moveFile(path, target)
+	} else {
+		err = copyFile(path, target)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "flatten: %v\n", err)
+		f.failed++
+		return nil
+	}
+	f.done++
+	if f.verbose {
+		fmt.Printf("%s -> %s\n", path, target)
+	}
+	return nil
+}
+
+// freeName picks the destination name for the file at rel (relative to the
+// source): its base name, else its path joined with "_", else that with a
+// counter
+func (f *flattener) freeName(rel string) string {
+	if base := filepath.Base(rel); !f.taken[base] {
+		return base
+	}
+	flat := strings.ReplaceAll(filepath.ToSlash(rel), "/", "_")
+	if !f.taken[flat] {
+		return flat
+	}
+
+	ext := filepath.Ext(flat)
+	stem := strings.TrimSuffix(flat, ext)
+	for i := 2; ; i++ {
+		if name := stem + "-" + strconv.Itoa(i) + ext; !f.taken[name] {
+			return name
+		}
+	}
+}
+
+// copyFile copies src to a new file dst with the same permissions; it fails
+// rather than overwrite dst
+// Shell: cp -n "$src" "$dst"
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return fmt.Errorf("%s: %w", src, err)
+	}
+	return out.Close()
+}
+
+// moveFile renames src to dst, or copies and deletes it when they are on
+// different filesystems
+// Shell: mv -n "$src" "$dst"
+func moveFile(src, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s: already exists", dst)
+	}
+	err := os.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
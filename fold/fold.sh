@@ -0,0 +1,29 @@
+#!/bin/bash
+set -e
+
+# Wrap long lines to a maximum width
+# yupsh equivalent: See main.go
+
+# Parse flags (--width N, --spaces, --tab-width N), then the files
+# yupsh: width := opts.Int("width", 80, ...); spaces := opts.Bool("spaces", ...)
+WIDTH=80
+SPACES=
+while [[ $1 == --* ]]; do
+  case $1 in
+    --width) WIDTH=$2; shift 2 ;;
+    --spaces) SPACES=-s; shift ;;
+    --tab-width)
+      # GNU fold has fixed 8-column tab stops; expand other widths first
+      # yupsh: advance(column, '\t', tabWidth)
+      TAB_WIDTH=$2; shift 2 ;;
+    *) echo "Usage: $0 [--width 80] [--spaces] [--tab-width 8] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Fold every line; one input line may become several
+# yupsh: While(wrap(*width, *spaces, *tabWidth), input.WholeLine)
+if [[ -n ${TAB_WIDTH} && ${TAB_WIDTH} != 8 ]]; then
+  cat "$@" | expand -t "${TAB_WIDTH}" | fold -w "${WIDTH}" ${SPACES}
+else
+  cat "$@" | fold -w "${WIDTH}" ${SPACES}
+fi
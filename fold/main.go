@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Wrap long lines to a maximum width, like fold
+// Shell equivalent: See fold.sh
+//
+//   fold --width 20 --spaces
+//   The quick brown fox jumps over the lazy dog
+//   ->
+//   The quick brown fox
+//   jumps over the lazy
+//   dog
+//
+// Widths are in columns: a tab advances to the next multiple of --tab-width,
+// a backspace moves back one column, and a carriage return goes back to the
+// start. Unlike GNU fold, which counts bytes, each character (rune) is one
+// column, so UTF-8 text isn't split mid-character.
+//
+// Usage: fold [--width 80] [--spaces] [--tab-width 8] [file...]
+func main() {
+	opts := flags.New("fold", "[file...]")
+	width := opts.Int("width", 80, "wrap lines longer than `N` columns")
+	spaces := opts.Bool("spaces", false, "break at the last blank before the width, if any")
+	tabWidth := opts.Int("tab-width", 8, "columns between tab stops")
+	opts.Parse()
+	if *width < 1 {
+		opts.Fail("--width must be at least 1")
+	}
+	if *tabWidth < 1 {
+		opts.Fail("--tab-width must be at least 1")
+	}
+
+	// Shell: fold -w 80 [-s] "$@"
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		While(wrap(*width, *spaces, *tabWidth), input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fold: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// wrap returns the While() callback folding one input line into as many
+// output lines as it takes
+//
+// One line in, many lines out: the callback returns a single echo.Echo()
+// whose text holds the pieces joined by newlines.
+func wrap(width int, spaces bool, tabWidth int) Body {
+	return func(args ...any) gloo.Command {
+		pieces := foldLine(args[0].(string), width, spaces, tabWidth)
+		return echo.Echo(strings.Join(pieces, "\n"))
+	}
+}
+
+// foldLine splits line into pieces no wider than width columns
+func foldLine(line string, width int, spaces bool, tabWidth int) []string {
+	var pieces []string
+	var current []rune
+	column := 0
+
+	for _, c := range line {
+		for {
+			next := advance(column, c, tabWidth)
+			if next <= width || len(current) == 0 {
+				// It fits, or it is too wide on its own: keep it either way
+				current = append(current, c)
+				column = next
+				break
+			}
+
+			// Shell: fold -s  - break after the last blank, when there is one
+			if spaces {
+				if i := lastBlank(current); i >= 0 {
+					pieces = append(pieces, string(current[:i+1]))
+					current = append([]rune(nil), current[i+1:]...)
+					column = columns(current, tabWidth)
+					continue
+				}
+			}
+
+			pieces = append(pieces, string(current))
+			current, column = nil, 0
+		}
+	}
+	return append(pieces, string(current))
+}
+
+// advance returns the column after printing c at column
+func advance(column int, c rune, tabWidth int) int {
+	switch c {
+	case '\t':
+		return column + tabWidth - column%tabWidth
+	case '\b':
+		return max(column-1, 0)
+	case '\r':
+		return 0
+	}
+	return column + 1
+}
+
+// columns returns the width of the runes printed from column 0
+func columns(runes []rune, tabWidth int) int {
+	column := 0
+	for _, c := range runes {
+		column = advance(column, c, tabWidth)
+	}
+	return column
+}
+
+// lastBlank returns the index of the last space or tab in runes, or -1
+func lastBlank(runes []rune) int {
+	for i := len(runes) - 1; i >= 0; i-- {
+		if runes[i] == ' ' || runes[i] == '\t' {
+			return i
+		}
+	}
+	return -1
+}
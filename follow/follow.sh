@@ -0,0 +1,24 @@
+#!/bin/bash
+
+# Follow a growing file, like `tail -F`
+# yupsh equivalent: See main.go
+
+# Parse flags (--lines N, --interval 1s), then the file
+# yupsh: lines := opts.Int("lines", 10, ...); interval := opts.Duration("interval", time.Second, ...)
+LINES=10
+INTERVAL=1
+while [[ $1 == --* ]]; do
+  case $1 in
+    --lines) LINES=$2; shift 2 ;;
+    --interval) INTERVAL=${2%s}; shift 2 ;;
+    *) echo "Usage: $0 [--lines N] [--interval 1s] file" >&2; exit 2 ;;
+  esac
+done
+
+# Exit cleanly on Ctrl-C
+# yupsh: signal.NotifyContext(..., os.Interrupt, syscall.SIGTERM)
+trap 'exit 0' INT TERM
+
+# -F follows the name, so truncation and rotation are handled too
+# yupsh: gloo.RunWithContext(ctx, follow(file, *lines, *interval))
+tail -n "${LINES}" -F -s "${INTERVAL}" "$1"
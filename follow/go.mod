@@ -0,0 +1,11 @@
+module github.com/yupsh/script-examples/follow
+
+go 1.25
+
+require (
+	github.com/gloo-foo/framework v0.0.3
+	github.com/yupsh/script-examples/internal v0.0.0
+	github.com/yupsh/tail v0.0.3
+)
+
+replace github.com/yupsh/script-examples/internal => ../internal
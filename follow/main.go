@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	gloo `github.com/gloo-foo/framework`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	status `github.com/yupsh/script-examples/internal/status`
+	tail `github.com/yupsh/tail`
+)
+
+// Follow a growing file, like `tail -F`
+// Shell equivalent: See follow.sh
+//
+// Prints the last lines of a file, then keeps printing new data as it is
+// appended, until interrupted with Ctrl-C (which exits cleanly with status 0).
+//
+// Changes are detected by polling the file every --interval:
+//   - Growth:     the new bytes are printed
+//   - Truncation: the file shrank (e.g. `> app.log`), so reading restarts
+//                 from the beginning
+//   - Rotation:   the path now names a different file (e.g. logrotate moved
+//                 app.log to app.log.1 and created a new app.log), so the
+//                 rest of the old file is printed, then the new file is
+//                 opened and read from the beginning
+// While the path is temporarily missing during a rotation, the old file stays
+// open and polling continues until the new one appears.
+//
+// Usage: follow [--lines N] [--interval 1s] file
+func main() {
+	opts := flags.New("follow", "file")
+	lines := opts.Int("lines", 10, "print the last `N` lines before following")
+	interval := opts.Duration("interval", time.Second, "how often to check the file for changes")
+	opts.Parse()
+	if opts.NArg() != 1 {
+		opts.Fail("exactly one file is required")
+	}
+	if *interval <= 0 {
+		opts.Fail("--interval must be positive")
+	}
+
+	// Cancel the context on Ctrl-C, so the follow loop can return cleanly
+	// Shell: trap 'exit 0' INT TERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := gloo.RunWithContext(ctx, follow(opts.Arg(0), *lines, *interval))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "follow: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// follow returns a command that prints the last n lines of path and then
+// streams whatever is appended to it
+//
+// Shell equivalent:
+//   tail -n 10 -F -s 1 app.log
+//
+// It ignores stdin: the data comes from the file, and new data is noticed by
+// polling rather than by waiting on a pipe.
+func follow(path string, n int, interval time.Duration) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { f.Close() }() // f is replaced when the file is rotated
+
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+
+		// Print the last n lines of what is there now
+		// Shell: tail -n 10 app.log
+		offset := info.Size()
+		last := tail.Tail(tail.LineCount(n)).Executor()
+		if err := last(ctx, io.NewSectionReader(f, 0, offset), stdout, stderr); err != nil {
+			return err
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil // Ctrl-C: a normal way to stop following
+			case <-ticker.C:
+			}
+
+			// Rotation: the path now refers to a different file
+			if current, err := os.Stat(path); err == nil && !os.SameFile(info, current) {
+				if rotated, err := os.Open(path); err == nil {
+					// Finish the old file first: what was written to it
+					// since the last poll comes before the new file's lines
+					// Shell: tail -F reads the old file to its end too
+					_, err := io.Copy(stdout, io.NewSectionReader(f, offset, math.MaxInt64-offset))
+					f.Close()
+					f, info, offset = rotated, current, 0
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+			info, err = f.Stat()
+			if err != nil {
+				return err
+			}
+
+			// Truncation: start over from the beginning
+			if info.Size() < offset {
+				offset = 0
+			}
+
+			// Growth: print the new bytes
+			if info.Size() > offset {
+				copied, err := io.Copy(stdout, io.NewSectionReader(f, offset, info.Size()-offset))
+				offset += copied
+				if err != nil {
+					return err
+				}
+			}
+		}
+	})
+}
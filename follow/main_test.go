@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// output is a bytes.Buffer that follow can write to while the test reads it
+type output struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.Write(p)
+}
+
+func (o *output) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.String()
+}
+
+// waitFor waits until out has a line "line", failing the test after a while
+func waitFor(t *testing.T, out *output, line string) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		if strings.Contains(out.String(), line+"\n") {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("no line %q in the output so far:\n%s", line, out.String())
+}
+
+// TestRotation checks that a line written to the file just before it was
+// rotated, between two polls, is printed ahead of the new file's lines
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var out output
+	done := make(chan error, 1)
+	go func() {
+		done <- follow(path, 10, 200*time.Millisecond).Executor()(ctx, strings.NewReader(""), &out, &out)
+	}()
+	waitFor(t, &out, "two")
+
+	// Append to app.log and rotate it, all well within one --interval
+	// Shell: echo three >> app.log; mv app.log app.log.1; echo four > app.log
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("three\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("four\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, &out, "four")
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "one\ntwo\nthree\nfour\n"; got != want {
+		t.Errorf("follow printed %q, want %q", got, want)
+	}
+}
+
+// TestTruncation checks that reading starts over when the file shrinks
+func TestTruncation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("a long first line\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var out output
+	done := make(chan error, 1)
+	go func() {
+		done <- follow(path, 10, 10*time.Millisecond).Executor()(ctx, strings.NewReader(""), &out, &out)
+	}()
+	waitFor(t, &out, "a long first line")
+
+	// Shell: echo short > app.log
+	if err := os.WriteFile(path, []byte("short\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, &out, "short")
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,30 @@
+#!/bin/bash
+set -e
+
+# Collapse runs of adjacent lines that differ only in case or trailing
+# whitespace
+# yupsh equivalent: See main.go
+
+# Parse flags (--ignore-case, --ignore-trailing-space), then the files
+# yupsh: ignoreCase := opts.Bool("ignore-case", ...); ignoreTrailingSpace := ...
+IGNORE_CASE=0
+IGNORE_SPACE=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --ignore-case) IGNORE_CASE=1; shift ;;
+    --ignore-trailing-space) IGNORE_SPACE=1; shift ;;
+    *) echo "Usage: $0 [--ignore-case] [--ignore-trailing-space] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Compare each line's normalized form with the previous one's, and print
+# the line as it was read when they differ
+# yupsh: While(u.line, input.WholeLine) with the uniquer state
+cat "$@" | awk -v icase="${IGNORE_CASE}" -v ispace="${IGNORE_SPACE}" '
+  {
+    key = $0
+    if (ispace) sub(/[[:space:]]+$/, "", key)
+    if (icase) key = tolower(key)
+    if (NR == 1 || key != prev) print
+    prev = key
+  }'
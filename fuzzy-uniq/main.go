@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Collapse runs of adjacent lines that differ only in case or trailing
+// whitespace, a more forgiving uniq
+// Shell equivalent: See fuzzy-uniq.sh
+//
+// uniq.Uniq() compares lines exactly, so "Done", "done" and "done  " are
+// three different lines. fuzzy-uniq compares them after normalizing:
+//   --ignore-case              "Done" and "done" are the same (uniq -i)
+//   --ignore-trailing-space    "done" and "done \t" are the same
+// With both flags, all three lines above collapse into one; with neither,
+// fuzzy-uniq is plain uniq.
+//
+// As with uniq, only adjacent lines are compared, and the first line of
+// each run is printed as it was read: the normalized form is only used for
+// the comparison.
+//
+// Usage: fuzzy-uniq [--ignore-case] [--ignore-trailing-space] [file...]
+func main() {
+	opts := flags.New("fuzzy-uniq", "[file...]")
+	ignoreCase := opts.Bool("ignore-case", false, "compare lines case-insensitively")
+	ignoreTrailingSpace := opts.Bool("ignore-trailing-space", false, "ignore whitespace at the end of lines")
+	opts.Parse()
+
+	var steps []normalizer
+	if *ignoreTrailingSpace {
+		steps = append(steps, trimTrailingSpace)
+	}
+	if *ignoreCase {
+		steps = append(steps, strings.ToLower)
+	}
+	u := &uniquer{normalize: chain(steps...)}
+
+	// Shell: uniq -i "$@"
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		While(u.line, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fuzzy-uniq: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// normalizer maps a line to the form it is compared in: two lines are
+// duplicates when their normalized forms are equal
+type normalizer func(string) string
+
+// chain returns the normalizer applying steps in order; with none, lines
+// are compared as they are
+func chain(steps ...normalizer) normalizer {
+	return func(line string) string {
+		for _, step := range steps {
+			line = step(line)
+		}
+		return line
+	}
+}
+
+// trimTrailingSpace drops spaces, tabs, carriage returns and any other
+// Unicode whitespace from the end of line
+// Shell: sed 's/[[:space:]]*$//'
+func trimTrailingSpace(line string) string {
+	return strings.TrimRightFunc(line, unicode.IsSpace)
+}
+
+// uniquer is the state the While() callback carries from line to line:
+// only the previous line's normalized form, however long the input is
+//
+// Shell equivalent:
+//   awk '{key = tolower($0)} NR == 1 || key != prev {print} {prev = key}'
+type uniquer struct {
+	normalize normalizer
+
+	started bool   // a line has been read
+	prev    string // the previous line, normalized
+}
+
+// line is the While() callback: it echoes a line when its normalized form
+// differs from the previous line's, so the first line of each run is kept
+func (u *uniquer) line(args ...any) gloo.Command {
+	line := args[0].(string)
+	key := u.normalize(line)
+
+	if u.started && key == u.prev {
+		return nil
+	}
+	u.started, u.prev = true, key
+	return echo.Echo(line)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	input `github.com/yupsh/script-examples/internal/input`
+	. `github.com/yupsh/while`
+)
+
+// lines is a run of lines that differ only in case and trailing
+// whitespace, then others, ending with a repeat of the first that isn't
+// adjacent to it
+const lines = "Done\ndone\ndone  \nDONE\t\n  done\nÉTÉ\nété \nother\nDone\n"
+
+// fuzzyUniq runs the pipeline main() runs with the normalizer steps over
+// text and returns its output
+func fuzzyUniq(t *testing.T, text string, steps ...normalizer) string {
+	t.Helper()
+	u := &uniquer{normalize: chain(steps...)}
+	var stdout, stderr bytes.Buffer
+	if err := While(u.line, input.WholeLine).Executor()(context.Background(), strings.NewReader(text), &stdout, &stderr); err != nil {
+		t.Fatalf("fuzzy-uniq: %v\n%s", err, stderr.String())
+	}
+	return stdout.String()
+}
+
+// TestModes checks each normalization mode, with the steps in the order
+// main() chains them; the first line of each run is printed as it was read
+func TestModes(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []normalizer
+		want  string
+	}{
+		{
+			"neither: plain uniq",
+			nil,
+			lines,
+		},
+		{
+			"--ignore-case",
+			[]normalizer{strings.ToLower},
+			"Done\ndone  \nDONE\t\n  done\nÉTÉ\nété \nother\nDone\n",
+		},
+		{
+			"--ignore-trailing-space",
+			[]normalizer{trimTrailingSpace},
+			"Done\ndone\nDONE\t\n  done\nÉTÉ\nété \nother\nDone\n",
+		},
+		{
+			"both",
+			[]normalizer{trimTrailingSpace, strings.ToLower},
+			"Done\n  done\nÉTÉ\nother\nDone\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fuzzyUniq(t, lines, tt.steps...); got != tt.want {
+				t.Errorf("got:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEdges checks the input edges: nothing, one line, blank lines, and a
+// run of lines that normalize to nothing
+func TestEdges(t *testing.T) {
+	both := []normalizer{trimTrailingSpace, strings.ToLower}
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"one\n", "one\n"},
+		{"\n\n\n", "\n"},
+		{"\n \n\t\nx\n", "\nx\n"},
+		{"a\nA\na", "a\n"},
+	}
+	for _, tt := range tests {
+		if got := fuzzyUniq(t, tt.in, both...); got != tt.want {
+			t.Errorf("%q gave %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestTrimTrailingSpace checks which whitespace is trimmed: all of it at
+// the end, Unicode included, and none elsewhere
+func TestTrimTrailingSpace(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"a \t\r\v\f", "a"},
+		{"a\u00a0\u2003", "a"}, // no-break and em spaces
+		{"  a  b  ", "  a  b"},
+		{"   ", ""},
+	}
+	for _, tt := range tests {
+		if got := trimTrailingSpace(tt.in); got != tt.want {
+			t.Errorf("trimTrailingSpace(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
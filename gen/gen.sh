@@ -0,0 +1,112 @@
+#!/bin/bash
+
+# Generate lines of synthetic data, to feed and time the other examples
+# yupsh equivalent: See main.go
+#
+# awk's rand() is not Go's, so the same --seed generates different data in
+# each version; the kinds of line and their ranges are the same.
+
+# Parse flags (--type T, --csv SHAPE, --count N, --max N, --seed S)
+# yupsh: kind := opts.Choice("type", ...); csvSpec := opts.String("csv", ...); ...
+TYPE=
+CSV=
+COUNT=10
+MAX=1000
+SEED=0
+usage() {
+  echo "Usage: $0 [--type words|numbers|csv] [--csv cols=N[,header=no]] [--count N] [--max N] [--seed S]" >&2
+  exit 2
+}
+while [[ $1 == --* ]]; do
+  case $1 in
+    --type) TYPE=$2; shift 2 ;;
+    --csv) CSV=$2; shift 2 ;;
+    --count) COUNT=$2; shift 2 ;;
+    --max) MAX=$2; shift 2 ;;
+    --seed) SEED=$2; shift 2 ;;
+    *) usage ;;
+  esac
+done
+case ${TYPE} in
+  ''|words|numbers|csv) ;;
+  *) usage ;;
+esac
+if (( $# > 0 || COUNT < 0 || MAX < 1 )); then
+  usage
+fi
+
+# --csv implies --type csv, and sets its shape
+# yupsh: parseShape(*csvSpec)
+COLS=3
+HEADER=yes
+if [[ -n ${CSV} ]]; then
+  if [[ -n ${TYPE} && ${TYPE} != csv ]]; then
+    echo "gen: --csv is for --type csv, not --type ${TYPE}" >&2
+    exit 2
+  fi
+  TYPE=csv
+  IFS=, read -r -a settings <<< "${CSV}"
+  for setting in "${settings[@]}"; do
+    if [[ ${setting} =~ ^cols=([1-9][0-9]*)$ ]]; then
+      COLS=${BASH_REMATCH[1]}
+    elif [[ ${setting} == header=yes || ${setting} == header=no ]]; then
+      HEADER=${setting#header=}
+    else
+      echo "gen: --csv: invalid setting \"${setting}\" (want cols=N or header=yes|no)" >&2
+      exit 2
+    fi
+  done
+fi
+
+# Print the lines; awk dies of SIGPIPE when the reader closes the pipe, so
+# --count 0 stops too
+# yupsh: generate(header, *count, line)
+exec awk -v type="${TYPE:-words}" -v count="${COUNT}" -v max="${MAX}" \
+  -v cols="${COLS}" -v header="${HEADER}" -v seed="${SEED}" '
+  # yupsh: words[g.rng.IntN(len(words))]
+  function word() { return words[int(rand() * nwords) + 1] }
+  function number() { return int(rand() * max) }
+
+  # yupsh: g.sentence()
+  function sentence(    n, i, s) {
+    n = 4 + int(rand() * 9)
+    s = word()
+    s = toupper(substr(s, 1, 1)) substr(s, 2)
+    for (i = 2; i <= n; i++) s = s " " word()
+    return s "."
+  }
+
+  # yupsh: g.row(n, cols)
+  function row(n,    i, s) {
+    s = n
+    for (i = 1; i < cols; i++) s = s "," (i % 2 == 1 ? word() : number())
+    return s
+  }
+
+  # yupsh: headerRow(cols)
+  function header_row(    i, name, s) {
+    s = "id"
+    for (i = 1; i < cols; i++) {
+      name = i % 2 == 1 ? "word" : "number"
+      if (i > 2) name = name int((i + 1) / 2)
+      s = s "," name
+    }
+    return s
+  }
+
+  BEGIN {
+    nwords = split("lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod " \
+      "tempor incididunt ut labore et dolore magna aliqua enim ad minim veniam " \
+      "quis nostrud exercitation ullamco laboris nisi aliquip ex ea commodo " \
+      "consequat duis aute irure in reprehenderit voluptate velit esse cillum " \
+      "fugiat nulla pariatur excepteur sint occaecat cupidatat non proident sunt " \
+      "culpa qui officia deserunt mollit anim id est laborum", words, " ")
+    if (seed != 0) srand(seed); else srand()
+
+    if (type == "csv" && header == "yes") print header_row()
+    for (n = 1; count == 0 || n <= count; n++) {
+      if (type == "words") print sentence()
+      else if (type == "numbers") print number()
+      else print row(n)
+    }
+  }'
@@ -0,0 +1,10 @@
+module github.com/yupsh/script-examples/gen
+
+go 1.25
+
+require (
+	github.com/gloo-foo/framework v0.0.3
+	github.com/yupsh/script-examples/internal v0.0.0
+)
+
+replace github.com/yupsh/script-examples/internal => ../internal
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// words is the vocabulary of the generated text
+var words = strings.Fields(`
+	lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod
+	tempor incididunt ut labore et dolore magna aliqua enim ad minim veniam
+	quis nostrud exercitation ullamco laboris nisi aliquip ex ea commodo
+	consequat duis aute irure in reprehenderit voluptate velit esse cillum
+	fugiat nulla pariatur excepteur sint occaecat cupidatat non proident sunt
+	culpa qui officia deserunt mollit anim id est laborum`)
+
+// Generate lines of synthetic data, to feed and time the other examples
+// Shell equivalent: See gen.sh
+//
+//   gen --type csv --csv cols=4 --count 3 --seed 1
+//   id,word,number,word2
+//   1,laborum,102,culpa
+//   2,deserunt,285,mollit
+//   3,non,105,duis
+//
+// Three kinds of line, chosen by --type:
+//   words    a sentence of 4 to 12 lorem ipsum words
+//   numbers  an integer from 0 to --max - 1
+//   csv      a row of an id (the row number), then words and numbers in
+//            turn, after a header; --csv cols=N,header=no sets its shape
+//
+// --count 0 generates without end, as yes(1) does; the generator stops as
+// soon as the command after it closes the pipe, so `gen --count 0 | head`
+// is cheap. --seed makes the data reproducible; without it, every run
+// generates anew.
+//
+// Usage: gen [--type words|numbers|csv] [--csv cols=N[,header=no]] [--count N] [--max N] [--seed S]
+func main() {
+	opts := flags.New("gen", "")
+	kind := opts.Choice("type", "kind of line", "words", "numbers", "csv")
+	csvSpec := opts.String("csv", "", "CSV `shape`: cols=N (default 3) and header=yes|no; implies --type csv")
+	count := opts.Int("count", 10, "number of `lines` to generate, 0 for no end")
+	limit := opts.Int("max", 1000, "numbers are below `N`")
+	seed := opts.Uint64("seed", 0, "random `seed`, for reproducible data (default: random)")
+	opts.Parse()
+	if *count < 0 {
+		opts.Fail("--count must not be negative")
+	}
+	if *limit < 1 {
+		opts.Fail("--max must be at least 1")
+	}
+
+	shape := csvShape{cols: 3, header: true}
+	if *csvSpec != "" {
+		typeSet := false
+		opts.Visit(func(f *flag.Flag) { typeSet = typeSet || f.Name == "type" })
+		if typeSet && *kind != "csv" {
+			opts.Fail("--csv is for --type csv, not --type %s", *kind)
+		}
+		*kind = "csv"
+
+		var err error
+		if shape, err = parseShape(*csvSpec); err != nil {
+			opts.Fail("--csv: %v", err)
+		}
+	}
+
+	src := rand.NewPCG(rand.Uint64(), rand.Uint64())
+	if *seed != 0 {
+		src = rand.NewPCG(*seed, *seed)
+	}
+	g := &generator{rng: rand.New(src), limit: *limit}
+
+	var line func(n int) string
+	switch *kind {
+	case "words":
+		line = func(int) string { return g.sentence() }
+	case "numbers":
+		line = func(int) string { return strconv.Itoa(g.number()) }
+	case "csv":
+		line = func(n int) string { return g.row(n, shape.cols) }
+	}
+	header := ""
+	if *kind == "csv" && shape.header {
+		header = headerRow(shape.cols)
+	}
+
+	// Shell: awk 'BEGIN {srand(seed); for (n = 1; n <= count; n++) print ...}'
+	if err := gloo.Run(generate(header, *count, line)); err != nil {
+		fmt.Fprintf(os.Stderr, "gen: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// csvShape is the layout of --type csv rows
+type csvShape struct {
+	cols   int
+	header bool
+}
+
+// parseShape parses a --csv value: comma-separated key=value pairs
+func parseShape(spec string) (csvShape, error) {
+	shape := csvShape{cols: 3, header: true}
+	for _, pair := range strings.Split(spec, ",") {
+		key, value, _ := strings.Cut(pair, "=")
+		switch key {
+		case "cols":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return shape, fmt.Errorf("cols must be a number of columns, not %q", value)
+			}
+			shape.cols = n
+		case "header":
+			if value != "yes" && value != "no" {
+				return shape, fmt.Errorf("header must be yes or no, not %q", value)
+			}
+			shape.header = value == "yes"
+		default:
+			return shape, fmt.Errorf("unknown setting %q (want cols=N or header=yes|no)", pair)
+		}
+	}
+	return shape, nil
+}
+
+// generate returns the command that prints the header, unless it is "",
+// then line(n) for n from 1 to count, or without end if count is 0. It stops
+// early when the reader closes the pipe.
+//
+// Shell equivalent:
+//   for (n = 1; count == 0 || n <= count; n++) print line(n)
+func generate(header string, count int, line func(n int) string) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		out := bufio.NewWriter(stdout)
+		if header != "" {
+			if _, err := fmt.Fprintln(out, header); err != nil {
+				return err
+			}
+		}
+		for n := 1; count == 0 || n <= count; n++ {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			// A write error means the reader closed the pipe: stop
+			// generating. Through the buffer, it shows up at the next flush,
+			// within 4096 bytes of output.
+			if _, err := fmt.Fprintln(out, line(n)); err != nil {
+				return err
+			}
+		}
+		return out.Flush()
+	})
+}
+
+// generator makes up the values of the lines
+type generator struct {
+	rng   *rand.Rand
+	limit int // numbers are below limit
+}
+
+// word returns a random word of the vocabulary
+func (g *generator) word() string {
+	return words[g.rng.IntN(len(words))]
+}
+
+// number returns a random number from 0 to limit - 1
+func (g *generator) number() int {
+	return g.rng.IntN(g.limit)
+}
+
+// sentence returns 4 to 12 words, the first capitalized and the last
+// followed by a full stop
+func (g *generator) sentence() string {
+	n := 4 + g.rng.IntN(9)
+	s := make([]string, n)
+	for i := range s {
+		s[i] = g.word()
+	}
+	s[0] = strings.ToUpper(s[0][:1]) + s[0][1:]
+	return strings.Join(s, " ") + "."
+}
+
+// row returns CSV row n: its id, then a word and a number in turn. The
+// words hold no commas or quotes, so no field needs quoting.
+func (g *generator) row(n, cols int) string {
+	fields := []string{strconv.Itoa(n)}
+	for i := 1; i < cols; i++ {
+		if i%2 == 1 {
+			fields = append(fields, g.word())
+		} else {
+			fields = append(fields, strconv.Itoa(g.number()))
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// headerRow returns the header of cols columns: id, word, number, word2,
+// number2, ...
+func headerRow(cols int) string {
+	names := []string{"id"}
+	for i := 1; i < cols; i++ {
+		name := "word"
+		if i%2 == 0 {
+			name = "number"
+		}
+		if i > 2 {
+			name += strconv.Itoa((i + 1) / 2)
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,90 @@
+#!/bin/bash
+set -e
+
+# Number-sequence generators beyond `seq FIRST LAST`
+# yupsh equivalent: See main.go
+
+# Parse flags (--start, --end, --step, --width, --head)
+# yupsh: start := opts.String("start", "1", ...) and friends
+START=1
+END=10
+STEP=1
+WIDTH=0
+HEAD=0
+CUSTOM=
+while [[ $1 == --* ]]; do
+  case $1 in
+    --start) START=$2 ;;
+    --end) END=$2 ;;
+    --step) STEP=$2 ;;
+    --width) WIDTH=$2 ;;
+    --head) HEAD=$2 ;;
+    *) echo "Usage: $0 [--start 1] [--end 10] [--step 1] [--width N] [--head N]" >&2; exit 2 ;;
+  esac
+  CUSTOM=1
+  shift 2
+done
+
+if [[ -n ${CUSTOM} ]]; then
+  # Decimals of the most precise argument, as GNU seq formats them
+  # yupsh: precision := max(decimals(start), decimals(step), decimals(end))
+  PRECISION=0
+  for n in "${START}" "${STEP}" "${END}"; do
+    if [[ $n == *.* ]]; then
+      d=${n#*.}
+      (( ${#d} > PRECISION )) && PRECISION=${#d}
+    fi
+  done
+
+  # yupsh: pipe.Pipeline(sequence(start, step, end, width), head.Head(...))
+  if (( HEAD > 0 )); then
+    seq -f "%0${WIDTH}.${PRECISION}f" "${START}" "${STEP}" "${END}" | head -n "${HEAD}"
+  else
+    seq -f "%0${WIDTH}.${PRECISION}f" "${START}" "${STEP}" "${END}"
+  fi
+  exit 0
+fi
+
+echo "=== Example 1: Step values ==="
+echo "Count from 0 to 30 in steps of 5..."
+# yupsh: seq.Seq("0", "5", "30")
+seq 0 5 30
+echo
+
+echo "=== Example 2: Descending range ==="
+echo "A negative step counts down from 10 to 0 in steps of 3..."
+# yupsh: seq.Seq("10", "-3", "0")
+seq 10 -3 0
+echo
+
+echo "=== Example 3: Zero-padded widths ==="
+echo "seq.Format() pads every value to 3 digits..."
+# yupsh: seq.Seq("1", "5", seq.Format("%03g"))
+seq -f '%03g' 1 5
+echo
+
+echo "=== Example 4: Custom separator ==="
+echo "seq.Separator() joins the values on one line..."
+# yupsh: seq.Seq("1", "5", seq.Separator(","))
+seq -s , 1 5
+echo
+
+echo "=== Example 5: A billion values, but head stops after 4 ==="
+echo "Pipe closure stops seq long before it reaches the end..."
+# yupsh: pipe.Pipeline(seq.Seq("1", "3", "1000000000"), head.Head(head.LineCount(4)))
+seq 1 3 1000000000 | head -n 4
+echo
+
+echo "=== Example 6: Gap - equal width on a descending range ==="
+echo "GNU seq -w measures both ends, so 6 and 2 are padded:"
+# yupsh: seq.Seq("10", "-4", "1", seq.EqualWidth) does not pad; sequence(..., 2) does
+seq -w 10 -4 1
+echo
+
+echo "=== Example 7: Gap - fractional steps ==="
+echo "GNU seq prints at the arguments' precision:"
+# yupsh: seq.Seq("0", "0.1", "0.5") prints 0.30000000000000004; sequence() prints 0.3
+seq 0 0.1 0.5
+echo
+
+echo "Done! Run with --start/--end/--step/--width/--head for a custom sequence."
@@ -0,0 +1,13 @@
+module github.com/yupsh/script-examples/genseq
+
+go 1.25
+
+require (
+	github.com/gloo-foo/framework v0.0.3
+	github.com/gloo-foo/pipe v0.0.3
+	github.com/yupsh/head v0.0.3
+	github.com/yupsh/script-examples/internal v0.0.0
+	github.com/yupsh/seq v0.0.3
+)
+
+replace github.com/yupsh/script-examples/internal => ../internal
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	head `github.com/yupsh/head`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	status `github.com/yupsh/script-examples/internal/status`
+	seq `github.com/yupsh/seq`
+)
+
+// Number-sequence generators beyond `seq FIRST LAST`
+// Shell equivalent: See genseq.sh
+//
+// With no flags, walks through seq.Seq()'s options: steps, descending ranges,
+// zero padding, separators, and early termination by head. It doubles as a
+// check of seq.Seq()'s option surface, which has two gaps compared to GNU seq:
+//
+//  1. seq.EqualWidth pads to the width of the LAST value only, so a
+//     descending `seq -w 10 -1 1` is not padded ("9" instead of "09").
+//  2. Values are computed by repeatedly adding the step to a float64, so
+//     fractional steps accumulate error: `seq 0 0.1 0.5` prints
+//     0.30000000000000004.
+//
+// The sequence() generator below fills both gaps: it computes each value as
+// start + i*step, rounds to the precision of the arguments (as GNU seq does),
+// and pads to an explicit --width.
+//
+// With flags, prints that generator's sequence:
+// Usage: genseq [--start 1] [--end 10] [--step 1] [--width N] [--head N]
+func main() {
+	opts := flags.New("genseq", "")
+	start := opts.String("start", "1", "first `value`")
+	end := opts.String("end", "10", "last `value` (inclusive)")
+	step := opts.String("step", "1", "increment, negative to count down")
+	width := opts.Int("width", 0, "zero-pad values to `N` characters")
+	limit := opts.Int("head", 0, "stop after `N` values (0 = no limit)")
+	opts.Parse()
+
+	if opts.NFlag() == 0 {
+		showcase()
+		return
+	}
+
+	gen, err := sequence(*start, *step, *end, *width)
+	if err != nil {
+		opts.Fail("%v", err)
+	}
+
+	// Shell: seq -f "%0${WIDTH}g" "${START}" "${STEP}" "${END}" | head -n "${HEAD}"
+	stages := []any{gen}
+	if *limit > 0 {
+		stages = append(stages, head.Head(head.LineCount(*limit)))
+	}
+	runExample(pipe.Pipeline(stages...))
+}
+
+// showcase demonstrates seq.Seq()'s options, and the generator where seq
+// falls short
+func showcase() {
+	fmt.Println("=== Example 1: Step values ===")
+	fmt.Println("Count from 0 to 30 in steps of 5...")
+	runExample(seq.Seq("0", "5", "30"))
+	fmt.Println()
+
+	fmt.Println("=== Example 2: Descending range ===")
+	fmt.Println("A negative step counts down from 10 to 0 in steps of 3...")
+	runExample(seq.Seq("10", "-3", "0"))
+	fmt.Println()
+
+	fmt.Println("=== Example 3: Zero-padded widths ===")
+	fmt.Println("seq.Format() pads every value to 3 digits...")
+	runExample(seq.Seq("1", "5", seq.Format("%03g")))
+	fmt.Println()
+
+	fmt.Println("=== Example 4: Custom separator ===")
+	fmt.Println("seq.Separator() joins the values on one line...")
+	runExample(seq.Seq("1", "5", seq.Separator(",")))
+	fmt.Println() // seq.Separator() output has no trailing newline
+	fmt.Println()
+
+	fmt.Println("=== Example 5: A billion values, but head stops after 4 ===")
+	fmt.Println("Pipe closure stops seq long before it reaches the end...")
+	runExample(pipe.Pipeline(
+		seq.Seq("1", "3", "1000000000"),
+		head.Head(head.LineCount(4)),
+	))
+	fmt.Println()
+
+	fmt.Println("=== Example 6: Gap - equal width on a descending range ===")
+	fmt.Println("seq.EqualWidth only measures the last value, so 9..1 are not padded:")
+	runExample(pipe.Pipeline(seq.Seq("10", "-4", "1", seq.EqualWidth)))
+	fmt.Println("sequence() with an explicit width of 2:")
+	runExample(mustSequence("10", "-4", "1", 2))
+	fmt.Println()
+
+	fmt.Println("=== Example 7: Gap - fractional steps ===")
+	fmt.Println("seq.Seq accumulates floating-point error:")
+	runExample(seq.Seq("0", "0.1", "0.5"))
+	fmt.Println("sequence() computes start + i*step at the arguments' precision:")
+	runExample(mustSequence("0", "0.1", "0.5", 0))
+	fmt.Println()
+
+	fmt.Println("Done! Run with --start/--end/--step/--width/--head for a custom sequence.")
+}
+
+// sequence returns a generator printing start, start+step, ... up to end
+//
+// Shell equivalent:
+//   seq -f "%0${WIDTH}g" START STEP END
+//
+// Each value is computed as start + i*step (not by repeated addition), then
+// formatted with as many decimals as the most precise argument, so
+// `0 0.1 0.5` prints 0.3 rather than 0.30000000000000004. Negative values are
+// padded after the sign: -003. It stops as soon as the downstream command
+// closes the pipe, so it is safe with huge ranges.
+func sequence(start, step, end string, width int) (gloo.Command, error) {
+	var first, inc, last float64
+	for _, arg := range []struct {
+		text string
+		dest *float64
+	}{{start, &first}, {step, &inc}, {end, &last}} {
+		n, err := strconv.ParseFloat(arg.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", arg.text)
+		}
+		*arg.dest = n
+	}
+	if inc == 0 {
+		return nil, fmt.Errorf("step must not be zero")
+	}
+
+	precision := max(decimals(start), decimals(step), decimals(end))
+
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		for i := 0; ; i++ {
+			n := first + float64(i)*inc
+			if (inc > 0 && n > last) || (inc < 0 && n < last) {
+				return nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			// A write error means the reader closed the pipe: stop generating
+			if _, err := fmt.Fprintln(stdout, pad(strconv.FormatFloat(n, 'f', precision, 64), width)); err != nil {
+				return err
+			}
+		}
+	}), nil
+}
+
+// mustSequence is sequence() for the showcase's known-good arguments
+func mustSequence(start, step, end string, width int) gloo.Command {
+	cmd, err := sequence(start, step, end, width)
+	gloo.Must(err)
+	return cmd
+}
+
+// decimals returns the number of digits after the decimal point in s
+func decimals(s string) int {
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// pad zero-pads a formatted number to width characters, after any sign
+// Shell: printf '%03d' -3  ->  -03
+func pad(s string, width int) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	if zeros := width - len(sign) - len(s); zeros > 0 {
+		s = strings.Repeat("0", zeros) + s
+	}
+	return sign + s
+}
+
+func runExample(cmd gloo.Command) {
+	if err := gloo.Run(cmd); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
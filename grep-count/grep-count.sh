@@ -0,0 +1,29 @@
+#!/bin/bash
+
+# Count the lines matching a pattern and print just the number
+# yupsh equivalent: See main.go
+
+# Parse flags (--pattern P..., --invert, --ignore-case), then the files
+# yupsh: patterns := opts.List("pattern", ...); invert := opts.Bool("invert", ...)
+PATTERNS=()
+OPTIONS=()
+while [[ $1 == --* ]]; do
+  case $1 in
+    --pattern) PATTERNS+=(-e "$2"); shift 2 ;;
+    --invert) OPTIONS+=(-v); shift ;;
+    --ignore-case) OPTIONS+=(-i); shift ;;
+    *) echo "Usage: $0 [--invert] [--ignore-case] pattern [file...]" >&2; exit 2 ;;
+  esac
+done
+if (( ${#PATTERNS[@]} == 0 )); then
+  if (( $# == 0 )); then
+    echo "Usage: $0 [--invert] [--ignore-case] pattern [file...]" >&2
+    exit 2
+  fi
+  PATTERNS=(-e "$1")
+  shift
+fi
+
+# One count over all the input; grep exits 1 when it is zero
+# yupsh: countMatches(res, *invert, &n)
+cat "$@" | grep -c -E "${OPTIONS[@]}" "${PATTERNS[@]}"
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Count the lines matching a pattern and print just the number, like grep -c
+// Shell equivalent: See grep-count.sh
+//
+// The usual idiom chains two commands:
+//   grep ERROR app.log | wc -l
+// grep-count does it in one aggregation command that reads every line and
+// writes a single one at the end, so no matching lines are ever copied
+// between commands:
+//   grep-count ERROR app.log
+//   42
+//
+// It also fills a gap: grep.Grep() accepts grep.Count, but does not count.
+//
+// A line matches if any pattern matches it (patterns given with --pattern,
+// or the first argument). --invert counts the lines no pattern matches. As
+// with grep, the exit status is 0 when the count is positive, 1 when it is
+// zero and 2 on an error (the examples' convention, see internal/status), so
+// it works in `if` tests.
+//
+// Usage: grep-count [--invert] [--ignore-case] pattern [file...]
+//        grep-count [--invert] [--ignore-case] --pattern P [--pattern P]... [file...]
+func main() {
+	opts := flags.New("grep-count", "pattern [file...]")
+	patterns := opts.List("pattern", "count lines matching `regexp`; repeatable")
+	invert := opts.Bool("invert", false, "count lines that match no pattern")
+	ignoreCase := opts.Bool("ignore-case", false, "match case-insensitively")
+	opts.Parse()
+
+	files := opts.Args()
+	if len(*patterns) == 0 {
+		if len(files) == 0 {
+			opts.Fail("missing pattern")
+		}
+		*patterns, files = files[:1], files[1:]
+	}
+
+	var res []*regexp.Regexp
+	for _, p := range *patterns {
+		if *ignoreCase {
+			p = "(?i)" + p
+		}
+		re, err := regexp.Compile(p)
+		if err != nil {
+			opts.Fail("invalid pattern: %v", err)
+		}
+		res = append(res, re)
+	}
+
+	// Shell: grep -c -e P1 -e P2 "$@"
+	var n int
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(files...),
+		countMatches(res, *invert, &n),
+	))
+	status.Result{Count: n, Err: err}.Exit("grep-count")
+}
+
+// countMatches reads every line of stdin, then prints the number that match
+// (or, inverted, that don't) and stores it in *n
+//
+// Shell equivalent:
+//   awk '/P1|P2/ {n++} END {print n + 0}'
+func countMatches(res []*regexp.Regexp, invert bool, n *int) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		scanner := bufio.NewScanner(stdin)
+		for scanner.Scan() {
+			if matchesAny(res, scanner.Text()) != invert {
+				*n++
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+
+		_, err := fmt.Fprintln(stdout, *n)
+		return err
+	})
+}
+
+// matchesAny reports whether any of the patterns matches line
+func matchesAny(res []*regexp.Regexp, line string) bool {
+	for _, re := range res {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
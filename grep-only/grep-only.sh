@@ -0,0 +1,33 @@
+#!/bin/bash
+
+# Print only the parts of lines that match a pattern, one per line
+# yupsh equivalent: See main.go
+
+# Parse flags (--ignore-case, --count), then the pattern and files
+# yupsh: ignoreCase := opts.Bool("ignore-case", ...); count := opts.Bool("count", ...)
+OPTIONS=()
+COUNT=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --ignore-case) OPTIONS+=(-i); shift ;;
+    --count) COUNT=1; shift ;;
+    *) echo "Usage: $0 [--ignore-case] [--count] pattern [file...]" >&2; exit 2 ;;
+  esac
+done
+if (( $# == 0 )); then
+  echo "Usage: $0 [--ignore-case] [--count] pattern [file...]" >&2
+  exit 2
+fi
+PATTERN=$1
+shift
+
+# Every match on its own line; grep exits 1 when there are none
+# yupsh: While(m.line, input.WholeLine) with re.FindAllString()
+if (( COUNT )); then
+  # yupsh: echo.Echo(fmt.Sprint(m.n))
+  N=$(cat "$@" | grep -o -E "${OPTIONS[@]}" -e "${PATTERN}" | wc -l)
+  echo $(( N ))
+  (( N > 0 ))
+else
+  cat "$@" | grep -o -E "${OPTIONS[@]}" -e "${PATTERN}"
+fi
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Print only the parts of lines that match a pattern, one per line, like grep -o
+// Shell equivalent: See grep-only.sh
+//
+//   grep-only '[0-9]+\.[0-9]+\.[0-9]+\.[0-9]+' access.log
+//   10.0.0.5
+//   192.168.1.20
+//   10.0.0.5
+//
+// A line with several matches prints each of them; a line with none prints
+// nothing. Empty matches are skipped, as grep does. With --count, only the
+// total number of matches is printed:
+//   grep -o PATTERN | wc -l
+//
+// It fills a gap: grep.Grep() has no -o mode. As with grep, the exit status
+// is 0 if anything matched, 1 if nothing did and 2 on an error (the examples'
+// convention, see internal/status).
+//
+// Usage: grep-only [--ignore-case] [--count] pattern [file...]
+func main() {
+	opts := flags.New("grep-only", "pattern [file...]")
+	ignoreCase := opts.Bool("ignore-case", false, "match case-insensitively")
+	count := opts.Bool("count", false, "print the number of matches instead of the matches")
+	opts.Parse()
+	if opts.NArg() == 0 {
+		opts.Fail("missing pattern")
+	}
+
+	pattern := opts.Arg(0)
+	if *ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		opts.Fail("invalid pattern: %v", err)
+	}
+
+	m := &matcher{re: re, quiet: *count}
+
+	// Shell: grep -o -E PATTERN "$@"
+	err = gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()[1:]...),
+		While(m.line, input.WholeLine),
+	))
+	if err != nil {
+		status.Result{Err: err}.Exit("grep-only")
+	}
+
+	// Shell: | wc -l
+	if *count {
+		gloo.MustRun(echo.Echo(fmt.Sprint(m.n)))
+	}
+	status.Result{Count: m.n}.Exit("grep-only")
+}
+
+// matcher extracts the matches from each line and counts them
+type matcher struct {
+	re    *regexp.Regexp
+	quiet bool // only count, don't print
+	n     int  // matches so far
+}
+
+// line is the While() callback: it echoes every non-empty match in the line
+func (m *matcher) line(args ...any) gloo.Command {
+	var matches []string
+	for _, match := range m.re.FindAllString(args[0].(string), -1) {
+		if match != "" {
+			matches = append(matches, match)
+		}
+	}
+	m.n += len(matches)
+
+	if m.quiet || len(matches) == 0 {
+		return nil
+	}
+	return echo.Echo(strings.Join(matches, "\n"))
+}
@@ -0,0 +1,59 @@
+#!/bin/bash
+
+# Count the lines matching a pattern in every file under a directory, and
+# rank the files by their count
+# yupsh equivalent: See main.go
+#
+# The pattern is a POSIX extended regexp here (grep -E); the Go version
+# takes RE2, which is the same for everyday patterns.
+
+# Parse flags (--all, --ignore-case), then the pattern and the directory
+# yupsh: all := opts.Bool("all", ...); ignoreCase := opts.Bool("ignore-case", ...)
+ALL=0
+OPTIONS=()
+usage() {
+  echo "Usage: $0 [--all] [--ignore-case] pattern [directory]" >&2
+  exit 2
+}
+while [[ $1 == --* ]]; do
+  case $1 in
+    --all) ALL=1; shift ;;
+    --ignore-case) OPTIONS+=(-i); shift ;;
+    *) usage ;;
+  esac
+done
+if (( $# == 0 || $# > 2 )); then
+  usage
+fi
+PATTERN=$1
+DIR=${2:-.}
+if [[ ! -e ${DIR} ]]; then
+  echo "grep-tally: stat ${DIR}: no such file or directory" >&2
+  exit 2
+elif [[ ! -d ${DIR} ]]; then
+  echo "grep-tally: ${DIR}: not a directory" >&2
+  exit 2
+fi
+
+# "count TAB path" for every file; grep -c exits 1 for a count of 0, and 2
+# when the file can't be read
+# yupsh: find.Find(...) | While(t.file, input.WholeLine)
+FAILED=0
+COUNTS=$(mktemp)
+trap 'rm -f "${COUNTS}"' EXIT
+while IFS= read -r -d '' file; do
+  n=$(grep -c -E "${OPTIONS[@]}" -e "${PATTERN}" -- "${file}")
+  (( $? > 1 )) && { FAILED=1; continue; }
+  printf '%s\t%s\n' "${n}" "${file}"
+done < <(find "${DIR}" -type f -print0) > "${COUNTS}"
+
+# Most matches first, then by name; zeros only with --all
+# yupsh: t.report(*all)
+LC_ALL=C sort -t$'\t' -k1,1nr -k2,2 "${COUNTS}" |
+  awk -F'\t' -v all="${ALL}" '$1 > 0 || all {printf "%7d  %s\n", $1, substr($0, index($0, "\t") + 1)}'
+
+# yupsh: status.Result{Count: t.total, Err: err}.Exit("grep-tally")
+if (( FAILED )); then
+  exit 2
+fi
+awk -F'\t' '{n += $1} END {exit n > 0 ? 0 : 1}' "${COUNTS}"
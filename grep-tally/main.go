@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Count the lines matching a pattern in every file under a directory, and
+// rank the files by their count
+// Shell equivalent: See grep-tally.sh
+//
+//   grep-tally TODO src
+//         4  src/server/handler.go
+//         2  src/main.go
+//         2  src/server/tls config.go
+//         1  src/util/strings.go
+//
+// This is `grep -c` run on every file and aggregated into one ranked
+// table: the most matches first, and files with the same count by name.
+// Files without a match are left out, unless --all is given. Each file's
+// count is of matching lines, as with grep -c, so a line matching twice
+// counts once.
+//
+// As with grep, the exit status is 0 when some line matched, 1 when none
+// did and 2 on an error (see internal/status); a file that can't be read
+// is reported, the others are still counted, and the status is then 2.
+//
+// Usage: grep-tally [--all] [--ignore-case] pattern [directory]
+func main() {
+	opts := flags.New("grep-tally", "pattern [directory]")
+	all := opts.Bool("all", false, "also list the files without a match, with a count of 0")
+	ignoreCase := opts.Bool("ignore-case", false, "match case-insensitively")
+	opts.Parse()
+	if opts.NArg() == 0 || opts.NArg() > 2 {
+		opts.Fail("need a pattern, and at most one directory")
+	}
+	pattern := opts.Arg(0)
+	if *ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		opts.Fail("invalid pattern: %v", err)
+	}
+	dir := opts.ArgOr(1, ".")
+
+	// find only warns about a missing directory, so check it up front
+	if err := input.Dir(dir); err != nil {
+		status.Result{Err: err}.Exit("grep-tally")
+	}
+
+	t := &tally{re: re}
+	err = gloo.Run(pipe.Pipeline(
+		// Shell: find "${DIR}" -type f
+		find.Find(find.Dir(dir), find.FileType),
+
+		// Count each file's matching lines
+		// Shell: -exec grep -c -H -E "${PATTERN}" {} +
+		While(t.file, input.WholeLine),
+
+		// Rank them
+		// Shell: sort -t$'\t' -k1,1nr -k2,2 | awk '{printf "%7d  %s\n", ...}'
+		t.report(*all),
+	))
+	if err == nil {
+		err = t.err
+	}
+	status.Result{Count: t.total, Err: err}.Exit("grep-tally")
+}
+
+// count is one file's number of matching lines
+type count struct {
+	n    int
+	path string
+}
+
+// tally holds the count of each file seen so far
+type tally struct {
+	re     *regexp.Regexp
+	counts []count
+	total  int   // matching lines in all the files
+	err    error // set once a file couldn't be read
+}
+
+// file is the While() callback: it counts the lines of the file that match
+//
+// Shell equivalent:
+//   grep -c -E "${PATTERN}" "${file}"
+func (t *tally) file(args ...any) gloo.Command {
+	path := args[0].(string)
+
+	n, err := t.countLines(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "grep-tally: %v\n", err)
+		t.err = fmt.Errorf("not every file could be read")
+		return nil
+	}
+	t.counts = append(t.counts, count{n, path})
+	t.total += n
+	return nil
+}
+
+// countLines returns the number of lines of the named file that match
+func (t *tally) countLines(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	n := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<30) // minified files can have very long lines
+	for scanner.Scan() {
+		if t.re.Match(scanner.Bytes()) {
+			n++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+	return n, nil
+}
+
+// report returns the command that prints the counts, most first, as
+// "count  path" lines, leaving out the zeros unless all is set
+func (t *tally) report(all bool) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		// The callback prints nothing; its output ends when every file has
+		// been counted
+		if _, err := io.Copy(io.Discard, stdin); err != nil {
+			return err
+		}
+
+		slices.SortFunc(t.counts, func(a, b count) int {
+			if c := cmp.Compare(b.n, a.n); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.path, b.path)
+		})
+
+		out := bufio.NewWriter(stdout)
+		for _, c := range t.counts {
+			if c.n == 0 && !all {
+				break // the zeros sort last
+			}
+			fmt.Fprintf(out, "%7d  %s\n", c.n, c.path)
+		}
+		return out.Flush()
+	})
+}
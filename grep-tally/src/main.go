@@ -0,0 +1,6 @@
+package main
+
+// TODO: flags
+func main() {
+	// TODO: run
+}
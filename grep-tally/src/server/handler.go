@@ -0,0 +1,7 @@
+package server
+
+// TODO: timeouts
+// TODO: retries
+// todo: lower case
+func Handle() {} // TODO: TODO twice
+// TODO: metrics
@@ -0,0 +1,4 @@
+package server
+
+// TODO: shutdown
+// TODO: tls
@@ -0,0 +1,76 @@
+#!/bin/bash
+set -e
+
+# Aggregate a value column grouped by a key column, like SQL's GROUP BY
+# yupsh equivalent: See main.go
+
+# Parse flags (--group N, --agg FUNC:COL..., --delim SEP, --header), then the files
+# yupsh: opts.Int("group", 1, ...); opts.Var(&aggs, "agg", ...); ...
+GROUP=1
+AGGS=
+DELIM=$'\t'
+HEADER=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --group) GROUP=$2; shift 2 ;;
+    --agg) AGGS=${AGGS:+${AGGS},}$2; shift 2 ;;
+    --delim) DELIM=$2; shift 2 ;;
+    --header) HEADER=1; shift ;;
+    *) echo "Usage: $0 [--group N] [--agg FUNC:COL]... [--delim TAB] [--header] [file...]" >&2; exit 2 ;;
+  esac
+done
+# --delim '\t' means a tab, as in the yupsh version
+# yupsh: delim := opts.Delim("\t")
+[[ ${DELIM} == '\t' ]] && DELIM=$'\t'
+AGGS=${AGGS:-count}
+
+# One awk program for any list of aggregates
+# yupsh: awk.Awk(newGroupByProgram(*group, aggs, *header), awk.FieldSeparator(*delim))
+cat "$@" | awk -F"${DELIM}" -v key="${GROUP}" -v specs="${AGGS}" -v header="${HEADER}" '
+  BEGIN {
+    n = split(specs, spec, ",")
+    for (i = 1; i <= n; i++) {
+      split(spec[i], part, ":"); fn[i] = part[1]; col[i] = part[2]
+    }
+  }
+
+  # yupsh: Action() - update the key'"'"'s accumulators
+  {
+    k = $key
+    if (!(k in rows)) order[++groups] = k
+    rows[k]++
+    for (i = 1; i <= n; i++) {
+      if (fn[i] == "count") continue
+      v = $(col[i])
+      if (v !~ /^[ \t]*[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?[ \t]*$/) {
+        if (!((NR, col[i]) in warned))
+          printf "groupby: line %d: column %d: not a number: \"%s\"\n", NR, col[i], v > "/dev/stderr"
+        warned[NR, col[i]] = 1
+        continue
+      }
+      v += 0
+      if (!((k, i) in seen) || v < min[k, i]) min[k, i] = v
+      if (!((k, i) in seen) || v > max[k, i]) max[k, i] = v
+      seen[k, i]++; sum[k, i] += v
+    }
+  }
+
+  # yupsh: End() - one line per group, in first-seen order
+  END {
+    if (header) {
+      printf "group"; for (i = 1; i <= n; i++) printf "\t%s", spec[i]; print ""
+    }
+    for (g = 1; g <= groups; g++) {
+      k = order[g]; printf "%s", k
+      for (i = 1; i <= n; i++) {
+        if (fn[i] == "count") out = rows[k]
+        else if (!((k, i) in seen)) out = ""
+        else if (fn[i] == "sum") out = sum[k, i]
+        else if (fn[i] == "avg") out = sum[k, i] / seen[k, i]
+        else if (fn[i] == "min") out = min[k, i]
+        else out = max[k, i]
+        printf "\t%s", out
+      }
+      print ""
+    }
+  }'
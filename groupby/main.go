@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	awk `github.com/yupsh/awk`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	num `github.com/yupsh/script-examples/internal/num`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Aggregate a value column grouped by a key column, like SQL's GROUP BY
+// Shell equivalent: See groupby.sh
+//
+// SQL:
+//   SELECT ext, count(*), sum(size), avg(size) FROM files GROUP BY ext
+// groupby:
+//   groupby --group 1 --agg count --agg sum:2 --agg avg:2 files.tsv
+//   .go	12	48211	4017.58
+//   .md	3	9120	3040
+//
+// This generalizes file-stats' per-extension average: any key column, any
+// value column, and several aggregates (count, sum, avg, min, max) at once.
+// Groups are printed in the order their key first appears; pipe through
+// sort to order them. Values that are not numbers are left out of
+// sum/avg/min/max (with a warning), but still counted.
+//
+// Usage: groupby [--group N] [--agg FUNC:COL]... [--delim TAB] [--header] [file...]
+func main() {
+	opts := flags.New("groupby", "[file...]")
+	group := opts.Int("group", 1, "`column` to group by")
+	var aggs aggregates
+	opts.Var(&aggs, "agg", "aggregate `FUNC:COL` (count, sum, avg, min, max); repeatable (default count)")
+	delim := opts.Delim("\t")
+	header := opts.Bool("header", false, "print a header line first")
+	opts.Parse()
+	if *group < 1 {
+		opts.Fail("--group must be at least 1")
+	}
+	if len(aggs) == 0 {
+		aggs = aggregates{{fn: "count"}}
+	}
+
+	// Shell: awk -F'\t' '{count[$1]++; sum[$1] += $2} END {for (k in count) ...}'
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		awk.Awk(newGroupByProgram(*group, aggs, *header), awk.FieldSeparator(*delim)),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "groupby: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// aggregate is one --agg spec: a function applied to a column
+type aggregate struct {
+	fn     string
+	column int
+}
+
+func (a aggregate) String() string {
+	if a.fn == "count" {
+		return a.fn
+	}
+	return fmt.Sprintf("%s:%d", a.fn, a.column)
+}
+
+// aggregates is the repeatable --agg flag
+type aggregates []aggregate
+
+func (a *aggregates) String() string {
+	specs := make([]string, len(*a))
+	for i, agg := range *a {
+		specs[i] = agg.String()
+	}
+	return strings.Join(specs, ",")
+}
+
+// Set parses one FUNC:COL spec; count needs no column
+func (a *aggregates) Set(spec string) error {
+	fn, col, hasCol := strings.Cut(spec, ":")
+	switch fn {
+	case "count":
+		*a = append(*a, aggregate{fn: fn})
+		return nil
+	case "sum", "avg", "min", "max":
+	default:
+		return fmt.Errorf("unknown aggregate %q (want count, sum, avg, min or max)", fn)
+	}
+
+	column, err := strconv.Atoi(col)
+	if !hasCol || err != nil || column < 1 {
+		return fmt.Errorf("%s needs a column, as in %s:3", fn, fn)
+	}
+	*a = append(*a, aggregate{fn: fn, column: column})
+	return nil
+}
+
+// accumulator holds the running state of one aggregate for one group
+type accumulator struct {
+	n             int // numeric values seen
+	sum, min, max float64
+}
+
+// add folds one value into the accumulator
+func (acc *accumulator) add(v float64) {
+	if acc.n == 0 || v < acc.min {
+		acc.min = v
+	}
+	if acc.n == 0 || v > acc.max {
+		acc.max = v
+	}
+	acc.sum += v
+	acc.n++
+}
+
+// group is one key's row count and accumulators, one per aggregate
+type group struct {
+	rows int
+	accs []accumulator
+}
+
+// groupByProgram is a custom awk program keeping per-key accumulators
+//
+// Shell awk pattern:
+//   {count[$1]++; sum[$1] += $2}          - Action: update the key's accumulators
+//   END {for (k in count) print k, ...}   - End: one line per key
+//
+// Unlike awk's `for (k in ...)`, End() prints the groups in first-seen order,
+// so the output is deterministic.
+type groupByProgram struct {
+	awk.SimpleProgram
+	key    int
+	aggs   aggregates
+	header bool
+	groups map[string]*group
+	order  []string // keys in first-seen order
+}
+
+func newGroupByProgram(key int, aggs aggregates, header bool) *groupByProgram {
+	return &groupByProgram{
+		key:    key,
+		aggs:   aggs,
+		header: header,
+		groups: make(map[string]*group),
+	}
+}
+
+// Action updates the accumulators of the line's group
+// Shell: {count[$1]++; sum[$1] += $2}
+func (p *groupByProgram) Action(ctx *awk.Context) (string, bool) {
+	key := ctx.Field(p.key)
+	g, ok := p.groups[key]
+	if !ok {
+		g = &group{accs: make([]accumulator, len(p.aggs))}
+		p.groups[key] = g
+		p.order = append(p.order, key)
+	}
+	g.rows++
+
+	warned := make(map[int]bool) // columns already reported for this line
+	for i, agg := range p.aggs {
+		if agg.fn == "count" {
+			continue
+		}
+		field := ctx.Field(agg.column)
+		v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			if !warned[agg.column] {
+				fmt.Fprintf(os.Stderr, "groupby: line %d: column %d: not a number: %q\n", ctx.NR, agg.column, field)
+				warned[agg.column] = true
+			}
+			continue
+		}
+		g.accs[i].add(v)
+	}
+	return "", false
+}
+
+// End prints one line per group: the key, then each aggregate
+// Shell: END {for (k in count) print k "\t" count[k] "\t" sum[k]}
+func (p *groupByProgram) End(ctx *awk.Context) (string, error) {
+	var lines []string
+	if p.header {
+		lines = append(lines, "group\t"+strings.ReplaceAll(p.aggs.String(), ",", "\t"))
+	}
+
+	for _, key := range p.order {
+		g := p.groups[key]
+		row := []string{key}
+		for i, agg := range p.aggs {
+			row = append(row, result(agg.fn, g.rows, g.accs[i]))
+		}
+		lines = append(lines, strings.Join(row, "\t"))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// result computes one aggregate; it is blank when a group had no numeric
+// values to aggregate
+func result(fn string, rows int, acc accumulator) string {
+	if fn == "count" {
+		return strconv.Itoa(rows)
+	}
+	if acc.n == 0 {
+		return ""
+	}
+
+	switch fn {
+	case "sum":
+		return num.Format(acc.sum)
+	case "avg":
+		return num.Format(acc.sum / float64(acc.n))
+	case "min":
+		return num.Format(acc.min)
+	default:
+		return num.Format(acc.max)
+	}
+}
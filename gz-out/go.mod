@@ -0,0 +1,17 @@
+module github.com/yupsh/script-examples/gz-out
+
+go 1.25
+
+require (
+	github.com/gloo-foo/framework v0.0.3
+	github.com/gloo-foo/pipe v0.0.3
+	github.com/yupsh/script-examples/internal v0.0.0
+)
+
+require (
+	github.com/yupsh/while v0.0.4 // indirect
+	golang.org/x/sys v0.41.0 // indirect
+	golang.org/x/term v0.40.0 // indirect
+)
+
+replace github.com/yupsh/script-examples/internal => ../internal
@@ -0,0 +1,45 @@
+#!/bin/bash
+set -eo pipefail
+
+# Compress a pipeline's output with gzip as its last step
+# yupsh equivalent: See main.go
+
+# Parse flags (--out FILE, --level N, --verbose), then the files
+# yupsh: out := opts.String("out", ...); level := opts.Int("level", ...); ...
+OUT=-
+LEVEL=6
+VERBOSE=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --out) OUT=$2; shift 2 ;;
+    --level) LEVEL=$2; shift 2 ;;
+    --verbose) VERBOSE=1; shift ;;
+    *) echo "Usage: $0 [--out FILE.gz] [--level N] [--verbose] [file...]" >&2; exit 2 ;;
+  esac
+done
+if [[ ! ${LEVEL} =~ ^[1-9]$ ]]; then
+  echo "gz-out: --level must be from 1 to 9" >&2
+  exit 2
+fi
+
+# With no --out, compress to stdout, which gzip refuses when it's a terminal
+# yupsh: compress(stdout, stdin, level, n)
+if [[ ${OUT} == - ]]; then
+  cat "$@" | gzip -c "-${LEVEL}"
+  exit
+fi
+
+# Compress to a temporary file beside --out, then rename it into place, so
+# a failed run leaves no truncated file
+# yupsh: gzipTo(*out, *level, &n), os.CreateTemp then os.Rename
+TMP=$(mktemp "$(dirname "${OUT}")/.$(basename "${OUT}").XXXXXX")
+trap 'rm -f "${TMP}"' EXIT
+cat "$@" | gzip -c "-${LEVEL}" > "${TMP}"
+chmod 644 "${TMP}"
+mv "${TMP}" "${OUT}"
+
+# gzip -l reports the sizes from the trailer, modulo 4GiB
+# yupsh: size.Format(n.in), size.Format(n.out), ratio(n)
+if (( VERBOSE )); then
+  gzip -l "${OUT}" >&2
+fi
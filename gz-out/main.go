@@ -0,0 +1,160 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	size `github.com/yupsh/script-examples/internal/size`
+	status `github.com/yupsh/script-examples/internal/status`
+	term `github.com/yupsh/script-examples/internal/term`
+)
+
+// Compress a pipeline's output with gzip as its last step, like tee for a
+// .gz file
+// Shell equivalent: See gz-out.sh
+//
+//   seq 1 100000 | gz-out --out numbers.gz
+//   gz-out --out logs.gz --verbose /var/log/app/*.log
+//   gz-out: 2.1M in, 240.3K out (11% of the input)
+//
+// The last command of the pipeline is a binary sink: the commands before it
+// pass lines along as usual, and it writes them compressed to --out. In Go,
+// it ends any pipeline:
+//   gloo.Run(pipe.Pipeline(seq.Seq("1", "100000"), gzipTo("numbers.gz", 6, nil)))
+//
+// The file is written under a temporary name in the same directory and
+// renamed into place at the end, so a failed run never leaves a truncated
+// .gz where a good one was. With no --out, or --out -, the compressed
+// stream goes to stdout, like gzip -c, unless stdout is a terminal.
+//
+// Usage: gz-out [--out FILE.gz] [--level N] [--verbose] [file...]
+func main() {
+	opts := flags.New("gz-out", "[file...]")
+	out := opts.String("out", input.Stdin, "write the compressed output to `FILE`, or - for stdout")
+	level := opts.Int("level", gzip.DefaultCompression, "compression `level`, 1 (fastest) to 9 (smallest)")
+	verbose := opts.Bool("verbose", false, "report the sizes before and after compression")
+	opts.Parse()
+	if *level != gzip.DefaultCompression && (*level < gzip.BestSpeed || *level > gzip.BestCompression) {
+		opts.Fail("--level must be from 1 to 9")
+	}
+	if *out == input.Stdin && term.IsTerminal(os.Stdout) {
+		// Shell: gzip: compressed data not written to a terminal
+		opts.Fail("compressed data not written to a terminal; use --out or a redirect")
+	}
+
+	var n sizes
+	err := gloo.Run(pipe.Pipeline(
+		// Report a missing file instead of compressing nothing
+		// Shell: set -o pipefail
+		pipe.PipeFail,
+
+		// Read the named files, or stdin when there are none
+		// Shell: cat "$@"
+		input.Source(opts.Args()...),
+
+		// Compress everything to the --out file
+		// Shell: gzip -c > "${OUT}"
+		gzipTo(*out, *level, &n),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gz-out: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+
+	if *verbose {
+		// Shell: gzip -l "${OUT}"
+		fmt.Fprintf(os.Stderr, "gz-out: %s in, %s out (%s of the input)\n", size.Format(n.in), size.Format(n.out), ratio(n))
+	}
+}
+
+// sizes counts the bytes going into the compressor and coming out of it
+type sizes struct {
+	in, out int64
+}
+
+// gzipTo returns the command that compresses stdin to the file at path, or
+// to stdout for input.Stdin ("-"), and records the sizes in n if it isn't
+// nil
+//
+// Shell equivalent:
+//   gzip -c > "${path}.tmp" && mv "${path}.tmp" "${path}"
+func gzipTo(path string, level int, n *sizes) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		if n == nil {
+			n = &sizes{}
+		}
+		if path == input.Stdin {
+			return compress(stdout, stdin, level, n)
+		}
+
+		// The same directory keeps the final rename on one filesystem
+		tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name()) // no-op once renamed
+
+		if err := compress(tmp, stdin, level, n); err != nil {
+			tmp.Close()
+			return err
+		}
+		// CreateTemp makes the file private; a .gz is as readable as any
+		// file the shell's redirect would create
+		if err := tmp.Chmod(0o644); err != nil {
+			tmp.Close()
+			return err
+		}
+		if err := tmp.Close(); err != nil {
+			return err
+		}
+		return os.Rename(tmp.Name(), path)
+	})
+}
+
+// compress writes r to w as one gzip stream, counting the bytes both sides
+// of the compressor
+func compress(w io.Writer, r io.Reader, level int, n *sizes) error {
+	counted := &countingWriter{w: w, n: &n.out}
+	zw, err := gzip.NewWriterLevel(counted, level)
+	if err != nil {
+		return err
+	}
+
+	copied, err := io.Copy(zw, r)
+	n.in += copied
+	if err != nil {
+		return err
+	}
+	// Close writes the gzip trailer (the CRC and the length); without it
+	// the file is truncated as far as gunzip is concerned
+	return zw.Close()
+}
+
+// countingWriter is an io.Writer adding the bytes written through it to *n
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	written, err := c.w.Write(p)
+	*c.n += int64(written)
+	return written, err
+}
+
+// ratio returns the size of the output as a percentage of the input's; it
+// can pass 100% for a tiny or already compressed input
+func ratio(n sizes) string {
+	if n.in == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d%%", n.out*100/n.in)
+}
@@ -0,0 +1,23 @@
+#!/bin/bash
+set -e
+
+# Print a checksum for every file in a directory tree
+# yupsh equivalent: See main.go
+
+# Parse --algo, then the directory argument
+# yupsh: algo := opts.Choice("algo", "hash algorithm", digest.Algorithms...)
+ALGO=sha256
+if [[ $1 == --algo ]]; then
+  ALGO=$2
+  shift 2
+fi
+DIR=${1:-.}
+
+case ${ALGO} in
+  sha256|sha1|md5) ;;
+  *) echo "Usage: $0 [--algo sha256|sha1|md5] [directory]" >&2; exit 2 ;;
+esac
+
+# Hash every file, in find's order
+# yupsh: find.Find(...), While(hashFile(*algo), input.WholeLine)
+find "${DIR}" -type f -exec "${ALGO}sum" {} +
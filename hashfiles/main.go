@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	echo `github.com/yupsh/echo`
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	digest `github.com/yupsh/script-examples/internal/digest`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Print a checksum for every file in a directory tree
+// Shell equivalent: See hashfiles.sh
+//
+// Output is in sha256sum(1) format ("digest  path"), so it can be saved as a
+// manifest and checked later with the verify example or `sha256sum -c`.
+//
+// Pattern: find -> While(hash) — the per-file computation happens in Go inside
+// the callback, instead of spawning sha256sum once per file.
+//
+// Usage: hashfiles [--algo sha256|sha1|md5] [directory]
+func main() {
+	opts := flags.New("hashfiles", "[directory]")
+	algo := opts.Choice("algo", "hash algorithm", digest.Algorithms...)
+	opts.Parse()
+	dir := opts.ArgOr(0, ".")
+
+	err := gloo.Run(pipe.Pipeline(
+		// Find all regular files
+		// Shell: find "${DIR}" -type f
+		find.Find(find.Dir(dir), find.FileType),
+
+		// Hash each one: "digest  path"
+		// Shell: -exec sha256sum {} +
+		While(hashFile(*algo), input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "hashfiles: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// hashFile returns a While() callback that prints the digest of each file
+//
+// Shell equivalent:
+//   sha256sum "$file"
+//
+// digest.File() streams the file through the hash, so large files are never
+// loaded into memory. Unreadable files are reported on stderr and skipped,
+// as sha256sum does.
+func hashFile(algo string) Body {
+	return func(args ...any) gloo.Command {
+		path := args[0].(string)
+
+		sum, err := digest.File(path, algo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "hashfiles: %v\n", err)
+			return nil
+		}
+		return echo.Echo(digest.Line(sum, path))
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	find `github.com/yupsh/find`
+	pipe `github.com/gloo-foo/pipe`
+	input `github.com/yupsh/script-examples/internal/input`
+	. `github.com/yupsh/while`
+)
+
+// fixture is the tree hashed: known contents, one file empty, and one name
+// with a space in a subdirectory
+var fixture = map[string]string{
+	"hello.txt":          "hello\n",
+	"abc":                "abc",
+	"empty":              "",
+	"sub/with space.txt": "hello\n",
+}
+
+// digests are the fixture's contents' digests, as sha256sum, sha1sum and
+// md5sum print them
+var digests = map[string]map[string]string{
+	"sha256": {
+		"hello\n": "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+		"abc":     "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		"":        "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	},
+	"sha1": {
+		"hello\n": "f572d396fae9206628714fb2ce00f72e94f2258f",
+		"abc":     "a9993e364706816aba3e25717850c26c9cd0d89d",
+		"":        "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+	},
+	"md5": {
+		"hello\n": "b1946ac92492d2347c6235b4d2611184",
+		"abc":     "900150983cd24fb0d6963f7d28e17f72",
+		"":        "d41d8cd98f00b204e9800998ecf8427e",
+	},
+}
+
+// TestKnownDigests hashes the fixture with each --algo, as main() does, and
+// checks every line against the known digest of the file's contents
+func TestKnownDigests(t *testing.T) {
+	dir := t.TempDir()
+	for name, text := range fixture {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for algo, sums := range digests {
+		t.Run(algo, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := pipe.Pipeline(
+				find.Find(find.Dir(dir), find.FileType),
+				While(hashFile(algo), input.WholeLine),
+			).Executor()(context.Background(), strings.NewReader(""), &stdout, &stderr)
+			if err != nil {
+				t.Fatalf("hashfiles: %v\n%s", err, stderr.String())
+			}
+
+			var want []string
+			for name, text := range fixture {
+				want = append(want, sums[text]+"  "+filepath.Join(dir, name))
+			}
+			got := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+			slices.Sort(got)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("manifest:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+			}
+		})
+	}
+}
+
+// TestMissingFile checks that a file that can't be read is skipped, with
+// nothing printed for it
+func TestMissingFile(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := While(hashFile("sha256"), input.WholeLine).Executor()(
+		context.Background(), strings.NewReader(filepath.Join(t.TempDir(), "missing")+"\n"), &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("printed %q for a missing file", stdout.String())
+	}
+}
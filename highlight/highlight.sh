@@ -0,0 +1,28 @@
+#!/bin/bash
+set -e
+
+# Print every line, with the matches of a pattern highlighted in color
+# yupsh equivalent: See main.go
+
+# Parse flags (--color WHEN, --ignore-case), then the pattern and files
+# yupsh: color := opts.Choice("color", ..., "auto", "always", "never")
+COLOR=auto
+IGNORE_CASE=
+while [[ $1 == --* ]]; do
+  case $1 in
+    --color) COLOR=$2; shift 2 ;;
+    --ignore-case) IGNORE_CASE=-i; shift ;;
+    *) echo "Usage: $0 [--color auto|always|never] [--ignore-case] pattern [file...]" >&2; exit 2 ;;
+  esac
+done
+if (( $# < 1 )); then
+  echo "Usage: $0 [--color auto|always|never] [--ignore-case] pattern [file...]" >&2
+  exit 2
+fi
+PATTERN=$1
+shift
+
+# Matching the empty string at the end of every line keeps all lines;
+# grep --color=auto checks whether stdout is a terminal itself
+# yupsh: While(highlight(re, colored), input.WholeLine)
+cat "$@" | grep ${IGNORE_CASE} --color="${COLOR}" -E "${PATTERN}|\$"
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	term `github.com/yupsh/script-examples/internal/term`
+	. `github.com/yupsh/while`
+)
+
+// Print every line, with the matches of a pattern highlighted in color
+// Shell equivalent: See highlight.sh
+//
+// Like grep --color, but nothing is filtered out: lines without a match pass
+// through unchanged, so the matches stand out in context.
+//   tail -f app.log | highlight 'ERROR|WARN'
+//
+// --color decides when to emit ANSI escape codes:
+//   auto     only when stdout is a terminal (the default), so
+//            `highlight ... > file` writes plain text
+//   always   even into a pipe, e.g. for `less -R`
+//   never    plain text
+//
+// Usage: highlight [--color auto|always|never] [--ignore-case] pattern [file...]
+func main() {
+	opts := flags.New("highlight", "pattern [file...]")
+	color := opts.Choice("color", "when to color matches", "auto", "always", "never")
+	ignoreCase := opts.Bool("ignore-case", false, "match case-insensitively")
+	opts.Parse()
+	if opts.NArg() < 1 {
+		opts.Fail("missing pattern")
+	}
+
+	pattern := opts.Arg(0)
+	if *ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		opts.Fail("invalid pattern: %v", err)
+	}
+
+	// Shell: [ -t 1 ] && COLOR=always || COLOR=never
+	colored := *color == "always" || (*color == "auto" && term.IsTerminal(os.Stdout))
+
+	// Shell: grep --color=always -E "pattern|$" "$@"
+	err = gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()[1:]...),
+		While(highlight(re, colored), input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "highlight: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// The escape codes grep uses for a match: bold red, then reset. "\x1b[K"
+// clears to the end of the line, which keeps the background right when the
+// terminal scrolls.
+const (
+	matchStart = "\x1b[01;31m\x1b[K"
+	matchEnd   = "\x1b[m\x1b[K"
+)
+
+// highlight returns the While() callback wrapping each match in color codes
+func highlight(re *regexp.Regexp, colored bool) Body {
+	return func(args ...any) gloo.Command {
+		line := args[0].(string)
+		if !colored {
+			return echo.Echo(line)
+		}
+
+		// Empty matches (e.g. from `a*`) have nothing to color
+		return echo.Echo(re.ReplaceAllStringFunc(line, func(match string) string {
+			if match == "" {
+				return match
+			}
+			return matchStart + match + matchEnd
+		}))
+	}
+}
@@ -0,0 +1,60 @@
+#!/bin/bash
+set -e
+
+# Print a text histogram of numbers grouped into fixed-width buckets
+# yupsh equivalent: See main.go
+
+# Parse flags (--bucket WIDTH, --width N), then the files
+# yupsh: bucket := opts.Float64("bucket", 10, ...); width := opts.Int("width", 50, ...)
+BUCKET=10
+WIDTH=50
+while [[ $1 == --* ]]; do
+  case $1 in
+    --bucket) BUCKET=$2; shift 2 ;;
+    --width) WIDTH=$2; shift 2 ;;
+    *) echo "Usage: $0 [--bucket WIDTH] [--width N] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Count values per bucket, then print every bucket in END
+# yupsh: awk.Awk(newHistogramProgram(*bucket, *width))
+cat "$@" | awk -v w="${BUCKET}" -v width="${WIDTH}" '
+  function floor(x) { return x == int(x) || x > 0 ? int(x) : int(x) - 1 }
+
+  # yupsh: Action() — count the value in its bucket
+  NF == 0 { next }
+  $1 !~ /^[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?$/ {
+    printf "histogram: line %d: not a number: \"%s\"\n", NR, $1 > "/dev/stderr"
+    next
+  }
+  {
+    k = floor($1 / w)
+    if (!seen++ || k < lo) lo = k
+    if (seen == 1 || k > hi) hi = k
+    count[k]++
+  }
+
+  # yupsh: End() — every bucket from lo to hi, aligned by table.Align()
+  END {
+    if (!seen) exit
+    if (hi - lo >= 10000) {
+      print "histogram: values span more than 10000 buckets; use a larger --bucket" > "/dev/stderr"
+      exit 1
+    }
+    for (k = lo; k <= hi; k++) if (count[k] > most) most = count[k]
+    for (k = lo; k <= hi; k++) {
+      label[k] = "[" k * w ", " (k + 1) * w ")"
+      n = count[k] + 0
+      len = n
+      if (most > width) { len = int(n * width / most + 0.5); if (len < 1) len = 1 }
+      b = ""; for (i = 0; i < (n ? len : 0); i++) b = b "#"
+      bars[k] = b
+      if (length(label[k]) > lw) lw = length(label[k])
+      if (length(n "") > cw) cw = length(n "")
+    }
+    for (k = lo; k <= hi; k++) {
+      line = sprintf("%-" lw "s  %-" cw "s  %s", label[k], count[k] + 0, bars[k])
+      sub(/ +$/, "", line)
+      print line
+    }
+  }'
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	awk `github.com/yupsh/awk`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	num `github.com/yupsh/script-examples/internal/num`
+	status `github.com/yupsh/script-examples/internal/status`
+	table `github.com/yupsh/script-examples/internal/table`
+)
+
+// maxBuckets bounds the number of rows printed, so a stray outlier can't
+// produce millions of empty buckets
+const maxBuckets = 10000
+
+// Print a text histogram of numbers grouped into fixed-width buckets
+// Shell equivalent: See histogram.sh
+//
+//   histogram --bucket 100 latency.txt
+//   [100, 200)  6  ######
+//   [200, 300)  3  ###
+//   [300, 400)  0
+//   [400, 500)  1  #
+//
+// Bucket k holds the values in [k*width, (k+1)*width). Every bucket from the
+// lowest to the highest is printed, empty ones included, so gaps in the
+// distribution stay visible. Bars are one '#' per value, scaled down so the
+// longest is --width characters when counts are larger than that.
+//
+// Usage: histogram [--bucket WIDTH] [--width N] [file...]
+func main() {
+	opts := flags.New("histogram", "[file...]")
+	bucket := opts.Float64("bucket", 10, "`width` of each bucket")
+	width := opts.Int("width", 50, "length of the longest bar, in `characters`")
+	opts.Parse()
+	if *bucket <= 0 {
+		opts.Fail("--bucket must be positive")
+	}
+	if *width < 1 {
+		opts.Fail("--width must be at least 1")
+	}
+
+	// Shell: awk '{count[int($1 / 10)]++} END {for (k = min; k <= max; k++) ...}'
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		awk.Awk(newHistogramProgram(*bucket, *width)),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "histogram: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// histogramProgram is a custom awk program counting values per bucket
+//
+// Shell awk pattern:
+//   {count[int($1 / w)]++}                    - Action: count the value's bucket
+//   END {for (k = lo; k <= hi; k++) print}    - End: one row per bucket, in order
+type histogramProgram struct {
+	awk.SimpleProgram
+	bucket float64
+	width  int
+	counts map[int64]int // bucket index -> values in it
+	lo, hi int64         // lowest and highest bucket index seen
+}
+
+func newHistogramProgram(bucket float64, width int) *histogramProgram {
+	return &histogramProgram{bucket: bucket, width: width, counts: make(map[int64]int)}
+}
+
+// Action counts the line's value in its bucket
+// Shell: {count[int($1 / w)]++}
+func (p *histogramProgram) Action(ctx *awk.Context) (string, bool) {
+	field := ctx.Field(1)
+	if field == "" {
+		return "", false
+	}
+	v, err := strconv.ParseFloat(field, 64)
+	if err != nil || math.IsInf(v, 0) || math.IsNaN(v) {
+		fmt.Fprintf(os.Stderr, "histogram: line %d: not a number: %q\n", ctx.NR, field)
+		return "", false
+	}
+
+	// Floor, not truncation, so -5 lands in [-10, 0) rather than [0, 10)
+	k := int64(math.Floor(v / p.bucket))
+	if len(p.counts) == 0 || k < p.lo {
+		p.lo = k
+	}
+	if len(p.counts) == 0 || k > p.hi {
+		p.hi = k
+	}
+	p.counts[k]++
+	return "", false
+}
+
+// End prints every bucket from the lowest to the highest with its bar
+// Shell: END {for (k = lo; k <= hi; k++) printf "[%d, %d)  %d  %s\n", ...}
+func (p *histogramProgram) End(ctx *awk.Context) (string, error) {
+	if len(p.counts) == 0 {
+		return "", nil
+	}
+	if p.hi-p.lo >= maxBuckets {
+		return "", fmt.Errorf("values span more than %d buckets; use a larger --bucket", maxBuckets)
+	}
+
+	most := 0
+	for _, n := range p.counts {
+		most = max(most, n)
+	}
+
+	var rows [][]string
+	for k := p.lo; k <= p.hi; k++ {
+		n := p.counts[k]
+		label := fmt.Sprintf("[%s, %s)", num.Format(float64(k)*p.bucket), num.Format(float64(k+1)*p.bucket))
+		rows = append(rows, []string{label, strconv.Itoa(n), bar(n, most, p.width)})
+	}
+	return strings.Join(table.Align(rows), "\n"), nil
+}
+
+// bar draws n as '#'s, scaled so that most is at most width long. A
+// non-empty bucket always gets at least one '#'.
+func bar(n, most, width int) string {
+	if n == 0 {
+		return ""
+	}
+	length := n
+	if most > width {
+		length = max(1, int(math.Round(float64(n)*float64(width)/float64(most))))
+	}
+	return strings.Repeat("#", length)
+}
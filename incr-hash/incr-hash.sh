@@ -0,0 +1,85 @@
+#!/bin/bash
+set -eo pipefail
+
+# Update a hashed index manifest of a directory tree, hashing only the files
+# whose size or modification time changed since it was written
+# yupsh equivalent: See main.go
+#
+# As in index.sh, paths are written as they are, and the manifest is split
+# at every comma, so this version only handles paths without commas,
+# quotes, tabs or newlines; the Go version reads and writes real CSV.
+
+usage() {
+  echo "Usage: $0 [--jobs N] manifest.csv [directory]" >&2
+  exit 2
+}
+
+# Parse flags (--jobs N), then the manifest and the directory
+# yupsh: jobs := opts.Int("jobs", runtime.NumCPU(), ...)
+JOBS=$(nproc)
+while [[ $1 == --* ]]; do
+  case $1 in
+    --jobs) JOBS=$2; shift 2 ;;
+    *) usage ;;
+  esac
+done
+[[ ${JOBS} =~ ^[1-9][0-9]*$ ]] || usage
+(( $# == 1 || $# == 2 )) || usage
+MANIFEST=$1
+DIR=${2:-.}
+
+# yupsh: os.Stat(dir), then load(opts.Arg(0))
+if [[ ! -d ${DIR} ]]; then
+  echo "incr-hash: ${DIR}: not a directory" >&2
+  exit 2
+fi
+if ! head -n 1 "${MANIFEST}" | tr , '\n' | grep -qx sha256; then
+  echo "incr-hash: ${MANIFEST}: no \"sha256\" column: write it with index --hash" >&2
+  exit 2
+fi
+
+ROWS=$(mktemp)
+SUMS=$(mktemp)
+trap 'rm -f "${ROWS}" "${SUMS}"' EXIT
+
+# The tree now, as index.sh lists it, with the manifest's sha256 after each
+# file whose size and time match, or nothing after the tab; then 1 if the
+# file is in the manifest at all, else 0
+# yupsh: find.Find(...) | sort.Sort() | u.rows(), with u.file() per path
+TZ=UTC find "${DIR}" -type f -printf '%P\t%s\t%TY-%Tm-%TdT%TH:%TM:%TS\t%#m\n' \
+  | awk -F'\t' -v OFS='\t' '{ sub(/\.[0-9]+$/, "", $3); $3 = $3 "Z"; print }' \
+  | LC_ALL=C sort -t$'\t' -k1,1 \
+  | awk -F'\t' -v OFS='\t' -v manifest="${MANIFEST}" '
+      # yupsh: load(path) - the columns by name, then a row per file
+      BEGIN {
+        getline line < manifest
+        n = split(line, name, ",")
+        for (i = 1; i <= n; i++) column[name[i]] = i
+        while ((getline line < manifest) > 0) {
+          split(line, f, ",")
+          path = f[column["path"]]
+          old[path] = f[column["size"]] SUBSEP f[column["mtime"]]
+          sum[path] = f[column["sha256"]]
+        }
+      }
+      # yupsh: old.size == info.Size() && old.mtime == mtime.Unix() -> reuse
+      { print $0, ($1 in old && old[$1] == $2 SUBSEP $3) ? sum[$1] : "", ($1 in old) }
+    ' > "${ROWS}"
+
+# Hash the files with no sum, in parallel batches
+# yupsh: u.jobs workers calling digest.File(path, "sha256")
+awk -F'\t' '$5 == "" {print $1}' "${ROWS}" \
+  | (cd "${DIR}" && xargs -d '\n' -r -n 64 -P "${JOBS}" sha256sum --) \
+  | awk '{ sum = $1; sub(/^[0-9a-f]+  /, ""); print $0 "\t" sum }' > "${SUMS}"
+
+# The header, then every row with its sum, old or new, and the counts
+# yupsh: csv.NewWriter(stdout), out.Write(header), out.Write(r.row)
+echo "path,size,mtime,mode,sha256"
+awk -F'\t' -v OFS=, -v files="$(( $(wc -l < "${MANIFEST}") - 1 ))" '
+  FILENAME == ARGV[1] { sum[$1] = $2; next }
+  { present += $6 }
+  $5 == "" { $5 = sum[$1]; hashed++ }
+  $5 != "" && !($1 in sum) { reused++ }
+  { NF = 5; print }
+  END { printf "incr-hash: %d hashed, %d reused, %d removed\n", hashed, reused, files - present > "/dev/stderr" }
+' "${SUMS}" "${ROWS}"
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	digest `github.com/yupsh/script-examples/internal/digest`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	ordered `github.com/yupsh/script-examples/internal/ordered`
+	status `github.com/yupsh/script-examples/internal/status`
+	sort `github.com/yupsh/sort`
+)
+
+// Update a hashed index manifest of a directory tree, hashing only the files
+// whose size or modification time changed since it was written
+// Shell equivalent: See incr-hash.sh
+//
+//   index --hash site > site.csv       # once: hash everything
+//   incr-hash site.csv site > site.new && mv site.new site.csv
+//   incr-hash: 2 hashed, 3 reused, 1 removed
+//
+// Hashing a file reads all of it, and on a large tree that is most of the
+// time index --hash takes. But a file whose size and modification time are
+// what the manifest says is, for all practical purposes, the file that was
+// hashed, so its sha256 is copied from the manifest rather than computed
+// again. Only new files, and files whose size or time changed, are read.
+// This is the check make and rsync use to skip work.
+//
+// Pattern: find -> sort -> u.rows(), as in index. The output is exactly
+// what index --hash would write for the tree now, so it can replace the old
+// manifest, and dirdiff or diff can compare the two. A summary of how many
+// files were hashed and reused, and how many rows were dropped for files
+// that are gone, is written to stderr.
+//
+// Times are to the second, so a file rewritten within the second it was
+// indexed in, at the same size, keeps its old hash; index --hash, or
+// verify, reads everything when that matters. A change of mode alone
+// doesn't re-hash a file: its content is the same.
+//
+// A file that can't be read is reported and left out, and incr-hash exits
+// 1 once the others are written; 2 on any other trouble, such as a
+// manifest without a sha256 column.
+//
+// Usage: incr-hash [--jobs N] manifest.csv [directory]
+func main() {
+	opts := flags.New("incr-hash", "manifest.csv [directory]")
+	jobs := opts.Int("jobs", runtime.NumCPU(), "hash `N` files at a time")
+	opts.Parse()
+	if *jobs < 1 {
+		opts.Fail("--jobs must be at least 1")
+	}
+	if opts.NArg() < 1 || opts.NArg() > 2 {
+		opts.Fail("want a manifest written by index --hash, and at most one directory")
+	}
+	dir := opts.ArgOr(1, ".")
+
+	// find only warns about a missing directory; make it an error
+	// Shell: [[ -d ${DIR} ]] || exit 2
+	if err := input.Dir(dir); err != nil {
+		status.Result{Err: err}.Exit("incr-hash")
+	}
+
+	cache, err := load(opts.Arg(0))
+	if err != nil {
+		status.Result{Err: err}.Exit("incr-hash")
+	}
+
+	u := &updater{root: dir, cache: cache, jobs: *jobs}
+	err = gloo.Run(pipe.Pipeline(
+		// Find all regular files
+		// Shell: find "${DIR}" -type f
+		find.Find(find.Dir(dir), find.FileType),
+
+		// In the order index writes them
+		// Shell: LC_ALL=C sort
+		sort.Sort(),
+
+		// Stat each one, and hash the ones that changed, into a CSV row
+		// Shell: awk -F, 'FNR == NR {sum[$1 FS $2 FS $3] = $5; next} ...'
+		u.rows(),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "incr-hash: %s\n", status.Message(err))
+		os.Exit(2)
+	}
+
+	fmt.Fprintf(os.Stderr, "incr-hash: %d hashed, %d reused, %d removed\n",
+		u.hashed, u.reused, len(cache)-u.present)
+	if u.failed > 0 {
+		fmt.Fprintf(os.Stderr, "incr-hash: %d file(s) could not be indexed\n", u.failed)
+		os.Exit(1)
+	}
+}
+
+// cached is what the old manifest says about a file
+type cached struct {
+	size  int64
+	mtime int64 // Unix seconds
+	sum   string
+}
+
+// load reads a manifest written by index --hash into a map by path
+//
+// Shell equivalent:
+//   awk -F, 'NR > 1 {sum[$1] = $5}' manifest.csv
+func load(path string) (map[string]cached, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cr := csv.NewReader(bufio.NewReader(f))
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: reading the header: %w", path, err)
+	}
+	column := map[string]int{}
+	for i, name := range header {
+		column[name] = i
+	}
+	for _, name := range []string{"path", "size", "mtime", digest.Algorithms[0]} {
+		if _, ok := column[name]; !ok {
+			return nil, fmt.Errorf("%s: no %q column: write it with index --hash", path, name)
+		}
+	}
+
+	cache := map[string]cached{}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return cache, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		line, _ := cr.FieldPos(0)
+
+		size, err := strconv.ParseInt(record[column["size"]], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: line %d: size %q is not a number", path, line, record[column["size"]])
+		}
+		mtime, err := time.Parse(time.RFC3339, record[column["mtime"]])
+		if err != nil {
+			return nil, fmt.Errorf("%s: line %d: mtime %q is not an RFC 3339 time", path, line, record[column["mtime"]])
+		}
+		cache[record[column["path"]]] = cached{size, mtime.Unix(), record[column[digest.Algorithms[0]]]}
+	}
+}
+
+// updater turns file paths into manifest rows with a pool of workers,
+// reusing the old manifest's hashes, and writes them in the order the paths
+// arrived
+type updater struct {
+	root  string
+	cache map[string]cached // the old manifest, by path
+	jobs  int               // number of workers (--jobs)
+
+	hashed  int // files read and hashed
+	reused  int // files whose hash came from the manifest
+	present int // files in the manifest that are still there
+	failed  int // files that could not be indexed
+}
+
+// header is the manifest's columns, as index --hash writes them
+var header = []string{"path", "size", "mtime", "mode", digest.Algorithms[0]}
+
+// result is the manifest row of one file, or the error that stopped it
+type result struct {
+	row    []string
+	reused bool // the hash came from the manifest
+	known  bool // the file is in the manifest
+	err    error
+}
+
+// rows returns the command that reads one path per line from stdin and
+// writes the manifest: the header, then a row per file
+//
+// Shell equivalent:
+//   echo path,size,mtime,mode,sha256; while read -r path; do stat ...; done
+//
+// As in index, the files go through ordered.Map, --jobs at a time, and the
+// rows come back in the order of the paths; the workers stat and, when they
+// must, hash a file each. A reused hash costs only a stat, so the rows of an
+// unchanged tree come out as fast as find lists the files.
+func (u *updater) rows() gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		// Writer: the header, then each file's row, in order, counting
+		// the hashes computed and reused
+		out := csv.NewWriter(stdout)
+		if err := out.Write(header); err != nil {
+			return err
+		}
+		// Shell: xargs -P "${JOBS}"
+		err := ordered.Map(ctx, stdin, u.jobs, func(ctx context.Context, path string) result {
+			return u.file(path)
+		}, func(r result) error {
+			if r.err != nil {
+				// Shell: sha256sum: file: Permission denied
+				fmt.Fprintf(stderr, "incr-hash: %v\n", r.err)
+				u.failed++
+				return nil
+			}
+			if r.reused {
+				u.reused++
+			} else {
+				u.hashed++
+			}
+			if r.known {
+				u.present++
+			}
+			return out.Write(r.row)
+		})
+		if err != nil {
+			return err
+		}
+		out.Flush()
+		return out.Error()
+	})
+}
+
+// file returns the manifest row of the file at path, with the manifest's
+// hash if its size and time are unchanged, or a fresh one
+//
+// Shell equivalent:
+//   [[ ${size},${mtime} == "${old[$path]}" ]] && sum=${sums[$path]} || sum=$(sha256sum ...)
+//
+// It runs in a worker, so it only reads u, and returns everything else in
+// the result.
+func (u *updater) file(path string) result {
+	rel, err := filepath.Rel(u.root, path)
+	if err != nil {
+		return result{err: err}
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return result{err: err}
+	}
+	rel = filepath.ToSlash(rel)
+	mtime := info.ModTime().UTC().Truncate(time.Second)
+
+	row := []string{
+		rel,
+		strconv.FormatInt(info.Size(), 10),
+		mtime.Format(time.RFC3339),
+		fmt.Sprintf("%04o", info.Mode().Perm()),
+	}
+	old, known := u.cache[rel]
+	if known && old.size == info.Size() && old.mtime == mtime.Unix() && old.sum != "" {
+		return result{row: append(row, old.sum), reused: true, known: true}
+	}
+
+	// Shell: sha256sum "$path" | cut -d' ' -f1
+	sum, err := digest.File(path, digest.Algorithms[0])
+	if err != nil {
+		return result{err: err}
+	}
+	return result{row: append(row, sum), known: known}
+}
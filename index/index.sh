@@ -0,0 +1,66 @@
+#!/bin/bash
+set -eo pipefail
+
+# Write a CSV manifest of every file in a directory tree: its path, size,
+# modification time, permissions and, with --hash, its SHA-256
+# yupsh equivalent: See main.go
+#
+# Paths are written as they are, so this version only makes valid CSV for
+# paths without commas, quotes, tabs or newlines; the Go version quotes
+# them with encoding/csv.
+
+usage() {
+  echo "Usage: $0 [--hash] [--jobs N] [directory]" >&2
+  exit 2
+}
+
+# Parse flags (--hash, --jobs N), then the directory
+# yupsh: hash := opts.Bool("hash", false, ...); jobs := opts.Int("jobs", runtime.NumCPU(), ...)
+HASH=0
+JOBS=$(nproc)
+while [[ $1 == --* ]]; do
+  case $1 in
+    --hash) HASH=1; shift ;;
+    --jobs) JOBS=$2; shift 2 ;;
+    *) usage ;;
+  esac
+done
+[[ ${JOBS} =~ ^[1-9][0-9]*$ ]] || usage
+DIR=${1:-.}
+
+# find only warns about a missing directory; make it an error
+# yupsh: os.Stat(dir), then status.Result{Err: err}.Exit("index")
+if [[ ! -d ${DIR} ]]; then
+  echo "index: ${DIR}: not a directory" >&2
+  exit 2
+fi
+
+# One line per file, tab-separated, sorted by path: the path relative to
+# the directory, the size, the time in UTC to the second, the octal mode
+# yupsh: find.Find(...) | sort.Sort() | ix.rows(), with ix.file() per path
+list() {
+  TZ=UTC find "${DIR}" -type f -printf '%P\t%s\t%TY-%Tm-%TdT%TH:%TM:%TS\t%#m\n' \
+    | awk -F'\t' -v OFS='\t' '{ sub(/\.[0-9]+$/, "", $3); $3 = $3 "Z"; print }' \
+    | LC_ALL=C sort -t$'\t' -k1,1
+}
+
+# The header, then the rows, with commas between the columns
+# yupsh: csv.NewWriter(stdout), out.Write(ix.header()), out.Write(r.row)
+if (( ! HASH )); then
+  echo "path,size,mtime,mode"
+  list | tr '\t' ,
+  exit
+fi
+
+# Hash the files in parallel batches; the sums come back in any order, so
+# sort them by path and join them to the listing
+# yupsh: ix.jobs workers calling digest.File(path, "sha256"), in input order
+SUMS=$(mktemp)
+trap 'rm -f "${SUMS}"' EXIT
+(cd "${DIR}" && find . -type f -printf '%P\0' \
+  | xargs -0 -r -n 64 -P "${JOBS}" sha256sum --) \
+  | awk '{ sum = $1; sub(/^[0-9a-f]+  /, ""); print $0 "\t" sum }' \
+  | LC_ALL=C sort -t$'\t' -k1,1 > "${SUMS}"
+
+echo "path,size,mtime,mode,sha256"
+list | LC_ALL=C join -t$'\t' - "${SUMS}" | tr '\t' ,
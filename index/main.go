@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	digest `github.com/yupsh/script-examples/internal/digest`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	ordered `github.com/yupsh/script-examples/internal/ordered`
+	status `github.com/yupsh/script-examples/internal/status`
+	sort `github.com/yupsh/sort`
+)
+
+// Write a CSV manifest of every file in a directory tree: its path, size,
+// modification time, permissions and, with --hash, its SHA-256
+// Shell equivalent: See index.sh
+//
+//   index --hash site > site.csv
+//   path,size,mtime,mode,sha256
+//   about.html,1289,2026-10-14T09:12:44Z,0644,3f0a...
+//   posts/hello.md,412,2026-10-12T17:03:09Z,0644,9b1c...
+//
+// Pattern: find -> sort -> ix.rows(). find lists the regular files and sort
+// fixes their order, so two manifests of the same tree are the same file
+// and diff shows just what changed:
+//   diff <(index --hash site) <(index --hash mirror)
+//
+// Paths are relative to the directory and use "/", times are RFC 3339 in
+// UTC to the second, and modes are octal permission bits, so a manifest
+// reads the same from any machine and zone. The rows are written with
+// encoding/csv, so a path with a comma or a quote in it is quoted.
+//
+// Stat-ing a file is cheap, but hashing one reads all of it, so --hash is
+// off by default, and the sha256 column is left out. With it, the files are
+// hashed --jobs at a time, and the rows still come out in path order. The
+// digests are sha256sum's, so verify or sha256sum -c can check a file's
+// against it.
+//
+// A file that can't be read is reported and left out, and index exits 1
+// once the others are written; 2 on any other trouble.
+//
+// Usage: index [--hash] [--jobs N] [directory]
+func main() {
+	opts := flags.New("index", "[directory]")
+	hash := opts.Bool("hash", false, "add a sha256 column, reading every file")
+	jobs := opts.Int("jobs", runtime.NumCPU(), "with --hash, hash `N` files at a time")
+	opts.Parse()
+	if *jobs < 1 {
+		opts.Fail("--jobs must be at least 1")
+	}
+	dir := opts.ArgOr(0, ".")
+
+	// find only warns about a missing directory; make it an error
+	// Shell: [[ -d ${DIR} ]] || exit 2
+	if err := input.Dir(dir); err != nil {
+		status.Result{Err: err}.Exit("index")
+	}
+
+	ix := &indexer{root: dir, hash: *hash, jobs: *jobs}
+	err := gloo.Run(pipe.Pipeline(
+		// Find all regular files
+		// Shell: find "${DIR}" -type f
+		find.Find(find.Dir(dir), find.FileType),
+
+		// Put them in a fixed order, so the manifest doesn't depend on the
+		// directory's order or on --jobs
+		// Shell: LC_ALL=C sort
+		sort.Sort(),
+
+		// Stat, and hash, each one into a CSV row
+		// Shell: stat --printf ... | xargs -P "${JOBS}" sha256sum
+		ix.rows(),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "index: %s\n", status.Message(err))
+		os.Exit(2)
+	}
+	if ix.failed > 0 {
+		fmt.Fprintf(os.Stderr, "index: %d file(s) could not be indexed\n", ix.failed)
+		os.Exit(1)
+	}
+}
+
+// indexer turns file paths into manifest rows with a pool of workers, and
+// writes them in the order the paths arrived
+type indexer struct {
+	root   string
+	hash   bool // add the sha256 column (--hash)
+	jobs   int  // number of workers (--jobs)
+	failed int  // files that could not be indexed
+}
+
+// header returns the manifest's column names
+func (ix *indexer) header() []string {
+	columns := []string{"path", "size", "mtime", "mode"}
+	if ix.hash {
+		columns = append(columns, digest.Algorithms[0])
+	}
+	return columns
+}
+
+// result is the manifest row of one file, or the error that stopped it
+type result struct {
+	row []string
+	err error
+}
+
+// rows returns the command that reads one path per line from stdin and
+// writes the manifest: the header, then a row per file
+//
+// Shell equivalent:
+//   echo path,size,mtime,mode; while read -r path; do stat ...; done
+//
+// The files are stat-ed and hashed --jobs at a time by ordered.Map, as in
+// pgrep, which hands the rows back in the order of the paths. At most twice
+// --jobs files are in hand at once, so memory stays bounded however many
+// files there are, and each row is written as soon as the rows before it
+// are.
+func (ix *indexer) rows() gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		// Writer: the header, then each file's row, in order
+		// Shell: awk -v OFS=, '{print $1, $2, $3, $4}'
+		out := csv.NewWriter(stdout)
+		if err := out.Write(ix.header()); err != nil {
+			return err
+		}
+		// Shell: xargs -P "${JOBS}"
+		err := ordered.Map(ctx, stdin, ix.jobs, func(ctx context.Context, path string) result {
+			return ix.file(path)
+		}, func(r result) error {
+			if r.err != nil {
+				// Shell: sha256sum: file: Permission denied
+				fmt.Fprintf(stderr, "index: %v\n", r.err)
+				ix.failed++
+				return nil
+			}
+			return out.Write(r.row)
+		})
+		if err != nil {
+			return err
+		}
+		out.Flush()
+		return out.Error()
+	})
+}
+
+// file returns the manifest row of the file at path
+//
+// Shell equivalent:
+//   TZ=UTC stat --printf '%n,%s,%y,%a' "$path"; sha256sum "$path"
+//
+// It runs in a worker, so it only reads ix, and returns everything else in
+// the result.
+func (ix *indexer) file(path string) result {
+	rel, err := filepath.Rel(ix.root, path)
+	if err != nil {
+		return result{err: err}
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return result{err: err}
+	}
+
+	row := []string{
+		filepath.ToSlash(rel),
+		strconv.FormatInt(info.Size(), 10),
+		info.ModTime().UTC().Truncate(time.Second).Format(time.RFC3339),
+		fmt.Sprintf("%04o", info.Mode().Perm()),
+	}
+	if ix.hash {
+		// Shell: sha256sum "$path" | cut -d' ' -f1
+		sum, err := digest.File(path, digest.Algorithms[0])
+		if err != nil {
+			return result{err: err}
+		}
+		row = append(row, sum)
+	}
+	return result{row: row}
+}
@@ -0,0 +1,64 @@
+#!/bin/bash
+
+# Look up a value in an INI-style config file, or list a section's keys
+# yupsh equivalent: See main.go
+
+# Parse flags (--section NAME, --key NAME), then the files
+# yupsh: section := opts.String("section", ...); key := opts.String("key", ...)
+SECTION=
+KEY=
+while [[ $1 == --* ]]; do
+  case $1 in
+    --section) SECTION=$2; shift 2 ;;
+    --key) KEY=$2; shift 2 ;;
+    *) echo "Usage: $0 [--section NAME] [--key NAME] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Follow the file line by line, then print the last value, or the keys
+# yupsh: While(r.line, input.WholeLine), then fmt.Println(r.value)
+# awk exits 1 when nothing was found, like status.Result{Count: found}
+cat "$@" | awk -v section="${SECTION}" -v key="${KEY}" '
+  function trim(s) { gsub(/^[[:space:]]+|[[:space:]]+$/, "", s); return s }
+
+  { line = trim($0) }
+
+  # Blank lines and comments
+  # yupsh: the first case of the switch in r.line()
+  line == "" || line ~ /^[;#]/ { next }
+
+  # A section header, also for an empty section
+  # yupsh: r.current = strings.TrimSpace(line[1 : len(line)-1])
+  line ~ /^\[.*\]$/ {
+    current = trim(substr(line, 2, length(line) - 2))
+    if (current == section && key == "") found = 1
+    next
+  }
+
+  # key = value
+  # yupsh: k, v, ok := strings.Cut(line, "=")
+  {
+    eq = index(line, "=")
+    k = trim(substr(line, 1, eq - 1))
+    if (eq == 0 || k == "") {
+      printf "ini-get: line %d: not a [section], key = value or comment: \"%s\"\n", NR, line > "/dev/stderr"
+      next
+    }
+    if (current != section) next
+    if (key == "") {
+      found = 1
+      if (!(k in seen)) { seen[k] = 1; keys[++n] = k }
+    } else if (k == key) {
+      found = 1
+      value = trim(substr(line, eq + 1))
+      # yupsh: unquote(v)
+      if (length(value) >= 2 && value ~ /^".*"$/) value = substr(value, 2, length(value) - 2)
+    }
+  }
+
+  END {
+    if (key != "") { if (found) print value }
+    else for (i = 1; i <= n; i++) print keys[i]
+    exit !found
+  }
+'
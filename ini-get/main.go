@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Look up a value in an INI-style config file, or list a section's keys
+// Shell equivalent: See ini-get.sh
+//
+//   ini-get --section database --key port example.ini
+//   5432
+//   ini-get --section database example.ini
+//   host
+//   port
+//   ...
+//
+// The file is read one line at a time by a While() callback that keeps only
+// what the query needs: the section it is in, and the value or keys found
+// so far. The format is the common one:
+//   [section]        starts a section; keys before the first are in ""
+//   key = value      both sides trimmed; a value in "quotes" loses them
+//   ; comment        so does a line starting with #; both are ignored
+// Blank lines are ignored too. A key given more than once takes its last
+// value, as with `git config --get`, and a section given more than once is
+// one section. Names are case-sensitive. Any other line is reported on
+// stderr, with its number, and skipped.
+//
+// The exit status follows the examples' convention (see internal/status): 0
+// when the key (or, for a listing, the section) was found, 1 when it wasn't
+// and 2 on an error, so a script can test for a setting.
+//
+// Usage: ini-get [--section NAME] [--key NAME] [file...]
+func main() {
+	opts := flags.New("ini-get", "[--section NAME] [--key NAME] [file...]")
+	section := opts.String("section", "", "look in this `section` (default the keys before any section)")
+	key := opts.String("key", "", "print the value of this `key` (default list the section's keys)")
+	opts.Parse()
+
+	r := &reader{section: *section, key: *key, seen: make(map[string]bool)}
+	err := gloo.Run(pipe.Pipeline(
+		// Report a missing file instead of silently producing nothing
+		// Shell: set -o pipefail
+		pipe.PipeFail,
+
+		// Read the named files, or stdin when there are none
+		// Shell: cat "$@"
+		input.Source(opts.Args()...),
+
+		// Parse each line, keeping what the query needs
+		// Shell: awk '/^\[/ {...} /=/ {...}'
+		While(r.line, input.WholeLine),
+	))
+	if err != nil {
+		status.Result{Err: err}.Exit("ini-get")
+	}
+
+	// The value, or the keys, once the whole file is read: a later line
+	// could still change the value
+	// Shell: END {print value}
+	if *key != "" {
+		if r.found {
+			fmt.Println(r.value)
+		}
+	} else {
+		for _, k := range r.keys {
+			fmt.Println(k)
+		}
+	}
+	var found int
+	if r.found {
+		found = 1
+	}
+	status.Result{Count: found}.Exit("ini-get")
+}
+
+// reader follows an INI file line by line, looking for one section's key,
+// or for all of its keys when key is ""
+type reader struct {
+	section string // section to look in (--section)
+	key     string // key to look up (--key), or "" to list
+	current string // section of the line being read
+	lineNum int
+
+	found bool            // the key (or, listing, the section) was seen
+	value string          // the key's last value
+	keys  []string        // the section's keys, in the order first seen
+	seen  map[string]bool // keys already in keys
+}
+
+// line is the While() callback: it parses one line of the file
+//
+// Shell equivalent:
+//   /^[[:space:]]*[;#]/ {next}
+//   /^\[/ {current = name}
+//   current == section && k == key {value = v}
+func (r *reader) line(args ...any) gloo.Command {
+	r.lineNum++
+	line := strings.TrimSpace(args[0].(string))
+
+	switch {
+	case line == "" || line[0] == ';' || line[0] == '#':
+		// Blank lines and comments
+		// Shell: /^[[:space:]]*([;#]|$)/ {next}
+
+	case line[0] == '[' && line[len(line)-1] == ']':
+		// A section header, also for an empty section
+		// Shell: /^\[/ {current = substr($0, 2, length($0) - 2)}
+		r.current = strings.TrimSpace(line[1 : len(line)-1])
+		if r.current == r.section && r.key == "" {
+			r.found = true
+		}
+
+	default:
+		// key = value
+		// Shell: k = $0; sub(/[[:space:]]*=.*/, "", k)
+		k, v, ok := strings.Cut(line, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			fmt.Fprintf(os.Stderr, "ini-get: line %d: not a [section], key = value or comment: %q\n", r.lineNum, line)
+			return nil
+		}
+		if r.current != r.section {
+			return nil
+		}
+		if r.key == "" {
+			// Listing: keys before the first section count as found, too
+			r.found = true
+			if !r.seen[k] {
+				r.seen[k] = true
+				r.keys = append(r.keys, k)
+			}
+		} else if k == r.key {
+			// The last value wins
+			r.found = true
+			r.value = unquote(strings.TrimSpace(v))
+		}
+	}
+	return nil
+}
+
+// unquote removes one pair of double quotes around a value, which keep its
+// leading or trailing spaces, or a leading ; or #, from being trimmed away
+// Shell: sub(/^"/, "", v); sub(/"$/, "", v)
+func unquote(v string) string {
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
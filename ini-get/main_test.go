@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	input `github.com/yupsh/script-examples/internal/input`
+	. `github.com/yupsh/while`
+)
+
+// fixture has duplicate keys, within a section and across a section given
+// twice, and empty sections: one with nothing in it, one with only a comment
+const fixture = `; keys before any section
+name = top
+name = top again
+
+[database]
+host = db1
+port = 5432
+host = db2
+
+[empty]
+
+[comments only]
+; nothing = here
+# nor = here
+
+[cache]
+ttl = 60
+
+[database]
+port = 6543
+user = "  admin  "
+not a key line
+=no key
+host = db3
+
+[last]
+`
+
+// query runs a reader for section and key over the fixture, as main() does
+func query(t *testing.T, section, key string) *reader {
+	t.Helper()
+	r := &reader{section: section, key: key, seen: make(map[string]bool)}
+	var stdout, stderr bytes.Buffer
+	err := While(r.line, input.WholeLine).Executor()(context.Background(), strings.NewReader(fixture), &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// TestGet checks looking up one key: the last value wins, across a section
+// given twice too, and a key in an empty section, or none, is not found
+func TestGet(t *testing.T) {
+	tests := []struct {
+		section, key string
+		found        bool
+		value        string
+	}{
+		{"database", "host", true, "db3"},
+		{"database", "port", true, "6543"},
+		{"database", "user", true, "  admin  "},
+		{"", "name", true, "top again"},
+		{"cache", "ttl", true, "60"},
+		{"cache", "host", false, ""},
+		{"empty", "host", false, ""},
+		{"comments only", "nothing", false, ""},
+		{"last", "host", false, ""},
+		{"missing", "host", false, ""},
+		{"Database", "host", false, ""},
+	}
+	for _, tt := range tests {
+		r := query(t, tt.section, tt.key)
+		if r.found != tt.found || r.value != tt.value {
+			t.Errorf("[%s] %s: found %t, %q; want %t, %q", tt.section, tt.key, r.found, r.value, tt.found, tt.value)
+		}
+	}
+}
+
+// TestList checks listing a section's keys: each once, in the order first
+// seen, and an empty section is found with no keys
+func TestList(t *testing.T) {
+	tests := []struct {
+		section string
+		found   bool
+		keys    []string
+	}{
+		{"database", true, []string{"host", "port", "user"}},
+		{"", true, []string{"name"}},
+		{"cache", true, []string{"ttl"}},
+		{"empty", true, nil},
+		{"comments only", true, nil},
+		{"last", true, nil},
+		{"missing", false, nil},
+	}
+	for _, tt := range tests {
+		r := query(t, tt.section, "")
+		if r.found != tt.found || !slices.Equal(r.keys, tt.keys) {
+			t.Errorf("[%s]: found %t, %q; want %t, %q", tt.section, r.found, r.keys, tt.found, tt.keys)
+		}
+	}
+}
+
+// TestUnquote checks that one pair of quotes is taken off, and nothing else
+func TestUnquote(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{`"a"`, "a"},
+		{`""`, ""},
+		{`"`, `"`},
+		{`"a`, `"a`},
+		{`""a""`, `"a"`},
+		{`"; not a comment"`, "; not a comment"},
+		{"plain", "plain"},
+	}
+	for _, tt := range tests {
+		if got := unquote(tt.in); got != tt.want {
+			t.Errorf("unquote(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Package count implements a pass-through command that counts the lines
+// flowing through a point in a pipeline, for instrumentation.
+//
+// Shell equivalent:
+//   producer | tee >(wc -l >&2) | consumer
+//
+// Unlike the tee, the count reflects what the consumer actually took: when
+// a downstream command closes the pipe early (head, say), Lines stops there,
+// and the count is the number of lines passed on before it did.
+package count
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync/atomic"
+
+	gloo `github.com/gloo-foo/framework`
+)
+
+// BufferSize is the most of a line Lines holds in memory at once. A longer
+// line is passed on in pieces of this size, and still counts as one line, so
+// there is no limit on the length of a line, and a file that is one huge
+// line takes no more memory than any other.
+const BufferSize = 64 * 1024
+
+// Lines returns a command that copies stdin to stdout unchanged, adding one
+// to *n for every line it passes on.
+//
+// A line is counted only once it has been written downstream, so lines read
+// but never delivered, because the next command closed its input, are not
+// counted; nor is a line longer than BufferSize that was cut off partway. A
+// final line without a trailing newline counts as a line. When the write
+// fails because the pipe was closed, Lines returns that error, which
+// pipe.Pipeline() treats as a normal early stop, so the command in front of
+// it is stopped in turn.
+//
+// *n is updated atomically, so it can be read with atomic.LoadInt64 while
+// the pipeline runs; after gloo.Run() returns, a plain read is enough.
+func Lines(n *int64) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		r := bufio.NewReaderSize(stdin, BufferSize)
+		midLine := false // the last piece written was the start of a long line
+		for {
+			piece, readErr := r.ReadSlice('\n')
+			if len(piece) > 0 {
+				// One write per line (or per piece of a long one),
+				// unbuffered, so a successful write means the line really
+				// was handed on
+				if _, err := stdout.Write(piece); err != nil {
+					return err
+				}
+				midLine = readErr == bufio.ErrBufferFull
+				if !midLine {
+					atomic.AddInt64(n, 1)
+				}
+			}
+
+			switch readErr {
+			case nil, bufio.ErrBufferFull:
+			case io.EOF:
+				// A long last line, without a newline, may have ended with
+				// the input right after a full buffer
+				if midLine {
+					atomic.AddInt64(n, 1)
+				}
+				return nil
+			default:
+				return readErr
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package count_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	head `github.com/yupsh/head`
+	count `github.com/yupsh/script-examples/internal/count`
+	seq `github.com/yupsh/seq`
+	yes `github.com/yupsh/yes`
+)
+
+// run runs cmd with stdin and returns what it wrote to stdout
+func run(t *testing.T, cmd gloo.Command, stdin string) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	if err := cmd.Executor()(context.Background(), strings.NewReader(stdin), &stdout, &stderr); err != nil {
+		t.Fatalf("run: %v\n%s", err, stderr.String())
+	}
+	return stdout.String()
+}
+
+// lines counts the lines of s the way Lines does: a last line without a
+// newline is still a line
+func lines(s string) int64 {
+	n := int64(strings.Count(s, "\n"))
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		n++
+	}
+	return n
+}
+
+// TestLinesPassesEverything checks that, with nothing closing the pipe
+// early, the output is the input unchanged and the count is its lines
+func TestLinesPassesEverything(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"one line", "a\n"},
+		{"several lines", "a\nb\nc\n"},
+		{"blank lines", "\n\n\n"},
+		{"no final newline", "a\nb"},
+		{"only a partial line", "a"},
+		{"long line", strings.Repeat("x", 1<<20) + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var n int64
+			got := run(t, count.Lines(&n), tt.input)
+			if got != tt.input {
+				t.Errorf("output differs from the input: got %d bytes, want %d", len(got), len(tt.input))
+			}
+			if want := lines(tt.input); n != want {
+				t.Errorf("count = %d, want %d", n, want)
+			}
+		})
+	}
+}
+
+// TestLinesLongLines checks that a line longer than BufferSize counts as one
+// line and is passed on whole, while Lines never holds more than BufferSize
+// of it: each write downstream is at most that long
+func TestLinesLongLines(t *testing.T) {
+	long := strings.Repeat("x", 3*count.BufferSize+17)
+	tests := []struct {
+		name  string
+		input string
+		want  int64
+	}{
+		{"long line", long + "\n", 1},
+		{"between short lines", "a\n" + long + "\nb\n", 3},
+		{"last, without a newline", "a\n" + long, 2},
+		{"exactly two buffers, without a newline", strings.Repeat("y", 2*count.BufferSize), 1},
+		{"exactly one buffer and a newline", strings.Repeat("z", count.BufferSize) + "\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var n int64
+			var out writeSizes
+			var stderr bytes.Buffer
+			if err := count.Lines(&n).Executor()(context.Background(), strings.NewReader(tt.input), &out, &stderr); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.input {
+				t.Errorf("output differs from the input: got %d bytes, want %d", out.Len(), len(tt.input))
+			}
+			if n != tt.want {
+				t.Errorf("count = %d, want %d", n, tt.want)
+			}
+			if out.largest > count.BufferSize {
+				t.Errorf("a write of %d bytes, more than BufferSize (%d)", out.largest, count.BufferSize)
+			}
+		})
+	}
+}
+
+// writeSizes is a bytes.Buffer that records the largest single write to it
+type writeSizes struct {
+	bytes.Buffer
+	largest int
+}
+
+func (w *writeSizes) Write(p []byte) (int, error) {
+	w.largest = max(w.largest, len(p))
+	return w.Buffer.Write(p)
+}
+
+// TestLinesMatchesDownstream checks that the count is the number of lines
+// the next command actually received, whether it reads all of them or
+// closes its input early
+func TestLinesMatchesDownstream(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator gloo.Command
+		consumer  gloo.Command
+		want      int64 // the exact count, as pipe-closure documents it
+	}{
+		// Reads everything
+		{"seq 1 100 | cat", seq.Seq("1", "100"), catCommand(), 100},
+
+		// head.Head() reads one line past the last it keeps, then closes
+		{"seq 1 10 | head -n 3", seq.Seq("1", "10"), head.Head(head.LineCount(3)), 4},
+		{"seq 1 10000 | head -n 5", seq.Seq("1", "10000"), head.Head(head.LineCount(5)), 6},
+		{"yes | head -n 3", yes.Yes("hello"), head.Head(head.LineCount(3)), 4},
+		{"seq 1 2 | head -n 5", seq.Seq("1", "2"), head.Head(head.LineCount(5)), 2},
+
+		// A consumer that closes without reading anything
+		{"seq 1 10 | true", seq.Seq("1", "10"), trueCommand(), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A reader between the counter and the consumer counts what the
+			// consumer took, the way the consumer sees it
+			var n int64
+			received := &countingCommand{next: tt.consumer}
+			run(t, pipe.Pipeline(tt.generator, count.Lines(&n), received), "")
+
+			if n != received.lines {
+				t.Errorf("count = %d, but the consumer received %d lines", n, received.lines)
+			}
+			if n != tt.want {
+				t.Errorf("count = %d, want %d", n, tt.want)
+			}
+		})
+	}
+}
+
+// TestLinesAfterHead checks a counter placed after head: it sees only the
+// lines head keeps
+func TestLinesAfterHead(t *testing.T) {
+	var before, after int64
+	out := run(t, pipe.Pipeline(
+		seq.Seq("1", "100"),
+		count.Lines(&before),
+		head.Head(head.LineCount(3)),
+		count.Lines(&after),
+	), "")
+
+	if out != "1\n2\n3\n" {
+		t.Errorf("output = %q, want the first 3 numbers", out)
+	}
+	if after != 3 {
+		t.Errorf("count after head = %d, want 3", after)
+	}
+	if before != 4 {
+		t.Errorf("count before head = %d, want 4", before)
+	}
+}
+
+// countingCommand runs next, counting the lines next reads from its stdin
+type countingCommand struct {
+	next  gloo.Command
+	lines int64
+}
+
+func (c *countingCommand) Executor() gloo.CommandExecutor {
+	return func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		r := &lineCounter{r: stdin}
+		err := c.next.Executor()(ctx, r, stdout, stderr)
+		c.lines = r.lines()
+		return err
+	}
+}
+
+// lineCounter counts the lines read through it; a partial line at the end
+// of what was read counts too
+type lineCounter struct {
+	r       io.Reader
+	newline int64
+	partial bool
+}
+
+func (l *lineCounter) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	if n > 0 {
+		l.newline += int64(bytes.Count(p[:n], []byte("\n")))
+		l.partial = p[n-1] != '\n'
+	}
+	return n, err
+}
+
+func (l *lineCounter) lines() int64 {
+	if l.partial {
+		return l.newline + 1
+	}
+	return l.newline
+}
+
+// catCommand copies stdin to stdout, reading all of it
+func catCommand() gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		_, err := io.Copy(stdout, stdin)
+		return err
+	})
+}
+
+// trueCommand exits at once without reading stdin
+func trueCommand() gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		return nil
+	})
+}
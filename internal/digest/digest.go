@@ -0,0 +1,74 @@
+// Package digest computes file checksums for the integrity examples
+// (hashfiles, verify, dirhash, ...), in the same format as sha256sum(1).
+package digest
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+)
+
+// Algorithms are the supported --algo values; the first is the default
+var Algorithms = []string{"sha256", "sha1", "md5"}
+
+// New returns a fresh hash for the named algorithm
+func New(algo string) (hash.Hash, error) {
+	switch algo {
+	case "sha256":
+		return sha256.New(), nil
+	case "sha1":
+		return sha1.New(), nil
+	case "md5":
+		return md5.New(), nil
+	}
+	return nil, fmt.Errorf("unknown hash algorithm %q", algo)
+}
+
+// File returns the hex digest of a file's contents
+//
+// Shell equivalent:
+//   sha256sum "$path" | cut -d' ' -f1
+//
+// The file is streamed through the hash with io.Copy, so memory use is
+// constant however large the file is.
+func File(path, algo string) (string, error) {
+	h, err := New(algo)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Detect returns the algorithm that produces hex digests like sum, judged by
+// its length: 64 hex digits for sha256, 40 for sha1, 32 for md5
+func Detect(sum string) (string, error) {
+	switch len(sum) {
+	case sha256.Size * 2:
+		return "sha256", nil
+	case sha1.Size * 2:
+		return "sha1", nil
+	case md5.Size * 2:
+		return "md5", nil
+	}
+	return "", fmt.Errorf("unrecognized digest %q", sum)
+}
+
+// Line formats a manifest line as written by sha256sum: digest, two spaces, path
+func Line(sum, path string) string {
+	return sum + "  " + path
+}
@@ -0,0 +1,56 @@
+// Package dryrun implements the --dry-run convention shared by the examples
+// that change files (log-processor, rename, patch, prune-empty, ...).
+//
+// With --dry-run (registered by flags.Set.DryRun), an example does all of
+// its work but the change itself: it reads the same input, makes the same
+// decisions and reports the same problems, and exits with the status a real
+// run would. Each change it skips is printed to stdout instead, as the line
+// the real run would print or write, marked with Prefix:
+//   rename --dry-run --from '\.jpeg$' --to .jpg photos
+//   [dry-run] photos/a.jpeg -> photos/a.jpg
+//
+// Shell equivalent:
+//   if ((DRY_RUN)); then echo "[dry-run] $a -> $b"; else mv -- "$a" "$b"; fi
+package dryrun
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+
+	gloo `github.com/gloo-foo/framework`
+)
+
+// Prefix marks every line describing a change a dry run skipped
+const Prefix = "[dry-run] "
+
+// Line returns s marked as the output of a dry run when dryRun is set, and
+// unchanged otherwise, for messages that real runs print too
+func Line(dryRun bool, s string) string {
+	if dryRun {
+		return Prefix + s
+	}
+	return s
+}
+
+// Write returns write, a command that sends its stdin to a file (such as
+// tee.Tee()), or with dryRun a command that prints each line it would have
+// written, marked with Prefix, and writes nothing
+//
+// Shell equivalent:
+//   if ((DRY_RUN)); then sed 's/^/[dry-run] /'; else cat >> results.csv; fi
+func Write(dryRun bool, write gloo.Command) gloo.Command {
+	if !dryRun {
+		return write
+	}
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		scanner := bufio.NewScanner(stdin)
+		for scanner.Scan() {
+			if _, err := fmt.Fprintln(stdout, Prefix+scanner.Text()); err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	})
+}
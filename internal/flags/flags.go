@@ -0,0 +1,230 @@
+// Package flags is the command-line parsing shared by the examples.
+//
+// It wraps the standard flag package so that every example spells the common
+// options the same way, with the same defaults and usage text:
+//   --top N        number of entries in a ranked report (default 10, at least 1)
+//   --human        print sizes as 1.2K/3.4M instead of bytes
+//   --format NAME  output format, validated against the example's choices
+//   --dry-run      print the changes to files instead of making them
+//   --delim SEP    field separator: one character, \t for tab, " " for blanks
+//   --null, -0     pass file names NUL-terminated, for names with newlines
+//
+// Positional arguments (a directory or files) follow the flags:
+//   file-stats --top 5 --human ./src
+package flags
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	size `github.com/yupsh/script-examples/internal/size`
+)
+
+// DefaultTop is the default for --top, matching `head` with no -n
+const DefaultTop = 10
+
+// Set is a flag.FlagSet with the examples' shared options and usage text
+type Set struct {
+	*flag.FlagSet
+	choices map[string][]string // --name -> allowed values, checked by Parse
+}
+
+// New returns the flag set for the named example. synopsis describes its
+// positional arguments in the usage line, e.g. "[directory]" or "[file...]".
+func New(name, synopsis string) *Set {
+	s := &Set{
+		FlagSet: flag.NewFlagSet(name, flag.ExitOnError),
+		choices: make(map[string][]string),
+	}
+	s.Usage = func() {
+		fmt.Fprintf(s.Output(), "Usage: %s [flags] %s\n\nFlags:\n", name, synopsis)
+		s.PrintDefaults()
+	}
+	return s
+}
+
+// Top registers --top, the number of entries a ranked report shows. A value
+// below 1 is a usage error.
+// Shell: head -n N
+func (s *Set) Top() *int {
+	v := topValue{n: DefaultTop, min: 1}
+	s.Var(&v, "top", "show only the top `N` entries")
+	return &v.n
+}
+
+// TopOrAll registers --top like Top, for reports that can list every entry:
+// 0 lists them all, and only a negative value is a usage error
+// Shell: ls -S | head -n N
+func (s *Set) TopOrAll() *int {
+	v := topValue{n: DefaultTop, min: 0}
+	s.Var(&v, "top", "show only the top `N` entries (0 for all)")
+	return &v.n
+}
+
+// topValue is a flag.Value holding an entry count of at least min
+type topValue struct {
+	n, min int
+}
+
+func (v *topValue) String() string { return strconv.Itoa(v.n) }
+
+func (v *topValue) Set(text string) error {
+	n, err := strconv.Atoi(text)
+	if err != nil {
+		return errors.New("not a whole number")
+	}
+	if n < v.min {
+		return fmt.Errorf("must be at least %d", v.min)
+	}
+	v.n = n
+	return nil
+}
+
+// Human registers --human, printing sizes in human-readable units
+// Shell: ls -h, du -h
+func (s *Set) Human() *bool {
+	return s.Bool("human", false, "print sizes in human-readable units (1.2K, 3.4M)")
+}
+
+// DryRun registers --dry-run, for examples that change files; see package
+// dryrun for what a dry run prints
+// Shell: rsync --dry-run, make -n
+func (s *Set) DryRun() *bool {
+	return s.Bool("dry-run", false, "print what would change, without changing anything")
+}
+
+// Null registers --null, and -0 as its short form, for examples that pass
+// file names down a pipeline: with it they pass them NUL-terminated, so
+// names with newlines in them survive; see package null
+// Shell: find -print0 | xargs -0
+func (s *Set) Null() *bool {
+	null := new(bool)
+	s.BoolVar(null, "null", false, "pass file names NUL-terminated, safe for names with newlines")
+	s.BoolVar(null, "0", false, "short for --null")
+	return null
+}
+
+// Delim registers --delim, the field separator of delimited input, with
+// default def. The value is a single character, with two special cases:
+//   \t   a tab, so --delim '\t' works without the shell's $'\t'
+//   " "  runs of spaces and tabs, ignoring leading and trailing ones, as
+//        awk does by default
+// Any other character separates fields on its own: "a,,b" split on ","
+// has an empty middle field. input.Fields() turns the value into the
+// matching While() option.
+// Shell: awk -F SEP, cut -d SEP
+func (s *Set) Delim(def string) *string {
+	v := delimValue(def)
+	s.Var(&v, "delim", "field `separator`: one character, \\t for tab, or \" \" for runs of blanks")
+	return (*string)(&v)
+}
+
+// delimValue is a flag.Value holding a field separator
+type delimValue string
+
+func (v *delimValue) String() string {
+	switch *v {
+	case "\t":
+		return `\t`
+	case " ":
+		return `" "`
+	}
+	return string(*v)
+}
+
+func (v *delimValue) Set(text string) error {
+	if text == `\t` {
+		text = "\t"
+	}
+	if utf8.RuneCountInString(text) != 1 {
+		return fmt.Errorf("separator must be a single character, \\t or \" \", not %q", text)
+	}
+	*v = delimValue(text)
+	return nil
+}
+
+// Format registers --format; the first choice is the default
+func (s *Set) Format(choices ...string) *string {
+	return s.Choice("format", "output format", choices...)
+}
+
+// Choice registers a string flag restricted to choices, the first being the
+// default. Any other value is rejected by Parse with the usage text.
+func (s *Set) Choice(name, usage string, choices ...string) *string {
+	s.choices[name] = choices
+	usage = fmt.Sprintf("%s: %s", usage, strings.Join(choices, "|"))
+	return s.String(name, choices[0], usage)
+}
+
+// Size registers a byte-count flag that accepts human sizes such as "100MB"
+// or "1.5G" (see size.Parse)
+// Shell: find -size +100M
+func (s *Set) Size(name string, def int64, usage string) *int64 {
+	v := sizeValue(def)
+	s.Var(&v, name, usage)
+	return (*int64)(&v)
+}
+
+// sizeValue is a flag.Value holding a byte count
+type sizeValue int64
+
+func (v *sizeValue) String() string { return size.Format(int64(*v)) }
+
+func (v *sizeValue) Set(text string) error {
+	n, err := size.Parse(text)
+	*v = sizeValue(n)
+	return err
+}
+
+// List registers a repeatable string flag; each occurrence appends a value
+// Shell: grep -e foo -e bar
+func (s *Set) List(name, usage string) *[]string {
+	var v listValue
+	s.Var(&v, name, usage)
+	return (*[]string)(&v)
+}
+
+// listValue is a flag.Value collecting every occurrence of a flag
+type listValue []string
+
+func (v *listValue) String() string { return strings.Join(*v, ",") }
+
+func (v *listValue) Set(text string) error {
+	*v = append(*v, text)
+	return nil
+}
+
+// Parse parses os.Args[1:], exiting with status 2 on an invalid flag
+// (like flag.ExitOnError, and like most Unix tools on a usage error)
+func (s *Set) Parse() {
+	_ = s.FlagSet.Parse(os.Args[1:]) // ExitOnError: never returns an error
+
+	s.Visit(func(f *flag.Flag) {
+		choices, ok := s.choices[f.Name]
+		if ok && !slices.Contains(choices, f.Value.String()) {
+			s.Fail("invalid value %q for --%s", f.Value, f.Name)
+		}
+	})
+}
+
+// Fail reports a usage error, prints the usage text and exits with status 2
+func (s *Set) Fail(format string, args ...any) {
+	fmt.Fprintf(s.Output(), "%s: %s\n", s.Name(), fmt.Sprintf(format, args...))
+	s.Usage()
+	os.Exit(2)
+}
+
+// ArgOr returns positional argument i, or def when it was not given
+// Shell: ${1:-.}
+func (s *Set) ArgOr(i int, def string) string {
+	if i < s.NArg() {
+		return s.Arg(i)
+	}
+	return def
+}
@@ -0,0 +1,15 @@
+module github.com/yupsh/script-examples/internal
+
+go 1.25
+
+require (
+	github.com/gloo-foo/framework v0.0.3
+	github.com/gloo-foo/pipe v0.0.3
+	github.com/yupsh/head v0.0.3
+	github.com/yupsh/seq v0.0.3
+	github.com/yupsh/while v0.0.4
+	github.com/yupsh/yes v0.0.3
+	golang.org/x/term v0.40.0
+)
+
+require golang.org/x/sys v0.41.0 // indirect
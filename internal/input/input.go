@@ -0,0 +1,135 @@
+// Package input implements the input convention shared by the examples:
+// read the files named on the command line, or stdin when none are given.
+//
+// Shell equivalent:
+//   cat "$@"    # with no arguments, cat reads stdin
+//
+// This makes every text-processing example composable in a larger shell or
+// yupsh pipeline: `producer | example` and `example file...` both work.
+package input
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	gloo `github.com/gloo-foo/framework`
+	while `github.com/yupsh/while`
+)
+
+// Stdin is the path argument that explicitly selects standard input.
+// Shell: cat a.txt - b.txt
+const Stdin = "-"
+
+// Source returns the command that feeds an example's pipeline.
+//
+// With no files it copies stdin through unchanged. Otherwise it emits each
+// file in order, where Stdin ("-") stands for standard input.
+//
+// It is used instead of cat.Cat() as the first stage of a pipeline because
+// cat.Cat() silently skips files it cannot open (and falls back to stdin if
+// none open), whereas Source reports the missing file as an error:
+//   Shell: cat missing.txt  ->  "cat: missing.txt: No such file or directory"
+func Source(files ...string) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		if len(files) == 0 {
+			_, err := io.Copy(stdout, stdin)
+			return err
+		}
+
+		for _, name := range files {
+			if err := copyFile(name, stdin, stdout); err != nil {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+		return nil
+	})
+}
+
+// copyFile writes the contents of name (or stdin for "-") to stdout
+func copyFile(name string, stdin io.Reader, stdout io.Writer) error {
+	if name == Stdin {
+		_, err := io.Copy(stdout, stdin)
+		return err
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(stdout, f); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// Dir checks that path names a directory, for the examples that walk one
+// with find.Find(), which only warns about a missing directory and then
+// finds nothing. The error names the path, as os.Stat's errors do.
+//
+// Every example stops on it the same way, with status.Result{Err: err}:
+// the error on stderr and exit status 2, as for any error, so a missing
+// directory is never mistaken for one with nothing in it (status 1).
+//
+// Shell equivalent:
+//   [[ -d ${DIR} ]] || { echo "${DIR}: not a directory" >&2; exit 2; }
+func Dir(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s: not a directory", path)
+	}
+	return nil
+}
+
+// Piped reports whether stdin is a pipe or a redirected file rather than a
+// terminal, i.e. whether the user is feeding the example data.
+//
+// Shell equivalent:
+//   [ ! -t 0 ]
+func Piped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
+// WholeLine is the While() option for callbacks that take a whole line, such
+// as a file path, in args[0].
+//
+// Without a FieldSeparator, While() splits each line on whitespace, so a path
+// like "my notes.txt" would arrive as two args. A scanned line never contains
+// "\n", so splitting on it leaves the line intact.
+//
+// Shell equivalent:
+//   while IFS= read -r file; do ...; done
+var WholeLine = while.FieldSeparator("\n")
+
+// Fields returns the While() option that splits each line into fields on
+// delim, a --delim value (see flags.Set.Delim).
+//
+// A " " separator splits on runs of whitespace (While()'s own default, so
+// "a   b" is two fields), as awk does. Any other separator splits on every
+// occurrence, as cut does, so "a,,b" split on "," is three fields, the
+// middle one empty.
+//
+// Shell equivalent:
+//   awk -F "$DELIM" '{ ... }'    # " " collapses blanks, "," does not
+func Fields(delim string) while.FieldSeparator {
+	if delim == " " {
+		return while.FieldSeparator("") // no separator: strings.Fields()
+	}
+	return while.FieldSeparator(delim)
+}
@@ -0,0 +1,176 @@
+// Package merge implements a k-way merge of pre-sorted line streams into one
+// sorted stream.
+//
+// Shell equivalent:
+//   sort -m a.txt b.txt c.txt
+//
+// sort.Sort() accumulates its whole input before sorting. Merging streams
+// that are already sorted only needs the current line of each one, so it
+// runs in memory proportional to the number of inputs, not their size. That
+// makes it the final pass of an external sort (see bigsort).
+//
+// The unit merged is usually a line, but Records and RecordFiles take any
+// bufio.SplitFunc, so a record can span lines, such as a log entry followed
+// by its stack trace (see logmerge).
+package merge
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	input `github.com/yupsh/script-examples/internal/input`
+)
+
+// Less reports whether line a sorts before line b.
+type Less func(a, b string) bool
+
+// Lexical orders lines byte-wise, like sort.Sort() with no options.
+// Shell: LC_ALL=C sort -m
+func Lexical(a, b string) bool {
+	return a < b
+}
+
+// Numeric orders lines by their numeric value, like sort.Sort(sort.Numeric):
+// lines that both parse as numbers compare numerically, anything else falls
+// back to byte-wise order. Inputs sorted by sort.Sort(sort.Numeric) therefore
+// merge correctly.
+// Shell: sort -m -n
+func Numeric(a, b string) bool {
+	x, errX := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	y, errY := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if errX == nil && errY == nil {
+		return x < y
+	}
+	return a < b
+}
+
+// Files returns a command that merges the pre-sorted files (stdin for
+// input.Stdin, or when no files are given) into stdout.
+func Files(less Less, files ...string) gloo.Command {
+	return RecordFiles(less, bufio.ScanLines, files...)
+}
+
+// RecordFiles is Files for records cut by split instead of lines.
+func RecordFiles(less Less, split bufio.SplitFunc, files ...string) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		if len(files) == 0 {
+			files = []string{input.Stdin}
+		}
+
+		readers := make([]io.Reader, len(files))
+		for i, name := range files {
+			if name == input.Stdin {
+				readers[i] = stdin
+				continue
+			}
+
+			f, err := os.Open(name)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			readers[i] = f
+		}
+
+		return Records(ctx, stdout, less, split, readers...)
+	})
+}
+
+// Streams merges the pre-sorted readers into w, one line at a time.
+//
+// A min-heap holds the current line of every reader. Each step writes the
+// smallest line and replaces it with the next line from the same reader.
+// Equal lines are written in reader order, so the merge is stable.
+func Streams(ctx context.Context, w io.Writer, less Less, readers ...io.Reader) error {
+	return Records(ctx, w, less, bufio.ScanLines, readers...)
+}
+
+// Records merges the pre-sorted readers into w like Streams, one record at
+// a time, where split cuts each reader into records as it would for a
+// bufio.Scanner. less compares whole records, and each is written followed
+// by a newline, so split should return a record without its last one.
+func Records(ctx context.Context, w io.Writer, less Less, split bufio.SplitFunc, readers ...io.Reader) error {
+	h := &lineHeap{less: less}
+	for i, r := range readers {
+		scanner := bufio.NewScanner(r)
+		scanner.Split(split)
+		s := &stream{scanner: scanner, index: i}
+		if err := h.pushNext(s); err != nil {
+			return err
+		}
+	}
+
+	out := bufio.NewWriter(w)
+	for h.Len() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		s := h.streams[0]
+		if _, err := fmt.Fprintln(out, s.line); err != nil {
+			return err
+		}
+
+		heap.Pop(h)
+		if err := h.pushNext(s); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+// stream is one merge input and the line it currently offers
+type stream struct {
+	scanner *bufio.Scanner
+	index   int
+	line    string
+}
+
+// lineHeap is a container/heap of streams ordered by their current line
+type lineHeap struct {
+	streams []*stream
+	less    Less
+}
+
+// pushNext reads the next line of s and puts it back on the heap, unless s
+// is exhausted
+func (h *lineHeap) pushNext(s *stream) error {
+	if !s.scanner.Scan() {
+		return s.scanner.Err()
+	}
+	s.line = s.scanner.Text()
+	heap.Push(h, s)
+	return nil
+}
+
+func (h *lineHeap) Len() int { return len(h.streams) }
+
+func (h *lineHeap) Less(i, j int) bool {
+	a, b := h.streams[i], h.streams[j]
+	if h.less(a.line, b.line) {
+		return true
+	}
+	if h.less(b.line, a.line) {
+		return false
+	}
+	return a.index < b.index
+}
+
+func (h *lineHeap) Swap(i, j int) { h.streams[i], h.streams[j] = h.streams[j], h.streams[i] }
+
+func (h *lineHeap) Push(x any) { h.streams = append(h.streams, x.(*stream)) }
+
+func (h *lineHeap) Pop() any {
+	last := h.streams[len(h.streams)-1]
+	h.streams = h.streams[:len(h.streams)-1]
+	return last
+}
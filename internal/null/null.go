@@ -0,0 +1,129 @@
+// Package null carries file names through a pipeline as NUL-terminated
+// records, so that names containing newlines arrive intact.
+//
+// find.Find() writes one path per line, and While() calls its body once per
+// line, so a file named "a\nb" arrives as two paths, "a" and "b", neither of
+// which exists. NUL is the one byte a path can't contain, which is why find
+// and xargs can agree on it:
+//   Shell: find . -type f -print0 | xargs -0 ...
+//
+// Files and While are the NUL-terminated stand-ins for find.Find() with
+// find.FileType and While(..., input.WholeLine). Quote makes a name safe to
+// print in a line-oriented report, once it has left the NUL-terminated part
+// of the pipeline.
+package null
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	gloo `github.com/gloo-foo/framework`
+	while `github.com/yupsh/while`
+)
+
+// Terminator ends every record
+const Terminator = '\x00'
+
+// Files returns the command that writes the path of every regular file under
+// dir whose base name matches pattern ("" matches all), each followed by
+// Terminator.
+//
+// Paths and errors are as find.Find() reports them: dir is cleaned, symlinks
+// are not followed, and unreadable directories are reported on stderr and
+// skipped.
+//
+// Shell equivalent:
+//   find "$dir" -type f -name "$pattern" -print0
+func Files(dir, pattern string) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		out := bufio.NewWriter(stdout)
+		root := filepath.Clean(dir)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				fmt.Fprintf(stderr, "find: %s: %v\n", path, err)
+				return nil // keep walking
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			if pattern != "" {
+				if ok, err := filepath.Match(pattern, d.Name()); err != nil || !ok {
+					return nil
+				}
+			}
+			out.WriteString(path)
+			return out.WriteByte(Terminator)
+		})
+		if err != nil {
+			return err
+		}
+		return out.Flush()
+	})
+}
+
+// While returns the command that calls body once per NUL-terminated record
+// of stdin, with the whole record, newlines and all, in args[0], and runs the
+// command body returns, as While() does for each line.
+//
+// A final record without a terminator is still a record.
+//
+// Shell equivalent:
+//   while IFS= read -r -d '' file; do ...; done
+func While(body while.Body) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		scanner := bufio.NewScanner(stdin)
+		scanner.Split(scanRecords)
+		for scanner.Scan() {
+			cmd := body(scanner.Text())
+			if cmd == nil {
+				continue // body skipped this record
+			}
+			if err := cmd.Executor()(ctx, strings.NewReader(""), stdout, stderr); err != nil {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+		return scanner.Err()
+	})
+}
+
+// scanRecords is the bufio.SplitFunc for NUL-terminated records, the
+// counterpart of bufio.ScanLines
+func scanRecords(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexByte(data, Terminator); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// Quote returns name unchanged, or, if it contains a control character such
+// as a newline or a tab, as a Go-quoted string ("a\nb"), so it takes one
+// line, and one tab-separated field, of a report.
+//
+// Shell equivalent:
+//   printf '%q' "$name"    # bash quotes it as $'a\nb' instead
+func Quote(name string) string {
+	if strings.IndexFunc(name, unicode.IsControl) < 0 {
+		return name
+	}
+	return strconv.Quote(name)
+}
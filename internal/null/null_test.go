@@ -0,0 +1,146 @@
+package null_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	null `github.com/yupsh/script-examples/internal/null`
+)
+
+// tree makes a directory with the named files in it, and returns its path
+func tree(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Skipf("can't make a file named %q here: %v", name, err)
+		}
+	}
+	return dir
+}
+
+// TestFilesWhile checks that a name with a newline in it passes from Files
+// to While whole, as one record, and names the file that exists
+func TestFilesWhile(t *testing.T) {
+	dir := tree(t, "a\nb.txt", "plain.txt", "with space.txt", "sub/tab\there.log")
+
+	var got []string
+	var stdout, stderr bytes.Buffer
+	err := pipe.Pipeline(
+		// Shell: find "$dir" -type f -print0 | while IFS= read -r -d '' file; do ...; done
+		null.Files(dir, ""),
+		null.While(func(args ...any) gloo.Command {
+			got = append(got, args[0].(string))
+			return nil
+		}),
+	).Executor()(context.Background(), strings.NewReader(""), &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("pipeline: %v\n%s", err, stderr.String())
+	}
+
+	want := []string{
+		filepath.Join(dir, "a\nb.txt"),
+		filepath.Join(dir, "plain.txt"),
+		filepath.Join(dir, "sub", "tab\there.log"),
+		filepath.Join(dir, "with space.txt"),
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Fatalf("While got %q, want %q", got, want)
+	}
+	for _, path := range got {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("the name passed to While doesn't name the file: %v", err)
+		}
+	}
+}
+
+// TestFilesPattern checks that Files matches the pattern against the base
+// name, newline and all
+func TestFilesPattern(t *testing.T) {
+	dir := tree(t, "a\nb.txt", "c.log")
+
+	var stdout, stderr bytes.Buffer
+	err := null.Files(dir, "*.txt").Executor()(context.Background(), strings.NewReader(""), &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "a\nb.txt") + "\x00"; stdout.String() != want {
+		t.Errorf("Files wrote %q, want %q", stdout.String(), want)
+	}
+}
+
+// TestWhileFinalRecord checks that a last record without a terminator is
+// still a record, and that an empty input has none
+func TestWhileFinalRecord(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", nil},
+		{"a\nb\x00", []string{"a\nb"}},
+		{"a\nb\x00c", []string{"a\nb", "c"}},
+		{"a\x00\x00b\x00", []string{"a", "", "b"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		var stdout, stderr bytes.Buffer
+		err := null.While(func(args ...any) gloo.Command {
+			got = append(got, args[0].(string))
+			return nil
+		}).Executor()(context.Background(), strings.NewReader(tt.input), &stdout, &stderr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("While over %q got %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestQuote checks that a name with a control character in it is quoted
+// onto one line and one tab-separated field, and that others are left as
+// they are
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"plain.txt", "plain.txt"},
+		{"with space.txt", "with space.txt"},
+		{"dir/naïve.txt", "dir/naïve.txt"},
+		{`back\slash "quoted"`, `back\slash "quoted"`},
+		{"a\nb.txt", `"a\nb.txt"`},
+		{"tab\there", `"tab\there"`},
+		{"cr\rlf", `"cr\rlf"`},
+		{"bell\a", `"bell\a"`},
+	}
+	for _, tt := range tests {
+		got := null.Quote(tt.name)
+		if got != tt.want {
+			t.Errorf("Quote(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if strings.ContainsAny(got, "\n\t\r") {
+			t.Errorf("Quote(%q) = %q: not one line and one field", tt.name, got)
+		}
+	}
+
+	// In a report line, the quoted name keeps the report one line per file
+	report := "12\t" + null.Quote("dir/a\nb.txt") + "\n"
+	if n := strings.Count(report, "\n"); n != 1 {
+		t.Errorf("report %q has %d lines, want 1", report, n)
+	}
+	if fields := strings.Split(strings.TrimSuffix(report, "\n"), "\t"); len(fields) != 2 {
+		t.Errorf("report %q has %d fields, want 2", report, len(fields))
+	}
+}
@@ -0,0 +1,20 @@
+// Package num formats numbers the way awk prints them, for the examples
+// that stand in for an awk computation (stats, groupby, cumsum, ...).
+package num
+
+import (
+	"math"
+	"strconv"
+)
+
+// Format prints whole numbers without a decimal point and others with six
+// significant digits, as awk's print does
+//
+// Shell equivalent:
+//   awk 'BEGIN {print 10/3, 6/2}'   # 3.33333 3
+func Format(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
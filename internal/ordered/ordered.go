@@ -0,0 +1,127 @@
+// Package ordered runs a slow function on every line of a stream with a
+// pool of workers, and hands the results on in the order of the lines.
+//
+// Shell equivalent:
+//   nl | xargs -P "${JOBS}" ... | sort -n | cut -f2-
+//
+// A file to hash, a name to look up or a URL to fetch is mostly waiting, so
+// the examples that do one per line (pgrep, index, incr-hash, rdns,
+// fetch-process, pmap-lines) run several at once. The workers finish in
+// whatever order they finish; Map numbers each line as it reads it, and
+// holds the results that come back early in a reorder buffer until the
+// ones before them have been handed on, so the output is the same for any
+// number of workers.
+package ordered
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync"
+)
+
+// Map reads the lines of r, runs fn on each, jobs lines at a time, and calls
+// emit with each result in the order of the lines.
+//
+// Three parts run at once. The reader numbers each line and hands it to the
+// workers; the workers run fn a line at a time and send its result back,
+// tagged with the line's number, on one shared channel; the caller's
+// goroutine keeps the results that arrive early in a map by number, and
+// calls emit while the map holds the number due next. A slow line holds up
+// the emits after it, but not the work: the other workers go on to the next
+// lines.
+//
+// The reader takes a slot for each line and emit gives it back, so at most
+// 2 × jobs lines are between them, and memory stays bounded however long
+// the input is. emit runs on the caller's goroutine, one result at a time,
+// so it may keep counts without locking; fn runs on the workers, so it must
+// be safe to call concurrently.
+//
+// If emit returns an error, Map cancels the context passed to fn, stops
+// reading, and returns that error. Otherwise it returns once every line has
+// been emitted, with the error, if any, from reading r, or from ctx if it
+// was cancelled.
+func Map[T any](ctx context.Context, r io.Reader, jobs int, fn func(ctx context.Context, line string) T, emit func(T) error) error {
+	type job struct {
+		seq  int
+		line string
+	}
+	type result struct {
+		seq int
+		v   T
+	}
+
+	// Stops the reader, the workers and whatever fn is waiting on, if emit
+	// fails
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	work := make(chan job)
+	results := make(chan result, jobs)
+	slots := make(chan struct{}, 2*jobs)
+
+	// Workers: one line at a time until the reader is done; once they all
+	// are, no more results can come
+	var workers sync.WaitGroup
+	for range jobs {
+		workers.Go(func() {
+			for j := range work {
+				select {
+				case results <- result{j.seq, fn(ctx, j.line)}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		})
+	}
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	// Reader: number the lines, taking a slot for each
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(work)
+		scanner := bufio.NewScanner(r)
+		for seq := 0; scanner.Scan(); seq++ {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				readErr <- ctx.Err()
+				return
+			}
+			select {
+			case work <- job{seq, scanner.Text()}:
+			case <-ctx.Done():
+				readErr <- ctx.Err()
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	// Reorder buffer: hold each result until the ones before it are emitted
+	pending := map[int]T{}
+	next := 0
+	for r := range results {
+		pending[r.seq] = r.v
+		for {
+			v, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-slots
+			if err := emit(v); err != nil {
+				return err
+			}
+		}
+	}
+	if err := <-readErr; err != nil {
+		return err
+	}
+	// Cancelled from outside: the workers may have dropped results
+	return ctx.Err()
+}
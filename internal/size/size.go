@@ -0,0 +1,83 @@
+// Package size formats byte counts for the examples' --human output.
+package size
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// units are the binary prefixes used by `ls -h` and `du -h`
+var units = []string{"K", "M", "G", "T", "P", "E"}
+
+// Format returns n in human-readable binary units, like `ls -lh`
+//
+// Shell equivalent:
+//   numfmt --to=iec 1536   # 1.5K
+//
+// Sizes under 1024 bytes print as "512B"; larger ones use one decimal place
+// of the largest unit that keeps the value under 1024: "1.5K", "3.2G".
+func Format(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := int64(unit), 0
+	for q := n / unit; q >= unit; q /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%s", float64(n)/float64(div), units[exp])
+}
+
+// Parse reads a human size like "100MB", "1.5G", "512k" or "4096"
+//
+// Shell equivalent:
+//   numfmt --from=iec 100M
+//
+// Suffixes are binary multiples (K = 1024) and case-insensitive; a trailing
+// "B", or "iB" after a unit, is optional, so "100M", "100MB" and "100MiB"
+// are all the same. A bare number is a count of bytes. The number is plain
+// decimal digits, with an optional fraction: exponents, signs, "inf" and
+// "nan" are rejected, and so is a size that doesn't fit in an int64.
+func Parse(s string) (int64, error) {
+	invalid := fmt.Errorf("invalid size %q", s)
+
+	text := strings.ToUpper(strings.TrimSpace(s))
+	text = strings.TrimSuffix(text, "B")
+
+	multiplier := int64(1)
+	for i, u := range units {
+		if rest, ok := strings.CutSuffix(strings.TrimSuffix(text, "I"), u); ok {
+			multiplier = int64(1) << (10 * (i + 1))
+			text = rest
+			break
+		}
+	}
+
+	m := number.FindStringSubmatch(text)
+	if m == nil {
+		return 0, invalid
+	}
+	whole, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil || whole > math.MaxInt64/multiplier {
+		return 0, invalid
+	}
+	n := whole * multiplier
+	if m[2] != "" {
+		// The fraction of a unit, in whole bytes: "1.5K" is 1536
+		frac, _ := strconv.ParseFloat("0."+m[2], 64)
+		extra := int64(frac * float64(multiplier))
+		if n > math.MaxInt64-extra {
+			return 0, invalid
+		}
+		n += extra
+	}
+	return n, nil
+}
+
+// number is the numeric part of a size: digits, then an optional fraction
+var number = regexp.MustCompile(`^([0-9]+)(?:\.([0-9]+))?$`)
@@ -0,0 +1,146 @@
+package size
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"0", 0},
+		{"4096", 4096},
+		{"512B", 512},
+		{"512b", 512},
+		{"1K", 1 << 10},
+		{"1k", 1 << 10},
+		{"1KB", 1 << 10},
+		{"1KiB", 1 << 10},
+		{"1kib", 1 << 10},
+		{"100M", 100 << 20},
+		{"100MB", 100 << 20},
+		{"100MiB", 100 << 20},
+		{"3G", 3 << 30},
+		{"2T", 2 << 40},
+		{"1P", 1 << 50},
+		{"1E", 1 << 60},
+		{" 10M ", 10 << 20},
+
+		// Fractions of a unit, in whole bytes
+		{"1.5K", 1536},
+		{"0.5M", 512 << 10},
+		{"1.25G", 5 << 28},
+		{"1.0009K", 1024},
+		{"7.", -1},
+		{".5K", -1},
+
+		// The largest size that fits, and the first that doesn't
+		{"9223372036854775807", math.MaxInt64},
+		{"9223372036854775808", -1},
+		{"7E", 7 << 60},
+		{"8E", -1},
+		{"7.99999999999999999999E", -1},
+		{"99999999999999999999K", -1},
+
+		// Not plain decimal numbers
+		{"", -1},
+		{"B", -1},
+		{"K", -1},
+		{"iB", -1},
+		{"1i", -1},
+		{"-1", -1},
+		{"-1K", -1},
+		{"+1K", -1},
+		{"1e3", -1},
+		{"1E3", -1},
+		{"1e3K", -1},
+		{"0x10", -1},
+		{"1_000", -1},
+		{"inf", -1},
+		{"Inf", -1},
+		{"infinity", -1},
+		{"nan", -1},
+		{"NaN", -1},
+		{"1 K", -1},
+		{"1KK", -1},
+		{"1Z", -1},
+		{"ten", -1},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		switch {
+		case tt.want < 0 && err == nil:
+			t.Errorf("Parse(%q) = %d, want an error", tt.in, got)
+		case tt.want >= 0 && err != nil:
+			t.Errorf("Parse(%q): %v, want %d", tt.in, err, tt.want)
+		case tt.want >= 0 && got != tt.want:
+			t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		in   int64
+		want string
+	}{
+		{0, "0B"},
+		{512, "512B"},
+		{1023, "1023B"},
+		{1024, "1.0K"},
+		{1536, "1.5K"},
+		{1<<20 - 1, "1024.0K"},
+		{1 << 20, "1.0M"},
+		{100 << 20, "100.0M"},
+		{3435973837, "3.2G"},
+		{1 << 40, "1.0T"},
+		{1 << 50, "1.0P"},
+		{math.MaxInt64, "8.0E"},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.in); got != tt.want {
+			t.Errorf("Format(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestRoundTrip checks that what Format prints, Parse reads back as a size
+// Format prints the same way: exactly for sizes a unit and one decimal can
+// hold, and otherwise to within Format's rounding
+func TestRoundTrip(t *testing.T) {
+	sizes := []int64{0, 1, 512, 1023, 1024, 1536, 4096, 10 << 20, 5 << 28, 1 << 40, 3 << 50, 7 << 60}
+	for n := int64(1); n < math.MaxInt64/3; n = n*3 + 1 {
+		sizes = append(sizes, n)
+	}
+	for _, n := range sizes {
+		text := Format(n)
+		back, err := Parse(text)
+		if err != nil {
+			t.Errorf("Parse(Format(%d) = %q): %v", n, text, err)
+			continue
+		}
+		if again := Format(back); again != text {
+			t.Errorf("Format(Parse(%q)) = %q, want %q", text, again, text)
+		}
+		if n < 1024 && back != n {
+			t.Errorf("Parse(Format(%d) = %q) = %d", n, text, back)
+		}
+	}
+
+	// A size that is a whole number of tenths of a unit comes back exactly
+	for _, n := range []int64{1536, 5 << 19, 1<<30 + 1<<29, 5 << 39} {
+		if back, _ := Parse(Format(n)); back != n {
+			t.Errorf("Parse(Format(%d) = %q) = %d, want %d", n, Format(n), back, n)
+		}
+	}
+
+	// So does every bare count of bytes
+	for n := int64(0); n < 1024; n++ {
+		if back, err := Parse(strconv.FormatInt(n, 10)); err != nil || back != n {
+			t.Errorf("Parse(%d) = %d, %v", n, back, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Package status is the exit status convention shared by the examples that
+// look for something (grep-count, grep-only, file-stats, ...). It is grep's:
+//   0  something was found
+//   1  nothing was found
+//   2  an error stopped the run (usage errors too; see flags.Set.Fail)
+//
+// so the examples work in `if` tests and `&&`/`||` chains like the tools
+// they stand in for:
+//   grep-count ERROR app.log > /dev/null && echo "errors logged"
+//
+// Shell equivalent:
+//   if grep -q ERROR app.log; then ...; fi
+package status
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// The exit statuses, as grep uses them
+const (
+	Found    = 0
+	NotFound = 1
+	Failed   = 2
+)
+
+// Result is the outcome of a run: how many things it found (matches, lines,
+// files, ...), and the error that stopped it, if any
+type Result struct {
+	Count int
+	Err   error
+}
+
+// Code returns the exit status for r: Failed if there was an error, else
+// Found or NotFound
+func (r Result) Code() int {
+	switch {
+	case r.Err != nil:
+		return Failed
+	case r.Count > 0:
+		return Found
+	}
+	return NotFound
+}
+
+// Exit reports r.Err on stderr, prefixed with the example's name, and exits
+// with r.Code(). It is meant to end main().
+func (r Result) Exit(name string) {
+	if r.Err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, Message(r.Err))
+	}
+	os.Exit(r.Code())
+}
+
+// pipelineStage matches the "command N: " a pipeline puts before the error
+// of one of its commands, once per pipeline it is nested in
+var pipelineStage = regexp.MustCompile(`^(command \d+: )+`)
+
+// Message returns the message of err without the pipelines' "command N: ",
+// which numbers a command the user never wrote; it is what an example
+// prints for an error from a pipeline
+//
+//   command 2: open data.txt: no such file or directory
+//   open data.txt: no such file or directory
+func Message(err error) string {
+	return pipelineStage.ReplaceAllString(err.Error(), "")
+}
@@ -0,0 +1,30 @@
+package status_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// TestMessage checks that the pipelines' "command N: " is dropped, however
+// deeply the pipelines were nested, and that the rest is left alone
+func TestMessage(t *testing.T) {
+	open := errors.New("open data.txt: no such file or directory")
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{open, "open data.txt: no such file or directory"},
+		{fmt.Errorf("command 2: %w", open), "open data.txt: no such file or directory"},
+		{fmt.Errorf("command 1: command 12: %w", open), "open data.txt: no such file or directory"},
+		{errors.New("line 3: command 2: not a stage"), "line 3: command 2: not a stage"},
+		{errors.New("command not found"), "command not found"},
+	}
+	for _, tt := range tests {
+		if got := status.Message(tt.err); got != tt.want {
+			t.Errorf("Message(%q) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
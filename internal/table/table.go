@@ -0,0 +1,45 @@
+// Package table lays out rows of cells as aligned text columns.
+//
+// Shell equivalent:
+//   column -t -s $'\t'
+//
+// Every column is padded to its widest cell, columns are two spaces apart,
+// and trailing padding is trimmed. Widths count characters (runes), so UTF-8
+// cells line up.
+package table
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Gap separates adjacent columns.
+const Gap = "  "
+
+// Align returns one aligned line per row. Rows may have different lengths;
+// missing cells are treated as empty.
+func Align(rows [][]string) []string {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	lines := make([]string, len(rows))
+	for r, row := range rows {
+		var b strings.Builder
+		for i, cell := range row {
+			if i > 0 {
+				b.WriteString(Gap)
+			}
+			b.WriteString(cell)
+			b.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+		}
+		lines[r] = strings.TrimRight(b.String(), " ")
+	}
+	return lines
+}
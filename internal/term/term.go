@@ -0,0 +1,79 @@
+// Package term reports on the terminal the examples write to: whether
+// output goes to one, and its size, with fallbacks for when it doesn't.
+//
+// It wraps golang.org/x/term, which asks the terminal driver (the
+// TIOCGWINSZ ioctl on Unix, the console API on Windows).
+package term
+
+import (
+	"os"
+	"strconv"
+
+	xterm `golang.org/x/term`
+)
+
+// DefaultWidth and DefaultHeight are the size assumed when it can't be
+// detected.
+const (
+	DefaultWidth  = 80
+	DefaultHeight = 24
+)
+
+// IsTerminal reports whether f is a terminal rather than a pipe or file.
+//
+// Shell equivalent:
+//   [ -t 1 ]
+func IsTerminal(f *os.File) bool {
+	return xterm.IsTerminal(int(f.Fd()))
+}
+
+// Width returns the width of the terminal on stdout. When stdout isn't a
+// terminal (or the platform can't tell), it falls back to $COLUMNS, then to
+// DefaultWidth.
+//
+// Shell equivalent:
+//   tput cols
+func Width() int {
+	return WidthOf(os.Stdout)
+}
+
+// WidthOf returns the width of the terminal f is, with the same fallbacks
+// as Width, for output that goes somewhere other than stdout.
+//
+// Shell equivalent:
+//   stty size <&2 | cut -d' ' -f2
+func WidthOf(f *os.File) int {
+	if width, _, ok := size(f); ok {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return DefaultWidth
+}
+
+// Height returns the height of the terminal on stdout, in rows, falling
+// back to $LINES, then to DefaultHeight, as Width does.
+//
+// Shell equivalent:
+//   tput lines
+func Height() int {
+	if _, height, ok := size(os.Stdout); ok {
+		return height
+	}
+	if height, err := strconv.Atoi(os.Getenv("LINES")); err == nil && height > 0 {
+		return height
+	}
+	return DefaultHeight
+}
+
+// size returns the size of the terminal f is, in columns and rows; ok is
+// false when f isn't a terminal, or its size is unknown
+// Shell: stty size
+func size(f *os.File) (cols, rows int, ok bool) {
+	cols, rows, err := xterm.GetSize(int(f.Fd()))
+	if err != nil || cols <= 0 || rows <= 0 {
+		return 0, 0, false
+	}
+	return cols, rows, true
+}
@@ -0,0 +1,52 @@
+// Package welford keeps the running mean and standard deviation of a stream
+// of numbers in one pass, for the statistics examples (stats, zscore).
+//
+// Shell equivalent:
+//   awk '{n++; d = $1 - mean; mean += d / n; m2 += d * ($1 - mean)}'
+//
+// Welford's method: rather than summing the squares and subtracting the
+// squared mean at the end, which cancels catastrophically for large, close
+// values, it keeps the squared differences from the mean as it moves. Each
+// number is used once and forgotten, so memory is the same for ten numbers
+// or ten billion.
+package welford
+
+import "math"
+
+// Stats holds the running statistics of the numbers added so far; the zero
+// value is empty and ready to use
+type Stats struct {
+	n    int
+	mean float64
+	m2   float64 // sum of squared differences from the running mean
+}
+
+// Add updates the statistics with one more number
+//
+// Shell equivalent:
+//   n++; d = v - mean; mean += d / n; m2 += d * (v - mean)
+func (s *Stats) Add(v float64) {
+	s.n++
+	d := v - s.mean
+	s.mean += d / float64(s.n)
+	s.m2 += d * (v - s.mean)
+}
+
+// N returns how many numbers have been added
+func (s *Stats) N() int {
+	return s.n
+}
+
+// Mean returns the mean of the numbers added, or 0 if there are none
+func (s *Stats) Mean() float64 {
+	return s.mean
+}
+
+// StdDev returns the sample standard deviation, dividing by n-1; it is NaN
+// until there are two numbers
+func (s *Stats) StdDev() float64 {
+	if s.n < 2 {
+		return math.NaN()
+	}
+	return math.Sqrt(s.m2 / float64(s.n-1))
+}
@@ -0,0 +1,46 @@
+#!/bin/bash
+
+# Collect JSON Lines into a single JSON array, the inverse of json-explode
+# yupsh equivalent: See main.go
+#
+# This runs jq once per line to validate it, which is slow on large inputs;
+# `jq -s .` is fast, but reads every value into memory, reformats them and
+# gives up at the first invalid line instead of skipping it.
+
+# Read the named files, or stdin when there are none, one line at a time
+# yupsh: input.Source(opts.Args()...) | collect(&skipped)
+printf '['
+VALUES=0
+SKIPPED=0
+LINE_NUM=0
+while IFS= read -r line || [[ -n ${line} ]]; do
+  LINE_NUM=$((LINE_NUM + 1))
+
+  # Trim surrounding blanks, and skip blank lines
+  # yupsh: value := bytes.TrimSpace(line)
+  line=${line#"${line%%[![:space:]]*}"}
+  line=${line%"${line##*[![:space:]]}"}
+  [[ -z ${line} ]] && continue
+
+  # Report and count invalid lines
+  # yupsh: if !json.Valid(value) { ...; *skipped++ }
+  if ! jq . > /dev/null 2>&1 <<< "${line}"; then
+    echo "json-collect: line ${LINE_NUM}: invalid JSON, skipped" >&2
+    SKIPPED=$((SKIPPED + 1))
+    continue
+  fi
+
+  # A comma between values, and one value per line
+  # yupsh: out.WriteString(","); out.WriteString("\n"); out.Write(value)
+  (( VALUES > 0 )) && printf ','
+  printf '\n%s' "${line}"
+  VALUES=$((VALUES + 1))
+done < <(cat "$@")
+(( VALUES > 0 )) && printf '\n'
+printf ']\n'
+
+# yupsh: if skipped > 0 { ...; os.Exit(1) }
+if (( SKIPPED > 0 )); then
+  echo "json-collect: ${SKIPPED} invalid line(s) skipped" >&2
+  exit 1
+fi
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Collect JSON Lines into a single JSON array, the inverse of json-explode
+// Shell equivalent: See json-collect.sh
+//
+// The array is written as the lines are read: "[" first, then each value
+// with a comma before all but the first, then "]". Only the current line is
+// held in memory, however many there are, so it suits tools downstream
+// that want one array rather than a stream.
+//
+// Each line must hold one JSON value (usually an object, but any value
+// passes, so json-explode's output always collects back). Values are copied
+// as they were written, trimmed of surrounding blanks. Blank lines are
+// ignored; any other line that isn't valid JSON is reported with its line
+// number and skipped, so the array is still valid. Skipped lines make the
+// exit status 1.
+//
+// Usage: json-collect [file...]
+func main() {
+	opts := flags.New("json-collect", "[file...]")
+	opts.Parse()
+
+	var skipped int
+	err := gloo.Run(pipe.Pipeline(
+		// Report a missing file instead of silently producing nothing
+		// Shell: set -o pipefail
+		pipe.PipeFail,
+
+		// Read the named files, or stdin when there are none
+		// Shell: cat "$@"
+		input.Source(opts.Args()...),
+
+		// Wrap the valid lines into one array
+		// Shell: while read -r line; do ...; done
+		collect(&skipped),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "json-collect: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, "json-collect: %d invalid line(s) skipped\n", skipped)
+		os.Exit(1)
+	}
+}
+
+// collect returns the command that reads one JSON value per line from stdin
+// and writes them to stdout as a JSON array, counting the invalid lines it
+// skips in *skipped
+//
+// Shell equivalent:
+//   printf '['; while read -r line; do jq . <<< "$line" && printf ','; done; printf ']'
+//
+// Lines are read with ReadBytes instead of a bufio.Scanner, whose 64K limit
+// a single long JSON Lines record can pass.
+func collect(skipped *int) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		in := bufio.NewReader(stdin)
+		out := bufio.NewWriter(stdout)
+
+		// Shell: printf '['
+		out.WriteString("[")
+		values := 0
+		for lineNum := 1; ; lineNum++ {
+			line, err := in.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				return err
+			}
+
+			// Skip blank lines, and report and count invalid ones
+			// Shell: jq . <<< "$line" > /dev/null || echo "line N: invalid" >&2
+			if value := bytes.TrimSpace(line); len(value) > 0 {
+				if !json.Valid(value) {
+					fmt.Fprintf(stderr, "json-collect: line %d: invalid JSON, skipped\n", lineNum)
+					*skipped++
+				} else {
+					// A comma between values, and one value per line
+					// Shell: printf ',\n%s' "$line"
+					if values > 0 {
+						out.WriteString(",")
+					}
+					out.WriteString("\n")
+					out.Write(value)
+					values++
+				}
+			}
+
+			if err == io.EOF {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+
+		// Shell: printf '\n]\n'
+		if values > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString("]\n")
+		return out.Flush()
+	})
+}
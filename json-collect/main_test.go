@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// arrays are the JSON arrays the round trips start from: nested values, key
+// order, exact digits and awkward strings all come back as they went in
+var arrays = []string{
+	`[]`,
+	`[1]`,
+	`[{"b":1,"a":2},{"a":[1,2,{"c":null}]}]`,
+	`[1.50, 1e400, -0, 12345678901234567890]`,
+	`["a\nb", "tab\there", "é", "\"quoted\"", "comma, ]"]`,
+	`[true, false, null, "", [], {}]`,
+	`[
+	  {"name": "apple",  "size": 5},
+	  {"name": "banana", "size": 6}
+	]`,
+	`[` + strings.Repeat(`"x",`, 10000) + `{"long":"` + strings.Repeat("y", 100000) + `"}]`,
+}
+
+// TestExplodeCollect checks that an array exploded by json-explode and
+// collected back is the same array, once both are compacted
+//
+// Shell equivalent:
+//   json-explode a.json | json-collect
+func TestExplodeCollect(t *testing.T) {
+	explode := buildExplode(t)
+	for _, array := range arrays {
+		lines := run(t, explode, array)
+		var skipped int
+		got := collectString(t, lines, &skipped)
+		if skipped != 0 {
+			t.Errorf("%.40q: %d line(s) skipped", array, skipped)
+		}
+		if compact(t, got) != compact(t, array) {
+			t.Errorf("round trip of %.40q gave %.40q", array, got)
+		}
+	}
+}
+
+// TestCollectExplode checks the other way round: JSON Lines collected into
+// an array and exploded again are the same lines, blank lines dropped
+//
+// Shell equivalent:
+//   json-collect a.jsonl | json-explode
+func TestCollectExplode(t *testing.T) {
+	explode := buildExplode(t)
+	for _, array := range arrays {
+		lines := run(t, explode, array)
+		var skipped int
+		padded := strings.ReplaceAll(lines, "\n", "\n\n")
+		if got := run(t, explode, collectString(t, padded, &skipped)); got != lines {
+			t.Errorf("round trip of %.40q gave %.40q", lines, got)
+		}
+	}
+}
+
+// TestCollectSkipsInvalid checks that invalid lines are reported and
+// counted, and the array is still valid
+func TestCollectSkipsInvalid(t *testing.T) {
+	var skipped int
+	var stdout, stderr bytes.Buffer
+	in := "{\"a\":1}\nnot json\n\n  [1, 2]  \n{\"a\":\n"
+	if err := collect(&skipped).Executor()(context.Background(), strings.NewReader(in), &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if want := "[\n{\"a\":1},\n[1, 2]\n]\n"; stdout.String() != want {
+		t.Errorf("collected %q, want %q", stdout.String(), want)
+	}
+	if skipped != 2 {
+		t.Errorf("skipped = %d, want 2", skipped)
+	}
+	if want := "json-collect: line 2: invalid JSON, skipped\njson-collect: line 5: invalid JSON, skipped\n"; stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", stderr.String(), want)
+	}
+}
+
+// collectString runs collect() over lines and returns the array it wrote
+func collectString(t *testing.T, lines string, skipped *int) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	if err := collect(skipped).Executor()(context.Background(), strings.NewReader(lines), &stdout, &stderr); err != nil {
+		t.Fatalf("collect: %v\n%s", err, stderr.String())
+	}
+	if !json.Valid(stdout.Bytes()) {
+		t.Fatalf("collect wrote invalid JSON: %.80q", stdout.String())
+	}
+	return stdout.String()
+}
+
+// compact returns the JSON text s on one line, as json-explode prints it
+func compact(t *testing.T, s string) string {
+	t.Helper()
+	var b bytes.Buffer
+	if err := json.Compact(&b, []byte(s)); err != nil {
+		t.Fatalf("compact %.40q: %v", s, err)
+	}
+	return b.String()
+}
+
+// run runs the binary at path with stdin and returns its output
+func run(t *testing.T, path, stdin string) string {
+	t.Helper()
+	cmd := exec.Command(path)
+	cmd.Stdin = strings.NewReader(stdin)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("%s: %v", filepath.Base(path), err)
+	}
+	return string(out)
+}
+
+// buildExplode builds the json-explode example, whose output json-collect
+// collects, and returns the path of its binary
+func buildExplode(t *testing.T) string {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "json-explode")
+	cmd := exec.Command("go", "build", "-o", bin, ".")
+	cmd.Dir = filepath.Join("..", "json-explode")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("building the json-explode example: %v\n%s", err, out)
+	}
+	return bin
+}
@@ -0,0 +1,16 @@
+#!/bin/bash
+
+# Turn a JSON array into JSON Lines: one element per line
+# yupsh equivalent: See main.go
+#
+# jq parses each whole array into memory before printing its first element.
+# Its streaming mode keeps memory low like the Go version, but can't check
+# that the input is an array:
+#   jq -cn --stream 'fromstream(1 | truncate_stream(inputs))'
+# jq also rewrites numbers (1.0 becomes 1); the Go version copies them as is.
+
+# Read the named files, or stdin when there are none, and print each element
+# of each top-level array compactly, one per line
+# yupsh: input.Source(opts.Args()...) | explode()
+jq -c 'if type == "array" then .[]
+       else error("top-level value is \(type), not an array") end' "$@"
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Turn a JSON array into JSON Lines: one element per line
+// Shell equivalent: See json-explode.sh
+//
+// The array is read a token at a time with json.Decoder, and each element is
+// decoded and written out before the next is read, so only one element is
+// ever held in memory, however large the array. Elements are copied as raw
+// JSON (json.RawMessage) and only compacted onto one line: nested objects
+// and arrays, key order and the exact digits of numbers all come out as they
+// went in.
+//
+// Input follows the examples' convention (files, or stdin). Several arrays
+// one after another, as from `cat a.json b.json`, are exploded in turn. Any
+// top-level value that isn't an array is an error, since there are no
+// elements to split it into.
+//
+// Usage: json-explode [file...]
+func main() {
+	opts := flags.New("json-explode", "[file...]")
+	opts.Parse()
+
+	err := gloo.Run(pipe.Pipeline(
+		// Report a missing file instead of silently producing nothing
+		// Shell: set -o pipefail
+		pipe.PipeFail,
+
+		// Read the named files, or stdin when there are none
+		// Shell: cat "$@"
+		input.Source(opts.Args()...),
+
+		// One line per array element
+		// Shell: jq -c '.[]'
+		explode(),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "json-explode: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// explode returns the command that reads JSON arrays from stdin and writes
+// each of their elements to stdout as one compact line of JSON
+//
+// Shell equivalent:
+//   jq -c 'if type == "array" then .[] else error("not an array") end'
+//
+// Output is buffered, and flushed even when a later error stops the run, so
+// every element read before the error is written, as jq does.
+func explode() gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		out := bufio.NewWriter(stdout)
+		err := explodeArrays(ctx, json.NewDecoder(stdin), out)
+		if flushErr := out.Flush(); err == nil {
+			err = flushErr
+		}
+		return err
+	})
+}
+
+// explodeArrays writes the elements of each top-level array read by dec to
+// out, one per line
+//
+// dec.Token() reads the opening "[" of each array on its own; then, while
+// dec.More() says an element follows, dec.Decode() reads just that element.
+// Decoding the whole array at once, into a []json.RawMessage, would be
+// simpler, and hold the entire input in memory.
+func explodeArrays(ctx context.Context, dec *json.Decoder, out io.Writer) error {
+	var line bytes.Buffer
+	for arrays := 1; ; arrays++ {
+		// The next top-level value must start an array
+		// Shell: if type == "array" then ... else error(...) end
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if tok != json.Delim('[') {
+			return fmt.Errorf("top-level value %d is %s, not an array", arrays, describe(tok))
+		}
+
+		// Each element in turn, compacted onto one line
+		// Shell: .[] (with -c)
+		for n := 1; dec.More(); n++ {
+			var elem json.RawMessage
+			if err := dec.Decode(&elem); err != nil {
+				return fmt.Errorf("array %d, element %d: %w", arrays, n, err)
+			}
+			line.Reset()
+			if err := json.Compact(&line, elem); err != nil {
+				return fmt.Errorf("array %d, element %d: %w", arrays, n, err)
+			}
+			line.WriteByte('\n')
+			if _, err := out.Write(line.Bytes()); err != nil {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+
+		// The closing "]", or an array cut short
+		if _, err := dec.Token(); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("array %d: %w", arrays, err)
+		}
+	}
+}
+
+// describe names the JSON value a token starts, for the not-an-array error
+func describe(tok json.Token) string {
+	switch tok := tok.(type) {
+	case json.Delim:
+		return "an object" // "{": "]" and "}" can't start a value
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return fmt.Sprintf("%t", tok)
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%v", tok)
+}
@@ -0,0 +1,52 @@
+#!/bin/bash
+
+# Print the values at a dotted path in a JSON document
+# yupsh equivalent: See main.go
+#
+# jq has no missing-versus-null distinction for a path: .a.b is null both
+# when b is null and when it isn't there. Nulls are dropped here, so a
+# missing path prints nothing, as in the Go version, but so does a real
+# null, which the Go version prints as "null".
+
+# Parse flags (--path PATH, --json, --strict), then the files
+# yupsh: path := opts.String("path", ...); asJSON := opts.Bool("json", ...); strict := ...
+PATH_=
+RAW=-r
+STRICT=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --path) PATH_=$2; shift 2 ;;
+    --json) RAW=; shift ;;
+    --strict) STRICT=1; shift ;;
+    *) echo "Usage: $0 --path PATH [--json] [--strict] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Turn the dotted path into a jq filter, one step per stage: a step that
+# names nothing gives null, which is dropped at the end
+# yupsh: splitPath(*path), then walk() one step at a time
+FILTER=.
+IFS=. read -r -a STEPS <<< "${PATH_#.}"
+for step in "${STEPS[@]}"; do
+  if [[ ${step} == '*' ]]; then
+    # Every element, or every value sorted by key, as Go's map has no order
+    # yupsh: wildcard, every element or value
+    FILTER+='| if type == "object" then to_entries | sort_by(.key) | .[].value else .[]? end'
+  elif [[ ${step} =~ ^[0-9]+$ ]]; then
+    # An index into an array, or, on an object, a key of digits
+    # yupsh: strconv.Atoi(step) on a []any, v[step] on a map
+    FILTER+="| if type == \"array\" then .[${step}] elif type == \"object\" then .[\"${step}\"] else null end"
+  else
+    FILTER+="| if type == \"object\" then .[$(jq -n --arg k "${step}" '$k')] else null end"
+  fi
+done
+FILTER="${FILTER} | select(. != null)"
+
+# With --strict, a document where the path matches nothing is an error
+# yupsh: if len(matches) == 0 && strict { return fmt.Errorf(...) }
+if (( STRICT )); then
+  FILTER="[${FILTER}] | if length == 0 then error(\"nothing at path ${PATH_}\") else .[] end"
+fi
+
+# yupsh: extract(splitPath(*path), *asJSON, *strict)
+jq -c -S ${RAW} "${FILTER}" "$@"
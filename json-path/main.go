@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Print the values at a dotted path in a JSON document, a lightweight jq
+// Shell equivalent: See json-path.sh
+//
+//   json-path --path users.0.addresses.0.city users.json
+//   London
+//   json-path --path 'users.*.name' users.json
+//   Ada
+//   Grace
+//
+// The path is a list of steps separated by dots. On an object a step is a
+// key; on an array it is an index from 0; and "*" steps into every element
+// of an array, or every value of an object (sorted by key), so a path can
+// match many values. An empty path is the whole document.
+//
+// Each match is printed on its own line: strings as they are, like jq -r,
+// and every other value as compact JSON, numbers with the digits they were
+// written with and object keys sorted. With --json, strings are printed
+// quoted too, so every line is JSON.
+//
+// A path that matches nothing prints nothing, so the output of a missing
+// field is empty rather than "null". With --strict it is an error, and the
+// exit status 1. Several documents one after another, as from
+// `cat a.json b.json`, are each searched in turn.
+//
+// Keys containing dots can't be reached: the path has no quoting.
+//
+// Usage: json-path --path PATH [--json] [--strict] [file...]
+func main() {
+	opts := flags.New("json-path", "[file...]")
+	path := opts.String("path", "", "dotted `path` to extract, e.g. users.0.name or users.*.email")
+	asJSON := opts.Bool("json", false, "print strings as quoted JSON too")
+	strict := opts.Bool("strict", false, "fail when the path matches nothing")
+	opts.Parse()
+
+	err := gloo.Run(pipe.Pipeline(
+		// Report a missing file instead of silently producing nothing
+		// Shell: set -o pipefail
+		pipe.PipeFail,
+
+		// Read the named files, or stdin when there are none
+		// Shell: cat "$@"
+		input.Source(opts.Args()...),
+
+		// The values at the path, one per line
+		// Shell: jq -r '.users[0].name'
+		extract(splitPath(*path), *asJSON, *strict),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "json-path: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// wildcard is the path step that matches every element or value
+const wildcard = "*"
+
+// splitPath turns "users.0.name" into its steps; "" and "." are the empty
+// path, the whole document
+func splitPath(path string) []string {
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+// extract returns the command that reads JSON documents from stdin and
+// writes the values at path in each one to stdout, one per line
+//
+// Shell equivalent:
+//   jq -r '.users[].name'
+//
+// Output is buffered, and flushed even when a later error stops the run, so
+// the values found before a bad document are still written.
+func extract(path []string, asJSON, strict bool) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		out := bufio.NewWriter(stdout)
+		err := extractAll(json.NewDecoder(stdin), path, asJSON, strict, out)
+		if flushErr := out.Flush(); err == nil {
+			err = flushErr
+		}
+		return err
+	})
+}
+
+// extractAll decodes each document from dec and writes the values at path
+func extractAll(dec *json.Decoder, path []string, asJSON, strict bool, out io.Writer) error {
+	// Keep numbers as written ("1.50" stays "1.50") instead of float64
+	dec.UseNumber()
+
+	for docs := 1; ; docs++ {
+		var doc any
+		if err := dec.Decode(&doc); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("document %d: %w", docs, err)
+		}
+
+		var matches []any
+		walk(doc, path, func(v any) { matches = append(matches, v) })
+		if len(matches) == 0 && strict {
+			return fmt.Errorf("document %d: nothing at path %q", docs, strings.Join(path, "."))
+		}
+
+		for _, v := range matches {
+			line, err := format(v, asJSON)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(out, line); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// walk calls emit with each value reached by following path from v
+//
+// It recurses one step at a time: the first step picks the children of v
+// it names (none, one, or with "*" all of them), and the rest of the path
+// is followed from each. A step that names nothing, such as a missing key,
+// an index out of range or any step into a string, ends that branch
+// without a match.
+func walk(v any, path []string, emit func(any)) {
+	if len(path) == 0 {
+		emit(v)
+		return
+	}
+	step, rest := path[0], path[1:]
+
+	switch v := v.(type) {
+	case map[string]any:
+		if step == wildcard {
+			// Shell: .[] on an object, but sorted by key: a decoded map
+			// doesn't keep the document's order, which jq does
+			for _, key := range slices.Sorted(maps.Keys(v)) {
+				walk(v[key], rest, emit)
+			}
+			return
+		}
+		if child, ok := v[step]; ok {
+			walk(child, rest, emit)
+		}
+	case []any:
+		if step == wildcard {
+			for _, child := range v {
+				walk(child, rest, emit)
+			}
+			return
+		}
+		i, err := strconv.Atoi(step)
+		if err == nil && i >= 0 && i < len(v) {
+			walk(v[i], rest, emit)
+		}
+	}
+}
+
+// format prints a matched value: a string as it is (unless asJSON), and
+// anything else as compact JSON
+func format(v any, asJSON bool) (string, error) {
+	if s, ok := v.(string); ok && !asJSON {
+		return s, nil
+	}
+
+	// An Encoder rather than json.Marshal, so "<", ">" and "&" aren't
+	// escaped to \u003c and friends
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
@@ -0,0 +1,87 @@
+#!/bin/bash
+set -e
+
+# Check each record of a JSON Lines stream against a schema of required
+# fields and their types, passing the valid records on
+# yupsh equivalent: See main.go
+
+# Parse flags (--schema 'name:type,...', --lenient), then the files
+# yupsh: spec := opts.String("schema", "", ...); lenient := opts.Bool("lenient", false, ...)
+SCHEMA=
+LENIENT=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --schema) SCHEMA=$2; shift 2 ;;
+    --lenient) LENIENT=1; shift ;;
+    *) echo "Usage: $0 --schema 'name:type,...' [--lenient] [file...]" >&2; exit 2 ;;
+  esac
+done
+if [[ -z ${SCHEMA} ]]; then
+  echo "Usage: $0 --schema 'name:type,...' [--lenient] [file...]" >&2
+  exit 2
+fi
+
+# Number the lines, then have jq check each one: "ok", then the line as it
+# came in, or "bad", its number and its problems
+# yupsh: v.filter(), calling v.problems(line) on each non-blank line
+set +e
+cat "$@" | awk '{print NR "\t" $0}' | jq -R -r --arg schema "${SCHEMA}" '
+  # yupsh: parseSchema(*spec)
+  def schema: $schema | split(",") | map(
+    gsub("^\\s+|\\s+$"; "") | split(":") as [$name, $type]
+    | {name: ($name | rtrimstr("?")), type: $type, optional: ($name | endswith("?"))});
+
+  # yupsh: typeOf(value); jq parses every number as a float, so a whole one
+  # is taken as an integer, even written as 5.0
+  def kind: if type == "number" and . == floor then "integer" else type end;
+
+  # yupsh: fits(got, want)
+  def fits($want): kind as $got | $want == "any" or $got == $want or ($want == "number" and $got == "integer");
+
+  def article: if test("^[aeiou]") then "an" else "a" end;
+
+  # yupsh: v.problems(line)
+  def problems:
+    (try fromjson catch "not JSON") as $value
+    | if $value == "not JSON" then ["not JSON"]
+      elif ($value | type) != "object" then ["a JSON \($value | kind), not an object"]
+      else [schema[] as $f
+        | if ($value | has($f.name) | not) then
+            if $f.optional then empty else "missing field \"\($f.name)\"" end
+          elif ($value[$f.name] | fits($f.type)) then empty
+          else ($value[$f.name] | kind) as $got
+            | "field \"\($f.name)\" is \($got | article) \($got), want \($f.type)"
+          end]
+      end;
+
+  (index("\t")) as $tab
+  | .[:$tab] as $n | .[$tab + 1:] as $line
+  # Blank lines are skipped
+  | select($line | test("^\\s*$") | not)
+  | ($line | problems) as $p
+  | if $p == [] then "ok\t\($line)" else "bad\t\($n)\t\($p | join("; "))" end
+' | awk -v lenient="${LENIENT}" '
+  # yupsh: out.Write(line)
+  $1 == "ok" { records++; sub(/^ok\t/, ""); print; next }
+
+  # yupsh: fmt.Fprintf(stderr, "jsonl-schema: line %d: %s\n", lineNum, ...)
+  {
+    records++; failed++
+    split($0, f, "\t")
+    printf "jsonl-schema: line %d: %s\n", f[2], f[3] > "/dev/stderr"
+  }
+
+  END {
+    if (failed > 0) {
+      printf "jsonl-schema: %d of %d records failed\n", failed, records > "/dev/stderr"
+      if (!lenient) exit 1
+    }
+  }'
+STATUS=("${PIPESTATUS[@]}")
+set -e
+
+# yupsh: os.Exit(2) on trouble, 1 when a record failed
+if [[ ${STATUS[0]} -ne 0 || ${STATUS[2]} -ne 0 ]]; then
+  exit 2
+fi
+exit "${STATUS[3]}"
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Check each record of a JSON Lines stream against a schema of required
+// fields and their types, passing the valid records on
+// Shell equivalent: See jsonl-schema.sh
+//
+//   jsonl-schema --schema 'id:integer,name:string,email?:string' users.jsonl
+//   {"id": 1, "name": "Ada", "email": "ada@example.com"}
+//   jsonl-schema: line 2: missing field "name"
+//   jsonl-schema: line 3: field "id" is a string, want integer
+//   jsonl-schema: 2 of 3 records failed
+//
+// The schema is a comma-separated list of name:type. Every field listed
+// must be in every record, with a value of its type, unless its name ends
+// in ?, when it may be missing (but not of another type). Fields that
+// aren't listed are allowed, whatever they hold. The types are JSON's:
+//   string, number, integer (a number without a fraction or exponent),
+//   boolean, object, array, null, and any (present, whatever it is)
+//
+// Each line must be one JSON object; blank lines are skipped. A valid line
+// is written to stdout exactly as it came in. An invalid one is reported on
+// stderr with every reason it failed, and dropped.
+//
+// As a data-quality gate, the exit status is 0 if every record passed, 1 if
+// any failed, and 2 on trouble, as with csv-lint. --lenient exits 0 even
+// when records failed: they are still reported and dropped, but the
+// pipeline goes on.
+//
+// Usage: jsonl-schema --schema 'name:type,...' [--lenient] [file...]
+func main() {
+	opts := flags.New("jsonl-schema", "--schema 'name:type,...' [file...]")
+	spec := opts.String("schema", "", "the required fields and their types, as `name:type,...`")
+	lenient := opts.Bool("lenient", false, "exit 0 even when records fail")
+	opts.Parse()
+	if *spec == "" {
+		opts.Fail("missing --schema")
+	}
+	s, err := parseSchema(*spec)
+	if err != nil {
+		opts.Fail("--schema: %v", err)
+	}
+
+	v := &validator{schema: s}
+	err = gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+
+		// Read the named files, or stdin
+		// Shell: cat "$@"
+		input.Source(opts.Args()...),
+
+		// Pass on the records that fit the schema, report the others
+		// Shell: jq -c 'select(has("id") and (.id | type) == "number" ...)'
+		v.filter(),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jsonl-schema: %s\n", status.Message(err))
+		os.Exit(2)
+	}
+
+	if v.failed > 0 {
+		fmt.Fprintf(os.Stderr, "jsonl-schema: %d of %d records failed\n", v.failed, v.records)
+		if !*lenient {
+			os.Exit(1)
+		}
+	}
+}
+
+// types are the type names a schema may use
+var types = []string{"string", "number", "integer", "boolean", "object", "array", "null", "any"}
+
+// field is one entry of a schema
+type field struct {
+	name     string
+	typ      string
+	optional bool // the name ended in ?
+}
+
+// parseSchema reads a schema: "name:type" entries, separated by commas
+func parseSchema(spec string) ([]field, error) {
+	var schema []field
+	seen := map[string]bool{}
+	for _, entry := range strings.Split(spec, ",") {
+		name, typ, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%q is not name:type", entry)
+		}
+		if !slices.Contains(types, typ) {
+			return nil, fmt.Errorf("unknown type %q for %q; want one of %s", typ, name, strings.Join(types, ", "))
+		}
+		f := field{typ: typ}
+		f.name, f.optional = strings.CutSuffix(name, "?")
+		if seen[f.name] {
+			return nil, fmt.Errorf("field %q is listed twice", f.name)
+		}
+		seen[f.name] = true
+		schema = append(schema, f)
+	}
+	return schema, nil
+}
+
+// typeOf names the JSON type of a value decoded with UseNumber; a whole
+// json.Number is an integer, which is a number too
+func typeOf(v any) string {
+	switch v := v.(type) {
+	case string:
+		return "string"
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return "number"
+		}
+		return "integer"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	default:
+		return "null"
+	}
+}
+
+// fits reports whether a value of type got satisfies the schema type want
+func fits(got, want string) bool {
+	return want == "any" || got == want || (want == "number" && got == "integer")
+}
+
+// validator checks records against a schema, and counts them
+type validator struct {
+	schema  []field
+	records int // non-blank lines read
+	failed  int // records that didn't fit
+}
+
+// problems returns every way one line fails the schema; none means it fits
+//
+// Shell equivalent:
+//   jq -r '[if has("id") | not then "missing field \"id\"" else empty end, ...] | join("; ")'
+func (v *validator) problems(line []byte) []string {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return []string{fmt.Sprintf("not JSON: %v", err)}
+	}
+	if rest := bytes.TrimSpace(line[dec.InputOffset():]); len(rest) > 0 {
+		return []string{fmt.Sprintf("not JSON: %q after the value", rest[:min(len(rest), 10)])}
+	}
+	record, ok := value.(map[string]any)
+	if !ok {
+		return []string{fmt.Sprintf("a JSON %s, not an object", typeOf(value))}
+	}
+
+	var problems []string
+	for _, f := range v.schema {
+		value, ok := record[f.name]
+		if !ok {
+			if !f.optional {
+				problems = append(problems, fmt.Sprintf("missing field %q", f.name))
+			}
+			continue
+		}
+		if got := typeOf(value); !fits(got, f.typ) {
+			problems = append(problems, fmt.Sprintf("field %q is %s %s, want %s", f.name, article(got), got, f.typ))
+		}
+	}
+	return problems
+}
+
+// article returns "a" or "an" for a type name
+func article(typ string) string {
+	if strings.IndexByte("aeiou", typ[0]) >= 0 {
+		return "an"
+	}
+	return "a"
+}
+
+// filter returns the command that copies the records of stdin that fit
+// the schema to stdout, and reports each one that doesn't on stderr
+//
+// Shell equivalent:
+//   jq -c 'select(...)'
+func (v *validator) filter() gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		out := bufio.NewWriter(stdout)
+
+		scanner := bufio.NewScanner(stdin)
+		scanner.Buffer(nil, 1<<30) // a record can be a long line
+		for lineNum := 1; scanner.Scan(); lineNum++ {
+			line := scanner.Bytes()
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			v.records++
+
+			if problems := v.problems(line); len(problems) > 0 {
+				v.failed++
+				fmt.Fprintf(stderr, "jsonl-schema: line %d: %s\n", lineNum, strings.Join(problems, "; "))
+				continue
+			}
+			out.Write(line)
+			if err := out.WriteByte('\n'); err != nil {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return out.Flush()
+	})
+}
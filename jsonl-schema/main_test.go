@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// schema is the doc comment's: two required fields and an optional one
+const schema = "id:integer,name:string,email?:string"
+
+// TestProblems checks the reasons a record is reported: missing fields and
+// type mismatches, every one of them, in schema order
+func TestProblems(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		want   []string
+	}{
+		{"fits", `{"id": 1, "name": "Ada", "email": "ada@example.com"}`, nil},
+		{"optional field missing", `{"id": 1, "name": "Ada"}`, nil},
+		{"extra fields allowed", `{"id": 1, "name": "Ada", "admin": true, "tags": [1]}`, nil},
+
+		// Missing fields
+		{"missing name", `{"id": 2}`, []string{`missing field "name"`}},
+		{"missing both", `{}`, []string{`missing field "id"`, `missing field "name"`}},
+		{"name case matters", `{"id": 2, "Name": "Bo"}`, []string{`missing field "name"`}},
+
+		// Type mismatches
+		{"id a string", `{"id": "3", "name": "Cy"}`, []string{`field "id" is a string, want integer`}},
+		{"id a fraction", `{"id": 3.5, "name": "Cy"}`, []string{`field "id" is a number, want integer`}},
+		{"id an exponent", `{"id": 1e3, "name": "Cy"}`, []string{`field "id" is a number, want integer`}},
+		{"id null", `{"id": null, "name": "Cy"}`, []string{`field "id" is a null, want integer`}},
+		{"name an array", `{"id": 3, "name": ["Cy"]}`, []string{`field "name" is an array, want string`}},
+		{"name an object", `{"id": 3, "name": {"first": "Cy"}}`, []string{`field "name" is an object, want string`}},
+		{"name a boolean", `{"id": 3, "name": false}`, []string{`field "name" is a boolean, want string`}},
+		{"optional field of the wrong type", `{"id": 3, "name": "Cy", "email": 7}`, []string{`field "email" is an integer, want string`}},
+
+		// Both kinds at once
+		{"missing and mismatched", `{"id": "4", "email": null}`, []string{
+			`field "id" is a string, want integer`,
+			`missing field "name"`,
+			`field "email" is a null, want string`,
+		}},
+
+		// Not a record at all
+		{"an array", `[1, 2]`, []string{"a JSON array, not an object"}},
+		{"not JSON", `{"id": 1,`, []string{"not JSON: unexpected EOF"}},
+		{"two values", `{"id": 1, "name": "A"} {}`, []string{`not JSON: "{}" after the value`}},
+	}
+
+	s, err := parseSchema(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &validator{schema: s}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := v.problems([]byte(tt.record)); !slices.Equal(got, tt.want) {
+				t.Errorf("problems = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTypes checks each schema type against each kind of value
+func TestTypes(t *testing.T) {
+	values := map[string]string{
+		"string": `"s"`, "integer": `-7`, "number": `0.5`, "boolean": `true`,
+		"object": `{}`, "array": `[]`, "null": `null`,
+	}
+	fitsType := map[string][]string{
+		"string":  {"string"},
+		"number":  {"integer", "number"},
+		"integer": {"integer"},
+		"boolean": {"boolean"},
+		"object":  {"object"},
+		"array":   {"array"},
+		"null":    {"null"},
+		"any":     {"string", "integer", "number", "boolean", "object", "array", "null"},
+	}
+	for _, typ := range types {
+		s, err := parseSchema("v:" + typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v := &validator{schema: s}
+		for kind, value := range values {
+			fits := len(v.problems([]byte(`{"v": `+value+`}`))) == 0
+			if want := slices.Contains(fitsType[typ], kind); fits != want {
+				t.Errorf("%s value %s fits %s: %t, want %t", kind, value, typ, fits, want)
+			}
+		}
+	}
+}
+
+// TestFilter checks that only the records that fit are passed on, exactly
+// as they came in, that the others are reported with their line numbers,
+// and the counts
+func TestFilter(t *testing.T) {
+	in := `{"id": 1, "name": "Ada", "email": "ada@example.com"}
+{"id": 2}
+
+{"id": "3", "name": "Cy"}
+  {"name":"Di","id":4}  
+`
+	s, _ := parseSchema(schema)
+	v := &validator{schema: s}
+	var stdout, stderr bytes.Buffer
+	if err := v.filter().Executor()(context.Background(), strings.NewReader(in), &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "{\"id\": 1, \"name\": \"Ada\", \"email\": \"ada@example.com\"}\n  {\"name\":\"Di\",\"id\":4}  \n"; stdout.String() != want {
+		t.Errorf("passed on %q, want %q", stdout.String(), want)
+	}
+	want := "jsonl-schema: line 2: missing field \"name\"\n" +
+		"jsonl-schema: line 4: field \"id\" is a string, want integer\n"
+	if stderr.String() != want {
+		t.Errorf("reported %q, want %q", stderr.String(), want)
+	}
+	if v.records != 4 || v.failed != 2 {
+		t.Errorf("%d records, %d failed; want 4, 2", v.records, v.failed)
+	}
+}
+
+// TestParseSchema checks the schemas that are refused
+func TestParseSchema(t *testing.T) {
+	for _, spec := range []string{"", "id", ":string", "id:int", "id:string,", "id:string,id?:string"} {
+		if _, err := parseSchema(spec); err == nil {
+			t.Errorf("parseSchema(%q) accepted it", spec)
+		}
+	}
+}
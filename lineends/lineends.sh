@@ -0,0 +1,69 @@
+#!/bin/bash
+set -e
+
+# Convert line endings: CRLF to LF (dos2unix), or LF to CRLF (unix2dos)
+# yupsh equivalent: See main.go
+
+# Parse flags (--to-dos, --in-place), then the files
+# yupsh: toDOS := opts.Bool("to-dos", ...); inPlace := opts.Bool("in-place", ...)
+TO_DOS=
+IN_PLACE=
+while [[ $1 == --* ]]; do
+  case $1 in
+    --to-dos) TO_DOS=1; shift ;;
+    --in-place) IN_PLACE=1; shift ;;
+    *) echo "Usage: $0 [--to-dos] [--in-place] [file...]" >&2; exit 2 ;;
+  esac
+done
+if (( $# == 0 )); then
+  if [[ -n ${IN_PLACE} ]]; then
+    echo "$0: --in-place needs at least one file" >&2
+    exit 2
+  fi
+  set -- -
+fi
+
+# The sed program for the direction, and a grep counting the lines it changes
+# yupsh: convert(*toDOS, &changed)
+if [[ -n ${TO_DOS} ]]; then
+  SCRIPT=$'/\r$/!s/$/\r/'
+  COUNT=$'[^\r]$|^$'
+else
+  SCRIPT=$'s/\r$//'
+  COUNT=$'\r$'
+fi
+
+LINES=0
+CHANGED_FILES=0
+FAILED=
+for file in "$@"; do
+  if [[ ${file} != - && ! -r ${file} ]]; then
+    echo "lineends: ${file}: cannot read" >&2
+    FAILED=1
+    continue
+  fi
+
+  if [[ ${file} == - ]]; then
+    # yupsh: pipe.Pipeline(pipe.PipeFail, input.Source(file), convert(...))
+    tmp=$(mktemp)
+    cat > "${tmp}"
+    file=${tmp}
+    IN_PLACE= # stdin is always printed
+  fi
+
+  changed=$(grep -cE "${COUNT}" "${file}" || true)
+
+  if [[ -n ${IN_PLACE} ]]; then
+    # yupsh: convertInPlace(file, *toDOS)
+    (( changed > 0 )) && sed -i "${SCRIPT}" "${file}"
+  else
+    sed "${SCRIPT}" "${file}"
+  fi
+  [[ ${file} == "${tmp}" ]] && rm -f "${tmp}"
+
+  LINES=$(( LINES + changed ))
+  (( changed > 0 )) && CHANGED_FILES=$(( CHANGED_FILES + 1 ))
+done
+
+echo "lineends: converted ${LINES} line(s) in ${CHANGED_FILES} of $# file(s)" >&2
+[[ -z ${FAILED} ]]
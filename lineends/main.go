@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Convert line endings: CRLF to LF (dos2unix), or LF to CRLF (unix2dos)
+// Shell equivalent: See lineends.sh
+//
+// Without --in-place, the converted files are written to stdout (stdin with
+// no files). With --in-place, each file is rewritten through a temp file in
+// the same directory, which replaces the original only if a line changed, so
+// an interrupted run never leaves a half-written file and untouched files keep
+// their timestamps.
+//
+// A summary goes to stderr:
+//   lineends: converted 120 line(s) in 2 of 3 file(s)
+//
+// The conversion streams a line at a time, so large files are never fully
+// buffered. A lone "\r" (old Mac line ending) is left alone.
+//
+// Usage: lineends [--to-dos] [--in-place] [file...]
+func main() {
+	opts := flags.New("lineends", "[file...]")
+	toDOS := opts.Bool("to-dos", false, "convert LF to CRLF instead of CRLF to LF")
+	inPlace := opts.Bool("in-place", false, "rewrite the files instead of printing them")
+	opts.Parse()
+
+	files := opts.Args()
+	if len(files) == 0 {
+		if *inPlace {
+			opts.Fail("--in-place needs at least one file")
+		}
+		files = []string{input.Stdin}
+	}
+
+	var lines, changedFiles int
+	failed := false
+	for _, file := range files {
+		var changed int
+		var err error
+		if *inPlace {
+			// Shell: sed -i 's/\r$//' "$file"
+			changed, err = convertInPlace(file, *toDOS)
+		} else {
+			// Shell: sed 's/\r$//' "$file"
+			err = gloo.Run(pipe.Pipeline(pipe.PipeFail, input.Source(file), convert(*toDOS, &changed)))
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "lineends: %s\n", status.Message(err))
+			failed = true
+			continue
+		}
+
+		lines += changed
+		if changed > 0 {
+			changedFiles++
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "lineends: converted %d line(s) in %d of %d file(s)\n", lines, changedFiles, len(files))
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// convert copies stdin to stdout a line at a time, rewriting line endings,
+// and adds the number of lines it changed to *changed
+//
+// Shell equivalent:
+//   sed 's/\r$//'     # dos2unix
+//   sed 's/$/\r/'     # unix2dos (lines that don't already end in \r)
+func convert(toDOS bool, changed *int) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		r := bufio.NewReader(stdin)
+		w := bufio.NewWriter(stdout)
+		for {
+			line, readErr := r.ReadBytes('\n')
+			if bytes.HasSuffix(line, []byte("\n")) {
+				body := line[:len(line)-1]
+				hasCR := bytes.HasSuffix(body, []byte("\r"))
+				switch {
+				case toDOS && !hasCR:
+					line = append(body, '\r', '\n')
+					*changed++
+				case !toDOS && hasCR:
+					line = append(body[:len(body)-1], '\n')
+					*changed++
+				}
+			}
+
+			if _, err := w.Write(line); err != nil {
+				return err
+			}
+			if readErr == io.EOF {
+				return w.Flush()
+			}
+			if readErr != nil {
+				return readErr
+			}
+		}
+	})
+}
+
+// convertInPlace rewrites file through a temp file in the same directory and
+// returns the number of lines changed
+//
+// Shell equivalent:
+//   sed 's/\r$//' "$file" > "$tmp" && mv "$tmp" "$file"
+func convertInPlace(file string, toDOS bool) (int, error) {
+	src, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	// The same directory keeps the final rename on one filesystem
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	var changed int
+	exec := convert(toDOS, &changed).Executor()
+	if err := exec(context.Background(), src, tmp, os.Stderr); err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("%s: %w", file, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if changed == 0 {
+		return 0, nil // leave the original (and its mtime) untouched
+	}
+
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return 0, err
+	}
+	return changed, os.Rename(tmp.Name(), file)
+}
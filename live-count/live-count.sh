@@ -0,0 +1,77 @@
+#!/bin/bash
+set -eo pipefail
+
+# Count a key from each line of a stream, and keep a table of the most
+# common keys so far redrawn on stderr, while passing the lines through
+# yupsh equivalent: See main.go
+#
+# awk's clock is in whole seconds (srand() returns the time of the last
+# call), so the table is redrawn at most once a second, and only when a
+# line arrives; the Go version redraws every --interval from a goroutine.
+# awk's match() has no groups, so --match counts the whole match. Every
+# key is kept: there is no --keys bound.
+
+# Parse flags (--field N, --match REGEXP, --top N), then the files
+# yupsh: opts.Int("field", 1, ...); opts.String("match", ...); opts.Top()
+FIELD=1
+MATCH=
+TOP=10
+while [[ $1 == --* ]]; do
+  case $1 in
+    --field) FIELD=$2; shift 2 ;;
+    --match) MATCH=$2; shift 2 ;;
+    --top) TOP=$2; shift 2 ;;
+    *) echo "Usage: $0 [--field N | --match REGEXP] [--top N] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Redraw in place only on a terminal
+# yupsh: live: term.IsTerminal(os.Stderr)
+LIVE=
+[[ -t 2 ]] && LIVE=1
+
+# Print the lines, count their keys, and draw the table on stderr
+# yupsh: passCount(d, keyOf), d.draw() and d.finish()
+cat "$@" | awk -v field="${FIELD}" -v re="${MATCH}" -v top="${TOP}" -v live="${LIVE}" '
+  # The table: a header, then the top keys, most common first, equal
+  # counts by key
+  # yupsh: d.render(), with d.counts.top(d.top)
+  function render(   i, k, best, shown, rows, out) {
+    if (live && drawn) printf "\033[%dF\033[J", drawn > "/dev/stderr"
+    out = sprintf("live-count: %d lines, %d keys\n", NR, keys)
+    rows = 1
+    for (k in n) taken[k] = 0
+    for (i = 1; i <= top && i <= keys; i++) {
+      best = ""
+      for (k in n)
+        if (!taken[k] && (best == "" || n[k] > n[best] || (n[k] == n[best] && k < best)))
+          best = k
+      taken[best] = 1
+      out = out sprintf("%7d %s\n", n[best], best)
+      rows++
+    }
+    printf "%s", out > "/dev/stderr"
+    fflush("/dev/stderr")
+    drawn = rows
+    dirty = 0
+  }
+  {
+    print
+    fflush()
+    # yupsh: keyOf(line) - fieldKey(n) or matchKey(re)
+    key = ""; ok = 0
+    if (re != "") {
+      if (match($0, re)) { key = substr($0, RSTART, RLENGTH); ok = 1 }
+    } else if (NF >= field) {
+      key = $field; ok = 1
+    }
+    if (ok && !(key in n)) keys++
+    if (ok) n[key]++
+    dirty = 1
+    # yupsh: the ticker, every --interval
+    if (live) {
+      srand(); now = srand()
+      if (now > last) { render(); last = now }
+    }
+  }
+  END { if (dirty || !live || !drawn) render() }'
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"container/heap"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	term `github.com/yupsh/script-examples/internal/term`
+)
+
+// Count a key from each line of a stream, and keep a table of the most
+// common keys so far redrawn on stderr, while passing the lines through
+// Shell equivalent: See live-count.sh
+//
+//   tail -f access.log | live-count --field 9 > /dev/null
+//   live-count: 48213 lines, 7 keys
+//     41877 200
+//      3310 304
+//      2204 404
+//       ...
+//
+// It is a live `awk '{print $9}' | sort | uniq -c | sort -nr | head`: the
+// table is redrawn in place every --interval, so the counts can be watched
+// as the log grows, and the lines themselves go on to stdout unchanged, so
+// live-count can sit in the middle of a pipeline:
+//   tail -f access.log | live-count --match 'GET (/[^ ?]*)' | grep ' 5.. '
+//
+// The key of a line is its --field'th blank-separated field, as awk's $N,
+// or with --match the first group of a regexp (or the whole match, if it
+// has no group). A line without one, too short or not matching, is passed
+// through but not counted.
+//
+// At most --keys distinct keys are tracked, so a stream of endless new
+// keys (IDs, client ports) can't use up memory. Past that, the least
+// common key is replaced by each new one, which starts from that key's
+// count: the Space-Saving algorithm. The counts can then be too high, by at
+// most the count of the key replaced, and the header says by how much; a
+// key common enough to make the table is never dropped from it.
+//
+// The table is only redrawn when stderr is a terminal; otherwise, as into
+// a file, it is printed once, at the end. Ctrl-C also prints it, and exits
+// 130, so `tail -f` can be stopped to see the final counts.
+//
+// Usage: live-count [--field N | --match REGEXP] [--top N] [--keys N] [--interval DURATION] [file...]
+func main() {
+	opts := flags.New("live-count", "[file...]")
+	field := opts.Int("field", 1, "count the `N`th blank-separated field of each line")
+	match := opts.String("match", "", "count the first group (or the match) of `regexp` instead")
+	top := opts.Top()
+	keys := opts.Int("keys", 1000, "track at most `N` distinct keys")
+	interval := opts.Duration("interval", 250*time.Millisecond, "redraw the table every `duration`")
+	opts.Parse()
+	if *field < 1 {
+		opts.Fail("--field must be at least 1")
+	}
+	if *keys < *top {
+		opts.Fail("--keys must be at least --top")
+	}
+	if *interval <= 0 {
+		opts.Fail("--interval must be positive")
+	}
+
+	keyOf := fieldKey(*field)
+	if *match != "" {
+		re, err := regexp.Compile(*match)
+		if err != nil {
+			opts.Fail("invalid pattern: %v", err)
+		}
+		keyOf = matchKey(re)
+	}
+
+	d := &dashboard{
+		counts: newCounter(*keys),
+		top:    *top,
+		out:    os.Stderr,
+		live:   term.IsTerminal(os.Stderr),
+		width:  term.WidthOf(os.Stderr),
+	}
+
+	// Redraw every --interval, from a goroutine of its own, so a quiet
+	// stream still shows its last lines counted
+	// Shell: awk's clock, checked on each line
+	stop := make(chan struct{})
+	var redraws sync.WaitGroup
+	if d.live {
+		redraws.Go(func() {
+			ticker := time.NewTicker(*interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					d.draw()
+				case <-stop:
+					return
+				}
+			}
+		})
+	}
+
+	// Ctrl-C: show the final counts before exiting, as a shell reports a
+	// command killed by SIGINT
+	// Shell: trap 'show; exit 130' INT
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		d.finish()
+		os.Exit(130)
+	}()
+
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		passCount(d, keyOf),
+	))
+	close(stop)
+	redraws.Wait()
+	d.finish()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "live-count: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// fieldKey returns the key function taking the nth blank-separated field
+// Shell: awk '{print $N}'
+func fieldKey(n int) func(string) (string, bool) {
+	return func(line string) (string, bool) {
+		fields := strings.Fields(line)
+		if n > len(fields) {
+			return "", false
+		}
+		return fields[n-1], true
+	}
+}
+
+// matchKey returns the key function taking the first group of re, or its
+// whole match if it has no group
+// Shell: grep -oP 'GET \K/[^ ?]*'
+func matchKey(re *regexp.Regexp) func(string) (string, bool) {
+	return func(line string) (string, bool) {
+		m := re.FindStringSubmatch(line)
+		if m == nil {
+			return "", false
+		}
+		if len(m) > 1 {
+			return m[1], true
+		}
+		return m[0], true
+	}
+}
+
+// passCount returns the command that copies stdin to stdout, counting the
+// key of each line in d as it goes
+//
+// Shell equivalent:
+//   awk '{print; n[$9]++}'
+//
+// Each line is written as soon as it is read, unbuffered, so the lines
+// reach the next command as live as the table is.
+func passCount(d *dashboard, keyOf func(string) (string, bool)) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		scanner := bufio.NewScanner(stdin)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if _, err := fmt.Fprintln(stdout, line); err != nil {
+				return err
+			}
+			key, ok := keyOf(line)
+			d.add(key, ok)
+		}
+		return scanner.Err()
+	})
+}
+
+// entry is a tracked key and its count
+type entry struct {
+	key   string
+	count int
+	index int // position in the heap
+}
+
+// counter counts up to capacity keys; past that, a new key replaces the
+// least common one. It is a min-heap by count, so that one is at the root.
+type counter struct {
+	entries  []*entry
+	byKey    map[string]*entry
+	capacity int
+	replaced bool // a key has been replaced, so counts may be over
+}
+
+func newCounter(capacity int) *counter {
+	return &counter{byKey: map[string]*entry{}, capacity: capacity}
+}
+
+// add counts one more of key
+//
+// Shell equivalent:
+//   n[key]++
+func (c *counter) add(key string) {
+	if e, ok := c.byKey[key]; ok {
+		e.count++
+		heap.Fix(c, e.index)
+		return
+	}
+	if len(c.entries) < c.capacity {
+		heap.Push(c, &entry{key: key, count: 1})
+		return
+	}
+
+	// Full: the new key takes the place, and the count, of the least
+	// common one, which may have been it, evicted earlier
+	e := c.entries[0]
+	delete(c.byKey, e.key)
+	e.key = key
+	e.count++
+	c.byKey[key] = e
+	c.replaced = true
+	heap.Fix(c, 0)
+}
+
+// top returns the n most common keys, the most common first and equal
+// counts by key, as sort | uniq -c | sort -k1,1nr -k2 would
+func (c *counter) top(n int) []entry {
+	all := make([]entry, len(c.entries))
+	for i, e := range c.entries {
+		all[i] = *e
+	}
+	slices.SortFunc(all, func(a, b entry) int {
+		return cmp.Or(cmp.Compare(b.count, a.count), strings.Compare(a.key, b.key))
+	})
+	return all[:min(n, len(all))]
+}
+
+// maxOver returns how much any count may be over: the count of the least
+// common key, the most a new key can inherit
+func (c *counter) maxOver() int {
+	if !c.replaced {
+		return 0
+	}
+	return c.entries[0].count
+}
+
+func (c *counter) Len() int { return len(c.entries) }
+
+func (c *counter) Less(i, j int) bool { return c.entries[i].count < c.entries[j].count }
+
+func (c *counter) Swap(i, j int) {
+	c.entries[i], c.entries[j] = c.entries[j], c.entries[i]
+	c.entries[i].index, c.entries[j].index = i, j
+}
+
+func (c *counter) Push(x any) {
+	e := x.(*entry)
+	e.index = len(c.entries)
+	c.entries = append(c.entries, e)
+	c.byKey[e.key] = e
+}
+
+func (c *counter) Pop() any {
+	e := c.entries[len(c.entries)-1]
+	c.entries = c.entries[:len(c.entries)-1]
+	delete(c.byKey, e.key)
+	return e
+}
+
+// dashboard is the table on stderr: the counts, shared by the reader and
+// the redraws, and what is on the screen
+type dashboard struct {
+	mu     sync.Mutex // guards everything below
+	counts *counter
+	lines  int  // lines read, counted or not
+	dirty  bool // counted since the last draw
+	drawn  int  // rows of the table on the screen, to go back over
+	done   bool // the final table has been printed
+
+	top   int      // rows of keys (--top)
+	out   *os.File // stderr
+	live  bool     // stderr is a terminal: redraw in place
+	width int      // of the terminal, to keep each row on one
+}
+
+// add counts a line, and its key if it has one
+func (d *dashboard) add(key string, ok bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lines++
+	if ok {
+		d.counts.add(key)
+	}
+	d.dirty = true
+}
+
+// draw redraws the table in place, if anything was counted since the last
+// time
+func (d *dashboard) draw() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.dirty && !d.done {
+		d.render()
+	}
+}
+
+// finish prints the table a last time: over the live one, or, when stderr
+// isn't a terminal, the only time. It is called on Ctrl-C too, so the
+// first call wins.
+func (d *dashboard) finish() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.done && (d.dirty || !d.live || d.drawn == 0) {
+		d.render()
+	}
+	d.done = true
+}
+
+// render writes the table, first moving back up over the previous one and
+// clearing it, when live
+//
+// Shell equivalent:
+//   printf '\e[%dA\e[J' "$rows"; sort -nr counts | head
+//
+// The cursor goes back up as many rows as were drawn, to the start of the
+// line ("\x1b[nF"), and everything below is cleared ("\x1b[J") before the
+// new table is written. A row wider than the terminal would wrap and throw
+// the count off, so each is cut to fit.
+func (d *dashboard) render() {
+	var b strings.Builder
+	if d.live && d.drawn > 0 {
+		fmt.Fprintf(&b, "\x1b[%dF\x1b[J", d.drawn)
+	}
+
+	rows := []string{fmt.Sprintf("live-count: %d lines, %d keys", d.lines, len(d.counts.entries))}
+	if over := d.counts.maxOver(); over > 0 {
+		rows[0] += fmt.Sprintf(" (%d tracked; counts may be up to %d over)", d.counts.capacity, over)
+	}
+	for _, e := range d.counts.top(d.top) {
+		rows = append(rows, fmt.Sprintf("%7d %s", e.count, e.key))
+	}
+	for _, row := range rows {
+		if d.live {
+			row = cut(row, d.width-1)
+		}
+		b.WriteString(row + "\n")
+	}
+
+	d.out.WriteString(b.String())
+	d.drawn = len(rows)
+	d.dirty = false
+}
+
+// cut shortens s to at most n characters
+func cut(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -1,14 +1,16 @@
-module github.com/yupsh/scripts/log-processor
+module github.com/yupsh/script-examples/log-processor
 
 go 1.25
 
 require (
 	github.com/gloo-foo/framework v0.0.3
 	github.com/gloo-foo/pipe v0.0.3
-	github.com/yupsh/cat v0.0.3
 	github.com/yupsh/echo v0.0.3
 	github.com/yupsh/grep v0.0.3
 	github.com/yupsh/ls v0.0.3
+	github.com/yupsh/script-examples/internal v0.0.0
 	github.com/yupsh/tee v0.0.3
 	github.com/yupsh/while v0.0.4
 )
+
+replace github.com/yupsh/script-examples/internal => ../internal
@@ -1,15 +1,24 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 
-	cat `github.com/yupsh/cat`
 	echo `github.com/yupsh/echo`
 	gloo `github.com/gloo-foo/framework`
 	grep `github.com/yupsh/grep`
 	ls `github.com/yupsh/ls`
 	pipe `github.com/gloo-foo/pipe`
+	dryrun `github.com/yupsh/script-examples/internal/dryrun`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
 	tee `github.com/yupsh/tee`
 	. `github.com/yupsh/while`
 )
@@ -23,101 +32,282 @@ import (
 // Key pattern: Shell's "while read" loops become While() commands in yupsh.
 // Each While() receives a callback function that processes one line (or set
 // of fields) at a time.
+//
+// Input: log files named on the command line are processed as one stream,
+// and so is stdin when it is piped in ("-" selects stdin explicitly).
+// With neither, every logs/*.log file is processed; if logs/ is missing or
+// holds no .log file, log-processor says so and exits 2, as the examples do
+// for a missing directory (see input.Dir), rather than succeeding without
+// doing anything.
+//
+// Each file in logs/ is processed on its own: one that can't be read is
+// reported and the others are still processed. Once all have been, the
+// files that failed are listed, with their errors, on stderr, and
+// log-processor exits 1; the rows from the other files are kept.
+//
+// Rows are appended to results.csv, and echoed to stdout. --dry-run follows
+// the examples' dry-run convention (see internal/dryrun): results.csv is left
+// alone, and each row that would have been appended is printed instead,
+// prefixed with "[dry-run]".
+//
+// Fields are split on --delim, following the examples' flag convention (see
+// flags.Set.Delim). The default, " ", splits on runs of blanks, like awk, so
+// columns aligned with several spaces still give the timestamp and level.
+// Any other separator splits on each occurrence, like cut: --delim , for
+// CSV logs, --delim '\t' for tab-separated ones. An empty field is then a
+// field, so in "ts,,ERROR" the level is "".
+//
+// Usage: log-processor [--dry-run] [--delim SEP] [file...]
 func main() {
-	// Main pipeline: List log files and process each one
-	// Shell: ls -1 logs/*.log | while read -r file; do ... done
-	err := gloo.Run(pipe.Pipeline(
+	opts := flags.New("log-processor", "[file...]")
+	dryRun := opts.DryRun()
+	delim := opts.Delim(" ")
+	opts.Parse()
+
+	// Log data given directly: process it as a single stream
+	// Shell: if [ $# -gt 0 ] || [ ! -t 0 ]; then cat "$@" | grep ... ; fi
+	if files := opts.Args(); len(files) > 0 || input.Piped() {
+		run(pipe.Pipeline(
+			// Report a missing file instead of silently producing nothing
+			// Shell: set -o pipefail
+			pipe.PipeFail,
+
+			// Read the named files, or stdin when there are none
+			// Shell: cat "$@"
+			input.Source(files...),
+
+			// Same error/warning extraction as for each file in logs/
+			extractEntries(*dryRun, *delim),
+		))
+		return
+	}
+
+	// Stop with a clear message when there is nothing to process
+	// Shell: [[ -d logs ]] && compgen -G 'logs/*.log' || exit 2
+	if err := checkLogDir(logDir); err != nil {
+		status.Result{Err: err}.Exit("log-processor")
+	}
+
+	// Main pipeline: List log files and process each one, collecting the
+	// errors instead of stopping at the first
+	// Shell: while IFS= read -r file; do ... done < <(ls -1 logs/*.log)
+	failures := &fileErrors{}
+	run(pipe.Pipeline(
 		// List all .log files in logs/ directory
 		// Shell: ls -1 logs/*.log
-		ls.Ls("logs/*.log"),
+		ls.Ls(filepath.Join(logDir, logPattern)),
 
 		// For each filename (one per line), call processLogFile()
-		// Shell: while read -r file; do ... done
-		// The While() command reads each line and passes it as args[0]
-		While(processLogFile),
+		// Shell: while IFS= read -r file; do ... done
+		// input.WholeLine passes the whole line as args[0]; without it the
+		// line is split on blanks, and "my app.log" becomes "my", "app.log"
+		While(processLogFile(*dryRun, *delim, failures), input.WholeLine),
 	))
 
+	// Report the files that failed, now that the others are done
+	// Shell: if (( ${#FAILED[@]} )); then printf '  %s\n' "${FAILED[@]}"; exit 1; fi
+	failures.exit()
+}
+
+// logDir and logPattern select the files processed when no input is given
+const (
+	logDir     = "logs"
+	logPattern = "*.log"
+)
+
+// checkLogDir reports an error unless dir is a directory holding at least
+// one file matching logPattern
+//
+// ls.Ls() only warns about a pattern that matches nothing, and still
+// succeeds, so this is checked up front.
+func checkLogDir(dir string) error {
+	err := input.Dir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s: no such directory; name log files as arguments, or pipe them in", dir)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "log-processor: %v\n", err)
+		return err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, logPattern))
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("%s: no %s files", dir, logPattern)
+	}
+	return nil
+}
+
+// run executes the pipeline and exits on failure
+func run(cmd gloo.Command) {
+	if err := gloo.Run(cmd); err != nil {
+		fmt.Fprintf(os.Stderr, "log-processor: %s\n", status.Message(err))
 		os.Exit(1)
 	}
 }
 
-// processLogLine extracts timestamp and level from each matching log line
+// fileErrors collects the log files that failed, so that one bad file
+// doesn't stop the others from being processed
+type fileErrors struct {
+	files  int // files processed, failed or not
+	failed []fileError
+}
+
+// fileError is a log file that failed, and why
+type fileError struct {
+	path string
+	err  error
+}
+
+// capture returns a command that runs cmd, the pipeline for the log file at
+// path, and records its error instead of returning it, so the While() loop
+// over the files goes on to the next one
+//
+// Only a cancelled context is still returned: then there is no next file.
+func (e *fileErrors) capture(path string, cmd gloo.Command) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		e.files++
+		err := cmd.Executor()(ctx, stdin, stdout, stderr)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			e.failed = append(e.failed, fileError{path, err})
+		}
+		return nil
+	})
+}
+
+// exit lists the files that failed, with their errors, and exits 1; it
+// returns if none did
+func (e *fileErrors) exit() {
+	if len(e.failed) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "log-processor: %d of %d file(s) failed:\n", len(e.failed), e.files)
+	for _, f := range e.failed {
+		fmt.Fprintf(os.Stderr, "  %s: %s\n", f.path, reason(f.path, f.err))
+	}
+	os.Exit(1)
+}
+
+// reason returns the message of err, the error of the log file at path,
+// without the pipelines' "command N: " (see status.Message) and a leading
+// path, which the list already shows
+func reason(path string, err error) string {
+	return strings.TrimPrefix(status.Message(err), path+": ")
+}
+
+// processLogLine returns the While() callback that extracts timestamp and
+// level from each matching log line
 //
 // Shell equivalent:
-//   timestamp=$(echo "$line" | cut -d' ' -f1)
-//   level=$(echo "$line" | cut -d' ' -f2)
-//   echo "${timestamp},${level}" >> results.csv
 //
-// yupsh pattern: When FieldSeparator(" ") is specified in While(), each line
-// is automatically split on whitespace. The fields are passed as separate
-// arguments to this function:
-//   args[0] = first field (timestamp)
-//   args[1] = second field (level)
-//   args[2..n] = remaining fields (if any)
+//	timestamp=$(echo "$line" | awk -F"${DELIM}" '{print $1}')
+//	level=$(echo "$line" | awk -F"${DELIM}" '{print $2}')
+//	echo "${timestamp},${level}" >> results.csv
+//
+// yupsh pattern: When a FieldSeparator is specified in While() (here
+// input.Fields(delim)), each line is automatically split into fields. The
+// fields are passed as separate arguments to this function:
+//
+//	args[0] = first field (timestamp)
+//	args[1] = second field (level)
+//	args[2..n] = remaining fields (if any)
 //
 // This eliminates the need for manual field extraction with cut/awk.
-func processLogLine(args ...any) gloo.Command {
-	// Extract the fields we need
-	if len(args) < 2 {
-		return nil // Skip malformed lines (safety check)
-	}
-	timestamp := args[0].(string)
-	level := args[1].(string)
+func processLogLine(dryRun bool) Body {
+	return func(args ...any) gloo.Command {
+		// Extract the fields we need
+		if len(args) < 2 {
+			return nil // Skip malformed lines (safety check)
+		}
+		timestamp := args[0].(string)
+		level := args[1].(string)
 
-	return pipe.Pipeline(
-		// Format as CSV: timestamp,level
-		// Shell: echo "${timestamp},${level}"
-		echo.Echo(fmt.Sprintf("%s,%s", timestamp, level)),
-
-		// Append to results.csv
-		// Shell: >> results.csv
-		// tee.Append makes it append instead of overwrite
-		tee.Tee("results.csv", tee.Append),
-	)
+		return pipe.Pipeline(
+			// Format as CSV: timestamp,level
+			// Shell: echo "${timestamp},${level}"
+			echo.Echo(fmt.Sprintf("%s,%s", timestamp, level)),
+
+			// Append to results.csv
+			// Shell: >> results.csv
+			// tee.Append makes it append instead of overwrite; with
+			// --dry-run, dryrun.Write() prints "[dry-run] row" instead
+			dryrun.Write(dryRun, tee.Tee("results.csv", tee.Append)),
+		)
+	}
 }
 
-// processLogFile reads a log file and extracts errors/warnings
+// processLogFile returns the While() callback that reads a log file and
+// extracts errors/warnings
 //
 // Shell equivalent:
-//   grep -i "error\|warning" "${file}" | while read -r line; do
-//     timestamp=$(echo "$line" | cut -d' ' -f1)
-//     level=$(echo "$line" | cut -d' ' -f2)
-//     echo "${timestamp},${level}" >> results.csv
-//   done
+//
+//	grep -i "error\|warning" "${file}" | while read -r line; do
+//	  timestamp=$(echo "$line" | awk -F"${DELIM}" '{print $1}')
+//	  level=$(echo "$line" | awk -F"${DELIM}" '{print $2}')
+//	  echo "${timestamp},${level}" >> results.csv
+//	done
 //
 // This function is called once per filename from the outer While() loop.
-// It creates a nested pipeline to process each file.
-func processLogFile(args ...any) gloo.Command {
-	// args[0] is the filename from ls.Ls() output
-	// Shell: while read -r file; do ... "${file}" ... done
-	filename := args[0].(string)
-	filepath := "logs/" + filename
+// It creates a nested pipeline to process each file, and hands it to
+// failures.capture(), so an error is recorded against the file rather than
+// ending the loop.
+func processLogFile(dryRun bool, delim string, failures *fileErrors) Body {
+	return func(args ...any) gloo.Command {
+		// args[0] is the filename from ls.Ls() output: ls prints the base
+		// name of each match, unquoted, one per line
+		// Shell: while IFS= read -r file; do ... "${file}" ... done
+		filename := args[0].(string)
+		path := filepath.Join(logDir, filename)
 
-	// Print progress to stderr (won't interfere with pipeline)
-	// Shell: echo "Processing ${file}"
-	fmt.Fprintf(os.Stderr, "Processing %s\n", filepath)
+		// Print progress to stderr (won't interfere with pipeline)
+		// Shell: echo "Processing ${file}"
+		fmt.Fprintf(os.Stderr, "Processing %s\n", path)
 
-	return pipe.Pipeline(
-		// Read the file contents
-		// Shell: (implicit - grep reads the file)
-		cat.Cat(filepath),
+		return failures.capture(path, pipe.Pipeline(
+			// Fail the file when it can't be read; without PipeFail only an
+			// error at the end of the pipeline would count
+			// Shell: grep's exit status 2, from ${PIPESTATUS[0]}
+			pipe.PipeFail,
+
+			// Read the file contents
+			// Shell: (implicit - grep reads the file)
+			input.Source(path),
+
+			// Extract errors/warnings into results.csv
+			extractEntries(dryRun, delim),
+		))
+	}
+}
 
+// extractEntries filters log lines for errors/warnings and appends them to
+// results.csv (or, with dryRun, prints the rows it would append)
+//
+// Shell equivalent:
+//
+//	grep -i "error\|warning" | while read -r line; do ... done
+//
+// It is shared by the per-file loop and the stdin/arguments mode, so both
+// produce identical CSV rows.
+func extractEntries(dryRun bool, delim string) gloo.Command {
+	return pipe.Pipeline(
 		// Filter for lines containing "error" or "warning" (case insensitive)
 		// Shell: grep -i "error\|warning" "${file}"
 		// Note: yupsh uses "|" for regex alternation instead of "\|"
 		grep.Grep("error|warning", grep.IgnoreCase),
 
-		// For each matching line, split on whitespace and extract fields
+		// For each matching line, split on --delim and extract fields
 		// Shell: while read -r line; do
-		//          timestamp=$(echo "$line" | cut -d' ' -f1)
-		//          level=$(echo "$line" | cut -d' ' -f2)
+		//          timestamp=$(echo "$line" | awk -F"${DELIM}" '{print $1}')
+		//          level=$(echo "$line" | awk -F"${DELIM}" '{print $2}')
 		//        done
 		//
-		// yupsh: FieldSeparator(" ") automatically splits each line on spaces
-		// and passes the fields as separate args to processLogLine()
-		While(processLogLine, FieldSeparator(" ")),
+		// yupsh: input.Fields(delim) is the FieldSeparator for --delim: it
+		// splits each line on runs of blanks for " ", or on every delim
+		// otherwise, and passes the fields as separate args to processLogLine()
+		While(processLogLine(dryRun), input.Fields(delim)),
 	)
 }
-
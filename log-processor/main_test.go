@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ls `github.com/yupsh/ls`
+	pipe `github.com/gloo-foo/pipe`
+	input `github.com/yupsh/script-examples/internal/input`
+	. `github.com/yupsh/while`
+)
+
+// TestSpaceInLogName checks that a log file in logs/ whose name has a space
+// in it is read whole by processLogFile(), and its entries extracted, rather
+// than being split into "my" and "app.log"
+func TestSpaceInLogName(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		dryRun bool
+		stdout string // what the rows are echoed as
+	}{
+		{"append", false, "2024-01-01T10:00:00,ERROR\n2024-01-01T10:02:00,WARNING\n2024-01-02T08:00:00,error\n"},
+		{"dry-run", true, "[dry-run] 2024-01-01T10:00:00,ERROR\n[dry-run] 2024-01-01T10:02:00,WARNING\n[dry-run] 2024-01-02T08:00:00,error\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// Shell: mkdir logs; printf ... > 'logs/my app.log'
+			t.Chdir(t.TempDir())
+			writeLog(t, "my app.log",
+				"2024-01-01T10:00:00 ERROR disk full\n"+
+					"2024-01-01T10:01:00 INFO started\n"+
+					"2024-01-01T10:02:00   WARNING slow reply\n")
+			writeLog(t, "other.log", "2024-01-02T08:00:00 error lower case\n")
+
+			// The pipeline main() runs over logs/
+			failures := &fileErrors{}
+			var stdout, stderr bytes.Buffer
+			err := pipe.Pipeline(
+				ls.Ls(filepath.Join(logDir, logPattern)),
+				While(processLogFile(tt.dryRun, " ", failures), input.WholeLine),
+			).Executor()(context.Background(), strings.NewReader(""), &stdout, &stderr)
+			if err != nil {
+				t.Fatalf("pipeline: %v\n%s", err, stderr.String())
+			}
+
+			if failures.files != 2 || len(failures.failed) != 0 {
+				t.Fatalf("processed %d file(s), %d failed: %v", failures.files, len(failures.failed), failures.failed)
+			}
+			if stdout.String() != tt.stdout {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.stdout)
+			}
+
+			csv, err := os.ReadFile("results.csv")
+			switch {
+			case tt.dryRun && err == nil:
+				t.Errorf("--dry-run wrote results.csv: %q", csv)
+			case !tt.dryRun && err != nil:
+				t.Fatal(err)
+			case !tt.dryRun && string(csv) != tt.stdout:
+				t.Errorf("results.csv = %q, want %q", csv, tt.stdout)
+			}
+		})
+	}
+}
+
+// TestExtractEntries checks the rows extractEntries() makes of log lines
+// given directly, for the default and a CSV --delim
+func TestExtractEntries(t *testing.T) {
+	tests := []struct {
+		name, delim, input, want string
+	}{
+		{"blanks", " ", "t1 ERROR x\nt2 INFO y\nt3\tWarning z\n", "[dry-run] t1,ERROR\n[dry-run] t3,Warning\n"},
+		{"csv", ",", "t1,ERROR,x\nt2,,warning\nt3,INFO,y\n", "[dry-run] t1,ERROR\n[dry-run] t2,\n"},
+		{"no matches", " ", "t1 INFO x\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			var stdout, stderr bytes.Buffer
+			err := extractEntries(true, tt.delim).Executor()(context.Background(), strings.NewReader(tt.input), &stdout, &stderr)
+			if err != nil {
+				t.Fatalf("extractEntries: %v\n%s", err, stderr.String())
+			}
+			if stdout.String() != tt.want {
+				t.Errorf("rows = %q, want %q", stdout.String(), tt.want)
+			}
+		})
+	}
+}
+
+// writeLog writes a file named name, with text, into logs/
+func writeLog(t *testing.T, name, text string) {
+	t.Helper()
+	if err := os.MkdirAll(logDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(logDir, name), []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
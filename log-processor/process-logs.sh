@@ -4,10 +4,74 @@ set -e
 # Process log files to extract errors and warnings
 # yupsh equivalent: See main.go
 
+# --dry-run prints the rows instead of appending them to results.csv;
+# --delim SEP splits fields on SEP (" ", the default, on runs of blanks)
+# yupsh: dryRun := opts.DryRun(); delim := opts.Delim(" ")
+DRY_RUN=0
+DELIM=" "
+while [[ $1 == --* ]]; do
+  case $1 in
+    --dry-run) DRY_RUN=1; shift ;;
+    --delim) DELIM=$2; shift 2 ;;
+    *) echo "Usage: $0 [--dry-run] [--delim SEP] [file...]" >&2; exit 2 ;;
+  esac
+done
+# awk reads -F '\t' as a tab, and -F ' ' as runs of blanks, as Delim does
+if [[ ${DELIM} != '\t' && ${#DELIM} -ne 1 ]]; then
+  echo "Usage: $0 [--dry-run] [--delim SEP] [file...]" >&2
+  exit 2
+fi
+
+# Append one CSV row to results.csv, or print it marked "[dry-run]"
+# yupsh: dryrun.Write(dryRun, tee.Tee("results.csv", tee.Append))
+write_row() {
+  if (( DRY_RUN )); then
+    echo "[dry-run] $1"
+  else
+    echo "$1" >> results.csv
+  fi
+}
+
+# Log data given directly (file arguments or piped stdin): one stream
+# yupsh: if len(opts.Args()) > 0 || input.Piped() { input.Source(files...) ... }
+if [[ $# -gt 0 ]] || [[ ! -t 0 ]]; then
+  set -o pipefail
+  cat "$@" \
+  | grep -i "error\|warning" \
+  | while IFS= read -r line; do
+    timestamp=$(echo "${line}" | awk -F"${DELIM}" '{print $1}')
+    level=$(echo "${line}" | awk -F"${DELIM}" '{print $2}')
+    write_row "${timestamp},${level}"
+  done
+  exit
+fi
+
+# Stop with a clear message when there is nothing to process
+# yupsh: if err := checkLogDir(logDir); err != nil { status.Result{Err: err}.Exit(...) }
+if [[ ! -e logs ]]; then
+  echo "log-processor: logs: no such directory; name log files as arguments, or pipe them in" >&2
+  exit 2
+elif [[ ! -d logs ]]; then
+  echo "log-processor: logs: not a directory" >&2
+  exit 2
+fi
+if ! compgen -G 'logs/*.log' > /dev/null; then
+  echo "log-processor: logs: no *.log files" >&2
+  exit 2
+fi
+
 # List all .log files in logs/ directory
-# yupsh: ls.Ls("logs/*.log")
-ls -1 logs/*.log \
-| while read -r file; do
+# yupsh: ls.Ls(filepath.Join(logDir, logPattern))
+# ls quotes names containing spaces only on a terminal; through a pipe it
+# prints them as they are, and IFS= read -r keeps each line whole. The loop
+# reads the listing through process substitution rather than a pipe, so it
+# runs in this shell, and FAILED is still set when it ends.
+# yupsh: While(processLogFile(dryRun, delim, failures), input.WholeLine)
+FAILED=()
+FILES=0
+ERRORS=$(mktemp)
+trap 'rm -f "${ERRORS}"' EXIT
+while IFS= read -r file; do
   # For each file, process it
   # yupsh: While(processLogFile)
 
@@ -16,23 +80,39 @@ ls -1 logs/*.log \
   fi
 
   echo "Processing ${file}"
+  FILES=$(( FILES + 1 ))
 
   # Read file and filter for errors/warnings (case insensitive)
-  # yupsh: cat.Cat(filepath), grep.Grep("error|warning", grep.IgnoreCase)
-  grep -i "error\|warning" "${file}" \
-  | while read -r line; do
+  # yupsh: input.Source(path), grep.Grep("error|warning", grep.IgnoreCase)
+  grep -i "error\|warning" "${file}" 2> "${ERRORS}" \
+  | while IFS= read -r line; do
     # For each matching line, extract fields
-    # yupsh: While(processLogLine, FieldSeparator(" "))
-    #        This automatically splits the line on whitespace into args
+    # yupsh: While(processLogLine(dryRun), input.Fields(delim))
+    #        This automatically splits the line on --delim into args
 
     # Extract timestamp (1st field) and level (2nd field)
     # yupsh: args[0].(string) and args[1].(string)
-    timestamp=$(echo "${line}" | cut -d' ' -f1)
-    level=$(echo "${line}" | cut -d' ' -f2)
+    timestamp=$(echo "${line}" | awk -F"${DELIM}" '{print $1}')
+    level=$(echo "${line}" | awk -F"${DELIM}" '{print $2}')
 
     # Write CSV output to file
     # yupsh: echo.Echo(fmt.Sprintf("%s,%s", timestamp, level))
     #        tee.Tee("results.csv", tee.Append)
-    echo "${timestamp},${level}" >> results.csv
+    write_row "${timestamp},${level}"
   done
-done
+
+  # grep exits 1 when nothing matches, and 2 when the file can't be read:
+  # record the error, and go on to the next file
+  # yupsh: failures.capture(path, pipe.Pipeline(pipe.PipeFail, ...))
+  if (( PIPESTATUS[0] > 1 )); then
+    FAILED+=("${file}: $(sed "s|^grep: ${file}: ||" "${ERRORS}")")
+  fi
+done < <(ls -1 logs/*.log)
+
+# Report the files that failed, now that the others are done
+# yupsh: failures.exit()
+if (( ${#FAILED[@]} )); then
+  echo "log-processor: ${#FAILED[@]} of ${FILES} file(s) failed:" >&2
+  printf '  %s\n' "${FAILED[@]}" >&2
+  exit 1
+fi
@@ -0,0 +1,41 @@
+#!/bin/bash
+set -e
+
+# Merge log files from several servers into one stream, in time order
+# yupsh equivalent: See main.go
+#
+# sort -m compares text, not times, so this version only merges correctly
+# when every file writes the same ISO 8601 format in the same zone
+# (2026-10-14T09:00:01Z). The Go version parses each timestamp (--layout),
+# so zones and fractions of a second may differ.
+
+if (( $# == 0 )); then
+  set -- -
+fi
+
+# Join each entry onto one line: a line starting with a timestamp opens an
+# entry, and every other line is appended to it after a \036 (RS) byte
+# yupsh: stamps.entries, the bufio.SplitFunc cutting a log into entries
+entries() {
+  awk '/^[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]/ {
+         if (NR > 1) print entry
+         entry = $0
+         next
+       }
+       { entry = (NR > 1 ? entry "\036" : "") $0 }
+       END { if (NR > 0) print entry }' "$1"
+}
+
+# Merge the joined entries on their first word, the timestamp, keeping the
+# files' order for equal times, then split the entries back into lines; the
+# joined copies go to temporary files, which, unlike the Go version, hold
+# the whole input
+# yupsh: merge.RecordFiles(s.less, s.entries, opts.Args()...)
+TMP=$(mktemp -d)
+trap 'rm -rf "${TMP}"' EXIT
+inputs=()
+for file in "$@"; do
+  entries "${file}" > "${TMP}/${#inputs[@]}"
+  inputs+=("${TMP}/${#inputs[@]}")
+done
+sort -m -s -k1,1 "${inputs[@]}" | tr '\036' '\n'
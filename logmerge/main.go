@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	gloo `github.com/gloo-foo/framework`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	merge `github.com/yupsh/script-examples/internal/merge`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Merge log files from several servers into one stream, in time order
+// Shell equivalent: See logmerge.sh
+//
+//   logmerge web1.log web2.log db.log
+//   2026-10-14T09:00:01Z web1 GET / 200
+//   2026-10-14T09:00:02Z db slow query: 850ms
+//       SELECT * FROM orders WHERE status = 'open'
+//   2026-10-14T11:00:03+02:00 web2 GET /login 200
+//   ...
+//
+// Each file must already be in time order, as a log is; the files are
+// merged with the shared internal/merge package, holding one entry per file,
+// so logs of any size merge in little memory. Entries are ordered by the
+// time their leading timestamp stands for, not by its text, so timestamps
+// in different zones, or with and without fractions of a second, still
+// interleave correctly. Entries with the same time keep the order of the
+// files on the command line.
+//
+// A line that doesn't start with a timestamp, such as a line of a stack
+// trace, belongs to the entry above it and moves with it. Lines before the
+// first timestamp of a file sort before everything else.
+//
+// The timestamp is the first words of the line, as many as --layout has,
+// parsed with time.Parse and the Go reference time (Mon Jan 2 15:04:05 MST
+// 2006):
+//   --layout 2006-01-02T15:04:05Z07:00   2026-10-14T09:00:01Z (the default)
+//   --layout '2006-01-02 15:04:05'       2026-10-14 09:00:01
+//   --layout 'Jan _2 15:04:05'           Oct 14 09:00:01 (syslog)
+//
+// Usage: logmerge [--layout LAYOUT] [file...]
+func main() {
+	opts := flags.New("logmerge", "[file...]")
+	layout := opts.String("layout", time.RFC3339, "Go time `layout` of the leading timestamp")
+	opts.Parse()
+
+	s := stamps{layout: *layout, words: len(strings.Fields(*layout))}
+	if s.words == 0 {
+		opts.Fail("--layout must not be empty")
+	}
+
+	// Shell: sort -m -s -k1,1 "$@", on entries joined onto one line
+	if err := gloo.Run(merge.RecordFiles(s.less, s.entries, opts.Args()...)); err != nil {
+		fmt.Fprintf(os.Stderr, "logmerge: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// stamps reads the leading timestamps of log lines
+type stamps struct {
+	layout string
+	words  int // the number of words in layout, and so in a timestamp
+}
+
+// parse returns the time at the start of line, and whether there is one
+func (s stamps) parse(line string) (time.Time, bool) {
+	words := strings.Fields(line)
+	if len(words) < s.words {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(s.layout, strings.Join(words[:s.words], " "))
+	return t, err == nil
+}
+
+// less orders two entries by their timestamps. An entry without one, the
+// lines before a file's first timestamp, has the zero time, so it sorts
+// first.
+func (s stamps) less(a, b string) bool {
+	x, _ := s.parse(firstLine(a))
+	y, _ := s.parse(firstLine(b))
+	return x.Before(y)
+}
+
+// firstLine returns the line of an entry holding its timestamp
+func firstLine(entry string) string {
+	line, _, _ := strings.Cut(entry, "\n")
+	return line
+}
+
+// entries is the bufio.SplitFunc that cuts a log into entries: a line, and
+// every line after it that doesn't start with a timestamp
+//
+// Shell equivalent:
+//   awk '/^[0-9]{4}-/ && NR > 1 {print entry; entry = ""} {entry = entry ...}'
+//
+// Whether an entry has ended is only known at the start of the next one,
+// so each line after the first is read whole before the entry is cut. An
+// entry is returned without its last newline, which the merge writes back.
+func (s stamps) entries(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	end := bytes.IndexByte(data, '\n') // the end of the entry so far
+	if end < 0 {
+		if atEOF {
+			return len(data), data, nil // a last line with no newline
+		}
+		return 0, nil, nil
+	}
+
+	for {
+		next := end + 1
+		if next == len(data) {
+			if atEOF {
+				return len(data), data[:end], nil
+			}
+			return 0, nil, nil // the next line hasn't been read yet
+		}
+
+		n := bytes.IndexByte(data[next:], '\n')
+		if n < 0 && !atEOF {
+			return 0, nil, nil // the next line is only partly read
+		}
+		line := data[next:]
+		if n >= 0 {
+			line = line[:n]
+		}
+
+		// A timestamp starts the next entry; anything else continues this one
+		if _, ok := s.parse(string(line)); ok {
+			return next, data[:end], nil
+		}
+		if n < 0 {
+			return len(data), data, nil // a continuation line with no newline
+		}
+		end = next + n
+	}
+}
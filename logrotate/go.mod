@@ -0,0 +1,9 @@
+module github.com/yupsh/script-examples/logrotate
+
+go 1.25
+
+require github.com/yupsh/script-examples/internal v0.0.0
+
+require github.com/gloo-foo/framework v0.0.3 // indirect
+
+replace github.com/yupsh/script-examples/internal => ../internal
@@ -0,0 +1,118 @@
+#!/bin/bash
+
+# Rotate log files that have grown past a size: app.log becomes app.log.1,
+# app.log.1 becomes app.log.2, and so on, keeping --keep generations
+# yupsh equivalent: See main.go
+
+# Parse flags (--max-size SIZE, --keep K, --compress, --dry-run), then the logs
+# yupsh: maxSize := opts.Size("max-size", ...); keep := opts.Int("keep", ...); ...
+MAX_SIZE=10M
+KEEP=5
+COMPRESS=0
+DRY_RUN=0
+usage() {
+  echo "Usage: $0 [--max-size SIZE] [--keep K] [--compress] [--dry-run] file..." >&2
+  exit 2
+}
+while [[ $1 == --* ]]; do
+  case $1 in
+    --max-size) MAX_SIZE=$2; shift 2 ;;
+    --keep) KEEP=$2; shift 2 ;;
+    --compress) COMPRESS=1; shift ;;
+    --dry-run) DRY_RUN=1; shift ;;
+    *) usage ;;
+  esac
+done
+if (( $# == 0 || KEEP < 1 )); then
+  usage
+fi
+
+# Convert the human size to bytes
+# yupsh: size.Parse(text)
+MAX_BYTES=$(numfmt --from=iec "${MAX_SIZE%B}") || usage
+
+# yupsh: size.Format(n)
+format_size() {
+  if (( $1 < 1024 )); then
+    echo "$1B"
+  else
+    numfmt --to=iec --round=nearest --format=%.1f "$1"
+  fi
+}
+
+# Print a step, then take it unless this is a dry run; a step that fails
+# ends the rotation of the log (the subshell below)
+# yupsh: fmt.Println(dryrun.Line(*dryRun, s.String())); s.run(...)
+step() {
+  local text=$1
+  shift
+  if (( DRY_RUN )); then
+    echo "[dry-run] ${text}"
+    return
+  fi
+  echo "${text}"
+  "$@" || exit 1
+}
+
+# Rename, failing rather than replacing a file already there
+# yupsh: rename(from, to)
+rename() {
+  if [[ -e $2 || -L $2 ]]; then
+    echo "logrotate: $2: already exists, not overwritten" >&2
+    return 1
+  fi
+  mv -n -- "$1" "$2"
+}
+
+FAILED=0
+for log in "$@"; do
+  # yupsh: info, err := os.Stat(log); ... info.Size() <= *maxSize
+  if [[ ! -e ${log} ]]; then
+    echo "logrotate: stat ${log}: no such file or directory" >&2; FAILED=1; continue
+  elif [[ ! -f ${log} ]]; then
+    echo "logrotate: ${log}: not a regular file" >&2; FAILED=1; continue
+  fi
+  bytes=$(stat -L -c %s -- "${log}")
+  if (( bytes <= MAX_BYTES )); then
+    echo "logrotate: ${log}: $(format_size "${bytes}"), not over $(format_size "${MAX_BYTES}")" >&2
+    continue
+  fi
+  mode=$(stat -L -c %a -- "${log}")
+
+  # The rotated generations, as "N<TAB>path" lines
+  # yupsh: generations(log)
+  gens=()
+  for old in "${log}".*; do
+    [[ -e ${old} || -L ${old} ]] || continue
+    if [[ ${old#"${log}"} =~ ^\.([1-9][0-9]*)(\.gz)?$ ]]; then
+      gens+=("${BASH_REMATCH[1]}"$'\t'"${old}")
+    fi
+  done
+
+  # Remove or shift each generation, oldest (highest-numbered) first, then
+  # rotate the log itself
+  # yupsh: plan(log, *keep, *compress)
+  (
+    if (( ${#gens[@]} )); then
+      while IFS=$'\t' read -r n old; do
+        ext=${old#"${log}.${n}"}
+        if (( n >= KEEP )); then
+          step "remove ${old}" rm -f -- "${old}"
+        else
+          step "${old} -> ${log}.$((n + 1))${ext}" rename "${old}" "${log}.$((n + 1))${ext}"
+        fi
+      done < <(printf '%s\n' "${gens[@]}" | sort -t$'\t' -k1,1nr -k2,2)
+    fi
+
+    step "${log} -> ${log}.1" rename "${log}" "${log}.1"
+    if (( COMPRESS )); then
+      # yupsh: gzipFile(log+".1", log+".1.gz")
+      step "compress ${log}.1 -> ${log}.1.gz" gzip -- "${log}.1"
+    fi
+    # yupsh: os.OpenFile(log, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+    step "create ${log}" install -m "${mode}" /dev/null "${log}"
+  ) || FAILED=1
+done
+if (( FAILED )); then
+  exit 1
+fi
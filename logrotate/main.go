@@ -0,0 +1,284 @@
+package main
+
+import (
+	"cmp"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	dryrun `github.com/yupsh/script-examples/internal/dryrun`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	size `github.com/yupsh/script-examples/internal/size`
+)
+
+// generation matches the suffix of a rotated copy of a log, after its name:
+// ".3", or ".3.gz" if it was compressed
+var generation = regexp.MustCompile(`^\.([1-9][0-9]*)(\.gz)?$`)
+
+// Rotate log files that have grown past a size: app.log becomes app.log.1,
+// app.log.1 becomes app.log.2, and so on, keeping --keep generations
+// Shell equivalent: See logrotate.sh
+//
+//   logrotate --max-size 10M --keep 3 --compress app.log
+//   remove app.log.3.gz
+//   app.log.2.gz -> app.log.3.gz
+//   app.log.1.gz -> app.log.2.gz
+//   app.log -> app.log.1
+//   compress app.log.1 -> app.log.1.gz
+//   create app.log
+//
+// A log no larger than --max-size is left alone. Otherwise every step is
+// planned before any is taken, oldest generation first: generations from
+// --keep up are removed, each remaining one is renamed up by one, and only
+// then is the log renamed to .1, so no rename ever lands on a file that is
+// still there. (A rename that would, because the directory changed under
+// it, fails instead of overwriting.) Compressed and uncompressed
+// generations are shifted alike, each keeping its suffix. --compress gzips
+// the new .1, and a new empty log is created with the old one's
+// permissions, for the program writing it to reopen.
+//
+// --dry-run prints the plan, each step marked "[dry-run]", and changes
+// nothing (the examples' dry-run convention, see internal/dryrun).
+//
+// Usage: logrotate [--max-size SIZE] [--keep K] [--compress] [--dry-run] file...
+func main() {
+	opts := flags.New("logrotate", "file...")
+	maxSize := opts.Size("max-size", 10<<20, "rotate logs larger than `size` (K, M, G suffixes)")
+	keep := opts.Int("keep", 5, "keep `K` rotated generations")
+	compress := opts.Bool("compress", false, "gzip each log as it is rotated")
+	dryRun := opts.DryRun()
+	opts.Parse()
+	if opts.NArg() == 0 {
+		opts.Fail("no log files given")
+	}
+	if *keep < 1 {
+		opts.Fail("--keep must be at least 1")
+	}
+
+	failed := false
+	for _, log := range opts.Args() {
+		// Shell: [[ $(stat -c %s "${log}") -gt ${MAX_SIZE} ]] || continue
+		info, err := os.Stat(log)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logrotate: %v\n", err)
+			failed = true
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			fmt.Fprintf(os.Stderr, "logrotate: %s: not a regular file\n", log)
+			failed = true
+			continue
+		}
+		if info.Size() <= *maxSize {
+			fmt.Fprintf(os.Stderr, "logrotate: %s: %s, not over %s\n", log, size.Format(info.Size()), size.Format(*maxSize))
+			continue
+		}
+
+		steps, err := plan(log, *keep, *compress)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logrotate: %v\n", err)
+			failed = true
+			continue
+		}
+		for _, s := range steps {
+			fmt.Println(dryrun.Line(*dryRun, s.String()))
+			if *dryRun {
+				continue
+			}
+			if err := s.run(info.Mode().Perm()); err != nil {
+				fmt.Fprintf(os.Stderr, "logrotate: %v\n", err)
+				failed = true
+				break
+			}
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// step is one change of a rotation
+type step struct {
+	kind string // "remove", "rename", "compress" or "create"
+	path string
+	to   string // the new name, for "rename" and "compress"
+}
+
+// String describes the step as it is printed
+func (s step) String() string {
+	switch s.kind {
+	case "rename":
+		return s.path + " -> " + s.to
+	case "compress":
+		return "compress " + s.path + " -> " + s.to
+	}
+	return s.kind + " " + s.path
+}
+
+// run takes the step; perm is the log's permissions, for a created log
+func (s step) run(perm fs.FileMode) error {
+	switch s.kind {
+	case "remove":
+		// Shell: rm -f -- "${old}"
+		return os.Remove(s.path)
+	case "rename":
+		// Shell: mv -n -- "${old}" "${new}"
+		return rename(s.path, s.to)
+	case "compress":
+		// Shell: gzip -- "${log}.1"
+		return gzipFile(s.path, s.to)
+	case "create":
+		// Shell: install -m "${mode}" /dev/null "${log}"
+		f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+		if err != nil {
+			return err
+		}
+		if err := f.Chmod(perm); err != nil { // not narrowed by the umask
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	return fmt.Errorf("unknown step %q", s.kind)
+}
+
+// rotated is an existing rotated copy of a log
+type rotated struct {
+	path string
+	n    int    // its generation: 1 for app.log.1
+	ext  string // ".gz" if compressed, or ""
+}
+
+// plan returns the steps that rotate log, keeping keep generations: the
+// generations to remove or shift, oldest first, then the log's own rename
+// and, with compress, the compression of the new first generation
+func plan(log string, keep int, compress bool) ([]step, error) {
+	// Shell: for old in "${log}".*; do [[ ${old#"${log}"} =~ ^\.([0-9]+)(\.gz)?$ ]] ...
+	gens, err := generations(log)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []step
+	for _, g := range gens {
+		if g.n >= keep {
+			// Would be shifted past the last generation kept
+			steps = append(steps, step{kind: "remove", path: g.path})
+			continue
+		}
+		steps = append(steps, step{kind: "rename", path: g.path, to: name(log, g.n+1, g.ext)})
+	}
+
+	steps = append(steps, step{kind: "rename", path: log, to: name(log, 1, "")})
+	if compress {
+		steps = append(steps, step{kind: "compress", path: name(log, 1, ""), to: name(log, 1, ".gz")})
+	}
+	return append(steps, step{kind: "create", path: log}), nil
+}
+
+// generations lists the rotated copies of log in its directory, oldest
+// (highest-numbered) first
+func generations(log string) ([]rotated, error) {
+	dir, base := filepath.Split(log)
+	entries, err := os.ReadDir(cmp.Or(dir, "."))
+	if err != nil {
+		return nil, err
+	}
+
+	var gens []rotated
+	for _, e := range entries {
+		suffix, ok := strings.CutPrefix(e.Name(), base)
+		if !ok {
+			continue
+		}
+		m := generation.FindStringSubmatch(suffix)
+		if m == nil {
+			continue
+		}
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue // too many digits to be a generation
+		}
+		gens = append(gens, rotated{path: filepath.Join(dir, e.Name()), n: n, ext: m[2]})
+	}
+
+	slices.SortFunc(gens, func(a, b rotated) int {
+		if c := cmp.Compare(b.n, a.n); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ext, b.ext)
+	})
+	return gens, nil
+}
+
+// name returns the path of generation n of log, with ext (".gz" or "")
+func name(log string, n int, ext string) string {
+	return log + "." + strconv.Itoa(n) + ext
+}
+
+// rename renames from to to, failing rather than replacing a file already
+// at to
+func rename(from, to string) error {
+	if _, err := os.Lstat(to); err == nil {
+		return fmt.Errorf("%s: already exists, not overwritten", to)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return os.Rename(from, to)
+}
+
+// gzipFile compresses path into to, keeping its permissions and
+// modification time, and removes path, as gzip does
+func gzipFile(path, to string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	// Write a temporary file next to it, so a failure leaves no partial
+	// .gz behind, and rename it into place
+	tmp, err := os.CreateTemp(filepath.Dir(to), ".logrotate-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails harmlessly once renamed
+
+	zw := gzip.NewWriter(tmp)
+	zw.Name = filepath.Base(path)
+	zw.ModTime = info.ModTime()
+	if _, err := io.Copy(zw, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp.Name(), info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	if err := rename(tmp.Name(), to); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
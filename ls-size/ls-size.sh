@@ -0,0 +1,34 @@
+#!/bin/bash
+set -e
+
+# List files largest first, like `ls -lS` but across a whole tree
+# yupsh equivalent: See main.go
+
+# Parse flags (--top N, --human), then the directory argument
+# yupsh: opts := flags.New("ls-size", "[directory]"); opts.Top(); opts.Human(); opts.Parse()
+TOP=10
+HUMAN=
+while [[ $1 == --* ]]; do
+  case $1 in
+    --top) TOP=$2; shift 2 ;;
+    --human) HUMAN=1; shift ;;
+    *) echo "Usage: $0 [--top N] [--human] [directory]" >&2; exit 2 ;;
+  esac
+done
+DIR=${1:-.}
+
+# Find files and stat them: size, mode, mtime, path
+# yupsh: find.Find(find.Dir(dir), find.FileType), While(statFile, input.WholeLine)
+find "${DIR}" -type f -printf '%s\t%M\t%T@\t%p\n' \
+| sort -t$'\t' -k1,1 -nr \
+| if [[ ${TOP} -gt 0 ]]; then head -n "${TOP}"; else cat; fi \
+| while IFS=$'\t' read -r size mode mtime path; do
+    # Format one row like `ls -l`: mode, right-aligned size, time, path
+    # yupsh: While(formatRow(*human), FieldSeparator("\t"))
+    if [[ -n ${HUMAN} ]] && [[ ${size} -ge 1024 ]]; then
+      size=$(numfmt --to=iec --format=%.1f "${size}")
+    elif [[ -n ${HUMAN} ]]; then
+      size="${size}B"
+    fi
+    printf '%s %10s %s %s\n' "${mode}" "${size}" "$(date -d "@${mtime%.*}" '+%F %T')" "${path}"
+  done
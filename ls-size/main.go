@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	echo `github.com/yupsh/echo`
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	head `github.com/yupsh/head`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	size `github.com/yupsh/script-examples/internal/size`
+	status `github.com/yupsh/script-examples/internal/status`
+	sort `github.com/yupsh/sort`
+	. `github.com/yupsh/while`
+)
+
+// List files largest first, a pure-yupsh replacement for `ls -lS`
+// Shell equivalent: See ls-size.sh
+//
+// Unlike `ls -lS`, which only sorts the entries of one directory, this walks
+// the whole tree, so it answers "what are the biggest files under here?".
+//
+// Pattern: find -> While(stat) -> sort -> head -> While(format)
+// The size stays a raw byte count until after sorting, and is only turned into
+// a human-readable column (--human) in the final formatting stage.
+//
+// Usage: ls-size [--top N] [--human] [directory]
+//   --top 0 lists every file
+func main() {
+	// Shell: DIR=${1:-.}
+	opts := flags.New("ls-size", "[directory]")
+	top := opts.TopOrAll()
+	human := opts.Human()
+	opts.Parse()
+	dir := opts.ArgOr(0, ".")
+
+	if err := gloo.Run(listing(dir, *top, *human)); err != nil {
+		fmt.Fprintf(os.Stderr, "ls-size: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// listing returns the pipeline that lists the files under dir, largest
+// first: the top of them, or all for top 0
+//
+// Shell equivalent:
+//   find "${DIR}" -type f -printf ... | sort -t$'\t' -k1,1 -nr | head -n "${TOP}" | awk ...
+func listing(dir string, top int, human bool) gloo.Command {
+	stages := []any{
+		// Find all regular files
+		// Shell: find "${DIR}" -type f
+		find.Find(find.Dir(dir), find.FileType),
+
+		// Stat each file: "size\tmode\tmtime\tpath"
+		// Shell: -printf '%s\t%M\t%T@\t%p\n'
+		// yupsh: input.WholeLine keeps paths with spaces in one arg
+		While(statFile, input.WholeLine),
+
+		// Largest first, comparing only the size column
+		// Shell: sort -t$'\t' -k1,1 -nr
+		sort.Sort(sort.Field(1), sort.Delimiter("\t"), sort.Numeric, sort.Reverse),
+	}
+
+	// Only limit the listing when asked to
+	// Shell: head -n "${TOP}"
+	if top > 0 {
+		stages = append(stages, head.Head(head.LineCount(top)))
+	}
+
+	// Turn the tab-separated fields into aligned `ls -l` style columns
+	// Shell: awk -F'\t' '{ printf "%s %10s %s %s\n", $2, $1, strftime(...), $4 }'
+	stages = append(stages, While(formatRow(human), FieldSeparator("\t")))
+
+	return pipe.Pipeline(stages...)
+}
+
+// statFile emits "size\tmode\tmtime\tpath" for one file
+//
+// Shell equivalent:
+//   find -printf '%s\t%M\t%T@\t%p\n'
+//
+// The size comes first so the sort stage can key on field 1.
+func statFile(args ...any) gloo.Command {
+	// args[0] is the whole path (input.WholeLine)
+	path := args[0].(string)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil // Skip files removed or unreadable since find listed them
+	}
+
+	return echo.Echo(fmt.Sprintf("%d\t%s\t%d\t%s",
+		info.Size(), info.Mode(), info.ModTime().Unix(), path))
+}
+
+// formatRow returns a While() callback that prints one aligned listing row
+//
+// Shell equivalent:
+//   printf "%s %10s %s %s\n" "$mode" "$size" "$mtime" "$path"
+//
+// The output matches the column layout of `ls -l`: mode, right-aligned size,
+// modification time, path.
+func formatRow(human bool) Body {
+	return func(args ...any) gloo.Command {
+		// With FieldSeparator("\t"): size, mode, mtime, path
+		if len(args) < 4 {
+			return nil
+		}
+
+		var bytes, mtime int64
+		fmt.Sscanf(args[0].(string), "%d", &bytes)
+		fmt.Sscanf(args[2].(string), "%d", &mtime)
+
+		sizeCol := fmt.Sprintf("%d", bytes)
+		if human {
+			sizeCol = size.Format(bytes)
+		}
+
+		return echo.Echo(fmt.Sprintf("%s %10s %s %s",
+			args[1].(string), sizeCol, time.Unix(mtime, 0).Format(time.DateTime), args[3].(string)))
+	}
+}
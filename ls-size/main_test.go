@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sizes are the files of the test tree and their sizes, all different, so
+// the order is fixed
+var sizes = map[string]int{
+	"empty":              0,
+	"small.txt":          10,
+	"sub/with space.bin": 1536,
+	"sub/deeper/big.dat": 3 << 20,
+	"two-k":              2048,
+}
+
+// tree makes the files of sizes in a temporary directory, all modified at
+// the same time, and returns the directory and that time
+func tree(t *testing.T) (string, time.Time) {
+	t.Helper()
+	dir := t.TempDir()
+	when := time.Date(2026, 10, 1, 9, 30, 0, 0, time.Local)
+	for name, n := range sizes {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, bytes.Repeat([]byte("x"), n), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, when, when); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(dir, "small.txt"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir, when
+}
+
+// list runs listing() over dir and returns its output
+func list(t *testing.T, dir string, top int, human bool) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	if err := listing(dir, top, human).Executor()(context.Background(), strings.NewReader(""), &stdout, &stderr); err != nil {
+		t.Fatalf("ls-size: %v\n%s", err, stderr.String())
+	}
+	return stdout.String()
+}
+
+func TestListing(t *testing.T) {
+	dir, when := tree(t)
+	stamp := when.Format(time.DateTime)
+	row := func(mode, size, name string) string {
+		return fmt.Sprintf("%s %10s %s %s\n", mode, size, stamp, filepath.Join(dir, name))
+	}
+
+	tests := []struct {
+		name  string
+		top   int
+		human bool
+		want  string
+	}{
+		{"every file, largest first", 0, false,
+			row("-rw-r--r--", "3145728", "sub/deeper/big.dat") +
+				row("-rw-r--r--", "2048", "two-k") +
+				row("-rw-r--r--", "1536", "sub/with space.bin") +
+				row("-rw-------", "10", "small.txt") +
+				row("-rw-r--r--", "0", "empty")},
+		{"top 2", 2, false,
+			row("-rw-r--r--", "3145728", "sub/deeper/big.dat") +
+				row("-rw-r--r--", "2048", "two-k")},
+		{"top more than there are", 10, false,
+			row("-rw-r--r--", "3145728", "sub/deeper/big.dat") +
+				row("-rw-r--r--", "2048", "two-k") +
+				row("-rw-r--r--", "1536", "sub/with space.bin") +
+				row("-rw-------", "10", "small.txt") +
+				row("-rw-r--r--", "0", "empty")},
+		{"human sizes", 0, true,
+			row("-rw-r--r--", "3.0M", "sub/deeper/big.dat") +
+				row("-rw-r--r--", "2.0K", "two-k") +
+				row("-rw-r--r--", "1.5K", "sub/with space.bin") +
+				row("-rw-------", "10B", "small.txt") +
+				row("-rw-r--r--", "0B", "empty")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := list(t, dir, tt.top, tt.human); got != tt.want {
+				t.Errorf("listing:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEmptyDirectory checks that a tree without files lists nothing
+func TestEmptyDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "only-a-dir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := list(t, dir, 0, false); got != "" {
+		t.Errorf("listed %q", got)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	head `github.com/yupsh/head`
+	pipe `github.com/gloo-foo/pipe`
+	tail `github.com/yupsh/tail`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	sort `github.com/yupsh/sort`
+	uniq `github.com/yupsh/uniq`
+	. `github.com/yupsh/while`
+)
+
+// lines is how many lines each action prints
+const lines = 10
+
+// action is a pipeline that can be run on the chosen file
+type action struct {
+	name   string // its --action value
+	label  string // its entry in the menu
+	stages func() []any
+}
+
+// actions are the pipelines on offer, in menu order
+var actions = []action{
+	{"head", "first lines", func() []any {
+		// Shell: head -n 10 "${item}"
+		return []any{head.Head(head.LineCount(lines))}
+	}},
+	{"tail", "last lines", func() []any {
+		// Shell: tail -n 10 "${item}"
+		return []any{tail.Tail(tail.LineCount(lines))}
+	}},
+	{"top", "most common lines", func() []any {
+		// Shell: sort "${item}" | uniq -c | sort -nr | head -n 10
+		return []any{
+			sort.Sort(),
+			uniq.Uniq(uniq.Count),
+			sort.Sort(sort.Numeric, sort.Reverse),
+			head.Head(head.LineCount(lines)),
+		}
+	}},
+}
+
+// Pick a file from a numbered menu, then a pipeline to run on it
+// Shell equivalent: See menu.sh
+//
+//   menu logs
+//     1) logs/app.log
+//     2) logs/db.log
+//   File [1-2]: 1
+//     1) first lines
+//     2) last lines
+//     3) most common lines
+//   Action [1-3]: 3
+//         4 INFO request served
+//         2 WARN slow request
+//         1 INFO server started
+//         1 ERROR upstream timeout
+//
+// The items are the files under the directory (default "."), sorted, or
+// the lines of --list FILE. The menus and prompts go to stderr and the
+// answers are read from stdin, so stdout carries only the pipeline's
+// output, and `menu logs > out.txt` still shows the menus. --action skips
+// the second menu.
+//
+// An answer that isn't one of the numbers is reported and asked again; an
+// empty one shows the menu again, as bash's select does. The end of stdin
+// before an answer (Ctrl-D) exits with status 1 without running anything;
+// a missing directory or --list file exits with status 2, as an error (see
+// input.Dir).
+//
+// Answers can be piped in like any input, for scripting the menu:
+//   printf '1\n3\n' | menu logs
+//
+// Usage: menu [--list FILE] [--action ask|head|tail|top] [directory]
+func main() {
+	opts := flags.New("menu", "[directory]")
+	list := opts.String("list", "", "choose from the lines of `file` instead of the files under the directory")
+	act := opts.Choice("action", "pipeline to run, or ask", "ask", "head", "tail", "top")
+	opts.Parse()
+	if *list != "" && opts.NArg() > 0 {
+		opts.Fail("give either --list or a directory, not both")
+	}
+	if *list == input.Stdin {
+		opts.Fail("--list: stdin is read for the answers")
+	}
+
+	items, err := loadItems(*list, opts.ArgOr(0, "."))
+	if err != nil {
+		status.Result{Err: err}.Exit("menu")
+	}
+	if len(items) == 0 {
+		fmt.Fprintln(os.Stderr, "menu: nothing to choose from")
+		os.Exit(1)
+	}
+
+	answers := bufio.NewReader(os.Stdin)
+
+	// Shell: select item in "${items[@]}"
+	i, err := choose(answers, "File", items)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "menu: %v\n", err)
+		os.Exit(1)
+	}
+	item := items[i]
+
+	a, err := pick(answers, *act)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "menu: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Shell: cat "${item}" | ...
+	stages := append([]any{input.Source(item)}, a.stages()...)
+	if err := gloo.Run(pipe.Pipeline(stages...)); err != nil {
+		fmt.Fprintf(os.Stderr, "menu: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// loadItems returns the lines of list, or if it is "" the files under dir,
+// sorted
+func loadItems(list, dir string) ([]string, error) {
+	var items []string
+	add := func(args ...any) gloo.Command {
+		if item := args[0].(string); item != "" {
+			items = append(items, item)
+		}
+		return nil
+	}
+
+	// Shell: mapfile -t items < "${LIST}"
+	source := input.Source(list)
+	if list == "" {
+		// find only warns about a missing directory, so check it up front
+		if err := input.Dir(dir); err != nil {
+			return nil, err
+		}
+		// Shell: mapfile -t items < <(find "${DIR}" -type f | sort)
+		source = pipe.Pipeline(find.Find(find.Dir(dir), find.FileType), sort.Sort())
+	}
+
+	err := gloo.Run(pipe.Pipeline(source, While(add, input.WholeLine)))
+	return items, err
+}
+
+// pick returns the action named by --action, or asks for one
+func pick(answers *bufio.Reader, name string) (action, error) {
+	for _, a := range actions {
+		if a.name == name {
+			return a, nil
+		}
+	}
+
+	labels := make([]string, len(actions))
+	for i, a := range actions {
+		labels[i] = a.label
+	}
+	i, err := choose(answers, "Action", labels)
+	if err != nil {
+		return action{}, err
+	}
+	return actions[i], nil
+}
+
+// choose shows options numbered from 1 on stderr and asks for one of the
+// numbers until it gets one, returning its index
+//
+// Shell equivalent:
+//   PS3="File [1-2]: "; select item in "${items[@]}"; do ...; done
+func choose(answers *bufio.Reader, prompt string, options []string) (int, error) {
+	show := func() {
+		width := len(strconv.Itoa(len(options)))
+		for i, o := range options {
+			fmt.Fprintf(os.Stderr, "  %*d) %s\n", width, i+1, o)
+		}
+	}
+
+	show()
+	for {
+		fmt.Fprintf(os.Stderr, "%s [1-%d]: ", prompt, len(options))
+		answer, err := answers.ReadString('\n')
+		if err != nil && answer == "" {
+			fmt.Fprintln(os.Stderr)
+			if errors.Is(err, io.EOF) {
+				return 0, errors.New("nothing chosen")
+			}
+			return 0, err
+		}
+
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			show()
+			continue
+		}
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < 1 || n > len(options) {
+			fmt.Fprintf(os.Stderr, "menu: %q: choose a number from 1 to %d\n", answer, len(options))
+			continue
+		}
+		return n - 1, nil
+	}
+}
@@ -0,0 +1,93 @@
+#!/bin/bash
+
+# Pick a file from a numbered menu, then a pipeline to run on it
+# yupsh equivalent: See main.go
+
+# Parse flags (--list FILE, --action A), then the directory
+# yupsh: list := opts.String("list", ...); act := opts.Choice("action", ...)
+LIST=
+ACTION=ask
+usage() {
+  echo "Usage: $0 [--list FILE] [--action ask|head|tail|top] [directory]" >&2
+  exit 2
+}
+while [[ $1 == --* ]]; do
+  case $1 in
+    --list) LIST=$2; shift 2 ;;
+    --action) ACTION=$2; shift 2 ;;
+    *) usage ;;
+  esac
+done
+case ${ACTION} in
+  ask|head|tail|top) ;;
+  *) usage ;;
+esac
+if [[ ( -n ${LIST} && $# -gt 0 ) || ${LIST} == - ]]; then
+  usage
+fi
+DIR=${1:-.}
+
+# The items: the lines of --list, or the files under the directory
+# yupsh: loadItems(*list, dir)
+if [[ -n ${LIST} ]]; then
+  [[ -r ${LIST} ]] || { echo "menu: open ${LIST}: no such file or directory" >&2; exit 2; }
+  mapfile -t ITEMS < <(grep -v '^$' -- "${LIST}")
+else
+  [[ -d ${DIR} ]] || { echo "menu: ${DIR}: not a directory" >&2; exit 2; }
+  mapfile -t ITEMS < <(find "${DIR}" -type f | sort)
+fi
+if (( ${#ITEMS[@]} == 0 )); then
+  echo "menu: nothing to choose from" >&2
+  exit 1
+fi
+
+# Show the options numbered on stderr and ask until one is chosen; its
+# index is left in CHOSEN. (bash's select does this too, but lays the menu
+# out in columns and keeps asking after an answer.)
+# yupsh: choose(answers, prompt, options)
+choose() {
+  local prompt=$1 answer
+  shift
+  local width=${##}
+  show() {
+    local i
+    for (( i = 1; i <= $#; i++ )); do
+      printf '  %*d) %s\n' "${width}" "${i}" "${!i}" >&2
+    done
+  }
+
+  show "$@"
+  while true; do
+    printf '%s [1-%d]: ' "${prompt}" "$#" >&2
+    if ! read -r answer; then
+      echo >&2
+      echo "menu: nothing chosen" >&2
+      exit 1
+    fi
+    if [[ -z ${answer} ]]; then
+      show "$@"
+    elif [[ ${answer} =~ ^[0-9]+$ ]] && (( 10#${answer} >= 1 && 10#${answer} <= $# )); then
+      CHOSEN=$(( 10#${answer} - 1 ))
+      return
+    else
+      printf 'menu: "%s": choose a number from 1 to %d\n' "${answer}" "$#" >&2
+    fi
+  done
+}
+
+choose File "${ITEMS[@]}"
+ITEM=${ITEMS[CHOSEN]}
+
+# yupsh: pick(answers, *act)
+if [[ ${ACTION} == ask ]]; then
+  ACTIONS=(head tail top)
+  choose Action "first lines" "last lines" "most common lines"
+  ACTION=${ACTIONS[CHOSEN]}
+fi
+
+# yupsh: gloo.Run(pipe.Pipeline(input.Source(item), a.stages()...))
+case ${ACTION} in
+  head) head -n 10 -- "${ITEM}" ;;
+  tail) tail -n 10 -- "${ITEM}" ;;
+  top) sort -- "${ITEM}" | uniq -c | sort -nr | head -n 10 ;;
+esac
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	gloo `github.com/gloo-foo/framework`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	merge `github.com/yupsh/script-examples/internal/merge`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Merge several pre-sorted files into one sorted stream
+// Shell equivalent: See merge.sh
+//
+// Unlike sort.Sort(), which reads everything before writing anything, the
+// merge holds just one line per input and writes as it goes:
+//   merge a.txt b.txt    a.txt: 1 4 9    b.txt: 2 3 10
+//   1 2 3 4 9 10
+//
+// The inputs must already be sorted the same way (lexically, or with
+// --numeric); out-of-order input is merged as-is, like `sort -m`.
+//
+// Usage: merge [--numeric] [file...]
+func main() {
+	opts := flags.New("merge", "[file...]")
+	numeric := opts.Bool("numeric", false, "compare lines numerically")
+	opts.Parse()
+
+	less := merge.Lexical
+	if *numeric {
+		less = merge.Numeric
+	}
+
+	// Shell: sort -m [-n] "$@"
+	if err := gloo.Run(merge.Files(less, opts.Args()...)); err != nil {
+		fmt.Fprintf(os.Stderr, "merge: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
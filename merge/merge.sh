@@ -0,0 +1,19 @@
+#!/bin/bash
+set -e
+
+# Merge several pre-sorted files into one sorted stream
+# yupsh equivalent: See main.go
+
+# Parse flags (--numeric), then the files
+# yupsh: numeric := opts.Bool("numeric", false, ...)
+NUMERIC=
+while [[ $1 == --* ]]; do
+  case $1 in
+    --numeric) NUMERIC=-n; shift ;;
+    *) echo "Usage: $0 [--numeric] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Merge without re-sorting
+# yupsh: gloo.Run(merge.Files(less, opts.Args()...))
+LC_ALL=C sort -m ${NUMERIC} "$@"
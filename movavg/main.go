@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	num `github.com/yupsh/script-examples/internal/num`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Print the moving average of the last N numbers of a stream
+// Shell equivalent: See movavg.sh
+//
+//   seq 6 | movavg --window 3
+//   2        (1+2+3)/3
+//   3        (2+3+4)/3
+//   4
+//   5
+//
+// Nothing is printed until the window has filled: the first N-1 numbers
+// only warm it up, so input shorter than the window prints nothing. Lines
+// that are not numbers are skipped with a warning.
+//
+// The window is a ring buffer of N values plus their sum. Each new value
+// replaces the oldest, and the sum is adjusted by the difference, so every
+// line costs O(1) and memory stays at N values however long the stream.
+//
+// Usage: movavg [--window N] [file...]
+func main() {
+	opts := flags.New("movavg", "[file...]")
+	window := opts.Int("window", 5, "average over the last `N` values")
+	opts.Parse()
+	if *window < 1 {
+		opts.Fail("--window must be at least 1")
+	}
+
+	// Shell: awk -v n=5 '{sum += $1 - ring[NR % n]; ring[NR % n] = $1} NR >= n {print sum / n}'
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		movingAverage(*window),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "movavg: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// movingAverage prints the mean of each full window of n values
+func movingAverage(n int) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		r := &ring{values: make([]float64, n)}
+		w := bufio.NewWriter(stdout)
+
+		scanner := bufio.NewScanner(stdin)
+		for lineNum := 1; scanner.Scan(); lineNum++ {
+			value, err := strconv.ParseFloat(strings.TrimSpace(scanner.Text()), 64)
+			if err != nil {
+				fmt.Fprintf(stderr, "movavg: line %d: not a number: %q\n", lineNum, scanner.Text())
+				continue
+			}
+
+			// Shell: NR >= n {print sum / n}
+			if r.push(value) {
+				if _, err := fmt.Fprintln(w, num.Format(r.sum/float64(n))); err != nil {
+					return err
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return w.Flush()
+	})
+}
+
+// ring holds the last len(values) numbers and their sum
+type ring struct {
+	values []float64
+	next   int  // index of the oldest value, overwritten by the next push
+	full   bool // every slot holds a real value
+	sum    float64
+}
+
+// push adds value in place of the oldest one and reports whether the
+// window is full, i.e. whether sum covers a whole window
+func (r *ring) push(value float64) bool {
+	r.sum += value - r.values[r.next]
+	r.values[r.next] = value
+	r.next++
+
+	if r.next == len(r.values) {
+		r.next = 0
+		r.full = true
+
+		// Adding and subtracting floats drifts over a long stream, so
+		// recompute the sum exactly once per lap; this keeps the amortized
+		// cost O(1)
+		r.sum = 0
+		for _, v := range r.values {
+			r.sum += v
+		}
+	}
+	return r.full
+}
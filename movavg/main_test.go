@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// movavg runs movingAverage(window) over the lines of input and returns the
+// averages it printed, space-separated, and its warnings
+func movavg(t *testing.T, window int, input string) (string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	if err := movingAverage(window).Executor()(context.Background(), strings.NewReader(input), &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	return strings.Join(strings.Fields(stdout.String()), " "), stderr.String()
+}
+
+// seq returns the numbers from 1 to n, one per line
+func seq(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintln(&b, i)
+	}
+	return b.String()
+}
+
+// TestWarmUp checks the README's warm-up table: nothing is printed until
+// the window is full, then one average per value
+func TestWarmUp(t *testing.T) {
+	tests := []struct {
+		name   string
+		window int
+		input  string
+		want   string
+	}{
+		{"seq 6, window 3", 3, seq(6), "2 3 4 5"},
+		{"window 1 is each value", 1, seq(6), "1 2 3 4 5 6"},
+		{"one full window", 6, seq(6), "3.5"},
+		{"window never fills", 3, seq(2), ""},
+		{"empty input", 3, "", ""},
+		{"non-number skipped, not counted", 3, "1\nx\n2\n3\n", "2"},
+		{"only non-numbers", 2, "x\ny\n", ""},
+		{"window fills on the last value", 4, seq(4), "2.5"},
+		{"laps round the ring", 2, "1\n3\n5\n7\n9\n", "2 4 6 8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := movavg(t, tt.window, tt.input); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWarmUpLength checks that the output has window-1 fewer lines than the
+// input, and none when the input is shorter than the window
+func TestWarmUpLength(t *testing.T) {
+	for window := 1; window <= 8; window++ {
+		for lines := 0; lines <= 10; lines++ {
+			got, _ := movavg(t, window, seq(lines))
+			want := max(lines-window+1, 0)
+			if n := len(strings.Fields(got)); n != want {
+				t.Errorf("window %d over %d lines: %d averages, want %d", window, lines, n, want)
+			}
+		}
+	}
+}
+
+// TestWarnings checks that lines that are not numbers are reported with
+// their line numbers
+func TestWarnings(t *testing.T) {
+	_, stderr := movavg(t, 2, "1\nx\n2\n\n3\n")
+	want := "movavg: line 2: not a number: \"x\"\nmovavg: line 4: not a number: \"\"\n"
+	if stderr != want {
+		t.Errorf("warnings = %q, want %q", stderr, want)
+	}
+}
+
+// TestNoDrift checks that the sum, recomputed each lap, doesn't drift over
+// a long stream of values that don't add exactly
+func TestNoDrift(t *testing.T) {
+	var b strings.Builder
+	for i := range 100000 {
+		if i%2 == 0 {
+			b.WriteString("1e15\n")
+		} else {
+			b.WriteString("0.1\n")
+		}
+	}
+	b.WriteString("0.1\n0.1\n")
+	got, _ := movavg(t, 2, b.String())
+	fields := strings.Fields(got)
+	if last := fields[len(fields)-1]; last != "0.1" {
+		t.Errorf("last average = %s, want 0.1", last)
+	}
+}
@@ -0,0 +1,35 @@
+#!/bin/bash
+set -e
+
+# Print the moving average of the last N numbers of a stream
+# yupsh equivalent: See main.go
+
+# Parse flags (--window N), then the files
+# yupsh: window := opts.Int("window", 5, ...)
+WINDOW=5
+while [[ $1 == --* ]]; do
+  case $1 in
+    --window) WINDOW=$2; shift 2 ;;
+    *) echo "Usage: $0 [--window N] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Keep the last N values in a ring and their sum alongside
+# yupsh: movingAverage(*window) with a ring struct
+cat "$@" | awk -v n="${WINDOW}" '
+  # yupsh: strconv.ParseFloat(...) fails -> warn and skip
+  $0 !~ /^[[:space:]]*[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?[[:space:]]*$/ {
+    printf "movavg: line %d: not a number: \"%s\"\n", NR, $0 > "/dev/stderr"
+    next
+  }
+
+  # yupsh: r.sum += value - r.values[r.next]; r.values[r.next] = value
+  {
+    slot = count % n
+    sum += $1 - ring[slot]
+    ring[slot] = $1
+    count++
+  }
+
+  # yupsh: if r.push(value) { fmt.Fprintln(w, formatNumber(r.sum / n)) }
+  count >= n { print sum / n }'
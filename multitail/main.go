@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	tail `github.com/yupsh/tail`
+)
+
+// Print the last lines of several files, each under a header
+// Shell equivalent: See multitail.sh
+//
+// Output matches GNU tail with multiple files:
+//   ==> app.log <==
+//   ...last N lines...
+//
+//   ==> db.log <==
+//   ...
+//
+// pipe-closure shows tail.Tail() on a single stream; here it is reused once
+// per file inside a plain Go loop that prints the headers between sections.
+// A file that can't be read is reported and skipped, and the exit status is
+// 1 at the end, like tail.
+//
+// Usage: multitail [--lines N] [file...]
+func main() {
+	opts := flags.New("multitail", "[file...]")
+	lines := opts.Int("lines", 10, "print the last `N` lines of each file")
+	opts.Parse()
+	files := opts.Args()
+
+	// A single file (or stdin) gets no header
+	// Shell: tail -n 10 file
+	if len(files) <= 1 {
+		if err := gloo.Run(tailOf(files, *lines)); err != nil {
+			fmt.Fprintf(os.Stderr, "multitail: %s\n", status.Message(err))
+			os.Exit(1)
+		}
+		return
+	}
+
+	failed := false
+	first := true
+	for _, file := range files {
+		// Unreadable files get an error instead of a section
+		// Shell: tail: cannot open 'nope' for reading: No such file or directory
+		if _, err := os.Stat(file); err != nil {
+			fmt.Fprintf(os.Stderr, "multitail: %v\n", err)
+			failed = true
+			continue
+		}
+
+		// Header, separated from the previous section by a blank line
+		// Shell: echo; echo "==> ${file} <=="
+		header := fmt.Sprintf("==> %s <==", file)
+		if !first {
+			header = "\n" + header
+		}
+		first = false
+		gloo.MustRun(echo.Echo(header))
+
+		// Shell: tail -n "${LINES}" "${file}"
+		if err := gloo.Run(tailOf([]string{file}, *lines)); err != nil {
+			fmt.Fprintf(os.Stderr, "multitail: %s\n", status.Message(err))
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// tailOf returns a pipeline printing the last n lines of files (stdin if none)
+//
+// Shell equivalent:
+//   cat "$@" | tail -n 10
+//
+// The file is read with input.Source() rather than passed to tail.Tail(),
+// so a missing file is reported instead of tail falling back to stdin.
+func tailOf(files []string, n int) gloo.Command {
+	return pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(files...),
+		tail.Tail(tail.LineCount(n)),
+	)
+}
@@ -0,0 +1,16 @@
+#!/bin/bash
+
+# Print the last lines of several files, each under a header
+# yupsh equivalent: See main.go
+
+# Parse --lines N, then the file arguments
+# yupsh: lines := opts.Int("lines", 10, ...)
+LINES=10
+if [[ $1 == --lines ]]; then
+  LINES=$2
+  shift 2
+fi
+
+# GNU tail already prints "==> file <==" headers for multiple files
+# yupsh: for _, file := range files { echo.Echo(header); tailOf(file) }
+tail -n "${LINES}" "$@"
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	head `github.com/yupsh/head`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	sort `github.com/yupsh/sort`
+	uniq `github.com/yupsh/uniq`
+	. `github.com/yupsh/while`
+)
+
+// word matches one token: a run of letters, digits and apostrophes
+var word = regexp.MustCompile(`[\p{L}\p{N}']+`)
+
+// Count the most frequent n-grams (runs of N consecutive words) in text
+// Shell equivalent: See ngrams.sh
+//
+//   ngrams --n 2 --top 3 story.txt
+//         5 the river
+//         4 the town
+//         4 in the
+//
+// Words are lowercased runs of letters, digits and apostrophes; punctuation
+// separates them. The window slides across line breaks, so a phrase split
+// over two lines still counts, but a blank line (a paragraph break) starts
+// a new window.
+//
+// Pipeline: While(tokenize) | sort | uniq -c | sort -nr | head — the
+// callback turns each line into its n-grams, one per line, and the counting
+// is left to the usual commands.
+//
+// Usage: ngrams [--n N] [--top N] [file...]
+func main() {
+	opts := flags.New("ngrams", "[file...]")
+	n := opts.Int("n", 2, "words per n-gram (2 for bigrams, 3 for trigrams)")
+	top := opts.Top()
+	opts.Parse()
+	if *n < 1 {
+		opts.Fail("--n must be at least 1")
+	}
+
+	t := &tokenizer{n: *n}
+
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+
+		// One n-gram per line
+		// Shell: tr -cs "[:alnum:]'" '\n' | awk '{w[NR % n] = $0} NR >= n {...}'
+		While(t.line, input.WholeLine),
+
+		// Group identical n-grams together, then count them
+		// Shell: sort | uniq -c
+		sort.Sort(),
+		uniq.Uniq(uniq.Count),
+
+		// Most frequent first
+		// Shell: sort -nr
+		sort.Sort(sort.Numeric, sort.Reverse),
+
+		// Shell: head -n "${TOP}"
+		head.Head(head.LineCount(*top)),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ngrams: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// tokenizer keeps a sliding window of the last n words
+type tokenizer struct {
+	n      int
+	window []string
+}
+
+// line is the While() callback: it echoes every n-gram completed by the
+// words of this line
+func (t *tokenizer) line(args ...any) gloo.Command {
+	line := args[0].(string)
+
+	// A paragraph break: phrases don't run across it
+	if strings.TrimSpace(line) == "" {
+		t.window = t.window[:0]
+		return nil
+	}
+
+	var grams []string
+	for _, w := range word.FindAllString(strings.ToLower(line), -1) {
+		t.window = append(t.window, w)
+		if len(t.window) > t.n {
+			t.window = t.window[1:]
+		}
+		if len(t.window) == t.n {
+			grams = append(grams, strings.Join(t.window, " "))
+		}
+	}
+
+	if len(grams) == 0 {
+		return nil
+	}
+	return echo.Echo(strings.Join(grams, "\n"))
+}
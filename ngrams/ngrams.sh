@@ -0,0 +1,36 @@
+#!/bin/bash
+set -e
+
+# Count the most frequent n-grams (runs of N consecutive words) in text
+# yupsh equivalent: See main.go
+
+# Parse flags (--n N, --top N), then the files
+# yupsh: n := opts.Int("n", 2, ...); top := opts.Top()
+N=2
+TOP=10
+while [[ $1 == --* ]]; do
+  case $1 in
+    --n) N=$2; shift 2 ;;
+    --top) TOP=$2; shift 2 ;;
+    *) echo "Usage: $0 [--n N] [--top N] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# One n-gram per line, from a window that slides across lines but not
+# across blank ones
+# yupsh: While(t.line, input.WholeLine) with a tokenizer struct
+cat "$@" | tr '[:upper:]' '[:lower:]' | awk -v n="${N}" '
+  /^[[:space:]]*$/ { count = 0; next }
+  {
+    gsub(/[^[:alnum:]\047]+/, " ")
+    for (i = 1; i <= NF; i++) {
+      w[count++ % n] = $i
+      if (count >= n) {
+        gram = w[(count - n) % n]
+        for (j = count - n + 1; j < count; j++) gram = gram " " w[j % n]
+        print gram
+      }
+    }
+  }' |
+  # yupsh: sort.Sort() | uniq.Uniq(uniq.Count) | sort.Sort(sort.Numeric, sort.Reverse) | head.Head(...)
+  sort | uniq -c | sort -nr | head -n "${TOP}"
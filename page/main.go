@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"unicode/utf8"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	term `github.com/yupsh/script-examples/internal/term`
+)
+
+// prompt is shown after each screenful, on the terminal
+const prompt = "--More-- (Enter: next page, q: quit) "
+
+// errQuit stops the pager when the user answers q
+var errQuit = errors.New("quit")
+
+// Show a long output a screenful at a time, like a minimal more
+// Shell equivalent: See page.sh
+//
+//   grep -r TODO . | page
+//   page --lines 20 app.log
+//
+// The lines are printed --lines at a time (by default, the terminal's
+// height less a row for the prompt), and after each screenful page waits
+// for Enter before printing the next; q quits. A line wider than the
+// terminal wraps onto several rows, and is counted as them.
+//
+// stdin is the data, so the answers are read from the terminal itself,
+// /dev/tty, as more and less do; that is what lets page sit at the end of
+// a pipeline. When there is no terminal to page on, because stdout is a
+// pipe or a file or /dev/tty can't be opened, everything is printed
+// straight through, so page is harmless in a script.
+//
+// Ctrl-C stops page at once, wherever it is, and it exits 130, as a shell
+// reports a command killed by SIGINT; q is a normal end, and exits 0.
+//
+// Usage: page [--lines N] [file...]
+func main() {
+	opts := flags.New("page", "[file...]")
+	lines := opts.Int("lines", term.Height()-1, "print `N` lines per page")
+	opts.Parse()
+	if *lines < 1 {
+		opts.Fail("--lines must be at least 1")
+	}
+	if opts.NArg() == 0 && !input.Piped() {
+		opts.Fail("nothing to page: name a file, or pipe the output of a command in")
+	}
+
+	// Nowhere to page: print everything
+	// Shell: [[ -t 1 ]] || exec cat "$@"
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil || !term.IsTerminal(os.Stdout) {
+		if err := gloo.Run(pipe.Pipeline(pipe.PipeFail, input.Source(opts.Args()...))); err != nil {
+			fmt.Fprintf(os.Stderr, "page: %s\n", status.Message(err))
+			os.Exit(1)
+		}
+		return
+	}
+	defer tty.Close()
+
+	// Cancel the context on Ctrl-C, even while waiting for an answer
+	// Shell: trap 'exit 130' INT
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	p := &pager{tty: tty, answers: bufio.NewReader(tty), lines: *lines, width: term.Width()}
+
+	// stdin is paged directly rather than copied through input.Source, so
+	// quitting doesn't wait on a slow command upstream to write again
+	cmd := p.page()
+	if opts.NArg() > 0 {
+		cmd = pipe.Pipeline(pipe.PipeFail, input.Source(opts.Args()...), cmd)
+	}
+
+	err = gloo.RunWithContext(ctx, cmd)
+	switch {
+	case errors.Is(err, errQuit):
+	case errors.Is(err, context.Canceled):
+		fmt.Fprintln(tty)
+		os.Exit(130)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "page: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// pager prints lines a screenful at a time, asking on the terminal before
+// each next one
+type pager struct {
+	tty     *os.File      // the terminal, for the prompt
+	answers *bufio.Reader // the answers, read from the terminal
+	lines   int           // rows per page (--lines)
+	width   int           // columns, for counting wrapped lines
+}
+
+// page returns the command that prints stdin a page at a time
+//
+// Shell equivalent:
+//   more
+//
+// A page is printed line by line as the lines arrive, so a slow command
+// upstream still shows its output as it goes; the prompt only comes once
+// the page is full and there is another line to show.
+func (p *pager) page() gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		used := 0 // rows of the current page already printed
+		scanner := bufio.NewScanner(stdin)
+		for scanner.Scan() {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			line := scanner.Text()
+			rows := p.rows(line)
+			if used > 0 && used+rows > p.lines {
+				if err := p.ask(ctx); err != nil {
+					return err
+				}
+				used = 0
+			}
+
+			if _, err := fmt.Fprintln(stdout, line); err != nil {
+				return err
+			}
+			used += rows
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return ctx.Err()
+	})
+}
+
+// rows returns how many terminal rows line takes up once it wraps; tabs and
+// wide characters make it an estimate
+func (p *pager) rows(line string) int {
+	n := utf8.RuneCountInString(line)
+	if n == 0 {
+		return 1
+	}
+	return (n + p.width - 1) / p.width
+}
+
+// ask shows the prompt and waits for an answer: nil to go on, errQuit for
+// q, or the context's error on Ctrl-C
+//
+// Shell equivalent:
+//   read -r -p "--More-- " answer < /dev/tty
+//
+// The read can't be cancelled, so it runs in a goroutine, and Ctrl-C stops
+// the wait for it instead; the process is about to exit, so the goroutine
+// is never waited for.
+func (p *pager) ask(ctx context.Context) error {
+	fmt.Fprint(p.tty, prompt)
+
+	answer := make(chan string, 1)
+	go func() {
+		line, err := p.answers.ReadString('\n')
+		if err != nil && line == "" {
+			line = "q" // the terminal went away: stop
+		}
+		answer <- line
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case line := <-answer:
+		// Clear the prompt, on the row above the newline Enter echoed, so
+		// the next page follows straight on
+		// Shell: tput cuu1; tput el
+		fmt.Fprint(p.tty, "\x1b[1A\r\x1b[2K")
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(line)), "q") {
+			return errQuit
+		}
+		return nil
+	}
+}
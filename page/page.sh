@@ -0,0 +1,48 @@
+#!/bin/bash
+
+# Show a long output a screenful at a time, like a minimal more
+# yupsh equivalent: See main.go
+#
+# Lines are counted as one row each, however long; the Go version counts
+# the rows a wide line wraps onto.
+
+# Parse flags (--lines N), then the files
+# yupsh: lines := opts.Int("lines", term.Height()-1, ...)
+LINES_=$(( $(tput lines 2>/dev/null || echo 24) - 1 ))
+while [[ $1 == --* ]]; do
+  case $1 in
+    --lines) LINES_=$2; shift 2 ;;
+    *) echo "Usage: $0 [--lines N] [file...]" >&2; exit 2 ;;
+  esac
+done
+if [[ ! ${LINES_} =~ ^[1-9][0-9]*$ ]]; then
+  echo "page: --lines must be at least 1" >&2
+  exit 2
+fi
+
+# Nowhere to page: print everything
+# yupsh: os.OpenFile("/dev/tty", ...) fails, or !term.IsTerminal(os.Stdout)
+if [[ ! -t 1 ]] || ! { : < /dev/tty; } 2>/dev/null; then
+  exec cat "$@"
+fi
+
+# Ctrl-C: end the prompt's line and exit as killed by SIGINT
+# yupsh: signal.NotifyContext(...), then os.Exit(130)
+trap 'echo > /dev/tty; exit 130' INT
+
+# Print a page, then ask on the terminal, as stdin is the data
+# yupsh: p.page(), with p.ask(ctx) once a page is full
+used=0
+cat "$@" | while IFS= read -r line || [[ -n ${line} ]]; do
+  if (( used == LINES_ )); then
+    # yupsh: fmt.Fprint(p.tty, prompt); p.answers.ReadString('\n')
+    printf '%s' "--More-- (Enter: next page, q: quit) " > /dev/tty
+    read -r answer < /dev/tty || answer=q
+    # yupsh: fmt.Fprint(p.tty, "\x1b[1A\r\x1b[2K")
+    printf '\e[1A\r\e[2K' > /dev/tty
+    [[ ${answer,,} == q* ]] && exit 0
+    used=0
+  fi
+  printf '%s\n' "${line}"
+  (( used++ )) || true
+done
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	dryrun `github.com/yupsh/script-examples/internal/dryrun`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// hunkHeader matches "@@ -start[,count] +start[,count] @@"
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// errHunksFailed means at least one hunk did not apply; the file is left
+// unchanged
+var errHunksFailed = errors.New("hunks failed; file not changed")
+
+// Apply a unified diff, such as the diff example's output, to a file
+// Shell equivalent: See patch.sh
+//
+//   diff old.conf new.conf > change.diff
+//   patch old.conf change.diff
+//   patching file old.conf
+//
+// Each hunk's context and removed lines must match the file before it is
+// changed. A hunk that matches a few lines away from where its header says
+// (because the file was edited since the diff was made) is applied there,
+// with a note of the offset. If any hunk matches nowhere, it is reported and
+// the file is left untouched: changes are all or nothing. --dry-run checks
+// every hunk without writing, and marks its "patching file" line
+// "[dry-run]" (the examples' dry-run convention, see internal/dryrun).
+// A "\ No newline at end of file" line leaves the line before it without
+// one, so a round trip through diff and patch is exact.
+//
+// The patch is read from the second argument, or stdin; the file to patch is
+// the first argument, or the "---" name in the patch.
+//
+// Usage: patch [--dry-run] [file [patchfile]]
+func main() {
+	opts := flags.New("patch", "[file [patchfile]]")
+	dryRun := opts.DryRun()
+	opts.Parse()
+	if opts.NArg() > 2 {
+		opts.Fail("too many arguments")
+	}
+
+	p := &patcher{target: opts.ArgOr(0, ""), dryRun: *dryRun}
+
+	// Shell: patch old.conf < change.diff
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.ArgOr(1, input.Stdin)),
+		gloo.AccumulateAndOutput(p.apply),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "patch: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// patcher applies one patch to one file
+type patcher struct {
+	target string // file to patch; "" means the name in the patch
+	dryRun bool
+}
+
+// hunk is one "@@" section of a patch
+type hunk struct {
+	oldStart, newStart int      // 1-based, as in the header
+	lines              []string // each prefixed with ' ', '-' or '+', with its "\n" if it has one
+}
+
+// noNewline marks the hunk line before it as the last line of its file,
+// without a newline; as for patch, only the backslash matters
+const noNewline = `\ No newline at end of file`
+
+// apply parses the patch lines, applies them to the target and writes it back
+func (p *patcher) apply(patch []string, stdout io.Writer) error {
+	name, hunks, err := parse(patch)
+	if err != nil {
+		return err
+	}
+	if p.target == "" {
+		p.target = name
+	}
+	if p.target == "" {
+		return errors.New("no file to patch: the patch names none")
+	}
+
+	lines, err := readLines(p.target)
+	if err != nil {
+		return err
+	}
+
+	// Shell: patching file old.conf  /  [dry-run] patching file old.conf
+	fmt.Fprintln(stdout, dryrun.Line(p.dryRun, "patching file "+p.target))
+
+	out, ok := applyHunks(lines, hunks, stdout)
+	if !ok {
+		return errHunksFailed
+	}
+	if p.dryRun {
+		return nil
+	}
+	return writeLines(p.target, out)
+}
+
+// parse reads the file name from the "---" header and the hunks after it
+func parse(patch []string) (string, []hunk, error) {
+	var name string
+	var hunks []hunk
+	for i := 0; i < len(patch); i++ {
+		line := patch[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") && len(hunks) == 0:
+			// diff -u adds a tab and a timestamp after the name
+			name, _, _ = strings.Cut(strings.TrimPrefix(line, "--- "), "\t")
+		case strings.HasPrefix(line, "--- "):
+			return "", nil, fmt.Errorf("line %d: patch changes more than one file", i+1)
+		case strings.HasPrefix(line, "@@"):
+			h, next, err := parseHunk(patch, i)
+			if err != nil {
+				return "", nil, err
+			}
+			hunks = append(hunks, h)
+			i = next - 1
+		}
+	}
+	if len(hunks) == 0 {
+		return "", nil, errors.New("no hunks found in the patch")
+	}
+	return name, hunks, nil
+}
+
+// parseHunk reads the hunk whose header is patch[at], returning it and the
+// index of the line after it. The body must hold as many old and new lines
+// as the header counts.
+func parseHunk(patch []string, at int) (hunk, int, error) {
+	m := hunkHeader.FindStringSubmatch(patch[at])
+	if m == nil {
+		return hunk{}, 0, fmt.Errorf("line %d: malformed hunk header %q", at+1, patch[at])
+	}
+	h := hunk{oldStart: atoi(m[1], 0), newStart: atoi(m[3], 0)}
+	oldCount, newCount := atoi(m[2], 1), atoi(m[4], 1)
+
+	i := at + 1
+	for ; i < len(patch) && (oldCount > 0 || newCount > 0); i++ {
+		line := patch[i]
+		if line == "" {
+			line = " " // some editors strip the space from empty context lines
+		}
+		switch line[0] {
+		case ' ':
+			oldCount, newCount = oldCount-1, newCount-1
+		case '-':
+			oldCount--
+		case '+':
+			newCount--
+		case '\\':
+			if err := h.endsFile(i); err != nil {
+				return hunk{}, 0, err
+			}
+			continue
+		default:
+			return hunk{}, 0, fmt.Errorf("line %d: unexpected line in hunk: %q", i+1, patch[i])
+		}
+		h.lines = append(h.lines, line+"\n")
+	}
+	if oldCount != 0 || newCount != 0 {
+		return hunk{}, 0, fmt.Errorf("line %d: hunk is shorter than its header says", at+1)
+	}
+	// The marker for the hunk's last line comes after the counts run out
+	if i < len(patch) && strings.HasPrefix(patch[i], `\`) {
+		if err := h.endsFile(i); err != nil {
+			return hunk{}, 0, err
+		}
+		i++
+	}
+	return h, i, nil
+}
+
+// endsFile takes the newline off the hunk's last line, for the noNewline
+// marker at patch line index i
+func (h *hunk) endsFile(i int) error {
+	if len(h.lines) == 0 {
+		return fmt.Errorf("line %d: %q before any line of the hunk", i+1, noNewline)
+	}
+	last := len(h.lines) - 1
+	h.lines[last] = strings.TrimSuffix(h.lines[last], "\n")
+	return nil
+}
+
+// atoi converts a header number, which is def when the field is absent
+func atoi(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	n, _ := strconv.Atoi(s) // the regexp only matches digits
+	return n
+}
+
+// applyHunks returns lines with every hunk applied in order, reporting
+// offsets and failures on w. Hunks must not overlap or go backwards.
+func applyHunks(lines []string, hunks []hunk, w io.Writer) ([]string, bool) {
+	var out []string
+	ok := true
+	done := 0   // lines of the original already copied to out
+	offset := 0 // how far the last hunk was from its header's position
+
+	for n, h := range hunks {
+		old, repl := h.sides()
+
+		// The header's start is the line before the hunk when it removes
+		// and keeps nothing
+		want := h.oldStart - 1
+		if len(old) == 0 {
+			want = h.oldStart
+		}
+
+		at, found := locate(lines, old, want+offset, done)
+		if !found {
+			fmt.Fprintf(w, "Hunk #%d FAILED at %d.\n", n+1, h.oldStart)
+			ok = false
+			continue
+		}
+		if at != want {
+			fmt.Fprintf(w, "Hunk #%d succeeded at %d (offset %d lines).\n", n+1, at+1, at-want)
+		}
+		offset = at - want
+
+		out = append(out, lines[done:at]...)
+		out = append(out, repl...)
+		done = at + len(old)
+	}
+	return append(out, lines[done:]...), ok
+}
+
+// sides splits a hunk into the lines it expects (context and removed) and
+// the lines it leaves in their place (context and added)
+func (h hunk) sides() (old, repl []string) {
+	for _, line := range h.lines {
+		switch line[0] {
+		case ' ':
+			old = append(old, line[1:])
+			repl = append(repl, line[1:])
+		case '-':
+			old = append(old, line[1:])
+		case '+':
+			repl = append(repl, line[1:])
+		}
+	}
+	return old, repl
+}
+
+// locate finds old in lines, trying index want first and then ever further
+// from it, but never before from
+func locate(lines, old []string, want, from int) (int, bool) {
+	for d := 0; want-d >= from || want+d+len(old) <= len(lines); d++ {
+		for _, at := range []int{want - d, want + d} {
+			if at >= from && at+len(old) <= len(lines) && matchesAt(lines, old, at) {
+				return at, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// matchesAt reports whether old appears in lines starting at index at
+func matchesAt(lines, old []string, at int) bool {
+	for i, line := range old {
+		if lines[at+i] != line {
+			return false
+		}
+	}
+	return true
+}
+
+// readLines returns the lines of a file, each ending in "\n", except a
+// last line without a newline, so that the hunks can match it and keep it
+// that way; a "\r" before the newline is dropped, as the patch's own lines
+// are read
+func readLines(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadString('\n')
+		if line != "" {
+			text, eol := strings.CutSuffix(line, "\n")
+			text = strings.TrimSuffix(text, "\r")
+			if eol {
+				text += "\n"
+			}
+			lines = append(lines, text)
+		}
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+}
+
+// writeLines replaces a file with lines, via a temporary file and a rename so
+// that a failure part-way never leaves it half-written
+func writeLines(name string, lines []string) error {
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+
+	// The same directory keeps the final rename on one filesystem
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	// Each line has its newline already, but for a last one without
+	w := bufio.NewWriter(tmp)
+	for _, line := range lines {
+		w.WriteString(line)
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestRoundTrip checks that the diff example's output, applied by patch,
+// turns the old file into the new one: first for the cases around a
+// missing newline at the end of a file, then for random pairs of files
+func TestRoundTrip(t *testing.T) {
+	diff := buildDiff(t)
+
+	cases := []struct {
+		name     string
+		from, to string
+	}{
+		{"same", "a\nb\n", "a\nb\n"},
+		{"changed line", "a\nb\nc\n", "a\nx\nc\n"},
+		{"new lacks newline", "a\nb\nc\n", "a\nb\nc"},
+		{"old lacks newline", "a\nb\nc", "a\nb\nc\n"},
+		{"both lack newline", "a\nb\nc", "a\nx\nc"},
+		{"line added after one without newline", "a\nb", "a\nb\nc\n"},
+		{"last line removed", "a\nb\nc", "a\nb\n"},
+		{"old empty", "", "a\nb"},
+		{"new empty", "a\nb", ""},
+		{"only a newline lost", "a\n", "a"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			roundTrip(t, diff, c.from, c.to, 3)
+		})
+	}
+
+	rng := rand.New(rand.NewPCG(634, 1))
+	for i := range 600 {
+		from, to := randomFile(rng), randomFile(rng)
+		context := rng.IntN(4)
+		t.Run("random/"+strconv.Itoa(i), func(t *testing.T) {
+			roundTrip(t, diff, from, to, context)
+		})
+	}
+}
+
+// roundTrip diffs the texts from and to with the diff example, applies the
+// diff to a copy of from, and checks the copy is then to, byte for byte
+func roundTrip(t *testing.T, diff, from, to string, context int) {
+	t.Helper()
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old")
+	newPath := filepath.Join(dir, "new")
+	work := filepath.Join(dir, "work")
+	for path, text := range map[string]string{oldPath: from, newPath: to, work: from} {
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Shell: diff -U "${context}" old new > p
+	cmd := exec.Command(diff, "--context", strconv.Itoa(context), oldPath, newPath)
+	out, err := cmd.Output()
+	var exit *exec.ExitError
+	switch {
+	case err == nil:
+		if from != to {
+			t.Fatalf("diff found no difference between %q and %q", from, to)
+		}
+		return
+	case !errors.As(err, &exit) || exit.ExitCode() != 1:
+		t.Fatalf("diff %q %q: %v", from, to, err)
+	}
+
+	// Shell: patch work p
+	var patch []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		patch = append(patch, scanner.Text())
+	}
+	p := &patcher{target: work}
+	if err := p.apply(patch, io.Discard); err != nil {
+		t.Fatalf("patch of %q to %q: %v\n%s", from, to, err, out)
+	}
+
+	got, err := os.ReadFile(work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != to {
+		t.Errorf("patch of %q made %q, want %q\n%s", from, got, to, out)
+	}
+}
+
+// randomFile returns up to 8 lines drawn from a few short ones, so that
+// pairs share lines, ending in a newline or, sometimes, not
+func randomFile(rng *rand.Rand) string {
+	lines := make([]string, rng.IntN(9))
+	for i := range lines {
+		lines[i] = string(rune('a' + rng.IntN(5)))
+	}
+	text := strings.Join(lines, "\n")
+	if len(lines) > 0 && rng.IntN(3) > 0 {
+		text += "\n"
+	}
+	return text
+}
+
+// buildDiff builds the diff example, whose output patch applies, and
+// returns the path of its binary
+func buildDiff(t *testing.T) string {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "diff")
+	cmd := exec.Command("go", "build", "-o", bin, ".")
+	cmd.Dir = filepath.Join("..", "diff")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("building the diff example: %v\n%s", err, out)
+	}
+	return bin
+}
@@ -0,0 +1,34 @@
+#!/bin/bash
+set -eo pipefail
+
+# Apply a unified diff, such as the diff example's output, to a file
+# yupsh equivalent: See main.go
+
+# Parse flags (--dry-run), then the file and patch
+# yupsh: dryRun := opts.DryRun()
+DRY_RUN=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --dry-run) DRY_RUN=1; shift ;;
+    *) echo "Usage: $0 [--dry-run] [file [patchfile]]" >&2; exit 2 ;;
+  esac
+done
+FILE=$1
+PATCH=${2:--}
+
+# Read the patch once, since it may come from stdin and is used twice
+# yupsh: input.Source(patchfile) | gloo.AccumulateAndOutput(p.apply)
+PATCH_TEXT=$(cat -- "${PATCH}")
+
+# Check every hunk first, so a failure leaves the file untouched instead of
+# half patched (GNU patch would apply the hunks that fit and write a .rej)
+# yupsh: applyHunks() fails as a whole -> errHunksFailed
+# yupsh: dryrun.Line(p.dryRun, "patching file "+p.target)
+MARK=
+(( DRY_RUN )) && MARK='[dry-run] '
+patch --dry-run --no-backup-if-mismatch ${FILE:+"${FILE}"} <<< "${PATCH_TEXT}" |
+  sed "s/^checking file /${MARK}patching file /"
+if (( DRY_RUN )); then
+  exit 0
+fi
+patch --silent --no-backup-if-mismatch ${FILE:+"${FILE}"} <<< "${PATCH_TEXT}"
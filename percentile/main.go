@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	num `github.com/yupsh/script-examples/internal/num`
+	status `github.com/yupsh/script-examples/internal/status`
+	table `github.com/yupsh/script-examples/internal/table`
+)
+
+// Print percentiles and summary statistics of a column of numbers
+// Shell equivalent: See percentile.sh
+//
+//   percentile --p 50,90,99 latency.txt
+//   count  14
+//   min    120
+//   p50    160
+//   p90    275.1
+//   p99    394.14
+//   max    410
+//   mean   185.357
+//
+// Percentiles interpolate linearly between the two nearest values of the
+// sorted input, so p50 of an even count is the mean of the middle pair (the
+// median), p0 is the minimum and p100 the maximum. This is the default
+// method of numpy.percentile and R's quantile(type = 7).
+//
+// Usage: percentile [--p LIST] [file...]
+func main() {
+	opts := flags.New("percentile", "[file...]")
+	list := opts.String("p", "50,90,99", "comma-separated `percentiles` to print, from 0 to 100")
+	opts.Parse()
+
+	ps, err := parsePercentiles(*list)
+	if err != nil {
+		opts.Fail("--p: %v", err)
+	}
+
+	// Shell: sort -g "$@" | awk '{v[NR] = $1} END {print v[int(NR / 2)] ...}'
+	err = gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		summarize(ps),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "percentile: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// parsePercentiles reads a list like "50,90,99.9" into ascending order
+func parsePercentiles(list string) ([]float64, error) {
+	var ps []float64
+	for _, s := range strings.Split(list, ",") {
+		p, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil || !(p >= 0 && p <= 100) { // NaN fails both
+			return nil, fmt.Errorf("%q is not a percentile from 0 to 100", s)
+		}
+		ps = append(ps, p)
+	}
+	slices.Sort(ps)
+	return slices.Compact(ps), nil
+}
+
+// summarize buffers every number, sorts them, and prints the statistics
+func summarize(ps []float64) gloo.Command {
+	return gloo.AccumulateAndOutput(func(lines []string, stdout io.Writer) error {
+		// Shell: sort -g
+		values := make([]float64, 0, len(lines))
+		sum := 0.0
+		for i, line := range lines {
+			field := strings.TrimSpace(line)
+			if field == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil || math.IsNaN(v) {
+				fmt.Fprintf(os.Stderr, "percentile: line %d: not a number: %q\n", i+1, line)
+				continue
+			}
+			values = append(values, v)
+			sum += v
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("no numbers in the input")
+		}
+		slices.Sort(values)
+
+		// Shell: END {print "count", NR; print "min", v[1]; ...}
+		rows := [][]string{
+			{"count", strconv.Itoa(len(values))},
+			{"min", num.Format(values[0])},
+		}
+		for _, p := range ps {
+			rows = append(rows, []string{"p" + num.Format(p), num.Format(percentile(values, p))})
+		}
+		rows = append(rows,
+			[]string{"max", num.Format(values[len(values)-1])},
+			[]string{"mean", num.Format(sum / float64(len(values)))},
+		)
+
+		for _, line := range table.Align(rows) {
+			if _, err := fmt.Fprintln(stdout, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// percentile returns the p-th percentile of sorted, interpolating between
+// the values either side of rank p/100 * (n-1)
+// Shell: r = p / 100 * (NR - 1); v[lo] + (r - lo) * (v[lo + 1] - v[lo])
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	if lo+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lo] + (rank-float64(lo))*(sorted[lo+1]-sorted[lo])
+}
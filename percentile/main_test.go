@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestPercentile checks known datasets against Python's statistics.quantiles
+// (method="inclusive") and numpy.percentile, which use the same method
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		p      float64
+		want   float64
+	}{
+		// The README's table
+		{"1 2 3 4 p25", []float64{1, 2, 3, 4}, 25, 1.75},
+		{"1 2 3 4 p50", []float64{1, 2, 3, 4}, 50, 2.5},
+		{"1 2 3 4 p75", []float64{1, 2, 3, 4}, 75, 3.25},
+		{"seq 10 p25", seq10, 25, 3.25},
+		{"seq 10 p50", seq10, 50, 5.5},
+		{"seq 10 p75", seq10, 75, 7.75},
+		{"3 1 2 p25", []float64{3, 1, 2}, 25, 1.5},
+		{"3 1 2 p50", []float64{3, 1, 2}, 50, 2},
+		{"3 1 2 p75", []float64{3, 1, 2}, 75, 2.5},
+		{"one value p0", []float64{5}, 0, 5},
+		{"one value p50", []float64{5}, 50, 5},
+		{"one value p100", []float64{5}, 100, 5},
+
+		// The ends are the minimum and maximum
+		{"seq 10 p0", seq10, 0, 1},
+		{"seq 10 p100", seq10, 100, 10},
+		{"seq 10 p90", seq10, 90, 9.1},
+		{"seq 10 p99", seq10, 99, 9.91},
+		{"seq 10 p99.9", seq10, 99.9, 9.991},
+
+		// numpy.percentile([15, 20, 35, 40, 50], p)
+		{"numpy p40", []float64{15, 20, 35, 40, 50}, 40, 29},
+		{"numpy p10", []float64{15, 20, 35, 40, 50}, 10, 17},
+		{"numpy p99", []float64{15, 20, 35, 40, 50}, 99, 49.6},
+
+		// Negative and fractional values, and repeats
+		{"negatives p25", []float64{-3, -1.5, 0, 2.25}, 25, -1.875},
+		{"negatives p75", []float64{-3, -1.5, 0, 2.25}, 75, 0.5625},
+		{"repeats p50", []float64{7, 7, 7, 1}, 50, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted := slices.Sorted(slices.Values(tt.values))
+			if got := percentile(sorted, tt.p); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("percentile(%v, %v) = %v, want %v", sorted, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+// seq10 is the numbers 1 to 10
+var seq10 = []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+// TestParsePercentiles checks that --p is sorted and deduplicated, and that
+// values outside 0 to 100 are refused
+func TestParsePercentiles(t *testing.T) {
+	got, err := parsePercentiles("99, 50,90,50,0,100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{0, 50, 90, 99, 100}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for _, bad := range []string{"", "50,", "-1", "100.1", "x", "NaN", "1e3"} {
+		if _, err := parsePercentiles(bad); err == nil {
+			t.Errorf("parsePercentiles(%q) accepted it", bad)
+		}
+	}
+}
+
+// TestSummarize checks the whole report on the example's latency.txt, as
+// the doc comment shows it
+func TestSummarize(t *testing.T) {
+	f, err := os.Open("latency.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var stdout, stderr bytes.Buffer
+	if err := summarize([]float64{50, 90, 99}).Executor()(context.Background(), f, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"count  14",
+		"min    120",
+		"p50    160",
+		"p90    275.1",
+		"p99    394.14",
+		"max    410",
+		"mean   185.357",
+	}, "\n") + "\n"
+	if stdout.String() != want {
+		t.Errorf("report:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+}
+
+// TestSummarizeNoNumbers checks that input without a number is an error
+func TestSummarizeNoNumbers(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := summarize([]float64{50}).Executor()(context.Background(), strings.NewReader("\n\n"), &stdout, &stderr)
+	if err == nil {
+		t.Errorf("no error, printed %q", stdout.String())
+	}
+}
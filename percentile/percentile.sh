@@ -0,0 +1,38 @@
+#!/bin/bash
+set -e
+
+# Print percentiles and summary statistics of a column of numbers
+# yupsh equivalent: See main.go
+
+# Parse flags (--p LIST), then the files
+# yupsh: list := opts.String("p", "50,90,99", ...)
+PS=50,90,99
+while [[ $1 == --* ]]; do
+  case $1 in
+    --p) PS=$2; shift 2 ;;
+    *) echo "Usage: $0 [--p LIST] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Sort the numbers, then buffer them in awk and interpolate in END
+# yupsh: summarize(ps) built on gloo.AccumulateAndOutput() and slices.Sort()
+cat "$@" | grep -v '^[[:space:]]*$' | sort -g | awk -v list="${PS}" '
+  { v[NR] = $1; sum += $1 }
+
+  # yupsh: percentile(values, p)
+  function percentile(p,   r, lo) {
+    r = p / 100 * (NR - 1)
+    lo = int(r)
+    if (lo + 1 >= NR) return v[NR]
+    return v[lo + 1] + (r - lo) * (v[lo + 2] - v[lo + 1])
+  }
+
+  END {
+    if (NR == 0) { print "percentile: no numbers in the input" > "/dev/stderr"; exit 1 }
+    n = split(list, p, ",")
+    printf "%-5s  %s\n", "count", NR
+    printf "%-5s  %s\n", "min", v[1]
+    for (i = 1; i <= n; i++) printf "%-5s  %.6g\n", "p" p[i], percentile(p[i])
+    printf "%-5s  %s\n", "max", v[NR]
+    printf "%-5s  %.6g\n", "mean", sum / NR
+  }'
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	ordered `github.com/yupsh/script-examples/internal/ordered`
+	status `github.com/yupsh/script-examples/internal/status`
+	sort `github.com/yupsh/sort`
+)
+
+// binaryCheck is how many leading bytes of a file are checked for a NUL
+// byte, as git does to tell binary files from text
+const binaryCheck = 8000
+
+// Search every file in a directory tree for a pattern, several files at a
+// time, like a mini ripgrep
+// Shell equivalent: See pgrep.sh
+//
+// Pattern: find -> sort -> s.search(). find lists the files and sort fixes
+// their order; s.search() then fans them out to --jobs workers, each
+// scanning a whole file with the compiled regexp, and prints the matches of
+// each file as one group, in the sorted order, however the workers finish.
+// The output is the same for every --jobs value, so --jobs 1 is the
+// sequential version to time it against.
+//
+// Matches print as "path:line", like grep -r. Files that look binary (a NUL
+// byte near the start) are skipped, as grep -I and ripgrep do. The exit
+// status follows the examples' convention (see internal/status): 0 when a
+// line matched, 1 when none did and 2 on an error, including a file that
+// could not be read.
+//
+// Usage: pgrep [--jobs N] [--ignore-case] pattern [directory]
+func main() {
+	opts := flags.New("pgrep", "pattern [directory]")
+	jobs := opts.Int("jobs", runtime.NumCPU(), "search `N` files at a time")
+	ignoreCase := opts.Bool("ignore-case", false, "match case-insensitively")
+	opts.Parse()
+
+	if opts.NArg() == 0 {
+		opts.Fail("missing pattern")
+	}
+	if *jobs < 1 {
+		opts.Fail("--jobs must be at least 1")
+	}
+	pattern := opts.Arg(0)
+	if *ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		opts.Fail("invalid pattern: %v", err)
+	}
+	dir := opts.ArgOr(1, ".")
+
+	// find only warns about a missing directory; make it an error
+	// Shell: [[ -d ${DIR} ]] || exit 2
+	if err := input.Dir(dir); err != nil {
+		status.Result{Err: err}.Exit("pgrep")
+	}
+
+	s := &searcher{re: re, jobs: *jobs}
+	err = gloo.Run(pipe.Pipeline(
+		// Find all regular files
+		// Shell: find "${DIR}" -type f -print0
+		find.Find(find.Dir(dir), find.FileType),
+
+		// Put them in a fixed order, so the output doesn't depend on --jobs
+		// Shell: LC_ALL=C sort -z
+		sort.Sort(),
+
+		// Search them, --jobs at a time, printing each file's matches together
+		// Shell: xargs -0 -P "${JOBS}" grep -H -I -e "${PATTERN}" --
+		s.search(),
+	))
+	if err == nil && s.failed > 0 {
+		err = fmt.Errorf("%d file(s) could not be read", s.failed)
+	}
+	status.Result{Count: s.matches, Err: err}.Exit("pgrep")
+}
+
+// searcher searches files with a pool of workers and prints their matches
+// in the order the files arrived
+type searcher struct {
+	re      *regexp.Regexp
+	jobs    int // number of workers (--jobs)
+	matches int // matching lines printed
+	failed  int // files that could not be read
+}
+
+// result is the matching lines of one file, or the error that stopped its
+// search
+type result struct {
+	path  string
+	lines [][]byte
+	err   error
+}
+
+// search returns the command that reads one path per line from stdin and
+// prints every matching line of those files, grouped by file
+//
+// Shell equivalent:
+//   xargs -0 -P "${JOBS}" grep -H -I -e "${PATTERN}" --
+//
+// The files are searched --jobs at a time by ordered.Map, which hands the
+// results back in the order of the paths: a worker that finishes early has
+// its result held until the files before it are printed, and at most twice
+// --jobs files are in hand at once, so memory stays bounded however many
+// files there are.
+func (s *searcher) search() gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		// Printer: each file's results, in order, as "path:line"
+		// Shell: grep -H
+		out := bufio.NewWriter(stdout)
+		err := ordered.Map(ctx, stdin, s.jobs, func(ctx context.Context, path string) result {
+			return s.file(path)
+		}, func(r result) error {
+			if r.err != nil {
+				// Shell: grep: file: Permission denied
+				fmt.Fprintf(stderr, "pgrep: %v\n", r.err)
+				s.failed++
+				return nil
+			}
+			for _, line := range r.lines {
+				if _, err := fmt.Fprintf(out, "%s:%s\n", r.path, line); err != nil {
+					return err
+				}
+				s.matches++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return out.Flush()
+	})
+}
+
+// file returns the lines of the file at path that match the pattern, or no
+// lines if the file looks binary
+//
+// Shell equivalent:
+//   grep -I -e "${PATTERN}" -- "$path"
+//
+// It runs in a worker, so it only reads s.re, which is safe for concurrent
+// use, and returns everything else in the result.
+func (s *searcher) file(path string) result {
+	f, err := os.Open(path)
+	if err != nil {
+		return result{err: err}
+	}
+	defer f.Close()
+
+	r := bufio.NewReaderSize(f, 64*1024)
+
+	// Skip binary files rather than print lines of garbage
+	// Shell: grep -I
+	if head, _ := r.Peek(binaryCheck); bytes.IndexByte(head, 0) >= 0 {
+		return result{path: path}
+	}
+
+	res := result{path: path}
+	for {
+		line, err := r.ReadBytes('\n')
+		// A last line without a newline is still a line; EOF alone is not
+		if err == nil || len(line) > 0 {
+			line = bytes.TrimSuffix(line, []byte("\n"))
+			if s.re.Match(line) {
+				res.lines = append(res.lines, line)
+			}
+		}
+		if err == io.EOF {
+			return res
+		}
+		if err != nil {
+			return result{err: err}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// corpus writes files text files of lines lines each, every tenth line
+// holding "error", and a binary file holding it too, under a temporary
+// directory; it returns their paths, sorted, one per line, as sort.Sort()
+// hands them to search()
+func corpus(t testing.TB, files, lines int) string {
+	t.Helper()
+	dir := t.TempDir()
+	var paths []string
+	for i := range files {
+		var b strings.Builder
+		for n := range lines {
+			if n%10 == 0 {
+				fmt.Fprintf(&b, "file %d line %d: error: something failed\n", i, n)
+			} else {
+				fmt.Fprintf(&b, "file %d line %d: all is well, nothing to see here\n", i, n)
+			}
+		}
+		paths = append(paths, write(t, dir, fmt.Sprintf("f%04d.txt", i), b.String()))
+	}
+	paths = append(paths, write(t, dir, "z.bin", "\x00error\n"))
+	return strings.Join(paths, "\n") + "\n"
+}
+
+// write writes text to the file name in dir and returns its path
+func write(t testing.TB, dir, name, text string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// search runs s.search() over the paths and returns what it printed
+func search(t testing.TB, s *searcher, paths string, stdout io.Writer) {
+	t.Helper()
+	var stderr bytes.Buffer
+	if err := s.search().Executor()(context.Background(), strings.NewReader(paths), stdout, &stderr); err != nil {
+		t.Fatalf("search: %v\n%s", err, stderr.String())
+	}
+}
+
+// TestSameForEveryJobs checks that the output, and the counts, don't depend
+// on --jobs, and that the binary file is skipped
+func TestSameForEveryJobs(t *testing.T) {
+	paths := corpus(t, 50, 100)
+	re := regexp.MustCompile("error")
+
+	var want bytes.Buffer
+	first := &searcher{re: re, jobs: 1}
+	search(t, first, paths, &want)
+	if first.matches != 50*10 || first.failed != 0 {
+		t.Fatalf("--jobs 1: %d matches, %d failed; want 500, 0", first.matches, first.failed)
+	}
+	if bytes.Contains(want.Bytes(), []byte("z.bin")) {
+		t.Errorf("the binary file was searched")
+	}
+
+	for _, jobs := range []int{2, 3, 8, 64} {
+		var got bytes.Buffer
+		s := &searcher{re: re, jobs: jobs}
+		search(t, s, paths, &got)
+		if got.String() != want.String() {
+			t.Errorf("--jobs %d printed differently from --jobs 1", jobs)
+		}
+		if s.matches != first.matches {
+			t.Errorf("--jobs %d: %d matches, want %d", jobs, s.matches, first.matches)
+		}
+	}
+}
+
+// TestUnreadable checks that a file that can't be read is reported and
+// counted, and the others still searched
+func TestUnreadable(t *testing.T) {
+	dir := t.TempDir()
+	paths := write(t, dir, "a.txt", "error\n") + "\n" +
+		filepath.Join(dir, "missing.txt") + "\n" +
+		write(t, dir, "b.txt", "error\n") + "\n"
+
+	var stdout bytes.Buffer
+	s := &searcher{re: regexp.MustCompile("error"), jobs: 2}
+	search(t, s, paths, &stdout)
+	if s.matches != 2 || s.failed != 1 {
+		t.Errorf("%d matches, %d failed; want 2, 1", s.matches, s.failed)
+	}
+}
+
+// BenchmarkJobs times the same search with one worker, the sequential
+// version, and with more, as the README's benchmark does with --jobs:
+//
+//   go test -bench Jobs
+//
+// The files are cached after the first run, so this measures the
+// matching that the workers share out, not waiting for reads.
+func BenchmarkJobs(b *testing.B) {
+	paths := corpus(b, 200, 500)
+	re := regexp.MustCompile("error|fail(ed|ure)")
+	jobs := []int{1, 2, 4}
+	if n := runtime.NumCPU(); n > 4 {
+		jobs = append(jobs, n)
+	}
+	for _, n := range jobs {
+		b.Run(fmt.Sprintf("jobs=%d", n), func(b *testing.B) {
+			for b.Loop() {
+				search(b, &searcher{re: re, jobs: n}, paths, io.Discard)
+			}
+		})
+	}
+}
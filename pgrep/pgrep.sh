@@ -0,0 +1,47 @@
+#!/bin/bash
+
+# Search every file in a directory tree for a pattern, several files at a time
+# yupsh equivalent: See main.go
+#
+# xargs -P runs a grep per batch of files, in parallel. Unlike the Go
+# version, nothing puts the batches' output back in order, so it comes out as
+# the batches finish, and with several jobs it can change from run to run.
+
+usage() {
+  echo "Usage: $0 [--jobs N] [--ignore-case] pattern [directory]" >&2
+  exit 2
+}
+
+# Parse flags (--jobs N, --ignore-case), then the pattern and directory
+# yupsh: jobs := opts.Int("jobs", runtime.NumCPU(), ...); ignoreCase := opts.Bool(...)
+JOBS=$(nproc)
+OPTIONS=()
+while [[ $1 == --* ]]; do
+  case $1 in
+    --jobs) JOBS=$2; shift 2 ;;
+    --ignore-case) OPTIONS+=(-i); shift ;;
+    *) usage ;;
+  esac
+done
+[[ ${JOBS} =~ ^[1-9][0-9]*$ ]] || usage
+(( $# >= 1 )) || usage
+PATTERN=$1
+DIR=${2:-.}
+
+# find only warns about a missing directory; make it an error
+# yupsh: os.Stat(dir), then status.Result{Err: err}.Exit("pgrep")
+if [[ ! -d ${DIR} ]]; then
+  echo "pgrep: ${DIR}: not a directory" >&2
+  exit 2
+fi
+
+# List the files in a fixed order, then grep them in parallel batches
+# yupsh: find.Find(...) | sort.Sort() | s.search()
+#   -H prints "path:line" even for a batch of one file; -I skips binary files
+# The final awk passes the matches through and sets grep's exit status:
+# 0 if any line matched, 1 if none did (an unreadable file is only reported)
+# yupsh: status.Result{Count: s.matches, Err: err}.Exit("pgrep")
+find "${DIR}" -type f -print0 \
+  | LC_ALL=C sort -z \
+  | xargs -0 -r -n 64 -P "${JOBS}" grep -H -I -E "${OPTIONS[@]}" -e "${PATTERN}" -- \
+  | awk '{ print } END { exit NR == 0 }'
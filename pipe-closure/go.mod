@@ -1,14 +1,16 @@
-module github.com/yupsh/scripts/pipe-closure
+module github.com/yupsh/script-examples/pipe-closure
 
 go 1.25
 
 require (
 	github.com/gloo-foo/framework v0.0.3
 	github.com/gloo-foo/pipe v0.0.3
-	github.com/yupsh/echo v0.0.3
 	github.com/yupsh/grep v0.0.3
 	github.com/yupsh/head v0.0.3
+	github.com/yupsh/script-examples/internal v0.0.0
 	github.com/yupsh/seq v0.0.3
 	github.com/yupsh/tail v0.0.3
 	github.com/yupsh/yes v0.0.3
 )
+
+replace github.com/yupsh/script-examples/internal => ../internal
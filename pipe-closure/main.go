@@ -11,6 +11,8 @@ import (
 	seq "github.com/yupsh/seq"
 	tail "github.com/yupsh/tail"
 	yes "github.com/yupsh/yes"
+	count "github.com/yupsh/script-examples/internal/count"
+	status "github.com/yupsh/script-examples/internal/status"
 )
 
 // Demonstrates how pipes are closed when downstream commands end
@@ -24,29 +26,39 @@ import (
 // - Expensive data generation
 // - Resource conservation
 //
+// Each example puts a count.Lines() counter right after its generator and
+// reports how many lines the generator got to pass on before the pipe was
+// closed: far fewer than it would have produced, whenever head is involved.
+//
 // Shell equivalent: See pipe-closure.sh
 
 func main() {
+	// Lines passed on by the current example's generator
+	var generated int64
+
 	fmt.Println("=== Example 1: head closes pipe after 3 lines ===")
 	fmt.Println("Generating 10 lines, but head will only read 3...")
-	runExample(pipe.Pipeline(
+	runExample(&generated, pipe.Pipeline(
 		seq.Seq("1", "10"),
+		count.Lines(&generated),
 		head.Head(head.LineCount(3)),
 	))
 	fmt.Println()
 
 	fmt.Println("=== Example 2: tail closes pipe after reading for 5 lines ===")
 	fmt.Println("Generating 100 lines, but tail will only keep the last 5...")
-	runExample(pipe.Pipeline(
+	runExample(&generated, pipe.Pipeline(
 		seq.Seq("1", "100"),
+		count.Lines(&generated),
 		tail.Tail(tail.LineCount(5)),
 	))
 	fmt.Println()
 
 	fmt.Println("=== Example 3: Pipeline with head in middle ===")
 	fmt.Println("Generate 20 lines -> head keeps 5 -> grep filters for '3'...")
-	runExample(pipe.Pipeline(
+	runExample(&generated, pipe.Pipeline(
 		seq.Seq("1", "20"),
+		count.Lines(&generated),
 		head.Head(head.LineCount(5)),
 		grep.Grep("3"),
 	))
@@ -54,16 +66,18 @@ func main() {
 
 	fmt.Println("=== Example 4: Yes command with head (infinite stream) ===")
 	fmt.Println("yes generates infinite output, but head stops it after 3 lines...")
-	runExample(pipe.Pipeline(
+	runExample(&generated, pipe.Pipeline(
 		yes.Yes("hello"),
+		count.Lines(&generated),
 		head.Head(head.LineCount(3)),
 	))
 	fmt.Println()
 
 	fmt.Println("=== Example 5: Multiple heads in sequence ===")
 	fmt.Println("Generate 100 -> head 50 -> head 10 -> head 3...")
-	runExample(pipe.Pipeline(
+	runExample(&generated, pipe.Pipeline(
 		seq.Seq("1", "100"),
+		count.Lines(&generated),
 		head.Head(head.LineCount(50)),
 		head.Head(head.LineCount(10)),
 		head.Head(head.LineCount(3)),
@@ -74,19 +88,42 @@ func main() {
 	fmt.Println("Simulating expensive data generation that stops early...")
 	// In real scenarios, pipe closure prevents wasted computation
 	// Note: We use a smaller number (10000) to keep the demo fast
-	runExample(pipe.Pipeline(
+	runExample(&generated, pipe.Pipeline(
 		seq.Seq("1", "10000"),
+		count.Lines(&generated),
 		head.Head(head.LineCount(5)),
 	))
 	fmt.Println()
 
+	fmt.Println("=== Example 7: Counting down with a negative step ===")
+	fmt.Println("Count down from 10000 by 1, but head will only read 5...")
+	// The step sets the direction: seq.Seq("10000", "1"), with the default
+	// step of 1, counts up from 10000 and so generates nothing, as seq does
+	runExample(&generated, pipe.Pipeline(
+		seq.Seq("10000", "-1", "1"),
+		count.Lines(&generated),
+		head.Head(head.LineCount(5)),
+	))
+	fmt.Println()
+
+	fmt.Println("=== Example 8: Counting down through zero ===")
+	fmt.Println("Count down from 5 by 2 towards -10000, but head will only read 6...")
+	runExample(&generated, pipe.Pipeline(
+		seq.Seq("5", "-2", "-10000"),
+		count.Lines(&generated),
+		head.Head(head.LineCount(6)),
+	))
+	fmt.Println()
+
 	fmt.Println("Done! Notice how pipe closure prevents unnecessary work.")
 }
 
-func runExample(cmd gloo.Command) {
+func runExample(generated *int64, cmd gloo.Command) {
+	*generated = 0
 	if err := gloo.Run(cmd); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %s\n", status.Message(err))
 		os.Exit(1)
 	}
+	fmt.Printf("(the generator passed on %d lines)\n", *generated)
 }
 
@@ -5,6 +5,11 @@
 #
 # Key concept: Commands like head/tail read only N lines then close the pipe.
 # This causes upstream commands to receive SIGPIPE when they try to write more.
+#
+# The yupsh version also reports how many lines each generator passed on.
+# There is no stable shell equivalent: the kernel buffers up to 64K in each
+# pipe and head reads in blocks, so a generator gets well ahead of head, by
+# an amount that varies from run to run.
 
 echo "=== Example 1: head closes pipe after 3 lines ==="
 echo "Generating 10 lines, but head will only read 3..."
@@ -37,5 +42,16 @@ echo "Simulating expensive data generation that stops early..."
 (for i in {1..1000000}; do echo "Processing expensive item $i"; done) | head -n 5
 echo
 
+echo "=== Example 7: Counting down with a negative step ==="
+echo "Count down from 10000 by 1, but head will only read 5..."
+# The step sets the direction: seq 10000 1 counts up, and prints nothing
+seq 10000 -1 1 | head -n 5
+echo
+
+echo "=== Example 8: Counting down through zero ==="
+echo "Count down from 5 by 2 towards -10000, but head will only read 6..."
+seq 5 -2 -10000 | head -n 6
+echo
+
 echo "Done! Notice how pipe closure prevents unnecessary work."
 
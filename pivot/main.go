@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	table `github.com/yupsh/script-examples/internal/table`
+)
+
+// Reshape "row<TAB>col<TAB>value" triples into a table
+// Shell equivalent: See pivot.sh
+//
+// Input (long format):    Output (pivot --fill -):
+//   web  mon  12                mon  tue
+//   web  tue  9             web  12   9
+//   db   mon  3             db   3    -
+//
+// Rows and columns appear in the order they are first seen. A cell with no
+// triple gets the --fill value (blank by default); if a cell appears twice,
+// the last value wins.
+//
+// Unlike the line-at-a-time examples, a pivot can't print anything until it
+// has seen every distinct column, so it is built on
+// gloo.AccumulateAndOutput(): buffer all input, then write the whole table.
+//
+// Usage: pivot [--fill VALUE] [--delim TAB] [file...]
+func main() {
+	opts := flags.New("pivot", "[file...]")
+	fill := opts.String("fill", "", "`value` for missing cells")
+	delim := opts.String("delim", "\t", "input field `separator`")
+	opts.Parse()
+
+	// Shell: cat "$@" | awk -F'\t' '{cell[$1, $2] = $3} END {...}'
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		pivot(*delim, *fill),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pivot: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// pivot buffers every triple, then prints a header of the distinct columns
+// and one aligned line per distinct row
+func pivot(delim, fill string) gloo.Command {
+	return gloo.AccumulateAndOutput(func(lines []string, stdout io.Writer) error {
+		var rows, cols []string
+		seenRow := make(map[string]bool)
+		seenCol := make(map[string]bool)
+		cells := make(map[[2]string]string)
+
+		// Shell: {cell[$1, $2] = $3}
+		for n, line := range lines {
+			if line == "" {
+				continue
+			}
+			parts := strings.SplitN(line, delim, 3)
+			if len(parts) < 3 {
+				return fmt.Errorf("line %d: want row, column and value, got %q", n+1, line)
+			}
+			row, col, value := parts[0], parts[1], parts[2]
+
+			if !seenRow[row] {
+				seenRow[row] = true
+				rows = append(rows, row)
+			}
+			if !seenCol[col] {
+				seenCol[col] = true
+				cols = append(cols, col)
+			}
+			cells[[2]string{row, col}] = value
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		// Lay out the table: a header line, then one line per row
+		grid := [][]string{append([]string{""}, cols...)}
+		for _, row := range rows {
+			line := []string{row}
+			for _, col := range cols {
+				value, ok := cells[[2]string{row, col}]
+				if !ok {
+					value = fill
+				}
+				line = append(line, value)
+			}
+			grid = append(grid, line)
+		}
+
+		// Shell: column -t
+		for _, line := range table.Align(grid) {
+			if _, err := fmt.Fprintln(stdout, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,56 @@
+#!/bin/bash
+set -e
+
+# Reshape "row<TAB>col<TAB>value" triples into a table
+# yupsh equivalent: See main.go
+
+# Parse flags (--fill VALUE, --delim SEP), then the files
+# yupsh: fill := opts.String("fill", "", ...); delim := opts.String("delim", "\t", ...)
+FILL=
+DELIM=$'\t'
+while [[ $1 == --* ]]; do
+  case $1 in
+    --fill) FILL=$2; shift 2 ;;
+    --delim) DELIM=$2; shift 2 ;;
+    *) echo "Usage: $0 [--fill VALUE] [--delim TAB] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Buffer every cell, then print the table in END
+# yupsh: pivot(*delim, *fill) built on gloo.AccumulateAndOutput()
+cat "$@" | awk -F"${DELIM}" -v fill="${FILL}" '
+  NF == 0 { next }
+  NF < 3 {
+    printf "pivot: line %d: want row, column and value\n", NR > "/dev/stderr"
+    failed = 1; exit 1
+  }
+
+  # yupsh: cells[[2]string{row, col}] = value
+  {
+    if (!($1 in seenRow)) { seenRow[$1] = 1; row[++rows] = $1 }
+    if (!($2 in seenCol)) { seenCol[$2] = 1; col[++cols] = $2 }
+    value = $0; sub("^[^" FS "]*" FS "[^" FS "]*" FS, "", value)
+    cell[$1, $2] = value
+  }
+
+  # yupsh: table.Align(grid) (shared internal/table package)
+  END {
+    if (failed || rows == 0) exit failed
+    table[0, 0] = ""
+    for (c = 1; c <= cols; c++) table[0, c] = col[c]
+    for (r = 1; r <= rows; r++) {
+      table[r, 0] = row[r]
+      for (c = 1; c <= cols; c++)
+        table[r, c] = ((row[r], col[c]) in cell) ? cell[row[r], col[c]] : fill
+    }
+    for (r = 0; r <= rows; r++)
+      for (c = 0; c <= cols; c++)
+        if (length(table[r, c]) > width[c]) width[c] = length(table[r, c])
+    for (r = 0; r <= rows; r++) {
+      line = ""
+      for (c = 0; c <= cols; c++)
+        line = line (c ? "  " : "") sprintf("%-" width[c] "s", table[r, c])
+      sub(/ +$/, "", line)
+      print line
+    }
+  }'
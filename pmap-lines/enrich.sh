@@ -0,0 +1,8 @@
+#!/bin/bash
+# A stand-in for a slow per-line transform, such as a call to a web API:
+# reads a word on stdin, waits a random 0 to 0.9 seconds, and prints the
+# word, its length and the first 8 hex digits of its sha256
+read -r word
+sleep "0.$((RANDOM % 10))"
+sum=$(printf '%s' "${word}" | sha256sum)
+printf '%s\t%d\t%s\n' "${word}" "${#word}" "${sum:0:8}"
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	ordered `github.com/yupsh/script-examples/internal/ordered`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// placeholder is replaced by the line in the command's arguments, as in
+// xargs -I{}
+const placeholder = "{}"
+
+// errFailed reports that the command failed on some lines; each was
+// reported as it happened
+var errFailed = errors.New("failed")
+
+// Run a command on every line of the input, several lines at a time, and
+// print the outputs in the order of the lines
+// Shell equivalent: See pmap-lines.sh
+//
+//   pmap-lines --jobs 8 ./enrich.sh < words.txt
+//   apple	5	3a7bd3e2
+//   banana	6	b493d483
+//   ...
+//
+// Each line is given to its own run of the command: on stdin, or, where an
+// argument holds {}, in its place, so `pmap-lines dig +short {}` looks up
+// one name per line. --jobs runs go at once, and the slow ones don't hold
+// up the others. But each run's output is printed only once the outputs
+// of all the lines before it are, so the output is in input order for any
+// --jobs, as if the lines were done one at a time; parallel -k does the
+// same. The output of a run is printed whole, and a run that prints
+// nothing leaves no line.
+//
+// The order is restored with sequence numbers: each line is numbered as
+// it's read, the workers send back their results in whatever order they
+// finish, and the printer holds the early ones in a reorder buffer until
+// the number it's waiting for arrives. At most twice --jobs lines are
+// between the reader and the printer, so one slow line can hold up the
+// output, but can't make the buffer grow without end. The pattern is
+// internal/ordered's, which the other parallel examples use too.
+//
+// The command's stderr is passed through as it runs. A line whose run
+// fails is reported with its number, and its output is still printed; the
+// exit status is then 1, and 2 on any other trouble.
+//
+// Usage: pmap-lines [--jobs N] command [arg...]
+func main() {
+	opts := flags.New("pmap-lines", "command [arg...]")
+	jobs := opts.Int("jobs", runtime.NumCPU(), "run the command on `N` lines at a time")
+	opts.Parse()
+	if *jobs < 1 {
+		opts.Fail("--jobs must be at least 1")
+	}
+	if opts.NArg() == 0 {
+		opts.Fail("missing the command to run on each line")
+	}
+	if _, err := exec.LookPath(opts.Arg(0)); err != nil {
+		fmt.Fprintf(os.Stderr, "pmap-lines: %v\n", err)
+		os.Exit(2)
+	}
+
+	// The input is always stdin: the arguments are the command
+	// Shell: awk '{print NR "\t" $0}' | xargs -P "${JOBS}" ... | sort -n | cut -f2-
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(),
+		mapLines(*jobs, run(opts.Args())),
+	))
+	switch {
+	case errors.Is(err, errFailed):
+		os.Exit(1)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "pmap-lines: %s\n", status.Message(err))
+		os.Exit(2)
+	}
+}
+
+// run returns the function that runs the command on one line and returns
+// what it printed
+//
+// Shell equivalent:
+//   printf '%s\n' "$line" | "$@"
+func run(argv []string) func(ctx context.Context, line string) ([]byte, error) {
+	substitute := false
+	for _, arg := range argv[1:] {
+		if strings.Contains(arg, placeholder) {
+			substitute = true
+		}
+	}
+
+	return func(ctx context.Context, line string) ([]byte, error) {
+		args := argv[1:]
+		if substitute {
+			args = make([]string, len(argv)-1)
+			for i, arg := range argv[1:] {
+				args[i] = strings.ReplaceAll(arg, placeholder, line)
+			}
+		}
+
+		cmd := exec.CommandContext(ctx, argv[0], args...)
+		if !substitute {
+			cmd.Stdin = strings.NewReader(line + "\n")
+		}
+		cmd.Stderr = os.Stderr
+		out, err := cmd.Output()
+		if len(out) > 0 && out[len(out)-1] != '\n' {
+			out = append(out, '\n')
+		}
+		return out, err
+	}
+}
+
+// result is the output of one line's run, and the error that ended it
+type result struct {
+	out []byte
+	err error
+}
+
+// mapLines returns the command that runs fn on each line of stdin, jobs
+// lines at a time, and prints the outputs in the order of the lines
+//
+// Shell equivalent:
+//   nl | xargs -P "${JOBS}" ... | sort -n | cut -f2-
+//
+// The fan-out, and the fan-in through the reorder buffer, are ordered.Map's,
+// the worker pool the other parallel examples (pgrep, index, rdns) share;
+// what's left here is the printing. ordered.Map hands the results on in
+// order, so the nth one is line n's, and a failure can be reported with the
+// line it came from.
+func mapLines(jobs int, fn func(ctx context.Context, line string) ([]byte, error)) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		// Printer: each line's output, whole, as soon as it's due.
+		// Unbuffered, since each run is slow enough that its output is
+		// worth seeing at once
+		lines, failed := 0, false
+		err := ordered.Map(ctx, stdin, jobs, func(ctx context.Context, line string) result {
+			out, err := fn(ctx, line)
+			return result{out, err}
+		}, func(r result) error {
+			lines++
+			if r.err != nil {
+				fmt.Fprintf(stderr, "pmap-lines: line %d: %v\n", lines, r.err)
+				failed = true
+			}
+			_, err := stdout.Write(r.out)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if failed {
+			return errFailed
+		}
+		return nil
+	})
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestOrder checks that the outputs come out in the order of the lines, for
+// any --jobs, when each line takes a random time: later lines often finish
+// first, and must wait in the reorder buffer
+func TestOrder(t *testing.T) {
+	rng := rand.New(rand.NewPCG(706, 1))
+	var input, want strings.Builder
+	delays := map[string]time.Duration{}
+	for i := range 200 {
+		line := fmt.Sprintf("line %d", i)
+		delays[line] = time.Duration(rng.IntN(2000)) * time.Microsecond
+		fmt.Fprintln(&input, line)
+		fmt.Fprintln(&want, strings.ToUpper(line))
+	}
+
+	for _, jobs := range []int{1, 2, 4, 16, 64} {
+		t.Run(fmt.Sprintf("jobs=%d", jobs), func(t *testing.T) {
+			var running, most atomic.Int64
+			fn := func(ctx context.Context, line string) ([]byte, error) {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for m := most.Load(); n > m && !most.CompareAndSwap(m, n); m = most.Load() {
+				}
+				time.Sleep(delays[line])
+				return []byte(strings.ToUpper(line) + "\n"), nil
+			}
+
+			var stdout, stderr bytes.Buffer
+			err := mapLines(jobs, fn).Executor()(context.Background(), strings.NewReader(input.String()), &stdout, &stderr)
+			if err != nil {
+				t.Fatalf("mapLines: %v\n%s", err, stderr.String())
+			}
+			if stdout.String() != want.String() {
+				t.Errorf("output out of order:\n%s", stdout.String())
+			}
+			if m := most.Load(); m > int64(jobs) {
+				t.Errorf("%d lines ran at once, more than --jobs %d", m, jobs)
+			}
+		})
+	}
+}
+
+// TestFailedLine checks that a line whose run fails is reported with its
+// number, that its output and the others' are still printed, and that the
+// result is errFailed
+func TestFailedLine(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	fn := func(ctx context.Context, line string) ([]byte, error) {
+		mu.Lock()
+		seen = append(seen, line)
+		mu.Unlock()
+		if line == "b" || line == "d" {
+			return []byte("partial " + line + "\n"), errors.New("exit status 1")
+		}
+		return []byte(line + "\n"), nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := mapLines(3, fn).Executor()(context.Background(), strings.NewReader("a\nb\nc\nd\ne\n"), &stdout, &stderr)
+	if !errors.Is(err, errFailed) {
+		t.Errorf("err = %v, want errFailed", err)
+	}
+	if want := "a\npartial b\nc\npartial d\ne\n"; stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+	if want := "pmap-lines: line 2: exit status 1\npmap-lines: line 4: exit status 1\n"; stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", stderr.String(), want)
+	}
+	if len(seen) != 5 {
+		t.Errorf("ran %d lines, want 5", len(seen))
+	}
+}
+
+// TestRun checks the command gets the line on stdin, or in place of {}, and
+// that output without a final newline gets one
+func TestRun(t *testing.T) {
+	tests := []struct {
+		argv []string
+		line string
+		want string
+	}{
+		{[]string{"cat"}, "a b", "a b\n"},
+		{[]string{"echo", "<{}>", "{}{}"}, "x", "<x> xx\n"},
+		{[]string{"printf", "%s", "{}"}, "no newline", "no newline\n"},
+		{[]string{"true"}, "ignored", ""},
+	}
+	for _, tt := range tests {
+		out, err := run(tt.argv)(context.Background(), tt.line)
+		if err != nil {
+			t.Errorf("%q on %q: %v", tt.argv, tt.line, err)
+			continue
+		}
+		if string(out) != tt.want {
+			t.Errorf("%q on %q printed %q, want %q", tt.argv, tt.line, out, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+#!/bin/bash
+set -eo pipefail
+
+# Run a command on every line of the input, several lines at a time, and
+# print the outputs in the order of the lines
+# yupsh equivalent: See main.go
+#
+# xargs -P runs the commands in parallel but prints them as they finish, so
+# each output line is tagged with the number of its input line and sorted
+# back into order at the end; the Go version prints each line's output as
+# soon as the outputs before it are printed.
+
+# Parse flags (--jobs N), then the command
+# yupsh: jobs := opts.Int("jobs", runtime.NumCPU(), ...)
+JOBS=$(nproc)
+while [[ $1 == --* ]]; do
+  case $1 in
+    --jobs) JOBS=$2; shift 2 ;;
+    *) echo "Usage: $0 [--jobs N] command [arg...]" >&2; exit 2 ;;
+  esac
+done
+if [[ $# -eq 0 ]]; then
+  echo "Usage: $0 [--jobs N] command [arg...]" >&2
+  exit 2
+fi
+
+# Run the command on one numbered line: "n<TAB>line" -> "n<TAB>output",
+# for each line of its output
+# yupsh: run(opts.Args())
+# xargs puts the numbered line after the command, so it's the last argument
+apply() {
+  local numbered=${!#}
+  local n=${numbered%%$'\t'*} line=${numbered#*$'\t'} out status=0
+  set -- "${@:1:$#-1}"
+  local args=() arg substitute=
+  for arg in "$@"; do
+    [[ ${arg} == *{}* ]] && substitute=1
+    args+=("${arg//\{\}/${line}}")
+  done
+  if [[ -n ${substitute} ]]; then
+    out=$("${args[@]}") || status=$?
+  else
+    out=$(printf '%s\n' "${line}" | "$@") || status=$?
+  fi
+  if ((status != 0)); then
+    echo "pmap-lines: line ${n}: exit status ${status}" >&2
+  fi
+  # One printf, so the lines of one output aren't split up by another's
+  [[ -z ${out} ]] || printf '%s\t%s\n' "${n}" "${out//$'\n'/$'\n'${n}$'\t'}"
+  return $((status != 0))
+}
+export -f apply
+
+# Number the lines, run the command --jobs at a time, then restore the
+# order; sort -s keeps each output's own lines in order
+# yupsh: mapLines(*jobs, ...), with ordered.Map's reader, workers and reorder buffer
+# xargs exits 123 when a command failed; like the Go version, that's 1
+# yupsh: errFailed -> os.Exit(1)
+status=0
+awk -v OFS='\t' '{print NR, $0}' \
+  | tr '\n' '\0' \
+  | xargs -0 -r -P "${JOBS}" -n 1 bash -c 'apply "$@"' _ "$@" \
+  | sort -s -t$'\t' -k1,1n \
+  | cut -f2- \
+  || status=$?
+case ${status} in
+  0) ;;
+  123) exit 1 ;;
+  *) exit 2 ;;
+esac
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	echo `github.com/yupsh/echo`
+	find `github.com/yupsh/find`
+	sort `github.com/yupsh/sort`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	dryrun `github.com/yupsh/script-examples/internal/dryrun`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Remove the empty directories in a tree, including the ones that only
+// become empty once their empty subdirectories are gone
+// Shell equivalent: See prune-empty.sh
+//
+//   prune-empty build
+//   build/cache/tmp/a
+//   build/cache/tmp
+//   build/cache
+//
+// One pass in find's order would miss build/cache: it still holds tmp when
+// it is visited. So the directories are sorted deepest first, and visited
+// bottom-up: by the time a directory is checked, everything below it has
+// been. A directory is empty when it has no entries, or only subdirectories
+// already pruned; that test works the same with --dry-run, where nothing is
+// actually removed and each directory that would be is printed marked
+// "[dry-run]" (the examples' dry-run convention, see internal/dryrun). The
+// top directory itself is always kept.
+//
+// Usage: prune-empty [--dry-run] [directory]
+func main() {
+	opts := flags.New("prune-empty", "[directory]")
+	dryRun := opts.DryRun()
+	opts.Parse()
+	dir := opts.ArgOr(0, ".")
+
+	// find only warns about a missing directory, so check it up front
+	if err := input.Dir(dir); err != nil {
+		status.Result{Err: err}.Exit("prune-empty")
+	}
+
+	p := &pruner{top: filepath.Clean(dir), dryRun: *dryRun, pruned: map[string]bool{}}
+
+	err := gloo.Run(pipe.Pipeline(
+		// Every directory below the top one: "depth\tpath"
+		// Shell: find "${DIR}" -mindepth 1 -type d | awk -F/ '{print NF "\t" $0}'
+		find.Find(find.Dir(dir), find.DirectoryType),
+		While(p.depth, input.WholeLine),
+
+		// Deepest first, so children are always handled before parents
+		// Shell: sort -t$'\t' -k1,1 -nr
+		sort.Sort(sort.Field(1), sort.Delimiter("\t"), sort.Numeric, sort.Reverse),
+
+		// Remove each one that is (now) empty
+		// Shell: rmdir "$dir"
+		While(p.prune, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "prune-empty: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+
+	if *dryRun {
+		fmt.Fprintf(os.Stderr, "prune-empty: dry run: %d director(ies) would be removed\n", len(p.pruned))
+	}
+	if p.failed > 0 {
+		fmt.Fprintf(os.Stderr, "prune-empty: %d director(ies) could not be checked or removed\n", p.failed)
+		os.Exit(1)
+	}
+}
+
+// pruner removes directories bottom-up, remembering which ones it removed
+type pruner struct {
+	top    string
+	dryRun bool
+
+	pruned map[string]bool // directories removed (or, with --dry-run, that would be)
+	failed int
+}
+
+// depth is the first While() callback: it echoes each directory below the
+// top one, prefixed with its depth for sorting
+func (p *pruner) depth(args ...any) gloo.Command {
+	path := filepath.Clean(args[0].(string))
+	if path == p.top {
+		return nil
+	}
+	return echo.Echo(fmt.Sprintf("%d\t%s", strings.Count(path, string(filepath.Separator)), path))
+}
+
+// prune is the second While() callback: it removes the directory if it is
+// empty, and echoes its path (marked "[dry-run]" if it was only pruned in
+// the dry run)
+func (p *pruner) prune(args ...any) gloo.Command {
+	_, path, _ := strings.Cut(args[0].(string), "\t")
+
+	empty, err := p.empty(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "prune-empty: %v\n", err)
+		p.failed++
+		return nil
+	}
+	if !empty {
+		return nil
+	}
+
+	if !p.dryRun {
+		// os.Remove() on a directory is rmdir: it fails rather than
+		// delete anything that appeared there since the check
+		if err := os.Remove(path); err != nil {
+			fmt.Fprintf(os.Stderr, "prune-empty: %v\n", err)
+			p.failed++
+			return nil
+		}
+	}
+	p.pruned[path] = true
+	return echo.Echo(dryrun.Line(p.dryRun, path))
+}
+
+// empty reports whether every entry of the directory is a directory already
+// pruned; after real removals those are gone, so this is just "no entries"
+func (p *pruner) empty(path string) (bool, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() || !p.pruned[filepath.Join(path, e.Name())] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,54 @@
+#!/bin/bash
+
+# Remove the empty directories in a tree, including the ones that only
+# become empty once their empty subdirectories are gone
+# yupsh equivalent: See main.go
+
+# Parse flags (--dry-run), then the directory
+# yupsh: dryRun := opts.Bool("dry-run", ...)
+DRY_RUN=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --dry-run) DRY_RUN=1; shift ;;
+    *) echo "Usage: $0 [--dry-run] [directory]" >&2; exit 2 ;;
+  esac
+done
+DIR=${1:-.}
+
+# Directories already pruned, so --dry-run can treat them as gone
+# yupsh: pruned map[string]bool
+declare -A PRUNED
+shopt -s nullglob dotglob
+
+FAILED=0
+# A directory is empty if it only holds directories already pruned
+# yupsh: p.empty(path)
+is_empty() {
+  local entry
+  for entry in "$1"/*; do
+    [[ -d ${entry} && ! -L ${entry} && -n ${PRUNED[${entry}]} ]] || return 1
+  done
+}
+
+# yupsh: find | While(p.depth) | sort.Sort(..., sort.Numeric, sort.Reverse) | While(p.prune)
+while IFS=$'\t' read -r _ dir; do
+  is_empty "${dir}" || continue
+  if (( !DRY_RUN )); then
+    rmdir -- "${dir}" || { FAILED=$((FAILED + 1)); continue; }
+  fi
+  PRUNED[${dir}]=1
+  # yupsh: dryrun.Line(p.dryRun, path)
+  if (( DRY_RUN )); then
+    printf '[dry-run] %s\n' "${dir}"
+  else
+    printf '%s\n' "${dir}"
+  fi
+done < <(find "${DIR}" -mindepth 1 -type d | awk -F/ '{print NF "\t" $0}' | sort -t$'\t' -k1,1nr)
+
+if (( DRY_RUN )); then
+  echo "prune-empty: dry run: ${#PRUNED[@]} director(ies) would be removed" >&2
+fi
+if (( FAILED )); then
+  echo "prune-empty: ${FAILED} director(ies) could not be checked or removed" >&2
+  exit 1
+fi
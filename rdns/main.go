@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	ordered `github.com/yupsh/script-examples/internal/ordered`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Add the hostname of each IP address in a list, by reverse DNS
+// Shell equivalent: See rdns.sh
+//
+//   awk '{print $1}' access.log | sort -u | rdns
+//   127.0.0.1	localhost
+//   192.0.2.10
+//   2001:4860:4860::8888	dns.google
+//
+// Each line is an IP address, printed back with a tab and the name a
+// reverse lookup (a PTR record, or /etc/hosts) gives for it, or nothing
+// after the tab when there is none, so every address comes out and the
+// columns stay aligned for cut, join or awk -F'\t'. A line that isn't an
+// address is passed through the same way, with a warning.
+//
+// Lookups are slow, mostly waiting on a resolver, so --jobs of them run at
+// once, each with its own --timeout, after which the address is taken as
+// unnamed; one dead resolver can't stall the list. The output is still in
+// the input's order, whichever lookups finish first.
+//
+// Usage: rdns [--jobs N] [--timeout DURATION] [file...]
+func main() {
+	opts := flags.New("rdns", "[file...]")
+	jobs := opts.Int("jobs", 16, "look up `N` addresses at a time")
+	timeout := opts.Duration("timeout", 2*time.Second, "give up on a lookup after `duration`")
+	opts.Parse()
+	if *jobs < 1 {
+		opts.Fail("--jobs must be at least 1")
+	}
+	if *timeout <= 0 {
+		opts.Fail("--timeout must be positive")
+	}
+
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+
+		// Read the addresses from the named files, or stdin
+		// Shell: cat "$@"
+		input.Source(opts.Args()...),
+
+		// Look up --jobs addresses at a time, printing them in order
+		// Shell: xargs -P "${JOBS}" -I{} timeout 2 getent hosts {}
+		lookups(*jobs, hostname(net.DefaultResolver, *timeout)),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rdns: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// hostname returns the line function that appends a tab and the name of
+// the address on the line, or just a tab if it has none
+//
+// Shell equivalent:
+//   printf '%s\t%s\n' "$ip" "$(timeout 2 getent hosts "$ip" | awk '{print $2}')"
+func hostname(r *net.Resolver, timeout time.Duration) func(ctx context.Context, line string) string {
+	return func(ctx context.Context, line string) string {
+		addr, err := netip.ParseAddr(strings.TrimSpace(line))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rdns: not an IP address: %q\n", line)
+			return line + "\t"
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		// No name, a timeout and an unreachable resolver are all errors;
+		// each leaves the address unnamed
+		names, err := r.LookupAddr(ctx, addr.String())
+		if err != nil || len(names) == 0 {
+			return line + "\t"
+		}
+		// A PTR record is fully qualified: "dns.google."
+		return line + "\t" + strings.TrimSuffix(names[0], ".")
+	}
+}
+
+// lookups returns the command that runs fn on each line of stdin, jobs
+// lines at a time, and prints what fn returns for each, in the order of
+// the lines
+//
+// Shell equivalent:
+//   nl | xargs -P "${JOBS}" ... | sort -n | cut -f2-
+//
+// It is a While() whose callbacks run at once: ordered.Map, the worker pool
+// pgrep and index use too, hands the names back in the order of the lines,
+// holding a slow lookup's successors until it is done. At most twice jobs
+// lines are in hand, so memory stays bounded.
+func lookups(jobs int, fn func(ctx context.Context, line string) string) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		// Printer: each line's output, in order. Unbuffered, since lookups
+		// are slow enough that each line is worth seeing as it's ready
+		return ordered.Map(ctx, stdin, jobs, fn, func(line string) error {
+			_, err := fmt.Fprintln(stdout, line)
+			return err
+		})
+	})
+}
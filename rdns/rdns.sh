@@ -0,0 +1,46 @@
+#!/bin/bash
+set -eo pipefail
+
+# Add the hostname of each IP address in a list, by reverse DNS
+# yupsh equivalent: See main.go
+#
+# xargs -P runs the lookups in parallel but prints them as they finish, so
+# each line is numbered first and sorted back into order at the end; the
+# Go version prints each line as soon as the lines before it are done.
+
+# Parse flags (--jobs N, --timeout SECONDS), then the files
+# yupsh: jobs := opts.Int("jobs", 16, ...); timeout := opts.Duration("timeout", 2*time.Second, ...)
+JOBS=16
+TIMEOUT=2
+while [[ $1 == --* ]]; do
+  case $1 in
+    --jobs) JOBS=$2; shift 2 ;;
+    --timeout) TIMEOUT=${2%s}; shift 2 ;;
+    *) echo "Usage: $0 [--jobs N] [--timeout SECONDS] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Look up one numbered line: "n<TAB>address" -> "n<TAB>address<TAB>name"
+# yupsh: hostname(net.DefaultResolver, *timeout)
+lookup() {
+  local n=${1%%$'\t'*} ip=${1#*$'\t'} name=
+  if [[ ${ip} =~ ^[0-9.]+$ || ${ip} == *:* ]]; then
+    # getent reads /etc/hosts, then DNS, as the Go resolver does
+    # yupsh: r.LookupAddr(ctx, addr.String()) with context.WithTimeout
+    name=$(timeout "${TIMEOUT}" getent hosts "${ip}" | awk '{print $2; exit}') || true
+  else
+    echo "rdns: not an IP address: \"${ip}\"" >&2
+  fi
+  printf '%s\t%s\t%s\n' "${n}" "${ip}" "${name%.}"
+}
+export -f lookup
+export TIMEOUT
+
+# Number the lines, look them up --jobs at a time, then restore the order
+# yupsh: lookups(*jobs, ...), with ordered.Map's reader, workers and reorder buffer
+cat "$@" \
+  | awk -v OFS='\t' '{print NR, $0}' \
+  | tr '\n' '\0' \
+  | xargs -0 -r -P "${JOBS}" -n 1 bash -c 'lookup "$1"' _ \
+  | sort -t$'\t' -k1,1n \
+  | cut -f2-
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	echo `github.com/yupsh/echo`
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	sort `github.com/yupsh/sort`
+	. `github.com/yupsh/while`
+)
+
+// Find recently modified files, newest first
+// Shell equivalent: See recent.sh
+//
+// This answers "what changed recently?" by filtering on stat data:
+// the While() callback stats each file and returns nil for files older
+// than the cutoff, so they never reach the rest of the pipeline.
+//
+// Usage: recent [--within 24h] [directory]
+//   --within takes any time.ParseDuration value: 90m, 36h, 1h30m
+func main() {
+	// Shell: WITHIN=24h; DIR=${1:-.}
+	opts := flags.New("recent", "[directory]")
+	within := opts.Duration("within", 24*time.Hour, "list files modified within this `duration`")
+	opts.Parse()
+	dir := opts.ArgOr(0, ".")
+
+	// Compute the cutoff once, so every file is compared to the same instant
+	// Shell: find -newermt "-${WITHIN}"
+	cutoff := time.Now().Add(-*within)
+
+	err := gloo.Run(pipe.Pipeline(
+		// Find all regular files
+		// Shell: find "${DIR}" -type f
+		find.Find(find.Dir(dir), find.FileType),
+
+		// Keep only files modified after the cutoff: "mtime\tpath"
+		// Shell: -newermt "${CUTOFF}" -printf '%T@\t%p\n'
+		While(modifiedSince(cutoff), input.WholeLine),
+
+		// Newest first
+		// Shell: sort -t$'\t' -k1,1 -nr
+		sort.Sort(sort.Field(1), sort.Delimiter("\t"), sort.Numeric, sort.Reverse),
+
+		// Replace the epoch timestamp with a readable one
+		// Shell: while IFS=$'\t' read -r mtime path; do ...; done
+		While(formatRow, FieldSeparator("\t")),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "recent: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// modifiedSince returns a While() callback that emits "mtime\tpath" for files
+// modified after cutoff, and skips everything else
+//
+// Shell equivalent:
+//   find -newermt "${CUTOFF}" -printf '%T@\t%p\n'
+//
+// The mtime is emitted as Unix seconds so sort.Numeric can order it.
+func modifiedSince(cutoff time.Time) Body {
+	return func(args ...any) gloo.Command {
+		path := args[0].(string)
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil // Skip files we can't access
+		}
+
+		// Too old: return nil to drop the file from the pipeline
+		if !info.ModTime().After(cutoff) {
+			return nil
+		}
+
+		return echo.Echo(fmt.Sprintf("%d\t%s", info.ModTime().Unix(), path))
+	}
+}
+
+// formatRow prints "YYYY-MM-DD HH:MM:SS  path" from "mtime\tpath"
+// Shell: printf '%s  %s\n' "$(date -d "@${mtime}" '+%F %T')" "${path}"
+func formatRow(args ...any) gloo.Command {
+	if len(args) < 2 {
+		return nil
+	}
+
+	var mtime int64
+	fmt.Sscanf(args[0].(string), "%d", &mtime)
+
+	return echo.Echo(fmt.Sprintf("%s  %s", time.Unix(mtime, 0).Format(time.DateTime), args[1].(string)))
+}
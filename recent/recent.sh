@@ -0,0 +1,33 @@
+#!/bin/bash
+set -e
+
+# Find recently modified files, newest first
+# yupsh equivalent: See main.go
+
+# Parse --within (a duration such as 24h or 90m), then the directory argument
+# yupsh: within := opts.Duration("within", 24*time.Hour, ...)
+WITHIN=24h
+if [[ $1 == --within ]]; then
+  WITHIN=$2
+  shift 2
+fi
+DIR=${1:-.}
+
+# Convert the duration to a cutoff timestamp
+# yupsh: cutoff := time.Now().Add(-*within)
+case ${WITHIN} in
+  *h) AGO="${WITHIN%h} hours ago" ;;
+  *m) AGO="${WITHIN%m} minutes ago" ;;
+  *s) AGO="${WITHIN%s} seconds ago" ;;
+  *) echo "recent: invalid duration: ${WITHIN}" >&2; exit 2 ;;
+esac
+CUTOFF=$(date -d "${AGO}" '+%F %T')
+
+# Find files modified after the cutoff, newest first
+# yupsh: find.Find(...), While(modifiedSince(cutoff), input.WholeLine), sort.Sort(...)
+find "${DIR}" -type f -newermt "${CUTOFF}" -printf '%T@\t%p\n' \
+| sort -t$'\t' -k1,1 -nr \
+| while IFS=$'\t' read -r mtime path; do
+    # yupsh: While(formatRow, FieldSeparator("\t"))
+    printf '%s  %s\n' "$(date -d "@${mtime%.*}" '+%F %T')" "${path}"
+  done
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// rule is one kind of sensitive text: a pattern, and optionally a check
+// that weeds out matches that only look right
+type rule struct {
+	re    *regexp.Regexp
+	valid func(match string) bool // nil accepts every match
+}
+
+// builtins are the patterns selectable with --builtin
+var builtins = map[string]rule{
+	// Shell: [[:alnum:]._%+-]+@[[:alnum:].-]+\.[[:alpha:]]{2,}
+	"email": {re: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+
+	// 13 to 19 digits, optionally grouped by spaces or dashes, that pass
+	// the Luhn check, so order numbers and timestamps are left alone
+	"ccn": {re: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: luhn},
+}
+
+// builtinNames lists the builtins, in the order used when --builtin is not
+// given
+var builtinNames = []string{"email", "ccn"}
+
+// Mask the parts of each line that match sensitive patterns
+// Shell equivalent: See redact.sh
+//
+//   redact --builtin email,ccn support.log
+//   paid by jo@example.com with 4111 1111 1111 1111
+//   ->  paid by *** with ***
+//
+// Each match is replaced by --mask; everything around it, and every line
+// with no match, is copied unchanged. Built-in patterns are applied first,
+// then each --pattern in the order given. With neither flag, all built-in
+// patterns are used.
+//
+// Usage: redact [--builtin email,ccn] [--pattern REGEXP]... [--mask TEXT] [file...]
+func main() {
+	opts := flags.New("redact", "[file...]")
+	builtin := opts.String("builtin", "", "comma-separated built-in `patterns`: "+strings.Join(builtinNames, ", "))
+	patterns := opts.List("pattern", "also mask matches of `regexp`; repeatable")
+	mask := opts.String("mask", "***", "`text` that replaces each match")
+	opts.Parse()
+
+	var rules []rule
+	names := builtinNames
+	if *builtin != "" {
+		names = strings.Split(*builtin, ",")
+	} else if len(*patterns) > 0 {
+		names = nil
+	}
+	for _, name := range names {
+		if !slices.Contains(builtinNames, name) {
+			opts.Fail("--builtin: unknown pattern %q (want %s)", name, strings.Join(builtinNames, ", "))
+		}
+		rules = append(rules, builtins[name])
+	}
+	for _, p := range *patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			opts.Fail("invalid pattern: %v", err)
+		}
+		rules = append(rules, rule{re: re})
+	}
+
+	r := &redactor{rules: rules, mask: *mask}
+
+	// Shell: sed -E 's/PATTERN/***/g' "$@"
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		While(r.line, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "redact: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// redactor applies every rule to each line in turn
+type redactor struct {
+	rules []rule
+	mask  string
+}
+
+// line is the While() callback: it echoes the line with every match masked
+// Shell: sed -E -e 's/P1/***/g' -e 's/P2/***/g'
+func (r *redactor) line(args ...any) gloo.Command {
+	line := args[0].(string)
+	for _, rule := range r.rules {
+		line = rule.re.ReplaceAllStringFunc(line, func(match string) string {
+			if rule.valid != nil && !rule.valid(match) {
+				return match
+			}
+			return r.mask
+		})
+	}
+	return echo.Echo(line)
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum used by card
+// numbers: doubling every second digit from the right, the digit sum is a
+// multiple of 10
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
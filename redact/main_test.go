@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	input `github.com/yupsh/script-examples/internal/input`
+	. `github.com/yupsh/while`
+)
+
+// redact runs the pipeline main() runs, with rules, over text
+func redact(t *testing.T, rules []rule, text string) string {
+	t.Helper()
+	r := &redactor{rules: rules, mask: "***"}
+	var stdout, stderr bytes.Buffer
+	err := While(r.line, input.WholeLine).Executor()(context.Background(), strings.NewReader(text), &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("redact: %v\n%s", err, stderr.String())
+	}
+	return stdout.String()
+}
+
+// all is every built-in rule, as with neither flag
+var all = []rule{builtins["email"], builtins["ccn"]}
+
+// TestUntouched checks that lines with nothing to mask come out exactly as
+// they went in: spacing, tabs, backslashes, echo's own flags and all
+func TestUntouched(t *testing.T) {
+	lines := []string{
+		"plain text",
+		"",
+		"   leading and trailing spaces   ",
+		"two  spaces\tand\ta tab",
+		`back\slash \n \t \\ not escapes`,
+		"-n",
+		"-e \\x41",
+		"-- -E",
+		"100% of %s and %d",
+		"naïve café ünïcode 日本語",
+		"order 1234567890123 (13 digits, fails Luhn)",
+		"4111 1111 1111 1112 (fails Luhn)",
+		"timestamp 20261015093000124 (fails Luhn)",
+		"12 digits 411111111111 only",
+		"user@localhost has no dot",
+		"@example.com has no user",
+		"a.b@c.d is too short a domain",
+		`"quotes" and 'single' and $vars ${HOME} $(cmd)`,
+	}
+	for _, line := range lines {
+		if got := redact(t, all, line+"\n"); got != line+"\n" {
+			t.Errorf("%q came out as %q", line, got)
+		}
+	}
+
+	// The same lines as one input, so none is joined to or split from
+	// another
+	text := strings.Join(lines, "\n") + "\n"
+	if got := redact(t, all, text); got != text {
+		t.Errorf("the lines together came out as:\n%q\nwant:\n%q", got, text)
+	}
+}
+
+// TestMasked checks that only the matches are masked, and the text around
+// them is kept as it was
+func TestMasked(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"paid by jo@example.com with 4111 1111 1111 1111", "paid by *** with ***"},
+		{"  jo@example.com  ", "  ***  "},
+		{"card 4111-1111-1111-1111, again 4111111111111111.", "card ***, again ***."},
+		{"a@b.io\tb@c.io", "***\t***"},
+		{"ok 4111 1111 1111 1112 bad 4111 1111 1111 1111", "ok 4111 1111 1111 1112 bad ***"},
+	}
+	for _, tt := range tests {
+		if got := redact(t, all, tt.in+"\n"); got != tt.want+"\n" {
+			t.Errorf("%q came out as %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestPattern checks a --pattern rule after the built-ins
+func TestPattern(t *testing.T) {
+	rules := append(all, rule{re: regexp.MustCompile(`token=\w+`)})
+	got := redact(t, rules, "token=abc123 from jo@example.com\nno secrets  here\n")
+	if want := "*** from ***\nno secrets  here\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestLuhn checks the checksum on known card numbers and near misses
+func TestLuhn(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"4111111111111111", true},
+		{"4111 1111 1111 1111", true},
+		{"5500-0000-0000-0004", true},
+		{"378282246310005", true},
+		{"4111111111111112", false},
+		{"1234567890123", false},
+		{"79927398713", true},
+		{"79927398710", false},
+	}
+	for _, tt := range tests {
+		if got := luhn(tt.s); got != tt.want {
+			t.Errorf("luhn(%q) = %t, want %t", tt.s, got, tt.want)
+		}
+	}
+}
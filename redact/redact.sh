@@ -0,0 +1,85 @@
+#!/bin/bash
+set -e
+
+# Mask the parts of each line that match sensitive patterns
+# yupsh equivalent: See main.go
+#
+# --pattern takes a POSIX extended regexp here, and an RE2 regexp in the Go
+# version; simple patterns mean the same in both.
+
+# Parse flags (--builtin LIST, --pattern REGEXP..., --mask TEXT), then the files
+# yupsh: builtin := opts.String("builtin", ...); patterns := opts.List("pattern", ...)
+BUILTIN=
+PATTERNS=()
+MASK='***'
+while [[ $1 == --* ]]; do
+  case $1 in
+    --builtin) BUILTIN=$2; shift 2 ;;
+    --pattern) PATTERNS+=("$2"); shift 2 ;;
+    --mask) MASK=$2; shift 2 ;;
+    *) echo "Usage: $0 [--builtin email,ccn] [--pattern REGEXP]... [--mask TEXT] [file...]" >&2; exit 2 ;;
+  esac
+done
+if [[ -z ${BUILTIN} && ${#PATTERNS[@]} -eq 0 ]]; then
+  BUILTIN=email,ccn
+fi
+
+# Apply each rule to every line in turn
+# yupsh: While(r.line, input.WholeLine) with ReplaceAllStringFunc() per rule
+USER_PATTERNS=$(printf '%s\n' "${PATTERNS[@]}")
+cat "$@" | BUILTIN="${BUILTIN}" USER_PATTERNS="${USER_PATTERNS}" MASK="${MASK}" awk '
+  BEGIN {
+    builtin["email"] = "[[:alnum:]._%+-]+@[[:alnum:].-]+\\.[[:alpha:]][[:alpha:]]+"
+    builtin["ccn"] = "[0-9]([ -]?[0-9])+"
+    n = 0
+    split(ENVIRON["BUILTIN"], names, ",")
+    for (i = 1; i in names; i++) {
+      if (!(names[i] in builtin)) {
+        printf "redact: --builtin: unknown pattern \"%s\" (want email, ccn)\n", names[i] > "/dev/stderr"
+        exit 2
+      }
+      rule[++n] = builtin[names[i]]; check[n] = names[i]
+    }
+    if (ENVIRON["USER_PATTERNS"] != "") {
+      m = split(ENVIRON["USER_PATTERNS"], user, "\n")
+      for (i = 1; i <= m; i++) rule[++n] = user[i]
+    }
+    mask = ENVIRON["MASK"]
+  }
+
+  # yupsh: luhn(match)
+  function luhn(s,   i, d, sum, double) {
+    for (i = length(s); i >= 1; i--) {
+      d = substr(s, i, 1)
+      if (d !~ /[0-9]/) continue
+      if (double) { d *= 2; if (d > 9) d -= 9 }
+      sum += d; double = !double
+    }
+    return sum % 10 == 0
+  }
+
+  # yupsh: rule.valid — a card number has 13 to 19 digits, not inside a
+  # longer word, and passes the Luhn check
+  function valid(r, s, before, after,   digits) {
+    if (check[r] != "ccn") return 1
+    digits = s; gsub(/[^0-9]/, "", digits)
+    return length(digits) >= 13 && length(digits) <= 19 &&
+      before !~ /[[:alnum:]_]$/ && after !~ /^[[:alnum:]_]/ && luhn(s)
+  }
+
+  # yupsh: line = rule.re.ReplaceAllStringFunc(line, ...)
+  {
+    line = $0
+    for (r = 1; r <= n; r++) {
+      out = ""; rest = line
+      while (rest != "" && match(rest, rule[r]) && RLENGTH > 0) {
+        hit = substr(rest, RSTART, RLENGTH)
+        before = out substr(rest, 1, RSTART - 1)
+        after = substr(rest, RSTART + RLENGTH)
+        out = before (valid(r, hit, before, after) ? mask : hit)
+        rest = after
+      }
+      line = out rest
+    }
+    print line
+  }'
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	dryrun `github.com/yupsh/script-examples/internal/dryrun`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Rename files in bulk by applying a regexp substitution to their names
+// Shell equivalent: See rename.sh
+//
+//   rename --from '^IMG_(\d+)\.JPG$' --to 'photo-$1.jpg' ./photos
+//   photos/IMG_0001.JPG -> photos/photo-0001.jpg
+//   photos/IMG_0002.JPG -> photos/photo-0002.jpg
+//
+// Only base names are rewritten; files stay in their directories, and names
+// the pattern doesn't match are left alone. --to may refer to groups of
+// --from as $1, ${name}, etc. (regexp.Expand).
+//
+// Every rename is planned before any is made, and the plan is refused as a
+// whole if two files would get the same name, a new name already exists, or
+// a new name is invalid. --dry-run prints the plan, each line marked
+// "[dry-run]", without renaming (the examples' dry-run convention, see
+// internal/dryrun).
+//
+// Usage: rename --from REGEXP --to REPLACEMENT [--dry-run] [directory]
+func main() {
+	opts := flags.New("rename", "--from REGEXP --to REPLACEMENT [directory]")
+	from := opts.String("from", "", "`regexp` matched against each file's base name")
+	to := opts.String("to", "", "`replacement` for the match; $1 etc. refer to its groups")
+	dryRun := opts.DryRun()
+	opts.Parse()
+	if *from == "" {
+		opts.Fail("--from is required")
+	}
+	re, err := regexp.Compile(*from)
+	if err != nil {
+		opts.Fail("invalid --from: %v", err)
+	}
+	dir := opts.ArgOr(0, ".")
+
+	p := &planner{re: re, to: *to}
+
+	err = gloo.Run(pipe.Pipeline(
+		// Shell: find "${DIR}" -type f
+		find.Find(find.Dir(dir), find.FileType),
+
+		// Work out each file's new name
+		// Shell: new=$(basename "$file" | sed -E "s/$FROM/$TO/")
+		While(p.add, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rename: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+
+	// Refuse the whole plan if any rename is unsafe
+	if problems := p.check(); len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "rename: %s\n", problem)
+		}
+		fmt.Fprintln(os.Stderr, "rename: nothing renamed")
+		os.Exit(1)
+	}
+
+	for _, r := range p.renames {
+		fmt.Println(dryrun.Line(*dryRun, r.old+" -> "+r.new))
+		if *dryRun {
+			continue
+		}
+		// Shell: mv -n "$file" "$new"
+		if err := os.Rename(r.old, r.new); err != nil {
+			fmt.Fprintf(os.Stderr, "rename: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *dryRun {
+		fmt.Fprintf(os.Stderr, "rename: dry run: %d file(s) would be renamed\n", len(p.renames))
+	}
+}
+
+// renaming is one planned rename
+type renaming struct {
+	old, new string
+}
+
+// planner collects the renames, one file at a time
+type planner struct {
+	re      *regexp.Regexp
+	to      string
+	renames []renaming
+}
+
+// add is the While() callback: it plans the rename of one file, if its name
+// matches and the substitution changes it
+func (p *planner) add(args ...any) gloo.Command {
+	path := args[0].(string)
+	base := filepath.Base(path)
+
+	// Shell: sed -E "s/$FROM/$TO/"
+	if newBase := p.re.ReplaceAllString(base, p.to); newBase != base {
+		p.renames = append(p.renames, renaming{path, filepath.Join(filepath.Dir(path), newBase)})
+	}
+	return nil
+}
+
+// check sorts the plan and returns a description of each rename that must
+// not go ahead: a new name that is empty or would move the file, that
+// another rename also produces, or that exists already
+func (p *planner) check() []string {
+	var problems []string
+	claimed := make(map[string]string) // new path -> old path claiming it
+
+	slices.SortFunc(p.renames, func(a, b renaming) int { return strings.Compare(a.old, b.old) })
+	for _, r := range p.renames {
+		base := filepath.Base(r.new)
+		switch {
+		case base == "." || base == ".." || filepath.Dir(r.new) != filepath.Dir(r.old):
+			problems = append(problems, fmt.Sprintf("%s: new name %q is not a plain file name", r.old, r.new))
+		case claimed[r.new] != "":
+			problems = append(problems, fmt.Sprintf("%s and %s would both be renamed to %s", claimed[r.new], r.old, r.new))
+		case fileExists(r.new):
+			problems = append(problems, fmt.Sprintf("%s: %s already exists", r.old, r.new))
+		}
+		if claimed[r.new] == "" {
+			claimed[r.new] = r.old
+		}
+	}
+	return problems
+}
+
+// fileExists reports whether anything exists at path, without following a
+// final symlink
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
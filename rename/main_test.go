@@ -0,0 +1,143 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// plan makes the named files in a temporary directory, plans renaming them
+// with from and to, and returns the directory and what check() found
+func plan(t *testing.T, from, to string, names ...string) (string, *planner, []string) {
+	t.Helper()
+	dir := t.TempDir()
+	p := &planner{re: regexp.MustCompile(from), to: to}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Added in reverse, as find might list them: check() sorts the plan
+	for _, name := range slices.Backward(names) {
+		p.add(filepath.Join(dir, name))
+	}
+	return dir, p, p.check()
+}
+
+// TestNoCollision checks a plan with nothing wrong: only the matching names
+// are renamed, in order, and nothing is refused
+func TestNoCollision(t *testing.T) {
+	dir, p, problems := plan(t, `^IMG_(\d+)\.JPG$`, "photo-$1.jpg", "IMG_0001.JPG", "IMG_0002.JPG", "notes.txt", "IMG_x.JPG")
+	if len(problems) > 0 {
+		t.Fatalf("refused: %q", problems)
+	}
+	want := []renaming{
+		{filepath.Join(dir, "IMG_0001.JPG"), filepath.Join(dir, "photo-0001.jpg")},
+		{filepath.Join(dir, "IMG_0002.JPG"), filepath.Join(dir, "photo-0002.jpg")},
+	}
+	if !slices.Equal(p.renames, want) {
+		t.Errorf("plan = %q, want %q", p.renames, want)
+	}
+}
+
+// TestUnchangedName checks that a name the substitution leaves as it is is
+// not a rename, and so can't collide with anything
+func TestUnchangedName(t *testing.T) {
+	_, p, problems := plan(t, `\.jpeg$`, ".jpg", "a.jpg", "b.jpeg")
+	if len(problems) > 0 || len(p.renames) != 1 {
+		t.Errorf("plan %q, problems %q; want only b.jpeg renamed", p.renames, problems)
+	}
+}
+
+// TestCollisions checks each reason check() refuses a plan
+func TestCollisions(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		files    []string
+		want     []string // the problems, with the directory as "D" and its parent as "PARENT"
+	}{
+		{
+			"two files to one name",
+			`^(\w+)-v\d\.txt$`, "$1.txt",
+			[]string{"report-v1.txt", "report-v2.txt"},
+			[]string{"D/report-v1.txt and D/report-v2.txt would both be renamed to D/report.txt"},
+		},
+		{
+			"three files to one name",
+			`\d`, "",
+			[]string{"a1", "a2", "a3"},
+			[]string{
+				"D/a1 and D/a2 would both be renamed to D/a",
+				"D/a1 and D/a3 would both be renamed to D/a",
+			},
+		},
+		{
+			"new name exists",
+			`^draft-`, "",
+			[]string{"draft-plan.md", "plan.md"},
+			[]string{"D/draft-plan.md: D/plan.md already exists"},
+		},
+		{
+			"new name is another file being renamed away",
+			`^(\w)$`, "${1}${1}",
+			[]string{"a", "aa"},
+			[]string{"D/a: D/aa already exists"},
+		},
+		{
+			"new name moves the file",
+			`^(\w+)\.log$`, "old/$1.log",
+			[]string{"app.log"},
+			[]string{`D/app.log: new name "D/old/app.log" is not a plain file name`},
+		},
+		{
+			"new name is ..",
+			`^x$`, "..",
+			[]string{"x"},
+			[]string{`D/x: new name "PARENT" is not a plain file name`}, // Join cleans ".." away
+		},
+		{
+			"new name is empty",
+			`^.*$`, "",
+			[]string{"x"},
+			[]string{`D/x: new name "D" is not a plain file name`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, _, problems := plan(t, tt.from, tt.to, tt.files...)
+			got := make([]string, len(problems))
+			for i, problem := range problems {
+				got[i] = strings.ReplaceAll(problem, dir, "D")
+			}
+			want := make([]string, len(tt.want))
+			for i, problem := range tt.want {
+				want[i] = strings.ReplaceAll(problem, "PARENT", filepath.Dir(dir))
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("problems = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// TestDanglingSymlink checks that a symlink in the way counts as existing,
+// even when what it points to doesn't
+func TestDanglingSymlink(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Symlink(filepath.Join(dir, "nowhere"), filepath.Join(dir, "b")); err != nil {
+		t.Skip(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := &planner{re: regexp.MustCompile(`^a$`), to: "b"}
+	p.add(filepath.Join(dir, "a"))
+	if problems := p.check(); len(problems) != 1 || !strings.HasSuffix(problems[0], "already exists") {
+		t.Errorf("problems = %q, want b already exists", problems)
+	}
+}
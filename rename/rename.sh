@@ -0,0 +1,75 @@
+#!/bin/bash
+set -e
+
+# Rename files in bulk by applying a regexp substitution to their names
+# yupsh equivalent: See main.go
+#
+# --from is a POSIX extended regexp here (use [0-9], not \d) and --to uses
+# sed's \1 for groups; the Go version takes RE2 and $1.
+
+# Parse flags (--from REGEXP, --to REPLACEMENT, --dry-run), then the directory
+# yupsh: from := opts.String("from", ...); to := opts.String("to", ...); dryRun := ...
+FROM=
+TO=
+DRY_RUN=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --from) FROM=$2; shift 2 ;;
+    --to) TO=$2; shift 2 ;;
+    --dry-run) DRY_RUN=1; shift ;;
+    *) echo "Usage: $0 --from REGEXP --to REPLACEMENT [--dry-run] [directory]" >&2; exit 2 ;;
+  esac
+done
+if [[ -z ${FROM} ]]; then
+  echo "rename: --from is required" >&2
+  exit 2
+fi
+DIR=${1:-.}
+
+# Plan every rename first
+# yupsh: While(p.add, input.WholeLine) after find.Find()
+OLD=()
+NEW=()
+while IFS= read -r -d '' file; do
+  base=${file##*/}
+  # \001 as the delimiter, so --from and --to may contain "/"
+  new_base=$(sed -E $'s\001'"${FROM}"$'\001'"${TO}"$'\001''g' <<< "${base}")
+  if [[ ${new_base} != "${base}" ]]; then
+    OLD+=("${file}")
+    NEW+=("${file%/*}/${new_base}")
+  fi
+done < <(find "${DIR}" -type f -print0 | sort -z)
+
+# Refuse the whole plan if any rename is unsafe
+# yupsh: p.check()
+declare -A CLAIMED
+PROBLEMS=0
+for i in "${!OLD[@]}"; do
+  old=${OLD[i]} new=${NEW[i]}
+  base=${new##*/}
+  if [[ -z ${base} || ${base} == . || ${base} == .. || ${new%/*} != "${old%/*}" ]]; then
+    echo "rename: ${old}: new name \"${new}\" is not a plain file name" >&2; PROBLEMS=1
+  elif [[ -n ${CLAIMED[${new}]} ]]; then
+    echo "rename: ${CLAIMED[${new}]} and ${old} would both be renamed to ${new}" >&2; PROBLEMS=1
+  elif [[ -e ${new} || -L ${new} ]]; then
+    echo "rename: ${old}: ${new} already exists" >&2; PROBLEMS=1
+  fi
+  CLAIMED[${new}]=${CLAIMED[${new}]:-${old}}
+done
+if (( PROBLEMS )); then
+  echo "rename: nothing renamed" >&2
+  exit 1
+fi
+
+# yupsh: os.Rename(r.old, r.new), or with --dry-run dryrun.Line(...) alone
+for i in "${!OLD[@]}"; do
+  if (( DRY_RUN )); then
+    echo "[dry-run] ${OLD[i]} -> ${NEW[i]}"
+  else
+    echo "${OLD[i]} -> ${NEW[i]}"
+    mv -n -- "${OLD[i]}" "${NEW[i]}"
+  fi
+done
+if (( DRY_RUN )); then
+  echo "rename: dry run: ${#OLD[@]} file(s) would be renamed" >&2
+fi
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Send each line of a stream to one of several files, chosen by the first
+// of a list of patterns it matches, as syslog routes messages
+// Shell equivalent: See route.sh
+//
+//   route --rule 'sshd=auth.log' --rule 'kernel:=kern.log' \
+//         --rule 'error|fail=errors.log' --default other.log messages.log
+//
+// Each --rule is a regexp, an =, and a file; a pattern may contain = itself,
+// as the file is what follows the last one. The rules are tried in the
+// order given, and the first that matches a line decides where it goes, so
+// a line from sshd that mentions a failure goes to auth.log only. A line no
+// rule matches goes to the --default file, or, without one, to stdout, so
+// route can sit in a pipeline and pass on what it doesn't claim:
+//   tail -f /var/log/messages | route --append --rule 'sshd=auth.log' | grep ...
+//
+// This is tee turned inside out: tee copies every line to every file, route
+// writes each line to exactly one. "-" as a file means stdout.
+//
+// Every file is created before the first line is read, as tee does, so a
+// path that can't be written fails at once, and a file whose rule never
+// matched is left empty. --append adds to the files instead, as tee -a.
+// Several rules may name the same file.
+//
+// Usage: route [--append] [--default FILE] --rule 'PATTERN=FILE' [--rule ...] [file...]
+func main() {
+	opts := flags.New("route", "--rule 'PATTERN=FILE' [--rule ...] [file...]")
+	rules := opts.List("rule", "send the lines matching a regexp to a file, as `PATTERN=FILE`; repeatable, the first match wins")
+	fallback := opts.String("default", "-", "send the lines no rule matches to `FILE`")
+	appendTo := opts.Bool("append", false, "add to the files instead of overwriting them")
+	opts.Parse()
+	if len(*rules) == 0 {
+		opts.Fail("no --rule given: nothing to route")
+	}
+
+	r := &router{files: map[string]*destination{}, appendTo: *appendTo}
+	for _, text := range *rules {
+		pattern, file, ok := parseRule(text)
+		if !ok {
+			opts.Fail("invalid rule %q: want PATTERN=FILE", text)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			opts.Fail("invalid rule %q: %v", text, err)
+		}
+		dest, err := r.open(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "route: %v\n", err)
+			os.Exit(1)
+		}
+		r.rules = append(r.rules, rule{re, dest})
+	}
+	dest, err := r.open(*fallback)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "route: %v\n", err)
+		os.Exit(1)
+	}
+	r.fallback = dest
+
+	err = gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+
+		// Read the named files, or stdin
+		// Shell: cat "$@"
+		input.Source(opts.Args()...),
+
+		// Write each line where its first matching rule says
+		// Shell: awk '/P1/ {print > "F1"; next} ... {print}'
+		While(r.line, input.WholeLine),
+	))
+	if closeErr := r.close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "route: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// parseRule splits a --rule into its pattern and file, at the last =
+func parseRule(text string) (pattern, file string, ok bool) {
+	i := strings.LastIndex(text, "=")
+	if i <= 0 || i == len(text)-1 {
+		return "", "", false
+	}
+	return text[:i], text[i+1:], true
+}
+
+// rule sends the lines matching pattern to dest
+type rule struct {
+	pattern *regexp.Regexp
+	dest    *destination
+}
+
+// destination is an output file, shared by all the rules that name it
+type destination struct {
+	file *os.File      // nil for stdout ("-")
+	w    *bufio.Writer // buffers the writes to file
+}
+
+// router is the state the While() callback uses to place each line
+type router struct {
+	rules    []rule
+	fallback *destination // where the lines no rule matches go (--default)
+	appendTo bool         // add to the files (--append)
+
+	files map[string]*destination // open files, by cleaned path
+	order []*destination          // the same, in the order opened, to close
+}
+
+// open returns the destination for file, creating (or, with --append,
+// opening) it the first time it is named
+//
+// Shell equivalent:
+//   : > "$file"    or, with --append,    touch "$file"
+func (r *router) open(file string) (*destination, error) {
+	key := filepath.Clean(file)
+	if d, ok := r.files[key]; ok {
+		return d, nil
+	}
+
+	d := &destination{}
+	if file != "-" {
+		flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if r.appendTo {
+			flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+		f, err := os.OpenFile(file, flag, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		d.file, d.w = f, bufio.NewWriter(f)
+	}
+	r.files[key] = d
+	r.order = append(r.order, d)
+	return d, nil
+}
+
+// line is the While() callback: it picks the destination of the line, and
+// returns the command that writes it there
+//
+// Shell equivalent:
+//   /P1/ {print > "F1"; next}  /P2/ {print > "F2"; next}  {print > "DEFAULT"}
+func (r *router) line(args ...any) gloo.Command {
+	line := args[0].(string)
+
+	dest := r.fallback
+	for _, rule := range r.rules {
+		if rule.pattern.MatchString(line) {
+			dest = rule.dest
+			break
+		}
+	}
+
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		w := io.Writer(stdout)
+		if dest.w != nil {
+			w = dest.w
+		}
+		_, err := fmt.Fprintln(w, line)
+		return err
+	})
+}
+
+// close flushes and closes every file, returning the first error
+func (r *router) close() error {
+	var first error
+	for _, d := range r.order {
+		if d.file == nil {
+			continue
+		}
+		err := d.w.Flush()
+		if closeErr := d.file.Close(); err == nil {
+			err = closeErr
+		}
+		if first == nil && err != nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,76 @@
+#!/bin/bash
+set -eo pipefail
+
+# Send each line of a stream to one of several files, chosen by the first
+# of a list of patterns it matches
+# yupsh equivalent: See main.go
+#
+# awk does the routing: each rule is a pattern and a file, tried in order,
+# and "print > file" keeps each file open once it has been written to.
+
+# Parse flags (--rule PATTERN=FILE, repeatable; --default FILE; --append),
+# then the files
+# yupsh: rules := opts.List("rule", ...); opts.String("default", "-", ...); opts.Bool("append", ...)
+PATTERNS=()
+FILES=()
+DEFAULT=-
+APPEND=
+while [[ $1 == --* ]]; do
+  case $1 in
+    --rule)
+      # The file is what follows the last =
+      # yupsh: parseRule(text)
+      if [[ $2 != ?*=?* ]]; then
+        echo "route: invalid rule \"$2\": want PATTERN=FILE" >&2
+        exit 2
+      fi
+      PATTERNS+=("${2%=*}")
+      FILES+=("${2##*=}")
+      shift 2
+      ;;
+    --default) DEFAULT=$2; shift 2 ;;
+    --append) APPEND=1; shift ;;
+    *) echo "Usage: $0 [--append] [--default FILE] --rule 'PATTERN=FILE' [--rule ...] [file...]" >&2; exit 2 ;;
+  esac
+done
+if ((${#PATTERNS[@]} == 0)); then
+  echo "route: no --rule given: nothing to route" >&2
+  exit 2
+fi
+
+# Create every file before reading a line, or just make sure it exists
+# with --append
+# yupsh: r.open(file), with O_TRUNC or O_APPEND
+for file in "${FILES[@]}" "${DEFAULT}"; do
+  [[ ${file} == - ]] && continue
+  if [[ -n ${APPEND} ]]; then
+    : >> "${file}"
+  else
+    : > "${file}"
+  fi
+done
+
+# The rules, one "pattern<TAB>file" line each, for awk to read first;
+# "-" is stdout
+# yupsh: r.rules = append(r.rules, rule{re, dest})
+rules() {
+  local i
+  for i in "${!PATTERNS[@]}"; do
+    printf '%s\t%s\n' "${PATTERNS[i]}" "${FILES[i]/#-/\/dev\/stdout}"
+  done
+}
+
+# The first matching rule wins; unmatched lines go to the default. The files
+# were truncated already, so awk only ever appends.
+# yupsh: While(r.line, input.WholeLine)
+cat "$@" | awk -F'\t' -v fallback="${DEFAULT/#-/\/dev\/stdout}" '
+  FNR == NR {pattern[++n] = $1; file[n] = $2; next}
+  {
+    for (i = 1; i <= n; i++) {
+      if ($0 ~ pattern[i]) {
+        print >> file[i]
+        next
+      }
+    }
+    print >> fallback
+  }' <(rules) -
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Pass on only every Nth line of a stream, to thin out a chatty log
+// Shell equivalent: See sample-stream.sh
+//
+//   tail -f app.log | sample-stream --every 100
+//
+// Lines N, 2N, 3N, ... are printed, as they arrive, and the rest are
+// dropped, so the output runs at 1/N of the input's rate and still spreads
+// over all of it. With --first, line 1 is printed too, so even a short
+// input shows something, and the output of a long one starts at once
+// rather than after N lines.
+//
+// The sample is deterministic: the same input always gives the same lines,
+// unlike the random picks of weighted-sample, and only a count is kept, so
+// it runs in constant memory on a stream with no end. The price is that a
+// pattern repeating every N lines is seen always, or never.
+//
+// Usage: sample-stream [--every N] [--first] [file...]
+func main() {
+	opts := flags.New("sample-stream", "[file...]")
+	every := opts.Int("every", 10, "print every `N`th line")
+	first := opts.Bool("first", false, "also print the first line")
+	opts.Parse()
+	if *every < 1 {
+		opts.Fail("--every must be at least 1")
+	}
+
+	s := &sampler{every: *every, first: *first}
+
+	// Shell: awk 'NR % 10 == 0 {print; fflush()}' "$@"
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		While(s.line, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sample-stream: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// sampler counts the lines of the stream
+type sampler struct {
+	every int  // print every Nth line (--every)
+	first bool // print line 1 too (--first)
+	count int  // lines seen so far
+}
+
+// line is the While() callback: it echoes the line if its number is a
+// multiple of every, or if it is the first and first is set
+// Shell: NR % n == 0 || (first && NR == 1)
+func (s *sampler) line(args ...any) gloo.Command {
+	line := args[0].(string)
+	s.count++
+	if s.count%s.every == 0 || (s.first && s.count == 1) {
+		return echo.Echo(line)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+#!/bin/bash
+set -e
+
+# Pass on only every Nth line of a stream, to thin out a chatty log
+# yupsh equivalent: See main.go
+
+# Parse flags (--every N, --first), then the files
+# yupsh: every := opts.Int("every", 10, ...); first := opts.Bool("first", ...)
+EVERY=10
+FIRST=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --every) EVERY=$2; shift 2 ;;
+    --first) FIRST=1; shift ;;
+    *) echo "Usage: $0 [--every N] [--first] [file...]" >&2; exit 2 ;;
+  esac
+done
+if (( EVERY < 1 )); then
+  echo "Usage: $0 [--every N] [--first] [file...]" >&2
+  exit 2
+fi
+
+# Print lines N, 2N, 3N, ..., and line 1 with --first; fflush so each one
+# shows at once on a live stream
+# yupsh: While(s.line, input.WholeLine), with a line counter
+cat "$@" | awk -v n="${EVERY}" -v first="${FIRST}" '
+  NR % n == 0 || (first && NR == 1) {print; fflush()}'
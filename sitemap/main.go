@@ -0,0 +1,240 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	grep `github.com/yupsh/grep`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	table `github.com/yupsh/script-examples/internal/table`
+	sort `github.com/yupsh/sort`
+	. `github.com/yupsh/while`
+	html `golang.org/x/net/html`
+)
+
+// untitled stands in for the title of a page that has none
+const untitled = "(untitled)"
+
+// List the pages of a static site, each with the <title> from its HTML,
+// in path order
+// Shell equivalent: See sitemap.sh
+//
+//   sitemap site
+//   about.html            About Us
+//   blog/first-post.html  Hello, World & Welcome
+//   blog/index.htm        Blog
+//   index.html            Home
+//   notes/draft.html      (untitled)
+//
+// Pattern: find -> grep -> sort -> While(m.page) -> print. find lists the
+// files, grep keeps the .html and .htm ones, and sort orders them by path,
+// which is the order they're listed in. Paths are relative to the
+// directory and use "/", as in the site's URLs.
+//
+// Titles are read with the tokenizer of golang.org/x/net/html, not a
+// regexp, so a <title> inside a comment or a script isn't taken for the
+// page's, entities such as &amp; are decoded, and line breaks and runs of
+// spaces in the title become single spaces. Reading stops at the end of
+// the title, or at <body>: an SVG icon's <title> in the body doesn't name
+// the page. A page without a title, or with an empty one, is "(untitled)".
+//
+// --format decides the layout:
+//   text       aligned columns, for reading (the default)
+//   tsv        path, a tab and the title, for cut, awk or a spreadsheet
+//   markdown   a list of links, for a README or a docs page
+//
+// A file that can't be read is reported and left out, and sitemap exits 1
+// once the others are listed.
+//
+// Usage: sitemap [--format text|tsv|markdown] [directory]
+func main() {
+	opts := flags.New("sitemap", "[directory]")
+	format := opts.Format("text", "tsv", "markdown")
+	opts.Parse()
+	if opts.NArg() > 1 {
+		opts.Fail("at most one directory")
+	}
+	dir := opts.ArgOr(0, ".")
+
+	// find only warns about a missing directory; make it an error
+	// Shell: [[ -d ${DIR} ]] || exit 2
+	if err := input.Dir(dir); err != nil {
+		status.Result{Err: err}.Exit("sitemap")
+	}
+
+	m := &mapper{root: dir}
+	err := gloo.Run(pipe.Pipeline(
+		// Shell: find "${DIR}" -type f
+		find.Find(find.Dir(dir), find.FileType),
+
+		// Keep the HTML pages
+		// Shell: grep -i -E '\.html?$'
+		grep.Grep(grep.Pattern(`\.html?$`), grep.IgnoreCase),
+
+		// Shell: LC_ALL=C sort
+		sort.Sort(),
+
+		// Read each page's title
+		// Shell: while read -r page; do ...; done
+		While(m.page, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sitemap: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+
+	if err := m.write(os.Stdout, *format); err != nil {
+		fmt.Fprintf(os.Stderr, "sitemap: %v\n", err)
+		os.Exit(1)
+	}
+	if m.failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// entry is one page of the site
+type entry struct {
+	path  string // relative to the root, with "/"
+	title string
+}
+
+// mapper collects the pages of the site under root
+type mapper struct {
+	root    string
+	entries []entry
+	failed  int // pages that could not be read
+}
+
+// page is the While() callback: it adds the page at path, with its title
+//
+// Shell equivalent:
+//   printf '%s\t%s\n' "${page#"${DIR}"/}" "$(title "${page}")"
+func (m *mapper) page(args ...any) gloo.Command {
+	path := args[0].(string)
+
+	rel, err := filepath.Rel(m.root, path)
+	if err == nil {
+		var t string
+		if t, err = readTitle(path); err == nil {
+			if t == "" {
+				t = untitled
+			}
+			m.entries = append(m.entries, entry{filepath.ToSlash(rel), t})
+			return nil
+		}
+	}
+	fmt.Fprintf(os.Stderr, "sitemap: %v\n", err)
+	m.failed++
+	return nil
+}
+
+// readTitle returns the title of the HTML page at path, or "" if it has
+// none
+func readTitle(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	t, err := title(f)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return t, nil
+}
+
+// title reads HTML a token at a time up to the end of the first <title>,
+// or to <body>, and returns the title's text with its spaces collapsed
+//
+// Shell equivalent:
+//   tr '\n' ' ' < page.html | sed -n 's:.*<title[^>]*>\([^<]*\)</title>.*:\1:Ip'
+//
+// The tokenizer reads the text of <title> as raw text, so "<b>" in a title
+// is part of it, and doesn't report what's inside a comment as tags at all.
+func title(r io.Reader) (string, error) {
+	z := html.NewTokenizer(r)
+	var text strings.Builder
+	inTitle := false
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				return "", err
+			}
+			// The page ended, perhaps before </title>
+			return strings.Join(strings.Fields(text.String()), " "), nil
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = true
+			case "body":
+				return "", nil
+			}
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if inTitle && string(name) == "title" {
+				return strings.Join(strings.Fields(text.String()), " "), nil
+			}
+
+		case html.TextToken:
+			if inTitle {
+				text.Write(z.Text()) // with entities decoded
+			}
+		}
+	}
+}
+
+// write prints the pages in the chosen format
+//
+// Shell equivalent:
+//   column -t -s $'\t'                            # text
+//   awk -F'\t' '{print "- [" $2 "](" $1 ")"}'     # markdown
+func (m *mapper) write(w io.Writer, format string) error {
+	var lines []string
+	switch format {
+	case "text":
+		rows := make([][]string, len(m.entries))
+		for i, e := range m.entries {
+			rows[i] = []string{e.path, e.title}
+		}
+		lines = table.Align(rows)
+	case "tsv":
+		for _, e := range m.entries {
+			lines = append(lines, e.path+"\t"+e.title)
+		}
+	case "markdown":
+		for _, e := range m.entries {
+			lines = append(lines, fmt.Sprintf("- [%s](%s)", markdownText(e.title), markdownURL(e.path)))
+		}
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markdownText escapes the characters that would end a link's text early
+func markdownText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`).Replace(s)
+}
+
+// markdownURL escapes the characters that would end a link's URL early
+func markdownURL(s string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(s)
+}
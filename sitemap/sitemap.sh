@@ -0,0 +1,76 @@
+#!/bin/bash
+set -o pipefail
+
+# List the pages of a static site, each with the <title> from its HTML,
+# in path order
+# yupsh equivalent: See main.go
+#
+# The title is cut out with sed, after joining the lines and dropping
+# everything from <body> on. That is a regexp, not an HTML parser: it takes
+# the last <title> before <body>, including one in a comment, and decodes
+# only the commonest entities. The Go version tokenizes the HTML.
+
+# Parse flags (--format text|tsv|markdown), then the directory
+# yupsh: format := opts.Format("text", "tsv", "markdown")
+FORMAT=text
+usage() {
+  echo "Usage: $0 [--format text|tsv|markdown] [directory]" >&2
+  exit 2
+}
+while [[ $1 == --* ]]; do
+  case $1 in
+    --format) FORMAT=$2; shift 2 ;;
+    *) usage ;;
+  esac
+done
+case ${FORMAT} in
+  text|tsv|markdown) ;;
+  *) usage ;;
+esac
+(( $# <= 1 )) || usage
+DIR=${1:-.}
+if [[ ! -d ${DIR} ]]; then
+  echo "sitemap: ${DIR}: not a directory" >&2
+  exit 2
+fi
+
+# The title of a page, with its spaces collapsed; "" if it has none
+# yupsh: readTitle(path), with title(r)
+title() {
+  tr '\n\r\t' '   ' < "$1" |
+    sed -e 's/<body[ >].*//I' |
+    sed -n 's:.*<title[^>]*>\([^<]*\)</title>.*:\1:Ip' |
+    sed -e 's/  */ /g' -e 's/^ //' -e 's/ $//' \
+      -e 's/&lt;/</g' -e 's/&gt;/>/g' -e 's/&quot;/"/g' -e "s/&#39;/'/g" -e 's/&amp;/\&/g'
+}
+
+# path TAB title for each page, in path order
+# yupsh: find.Find(...) | grep.Grep(`\.html?$`, grep.IgnoreCase) | sort.Sort() | While(m.page, ...)
+FAILED=0
+LIST=$(
+  find "${DIR}" -type f | grep -i -E '\.html?$' | LC_ALL=C sort |
+    while IFS= read -r page; do
+      if [[ ! -r ${page} ]]; then
+        echo "sitemap: open ${page}: permission denied" >&2
+        continue
+      fi
+      t=$(title "${page}")
+      printf '%s\t%s\n' "${page#"${DIR%/}"/}" "${t:-(untitled)}"
+    done
+)
+[[ $(find "${DIR}" -type f ! -readable | grep -c -i -E '\.html?$') -eq 0 ]] || FAILED=1
+
+# yupsh: m.write(os.Stdout, *format)
+[[ -n ${LIST} ]] && case ${FORMAT} in
+  text) column -t -s $'\t' <<< "${LIST}" ;;
+  tsv) printf '%s\n' "${LIST}" ;;
+  markdown)
+    awk -F'\t' '{
+      t = $2; gsub(/[][\\]/, "\\\\&", t)
+      u = $1; gsub(/ /, "%20", u); gsub(/\(/, "%28", u); gsub(/\)/, "%29", u)
+      print "- [" t "](" u ")"
+    }' <<< "${LIST}"
+    ;;
+esac
+
+exit "${FAILED}"
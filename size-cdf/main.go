@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	awk `github.com/yupsh/awk`
+	echo `github.com/yupsh/echo`
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	size `github.com/yupsh/script-examples/internal/size`
+	status `github.com/yupsh/script-examples/internal/status`
+	table `github.com/yupsh/script-examples/internal/table`
+	. `github.com/yupsh/while`
+)
+
+// defaultThresholds are the sizes the distribution is measured at: powers
+// of ten of a kilobyte, from 1K to 10G
+const defaultThresholds = "1K,10K,100K,1M,10M,100M,1G,10G"
+
+// Print the cumulative distribution of the file sizes in a tree: for each
+// size, what share of the files, and of the bytes, are in files no larger
+// Shell equivalent: See size-cdf.sh
+//
+//   size-cdf --human /usr
+//   at most  files  % files  bytes   % bytes
+//   1.0K     14740  29.5%    6.8M    0.2%
+//   10.0K    37706  75.5%    87.4M   3.1%
+//   100.0K   48346  96.8%    390.3M  14.0%
+//   ...
+//   10.0G    49938  100.0%   2.7G    100.0%
+//   total    49938  100.0%   2.7G    100.0%
+//
+// Each row counts every file at or below its size, so the columns only
+// grow, and together they show where the storage goes: above, three files
+// in four are 10K or less, but they hold 3% of the bytes. The percentages
+// are rounded down, so 100.0% means every file.
+//
+// The sizes are the --thresholds, in any order, and every one is printed,
+// so reports of different trees line up row for row. The last row is the
+// whole tree, and covers any files larger than the largest threshold.
+// --human prints the sizes, and the byte totals, as 1.5K, 3.2M...
+//
+// Usage: size-cdf [--human] [--thresholds SIZE,...] [directory]
+func main() {
+	opts := flags.New("size-cdf", "[directory]")
+	human := opts.Human()
+	list := opts.String("thresholds", defaultThresholds, "measure the distribution at these `sizes`, comma-separated")
+	opts.Parse()
+	dir := opts.ArgOr(0, ".")
+
+	thresholds, err := parseThresholds(*list)
+	if err != nil {
+		opts.Fail("--thresholds: %v", err)
+	}
+
+	// find only warns about a missing directory, so check it up front
+	if err := input.Dir(dir); err != nil {
+		status.Result{Err: err}.Exit("size-cdf")
+	}
+
+	err = gloo.Run(pipe.Pipeline(
+		// Find all regular files
+		// Shell: find "${DIR}" -type f
+		find.Find(find.Dir(dir), find.FileType),
+
+		// Each file's size
+		// Shell: -printf '%s\n'
+		While(fileSize, input.WholeLine),
+
+		// Count and sum per threshold, then add them up in order
+		// Shell: awk '{...; n[i]++; bytes[i] += $1} END {...}'
+		awk.Awk(newCDFProgram(thresholds, *human)),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "size-cdf: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// parseThresholds reads a comma-separated list of sizes, and returns them
+// in increasing order, without repeats
+func parseThresholds(list string) ([]int64, error) {
+	var thresholds []int64
+	for _, field := range strings.Split(list, ",") {
+		n, err := size.Parse(field)
+		if err != nil {
+			return nil, err
+		}
+		thresholds = append(thresholds, n)
+	}
+	slices.Sort(thresholds)
+	return slices.Compact(thresholds), nil
+}
+
+// fileSize is the While() callback that emits the size of each file
+//
+// Shell equivalent:
+//   find -printf '%s\n'
+func fileSize(args ...any) gloo.Command {
+	path := args[0].(string)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil // Skip files we can't access
+	}
+	return echo.Echo(strconv.FormatInt(info.Size(), 10))
+}
+
+// cdfProgram is a custom awk program that buckets the sizes by the first
+// threshold at or above them, then adds the buckets up into the cumulative
+// distribution
+//
+// Shell awk pattern:
+//   {for (i = 1; i <= k && $1 > t[i]; i++) ; n[i]++; bytes[i] += $1}
+//   END {for (i = 1; i <= k; i++) {files += n[i]; ...; print ...}}
+//
+// Only a count and a total per bucket are kept, so memory doesn't grow
+// with the number of files, and no sort is needed: the buckets are in
+// order already.
+type cdfProgram struct {
+	awk.SimpleProgram
+	thresholds []int64
+	human      bool
+	files      []int   // bucket -> files in it; the last is above every threshold
+	bytes      []int64 // bucket -> their total size
+}
+
+func newCDFProgram(thresholds []int64, human bool) *cdfProgram {
+	return &cdfProgram{
+		thresholds: thresholds,
+		human:      human,
+		files:      make([]int, len(thresholds)+1),
+		bytes:      make([]int64, len(thresholds)+1),
+	}
+}
+
+// Action adds the file to the bucket of the smallest threshold it doesn't
+// exceed
+// Shell: {for (i = 1; i <= k && $1 > t[i]; i++) ; n[i]++; bytes[i] += $1}
+func (p *cdfProgram) Action(ctx *awk.Context) (string, bool) {
+	n, err := strconv.ParseInt(ctx.Field(1), 10, 64)
+	if err != nil {
+		return "", false
+	}
+	i, _ := slices.BinarySearch(p.thresholds, n)
+	p.files[i]++
+	p.bytes[i] += n
+	return "", false
+}
+
+// End prints a row per threshold, each counting the buckets up to it, then
+// the row of the whole tree
+// Shell: END {for (i = 1; i <= k; i++) {files += n[i]; sum += bytes[i]; printf ...}}
+func (p *cdfProgram) End(ctx *awk.Context) (string, error) {
+	var totalFiles int
+	var totalBytes int64
+	for i := range p.files {
+		totalFiles += p.files[i]
+		totalBytes += p.bytes[i]
+	}
+
+	rows := [][]string{{"at most", "files", "% files", "bytes", "% bytes"}}
+	row := func(label string, files int, bytes int64) {
+		rows = append(rows, []string{
+			label,
+			strconv.Itoa(files),
+			percent(int64(files), int64(totalFiles)),
+			p.size(bytes),
+			percent(bytes, totalBytes),
+		})
+	}
+
+	var files int
+	var bytes int64
+	for i, t := range p.thresholds {
+		files += p.files[i]
+		bytes += p.bytes[i]
+		row(p.size(t), files, bytes)
+	}
+	row("total", totalFiles, totalBytes)
+
+	return strings.Join(table.Align(rows), "\n"), nil
+}
+
+// size formats a size in bytes, or with --human in binary units
+func (p *cdfProgram) size(n int64) string {
+	if p.human {
+		return size.Format(n)
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// percent returns n as a percentage of total, rounded down to one decimal
+// place, so only the whole is 100.0%; an empty tree is 0% throughout
+func percent(n, total int64) string {
+	if total == 0 {
+		return "0.0%"
+	}
+	return fmt.Sprintf("%.1f%%", math.Floor(float64(n)*1000/float64(total))/10)
+}
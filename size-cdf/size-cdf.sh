@@ -0,0 +1,62 @@
+#!/bin/bash
+set -e
+
+# Print the cumulative distribution of the file sizes in a tree
+# yupsh equivalent: See main.go
+
+# Parse flags (--human, --thresholds SIZE,...), then the directory, and
+# check it, since find only warns
+# yupsh: opts.Human(); opts.String("thresholds", defaultThresholds, ...); dir := opts.ArgOr(0, ".")
+HUMAN=
+THRESHOLDS=1K,10K,100K,1M,10M,100M,1G,10G
+while [[ $1 == --* ]]; do
+  case $1 in
+    --human) HUMAN=1; shift ;;
+    --thresholds) THRESHOLDS=$2; shift 2 ;;
+    *) echo "Usage: $0 [--human] [--thresholds SIZE,...] [directory]" >&2; exit 2 ;;
+  esac
+done
+DIR=${1:-.}
+if [[ ! -d ${DIR} ]]; then
+  echo "size-cdf: ${DIR}: not a directory" >&2
+  exit 2
+fi
+
+# The thresholds in bytes, in increasing order, without repeats
+# yupsh: parseThresholds(*list)
+LIMITS=$(tr ',a-z' '\nA-Z' <<< "${THRESHOLDS}" | sed 's/I\?B$//' | numfmt --from=iec | sort -nu | paste -sd' ')
+
+# Each file's size, then count and sum per threshold, and add them up
+# yupsh: find.Find(...), While(fileSize, ...), awk.Awk(newCDFProgram(thresholds, *human))
+find "${DIR}" -type f -printf '%s\n' \
+| awk -v limits="${LIMITS}" -v human="${HUMAN}" '
+  # %.0f, not %d, as some awks stop %d at 2^31 - 1 bytes
+  function size(n,   units, u) {
+    if (!human) return sprintf("%.0f", n)
+    if (n < 1024) return n "B"
+    split("K M G T P E", units, " ")
+    for (u = 0; n >= 1024 && u < 6; u++) n /= 1024
+    return sprintf("%.1f%s", n, units[u])
+  }
+  function percent(n, total) {
+    return sprintf("%.1f%%", total ? int(n * 1000 / total) / 10 : 0)
+  }
+  function row(label, files, bytes) {
+    printf "%s\t%.0f\t%s\t%s\t%s\n", label, files, percent(files, total_n),
+      size(bytes), percent(bytes, total_bytes)
+  }
+  BEGIN { k = split(limits, t, " ") }
+  {
+    for (i = 1; i <= k && $1 > t[i]; i++) ;
+    n[i]++; bytes[i] += $1
+    total_n++; total_bytes += $1
+  }
+  END {
+    print "at most\tfiles\t% files\tbytes\t% bytes"
+    for (i = 1; i <= k; i++) {
+      files += n[i]; sum += bytes[i]
+      row(size(t[i]), files, sum)
+    }
+    row("total", total_n, total_bytes)
+  }' \
+| column -t -s $'\t'
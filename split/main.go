@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Split input into numbered parts of at most N lines or N bytes, like split
+// Shell equivalent: See split.sh
+//
+//   split --lines 1000 big.log part-
+//   -> part-aa part-ab part-ac ...
+//
+// Parts are named PREFIX followed by a suffix of --suffix-length letters:
+// aa, ab, ..., az, ba, ..., zz. The last part holds whatever is left, and is
+// never empty: input of exactly 2000 lines makes two parts, not three, and
+// empty input makes none. With --bytes, part boundaries may fall mid-line.
+//
+// Usage: split [--lines N | --bytes SIZE] [--suffix-length 2] [--verbose] [file [prefix]]
+func main() {
+	opts := flags.New("split", "[file [prefix]]")
+	lines := opts.Int("lines", 0, "put `N` lines in each part (default 1000)")
+	bytes := opts.Size("bytes", 0, "put at most `SIZE` bytes in each part, e.g. 10M")
+	suffixLength := opts.Int("suffix-length", 2, "letters in each part's suffix")
+	verbose := opts.Bool("verbose", false, "print each part's name as it is created")
+	opts.Parse()
+
+	switch {
+	case *lines < 0 || *bytes < 0:
+		opts.Fail("part sizes must be positive")
+	case *lines > 0 && *bytes > 0:
+		opts.Fail("--lines and --bytes are mutually exclusive")
+	case *lines == 0 && *bytes == 0:
+		*lines = 1000
+	}
+	if *suffixLength < 1 {
+		opts.Fail("--suffix-length must be at least 1")
+	}
+	if opts.NArg() > 2 {
+		opts.Fail("too many arguments")
+	}
+
+	// Shell: split -l 1000 "${FILE:--}" "${PREFIX:-x}"
+	out := &parts{prefix: opts.ArgOr(1, "x"), suffixLength: *suffixLength, verbose: *verbose}
+	file := opts.ArgOr(0, input.Stdin)
+
+	splitter := splitLines(out, *lines)
+	if *bytes > 0 {
+		splitter = splitBytes(out, *bytes)
+	}
+
+	err := gloo.Run(pipe.Pipeline(pipe.PipeFail, input.Source(file), splitter))
+	if closeErr := out.close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "split: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// splitLines writes stdin to parts of n lines each
+// Shell: split -l N
+func splitLines(out *parts, n int) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		r := bufio.NewReader(stdin)
+		count := 0
+		for {
+			line, readErr := r.ReadBytes('\n')
+			if len(line) > 0 {
+				// Start a new part before the first line and after every n
+				if out.current == nil || count == n {
+					if err := out.next(stdout); err != nil {
+						return err
+					}
+					count = 0
+				}
+				if _, err := out.current.Write(line); err != nil {
+					return err
+				}
+				count++
+			}
+
+			if readErr == io.EOF {
+				return nil
+			}
+			if readErr != nil {
+				return readErr
+			}
+		}
+	})
+}
+
+// splitBytes writes stdin to parts of at most n bytes each
+// Shell: split -b N
+func splitBytes(out *parts, n int64) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		r := bufio.NewReader(stdin)
+		for {
+			// A part is only created once there is a byte to put in it, so
+			// the last part is never empty
+			if _, err := r.Peek(1); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			if err := out.next(stdout); err != nil {
+				return err
+			}
+			if _, err := io.CopyN(out.current, r, n); err != nil && err != io.EOF {
+				return err
+			}
+		}
+	})
+}
+
+// parts creates the numbered output files one after another
+type parts struct {
+	prefix       string
+	suffixLength int
+	verbose      bool
+
+	count   int // parts created so far
+	current *bufio.Writer
+	file    *os.File
+}
+
+// errSuffixesExhausted means the input needs more parts than the suffix
+// length allows (26^length)
+var errSuffixesExhausted = errors.New("output file suffixes exhausted; use a larger --suffix-length")
+
+// next closes the current part and creates the next one
+func (p *parts) next(stdout io.Writer) error {
+	if err := p.close(); err != nil {
+		return err
+	}
+
+	name, ok := partName(p.prefix, p.count, p.suffixLength)
+	if !ok {
+		return errSuffixesExhausted
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	p.count++
+	p.file, p.current = f, bufio.NewWriter(f)
+
+	if p.verbose {
+		// Shell: split --verbose  ->  creating file 'xaa'
+		fmt.Fprintf(stdout, "creating file '%s'\n", name)
+	}
+	return nil
+}
+
+// close flushes and closes the current part, if any
+func (p *parts) close() error {
+	if p.file == nil {
+		return nil
+	}
+	err := p.current.Flush()
+	if closeErr := p.file.Close(); err == nil {
+		err = closeErr
+	}
+	p.file, p.current = nil, nil
+	return err
+}
+
+// partName returns the name of part i: the prefix and i written in base 26
+// with the letters a-z, padded to length
+func partName(prefix string, i, length int) (string, bool) {
+	suffix := make([]byte, length)
+	for pos := length - 1; pos >= 0; pos-- {
+		suffix[pos] = 'a' + byte(i%26)
+		i /= 26
+	}
+	return prefix + string(suffix), i == 0
+}
@@ -0,0 +1,25 @@
+#!/bin/bash
+set -e
+
+# Split input into numbered parts of at most N lines or N bytes
+# yupsh equivalent: See main.go
+
+# Parse flags (--lines N | --bytes SIZE, --suffix-length N, --verbose), then
+# the file and prefix
+# yupsh: lines := opts.Int("lines", ...); bytes := opts.Size("bytes", ...)
+SPLIT_BY=(-l 1000)
+OPTIONS=()
+while [[ $1 == --* ]]; do
+  case $1 in
+    --lines) SPLIT_BY=(-l "$2"); shift 2 ;;
+    --bytes) SPLIT_BY=(-b "$2"); shift 2 ;;  # split accepts 10M, 1K, ...
+    --suffix-length) OPTIONS+=(-a "$2"); shift 2 ;;
+    --verbose) OPTIONS+=(--verbose); shift ;;
+    *) echo "Usage: $0 [--lines N | --bytes SIZE] [--suffix-length 2] [--verbose] [file [prefix]]" >&2; exit 2 ;;
+  esac
+done
+FILE=${1:--}
+PREFIX=${2:-x}
+
+# yupsh: splitLines(out, *lines) or splitBytes(out, *bytes) rotating parts
+split "${SPLIT_BY[@]}" "${OPTIONS[@]}" "${FILE}" "${PREFIX}"
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Collapse runs of blank lines into one, like `cat -s`
+// Shell equivalent: See squeeze.sh
+//
+// --strip-leading also drops the blank lines before the first line of text,
+// and --strip-trailing those after the last. A blank line is an empty one,
+// as for cat -s; a line of spaces is text.
+//
+// Usage: squeeze [--strip-leading] [--strip-trailing] [file...]
+func main() {
+	opts := flags.New("squeeze", "[file...]")
+	stripLeading := opts.Bool("strip-leading", false, "drop blank lines at the start")
+	stripTrailing := opts.Bool("strip-trailing", false, "drop blank lines at the end")
+	opts.Parse()
+
+	s := &squeezer{stripLeading: *stripLeading, stripTrailing: *stripTrailing}
+
+	// Shell: cat -s "$@"
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		While(s.line, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "squeeze: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// squeezer is the state the While() callback carries from line to line
+//
+// Shell equivalent:
+//   awk 'NF || !prev {print} {prev = !NF}'
+type squeezer struct {
+	stripLeading, stripTrailing bool
+
+	seenText bool // a non-blank line has been printed
+	blank    bool // the previous line was blank
+}
+
+// line is the While() callback. Only the first blank line of a run is
+// printed.
+//
+// --strip-trailing needs one line of lookahead: a blank line is only known
+// not to be trailing once text follows it. Rather than buffering, the first
+// blank of a run is held back and printed just before the next line of text;
+// if the input ends first, it is never printed.
+func (s *squeezer) line(args ...any) gloo.Command {
+	line := args[0].(string)
+
+	if line == "" {
+		first := !s.blank
+		s.blank = true
+		switch {
+		case !s.seenText && s.stripLeading:
+			return nil // leading blank: drop it
+		case first && !s.stripTrailing:
+			return echo.Echo("")
+		}
+		return nil // a repeat, or held back until text follows
+	}
+
+	held := s.blank && s.stripTrailing && (s.seenText || !s.stripLeading)
+	s.blank = false
+	s.seenText = true
+	if held {
+		return echo.Echo("\n" + line)
+	}
+	return echo.Echo(line)
+}
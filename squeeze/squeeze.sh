@@ -0,0 +1,33 @@
+#!/bin/bash
+set -e
+
+# Collapse runs of blank lines into one
+# yupsh equivalent: See main.go
+
+# Parse flags (--strip-leading, --strip-trailing), then the files
+# yupsh: stripLeading := opts.Bool("strip-leading", ...); stripTrailing := ...
+LEADING=0
+TRAILING=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --strip-leading) LEADING=1; shift ;;
+    --strip-trailing) TRAILING=1; shift ;;
+    *) echo "Usage: $0 [--strip-leading] [--strip-trailing] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Print the first blank line of each run; with --strip-trailing, hold it
+# back until text follows
+# yupsh: While(s.line, input.WholeLine) with the squeezer state
+cat "$@" | awk -v leading="${LEADING}" -v trailing="${TRAILING}" '
+  $0 == "" {
+    first = !blank; blank = 1
+    if (!seen && leading) next
+    if (first && !trailing) print ""
+    next
+  }
+  {
+    if (blank && trailing && (seen || !leading)) print ""
+    blank = 0; seen = 1
+    print
+  }'
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	num `github.com/yupsh/script-examples/internal/num`
+	status `github.com/yupsh/script-examples/internal/status`
+	welford `github.com/yupsh/script-examples/internal/welford`
+)
+
+// Print summary statistics of a column of numbers on one line
+// Shell equivalent: See stats.sh
+//
+//   stats --field 3 --delim '\t' requests.tsv
+//   count=8 sum=1397.5 min=95 max=310 mean=174.688 stddev=91.7298
+//
+// Like datamash count sum min max mean sstdev, in one pass: each number
+// updates the statistics as it is read and is then forgotten, so memory use
+// is the same for ten numbers or ten billion. The standard deviation is the
+// sample one (dividing by n-1), computed with Welford's method, which stays
+// accurate when the numbers are large and close together, where the
+// textbook sum-of-squares formula loses every significant digit.
+//
+// Lines whose field is not a number are skipped with a warning; blank lines
+// are skipped silently.
+//
+// Usage: stats [--field N] [--delim SEP] [file...]
+func main() {
+	opts := flags.New("stats", "[file...]")
+	field := opts.Int("field", 1, "`column` to summarize")
+	delim := opts.Delim(" ")
+	opts.Parse()
+	if *field < 1 {
+		opts.Fail("--field must be at least 1")
+	}
+
+	// Shell: awk '{n++; d = $1 - mean; mean += d / n; m2 += d * ($1 - mean)} END {...}'
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		summarize(*field, *delim),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "stats: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// summary holds the running statistics of the numbers seen so far: the
+// count, mean and standard deviation from Welford's method, and the rest
+type summary struct {
+	welford.Stats
+	sum      float64
+	min, max float64
+}
+
+// add updates the statistics with one more number
+//
+// Shell equivalent:
+//   n++; sum += v; if (n == 1 || v < min) min = v; ...
+func (s *summary) add(v float64) {
+	s.Add(v)
+	s.sum += v
+	if s.N() == 1 || v < s.min {
+		s.min = v
+	}
+	if s.N() == 1 || v > s.max {
+		s.max = v
+	}
+}
+
+// String formats the statistics as one line of key=value pairs; the
+// standard deviation of a single number is "nan", as awk prints it
+func (s *summary) String() string {
+	stddev := "nan"
+	if s.N() > 1 {
+		stddev = num.Format(s.StdDev())
+	}
+	return fmt.Sprintf("count=%d sum=%s min=%s max=%s mean=%s stddev=%s",
+		s.N(), num.Format(s.sum), num.Format(s.min), num.Format(s.max),
+		num.Format(s.Mean()), stddev)
+}
+
+// summarize returns the command that reads the numbers in column field of
+// each line of stdin and prints their summary once the input ends
+func summarize(field int, delim string) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		var s summary
+		scanner := bufio.NewScanner(stdin)
+		for lines := 1; scanner.Scan(); lines++ {
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+
+			v, err := strconv.ParseFloat(strings.TrimSpace(column(line, field, delim)), 64)
+			if err != nil || math.IsNaN(v) {
+				fmt.Fprintf(stderr, "stats: line %d: column %d is not a number\n", lines, field)
+				continue
+			}
+			s.add(v)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		if s.N() == 0 {
+			return fmt.Errorf("no numbers in the input")
+		}
+
+		// Shell: END {print "count=" n, "sum=" sum, ...}
+		_, err := fmt.Fprintln(stdout, s.String())
+		return err
+	})
+}
+
+// column returns field n of line, split on delim as --delim describes, or
+// "" if the line is too short
+func column(line string, n int, delim string) string {
+	var fields []string
+	if delim == " " {
+		fields = strings.Fields(line)
+	} else {
+		fields = strings.Split(line, delim)
+	}
+	if n > len(fields) {
+		return ""
+	}
+	return fields[n-1]
+}
@@ -0,0 +1,53 @@
+#!/bin/bash
+set -e
+
+# Print summary statistics of a column of numbers on one line
+# yupsh equivalent: See main.go
+
+# Parse flags (--field N, --delim SEP), then the files
+# yupsh: field := opts.Int("field", 1, ...); delim := opts.Delim(" ")
+FIELD=1
+DELIM=" "
+while [[ $1 == --* ]]; do
+  case $1 in
+    --field) FIELD=$2; shift 2 ;;
+    --delim) DELIM=$2; shift 2 ;;
+    *) echo "Usage: $0 [--field N] [--delim SEP] [file...]" >&2; exit 2 ;;
+  esac
+done
+# --delim '\t' means a tab, as in the yupsh version
+# yupsh: delim := opts.Delim(" ")
+[[ ${DELIM} == '\t' ]] && DELIM=$'\t'
+
+# Update the statistics with each number, then print them once
+# yupsh: summarize(field, delim), a gloo.RawCommand with a summary struct
+cat "$@" | awk -F"${DELIM}" -v field="${FIELD}" '
+  # yupsh: formatNumber(v): whole numbers in full, others to six digits
+  function num(v) {
+    return v == int(v) && v < 1e15 && v > -1e15 ? sprintf("%.0f", v) : sprintf("%.6g", v)
+  }
+
+  # yupsh: blank lines are skipped silently
+  $0 ~ /^[[:space:]]*$/ { next }
+
+  # yupsh: strconv.ParseFloat(...) fails -> warn and skip
+  $field !~ /^[[:space:]]*[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?[[:space:]]*$/ {
+    printf "stats: line %d: column %d is not a number\n", NR, field > "/dev/stderr"
+    next
+  }
+
+  # yupsh: s.add(v), with Welford updates of mean and m2
+  {
+    v = $field + 0
+    n++; sum += v
+    if (n == 1 || v < min) min = v
+    if (n == 1 || v > max) max = v
+    d = v - mean; mean += d / n; m2 += d * (v - mean)
+  }
+
+  # yupsh: fmt.Fprintln(stdout, s.String())
+  END {
+    if (n == 0) { print "stats: no numbers in the input" > "/dev/stderr"; exit 1 }
+    stddev = n > 1 ? num(sqrt(m2 / (n - 1))) : "nan"
+    print "count=" n, "sum=" num(sum), "min=" num(min), "max=" num(max), "mean=" num(mean), "stddev=" stddev
+  }'
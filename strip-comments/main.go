@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	gloo `github.com/gloo-foo/framework`
+	grep `github.com/yupsh/grep`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Strip comment lines and blank lines from a config file
+// Shell equivalent: See strip-comments.sh
+//
+// Shows inverse filtering: a single grep.Grep() with grep.Invert drops every
+// line matching "blank, or a comment", and everything else passes through.
+//
+// Only whole-line comments are removed: a "#" after a value is left alone,
+// since it is often part of the value (colors, URLs, passwords).
+//
+// Usage: strip-comments [--comment-char '#'] [file...]
+//   --comment-char ';' works for INI-style configs
+func main() {
+	opts := flags.New("strip-comments", "[file...]")
+	commentChar := opts.String("comment-char", "#", "`prefix` that starts a comment line")
+	opts.Parse()
+
+	// Build the pattern: optional leading whitespace, then a comment or nothing
+	// Shell: grep -v -E '^[[:space:]]*(#|$)'
+	// yupsh: QuoteMeta() so characters like ";" or "//" are taken literally
+	pattern := fmt.Sprintf(`^\s*(%s|$)`, regexp.QuoteMeta(*commentChar))
+
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+
+		// Read the named files, or stdin when there are none
+		// Shell: cat "$@"
+		input.Source(opts.Args()...),
+
+		// Drop comment and blank lines
+		// Shell: grep -v -E '^[[:space:]]*(#|$)'
+		grep.Grep(grep.Pattern(pattern), grep.Invert),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "strip-comments: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
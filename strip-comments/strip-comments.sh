@@ -0,0 +1,20 @@
+#!/bin/bash
+set -e -o pipefail
+
+# Strip comment lines and blank lines from a config file
+# yupsh equivalent: See main.go
+
+# Parse --comment-char, then the file arguments (stdin when none)
+# yupsh: commentChar := opts.String("comment-char", "#", ...)
+COMMENT_CHAR='#'
+if [[ $1 == --comment-char ]]; then
+  COMMENT_CHAR=$2
+  shift 2
+fi
+
+# Escape the comment prefix for use in a regex
+# yupsh: regexp.QuoteMeta(*commentChar)
+QUOTED=$(printf '%s' "${COMMENT_CHAR}" | sed 's/[][\.*^$+?(){}|/]/\\&/g')
+
+# yupsh: input.Source(opts.Args()...), grep.Grep(pattern, grep.Invert)
+cat "$@" | grep -v -E "^[[:space:]]*(${QUOTED}|\$)"
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	digest `github.com/yupsh/script-examples/internal/digest`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	. `github.com/yupsh/while`
+)
+
+// Compare two directory trees and list the files a sync from the first to
+// the second would copy, like a dry run of rsync
+// Shell equivalent: See sync-check.sh
+//
+//   sync-check site mirror
+//   changed  about.html
+//   extra    old.html
+//   changed  posts/hello.md
+//   new      posts/new.md
+//
+// Each tree is walked once with find, into a snapshot: a map from each
+// regular file's path, relative to the tree's root, to its size and
+// modification time. The two snapshots are then compared path by path:
+//   new      the file is only in the source: a sync would copy it
+//   changed  it is in both, but differs: a sync would copy it over
+//   extra    it is only in the destination: rsync leaves it, unless --delete
+//
+// By default a file differs when its size or its modification time (to the
+// second) does, which is rsync's quick check: fast, since no file is read,
+// but blind to an edit that kept both. --checksum compares the content
+// instead, as rsync --checksum does: files of the same size are hashed and
+// their digests compared, and their times are ignored.
+//
+// As with diff, the exit status is 0 if the trees are in sync (and nothing
+// is printed), 1 if they differ and 2 on trouble.
+//
+// Usage: sync-check [--checksum] source destination
+func main() {
+	opts := flags.New("sync-check", "source destination")
+	checksum := opts.Bool("checksum", false, "compare the files' content, not their size and time")
+	opts.Parse()
+	if opts.NArg() != 2 {
+		opts.Fail("want a source and a destination directory")
+	}
+
+	src, err := scan(opts.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sync-check: %v\n", err)
+		os.Exit(2)
+	}
+	dst, err := scan(opts.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sync-check: %v\n", err)
+		os.Exit(2)
+	}
+
+	changes, err := compare(src, dst, *checksum)
+	for _, c := range changes {
+		fmt.Printf("%-7s  %s\n", c.kind, c.path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sync-check: %v\n", err)
+		os.Exit(2)
+	}
+	if len(changes) > 0 {
+		os.Exit(1)
+	}
+}
+
+// snapshot is a tree's regular files, by their slash-separated path
+// relative to its root
+type snapshot struct {
+	root  string
+	files map[string]fs.FileInfo
+	err   error
+}
+
+// scan walks the tree under root into a snapshot
+//
+// Shell equivalent:
+//   (cd "${root}" && find . -type f -printf '%P\t%s\t%Ts\n')
+func scan(root string) (*snapshot, error) {
+	// find only warns about a missing directory, so check it up front
+	if err := input.Dir(root); err != nil {
+		return nil, err
+	}
+
+	s := &snapshot{root: root, files: map[string]fs.FileInfo{}}
+	err := gloo.Run(pipe.Pipeline(
+		find.Find(find.Dir(root), find.FileType),
+		While(s.add, input.WholeLine),
+	))
+	if err == nil {
+		err = s.err
+	}
+	return s, err
+}
+
+// add is the While() callback: it records the file's size and time
+func (s *snapshot) add(args ...any) gloo.Command {
+	path := args[0].(string)
+
+	rel, err := filepath.Rel(s.root, path)
+	if err != nil {
+		s.err = err
+		return nil
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		s.err = err
+		return nil
+	}
+	s.files[filepath.ToSlash(rel)] = info
+	return nil
+}
+
+// change is a file a sync would act on
+type change struct {
+	kind string // "new", "changed" or "extra"
+	path string
+}
+
+// compare returns the changes from src to dst, ordered by path. A file that
+// can't be hashed for --checksum is reported and left out, and the error
+// says so.
+//
+// Shell equivalent:
+//   awk -F'\t' 'FNR == NR {size[$1] = $2; ...; next} ...' dst.list src.list
+func compare(src, dst *snapshot, checksum bool) ([]change, error) {
+	paths := slices.Collect(maps.Keys(src.files))
+	for path := range dst.files {
+		if _, ok := src.files[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	// Shell: LC_ALL=C sort -t$'\t' -k2
+	slices.Sort(paths)
+
+	var changes []change
+	var err error
+	for _, path := range paths {
+		a, inSrc := src.files[path]
+		b, inDst := dst.files[path]
+		switch {
+		case !inDst:
+			changes = append(changes, change{"new", path})
+		case !inSrc:
+			changes = append(changes, change{"extra", path})
+		case a.Size() != b.Size():
+			changes = append(changes, change{"changed", path})
+		case !checksum:
+			if a.ModTime().Unix() != b.ModTime().Unix() {
+				changes = append(changes, change{"changed", path})
+			}
+		default:
+			same, hashErr := sameContent(filepath.Join(src.root, path), filepath.Join(dst.root, path))
+			if hashErr != nil {
+				fmt.Fprintf(os.Stderr, "sync-check: %v\n", hashErr)
+				err = fmt.Errorf("not every file could be compared")
+				continue
+			}
+			if !same {
+				changes = append(changes, change{"changed", path})
+			}
+		}
+	}
+	return changes, err
+}
+
+// sameContent reports whether two files have the same digest
+//
+// Shell equivalent:
+//   cmp -s "${SOURCE}/${path}" "${DEST}/${path}"
+func sameContent(a, b string) (bool, error) {
+	sumA, err := digest.File(a, digest.Algorithms[0])
+	if err != nil {
+		return false, err
+	}
+	sumB, err := digest.File(b, digest.Algorithms[0])
+	if err != nil {
+		return false, err
+	}
+	return sumA == sumB, nil
+}
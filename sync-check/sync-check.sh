@@ -0,0 +1,87 @@
+#!/bin/bash
+set -eo pipefail
+
+# Compare two directory trees and list the files a sync from the first to
+# the second would copy, like a dry run of rsync
+# yupsh equivalent: See main.go
+#
+# The listings are tab-separated lines, so file names with a tab or a
+# newline in them are not supported here.
+
+# Parse flags (--checksum), then the two directories
+# yupsh: checksum := opts.Bool("checksum", ...)
+CHECKSUM=0
+usage() {
+  echo "Usage: $0 [--checksum] source destination" >&2
+  exit 2
+}
+while [[ $1 == --* ]]; do
+  case $1 in
+    --checksum) CHECKSUM=1; shift ;;
+    *) usage ;;
+  esac
+done
+if (( $# != 2 )); then
+  usage
+fi
+SOURCE=$1
+DEST=$2
+for dir in "${SOURCE}" "${DEST}"; do
+  if [[ ! -e ${dir} ]]; then
+    echo "sync-check: stat ${dir}: no such file or directory" >&2
+    exit 2
+  elif [[ ! -d ${dir} ]]; then
+    echo "sync-check: ${dir}: not a directory" >&2
+    exit 2
+  fi
+done
+
+# A snapshot of each tree: "path TAB size TAB time" for every file
+# yupsh: scan(root), find.Find(...) | While(s.add, input.WholeLine)
+listing() {
+  (cd "$1" && find . -type f -printf '%P\t%s\t%Ts\n')
+}
+SOURCE_LIST=$(mktemp)
+DEST_LIST=$(mktemp)
+trap 'rm -f "${SOURCE_LIST}" "${DEST_LIST}"' EXIT
+listing "${SOURCE}" > "${SOURCE_LIST}"
+listing "${DEST}" > "${DEST_LIST}"
+
+# Compare them path by path; with --checksum, the files of the same size
+# are marked for cmp below
+# yupsh: compare(src, dst, *checksum)
+compare() {
+  awk -F'\t' -v checksum="${CHECKSUM}" '
+    FNR == NR { size[$1] = $2; time[$1] = $3; next }
+    !($1 in size) { print "new\t" $1; next }
+    $2 != size[$1] { print "changed\t" $1 }
+    $2 == size[$1] && checksum { print "cmp\t" $1 }
+    $2 == size[$1] && !checksum && $3 != time[$1] { print "changed\t" $1 }
+    { delete size[$1] }
+    END { for (path in size) print "extra\t" path }
+  ' "${DEST_LIST}" "${SOURCE_LIST}"
+}
+
+# Print them by path, and exit 1 if there were any
+# yupsh: fmt.Printf("%-7s  %s\n", c.kind, c.path)
+FAILED=0
+FOUND=0
+while IFS=$'\t' read -r kind path; do
+  if [[ ${kind} == cmp ]]; then
+    # yupsh: sameContent(...)
+    if cmp -s -- "${SOURCE}/${path}" "${DEST}/${path}"; then
+      continue
+    elif (( $? > 1 )); then
+      FAILED=1
+      continue
+    fi
+    kind=changed
+  fi
+  printf '%-7s  %s\n' "${kind}" "${path}"
+  FOUND=1
+done < <(compare | LC_ALL=C sort -t$'\t' -k2)
+
+if (( FAILED )); then
+  exit 2
+fi
+exit "${FOUND}"
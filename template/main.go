@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Render each delimited record through a Go text/template
+// Shell equivalent: See template.sh
+//
+//   template --tmpl '{{.f2}} <{{.f1}}>' users.tsv
+//   alice	Alice Smith    ->    Alice Smith <alice>
+//
+// Each record's fields are available as .f1, .f2, ..., along with .line (the
+// whole record) and .nr (the record number). A field the record doesn't have
+// renders as nothing. The whole template language is available, e.g.
+//   '{{if eq .f3 "admin"}}{{.f1}}{{end}}'  or  '{{printf "%-10s|" .f1}}'
+//
+// The template is parsed before any input is read, so a syntax error is
+// reported at once instead of on the first record.
+//
+// Usage: template --tmpl TEMPLATE [--delim TAB] [file...]
+func main() {
+	opts := flags.New("template", "--tmpl TEMPLATE [file...]")
+	text := opts.String("tmpl", "", "`template` rendered for each record, e.g. '{{.f1}}-{{.f2}}'")
+	delim := opts.Delim("\t")
+	opts.Parse()
+	if *text == "" {
+		opts.Fail("--tmpl is required")
+	}
+
+	// Fail early on a bad template
+	// Shell: there is no equivalent; awk would print the placeholders as-is
+	tmpl, err := template.New("tmpl").Option("missingkey=zero").Parse(*text)
+	if err != nil {
+		opts.Fail("--tmpl: %v", err)
+	}
+
+	r := &renderer{tmpl: tmpl, delim: *delim}
+
+	// Shell: awk -F'\t' '{out = tmpl; gsub("{{.f1}}", $1, out); print out}'
+	err = gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		While(r.record, input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "template: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// renderer executes the template once per record
+type renderer struct {
+	tmpl  *template.Template
+	delim string
+	nr    int // records read so far
+}
+
+// record is the While() callback: it renders one line through the template
+func (r *renderer) record(args ...any) gloo.Command {
+	line := args[0].(string)
+	r.nr++
+
+	// Shell: -F'\t' splits the record into $1, $2, ...
+	var fields []string
+	if r.delim == " " {
+		fields = strings.Fields(line)
+	} else {
+		fields = strings.Split(line, r.delim)
+	}
+
+	data := map[string]string{"line": line, "nr": strconv.Itoa(r.nr)}
+	for i, field := range fields {
+		data["f"+strconv.Itoa(i+1)] = field
+	}
+
+	var out strings.Builder
+	if err := r.tmpl.Execute(&out, data); err != nil {
+		fmt.Fprintf(os.Stderr, "template: record %d: %v\n", r.nr, err)
+		return nil
+	}
+	return echo.Echo(out.String())
+}
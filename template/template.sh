@@ -0,0 +1,52 @@
+#!/bin/bash
+set -e
+
+# Render each delimited record through a template
+# yupsh equivalent: See main.go
+#
+# awk has no template language, so only the plain placeholders {{.f1}},
+# {{.f2}}, ..., {{.line}} and {{.nr}} are substituted; actions like
+# {{if ...}} or {{printf ...}} need the Go version.
+
+# Parse flags (--tmpl TEMPLATE, --delim SEP), then the files
+# yupsh: text := opts.String("tmpl", "", ...); delim := opts.Delim("\t")
+TMPL=
+DELIM=$'\t'
+while [[ $1 == --* ]]; do
+  case $1 in
+    --tmpl) TMPL=$2; shift 2 ;;
+    --delim) DELIM=$2; shift 2 ;;
+    *) echo "Usage: $0 --tmpl TEMPLATE [--delim TAB] [file...]" >&2; exit 2 ;;
+  esac
+done
+# --delim '\t' means a tab, as in the yupsh version
+[[ ${DELIM} == '\t' ]] && DELIM=$'\t'
+if [[ -z ${TMPL} ]]; then
+  echo "template: --tmpl is required" >&2
+  exit 2
+fi
+
+# Substitute the placeholders in each record
+# yupsh: While(r.record, input.WholeLine) executing the parsed template
+cat "$@" | TMPL="${TMPL}" awk -F"${DELIM}" '
+  # Replace every occurrence of the literal key with value
+  function subst(s, key, value,   out, i) {
+    out = ""
+    while ((i = index(s, key)) > 0) {
+      out = out substr(s, 1, i - 1) value
+      s = substr(s, i + length(key))
+    }
+    return out s
+  }
+
+  {
+    out = ENVIRON["TMPL"]
+    out = subst(out, "{{.line}}", $0)
+    out = subst(out, "{{.nr}}", NR)
+    # Fields the record lacks render as nothing, as with missingkey=zero
+    while (match(out, /\{\{\.f[0-9]+\}\}/)) {
+      n = substr(out, RSTART + 4, RLENGTH - 6)
+      out = substr(out, 1, RSTART - 1) $n substr(out, RSTART + RLENGTH)
+    }
+    print out
+  }'
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	dryrun `github.com/yupsh/script-examples/internal/dryrun`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	sort `github.com/yupsh/sort`
+	. `github.com/yupsh/while`
+	encoding `golang.org/x/text/encoding`
+	charmap `golang.org/x/text/encoding/charmap`
+	unicode `golang.org/x/text/encoding/unicode`
+	transform `golang.org/x/text/transform`
+)
+
+// Convert text files to UTF-8 from the encoding they appear to be in:
+// UTF-16 with a byte order mark, or else Windows-1252 (or Latin-1)
+// Shell equivalent: See to-utf8.sh
+//
+//   to-utf8 --in-place docs
+//   docs/notes.txt: Windows-1252 -> UTF-8
+//   docs/export.csv: UTF-16LE -> UTF-8
+//   to-utf8: converted 2 of 5 file(s)
+//
+// Each file is classified as in detect-encoding, but from all of its
+// bytes, not just the first 8KB:
+//   UTF-16LE / UTF-16BE   starts with FF FE / FE FF: converted, without the BOM
+//   binary                has NUL or other non-text control bytes: left alone
+//   UTF-8                 valid UTF-8, ASCII included: left alone
+//   (anything else)       8-bit text in --from: converted
+// So a file that is already UTF-8 is never touched, and running to-utf8
+// twice changes nothing the second time. There is no way to tell the 8-bit
+// encodings apart from the bytes, so --from says which one to assume;
+// Windows-1252, the default, is Latin-1 with curly quotes, dashes and €
+// in place of control characters no text file uses.
+//
+// Without --in-place, the files (or stdin) are written to stdout, converted
+// or as they are, like iconv, and each conversion is reported on stderr.
+// With --in-place, the directories are walked and each file that needs it
+// is rewritten through a temp file, as lineends does, and reported on
+// stdout. --dry-run reports the files but leaves them as they are.
+//
+// Usage: to-utf8 [--from windows-1252|latin1] [file...]
+//        to-utf8 --in-place [--dry-run] [--from windows-1252|latin1] [directory | file...]
+func main() {
+	opts := flags.New("to-utf8", "[directory | file...]")
+	from := opts.Choice("from", "the 8-bit encoding of text that isn't UTF-8 or UTF-16", "windows-1252", "latin1")
+	inPlace := opts.Bool("in-place", false, "rewrite the files, walking directories, instead of printing them")
+	dryRun := opts.DryRun()
+	opts.Parse()
+	if *dryRun && !*inPlace {
+		opts.Fail("--dry-run only applies with --in-place")
+	}
+
+	c := &converter{fallback: fallbacks[*from], dryRun: *dryRun}
+	paths := opts.Args()
+	if *inPlace {
+		if len(paths) == 0 {
+			paths = []string{"."}
+		}
+		for _, path := range paths {
+			// Shell: find "$path" -type f | sort | while read -r file; do ...; done
+			err := gloo.Run(pipe.Pipeline(
+				find.Find(find.Dir(path), find.FileType),
+				sort.Sort(),
+				While(c.file, input.WholeLine),
+			))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "to-utf8: %s\n", status.Message(err))
+				c.failed++
+			}
+		}
+	} else {
+		if len(paths) == 0 {
+			paths = []string{input.Stdin}
+		}
+		for _, path := range paths {
+			// Shell: iconv -f WINDOWS-1252 -t UTF-8 "$path"
+			if err := c.print(path); err != nil {
+				fmt.Fprintf(os.Stderr, "to-utf8: %v\n", err)
+				c.failed++
+			}
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "to-utf8: %s %d of %d file(s)\n",
+		dryrun.Line(*dryRun, "converted"), c.converted, c.files)
+	if c.failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// fallbacks are the --from encodings, for text that is neither UTF-8 nor
+// UTF-16 with a BOM
+var fallbacks = map[string]source{
+	"windows-1252": {"Windows-1252", charmap.Windows1252},
+	"latin1":       {"Latin-1", charmap.ISO8859_1},
+}
+
+// source is an encoding to convert from, and its name for the report
+type source struct {
+	name string
+	enc  encoding.Encoding
+}
+
+// classify returns the encoding to convert data from, or ok false when it
+// is to be left alone: empty, binary, or UTF-8 already
+//
+// Shell equivalent:
+//   file --brief --mime-encoding "$file"
+func classify(data []byte, fallback source) (src source, ok bool) {
+	switch {
+	case len(data) == 0:
+		return source{}, false
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return source{"UTF-16LE", unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)}, true
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return source{"UTF-16BE", unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)}, true
+	}
+	for _, c := range data {
+		if (c < 0x20 && !isTextControl(c)) || c == 0x7F {
+			return source{}, false // binary
+		}
+	}
+	if utf8.Valid(data) {
+		return source{}, false
+	}
+	return fallback, true
+}
+
+// isTextControl reports whether a control byte is common in text files:
+// tab, newline, vertical tab, form feed, carriage return, or escape
+func isTextControl(c byte) bool {
+	return c == '\t' || c == '\n' || c == '\v' || c == '\f' || c == '\r' || c == 0x1B
+}
+
+// decode returns the command that copies stdin to stdout, decoded from enc
+// to UTF-8
+//
+// Shell equivalent:
+//   iconv -f WINDOWS-1252 -t UTF-8
+//
+// The decoder is a transform.Transformer, so the conversion streams; for
+// UTF-16, ExpectBOM consumes the byte order mark rather than converting it.
+func decode(enc encoding.Encoding) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		_, err := io.Copy(stdout, transform.NewReader(stdin, enc.NewDecoder()))
+		return err
+	})
+}
+
+// converter converts files, and counts them for the summary
+type converter struct {
+	fallback source // --from
+	dryRun   bool
+
+	files, converted, failed int
+}
+
+// print writes the file at path to stdout, converted if it needs to be, and
+// reports a conversion on stderr
+//
+// A file has to be read whole to know it isn't UTF-8, so it is read into
+// memory, and converted from there; stdin can't be read twice either.
+func (c *converter) print(path string) error {
+	var data []byte
+	var err error
+	if path == input.Stdin {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	c.files++
+
+	src, ok := classify(data, c.fallback)
+	if !ok {
+		// Shell: cat "$path"
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := decode(src.enc).Executor()(context.Background(), bytes.NewReader(data), os.Stdout, os.Stderr); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	c.converted++
+	fmt.Fprintf(os.Stderr, "to-utf8: %s: %s -> UTF-8\n", path, src.name)
+	return nil
+}
+
+// file is the While() callback for --in-place: it converts one file, if it
+// needs it, and reports it
+//
+// Shell equivalent:
+//   iconv -f WINDOWS-1252 -t UTF-8 "$file" > "$tmp" && mv "$tmp" "$file"
+//
+// A file that can't be read or written is reported, and the walk goes on.
+func (c *converter) file(args ...any) gloo.Command {
+	path := args[0].(string)
+	c.files++
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "to-utf8: %v\n", err)
+		c.failed++
+		return nil
+	}
+	src, ok := classify(data, c.fallback)
+	if !ok {
+		return nil
+	}
+
+	if !c.dryRun {
+		if err := rewrite(path, data, src.enc); err != nil {
+			fmt.Fprintf(os.Stderr, "to-utf8: %s: %v\n", path, err)
+			c.failed++
+			return nil
+		}
+	}
+	c.converted++
+	fmt.Println(dryrun.Line(c.dryRun, fmt.Sprintf("%s: %s -> UTF-8", path, src.name)))
+	return nil
+}
+
+// rewrite replaces the file at path with data converted from enc, through
+// a temp file in the same directory, keeping its permissions
+//
+// Shell equivalent:
+//   iconv -f WINDOWS-1252 -t UTF-8 "$file" > "$tmp" && mv "$tmp" "$file"
+func rewrite(path string, data []byte, enc encoding.Encoding) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	// The same directory keeps the final rename on one filesystem
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if err := decode(enc).Executor()(context.Background(), bytes.NewReader(data), tmp, os.Stderr); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,139 @@
+#!/bin/bash
+set -e
+
+# Convert text files to UTF-8 from the encoding they appear to be in:
+# UTF-16 with a byte order mark, or else Windows-1252 (or Latin-1)
+# yupsh equivalent: See main.go
+
+# Parse flags (--from windows-1252|latin1, --in-place, --dry-run), then the
+# files or directories
+# yupsh: opts.Choice("from", ...); opts.Bool("in-place", ...); opts.DryRun()
+FROM=WINDOWS-1252
+IN_PLACE=
+DRY_RUN=
+while [[ $1 == --* ]]; do
+  case $1 in
+    --from)
+      case $2 in
+        windows-1252) FROM=WINDOWS-1252 ;;
+        latin1) FROM=LATIN1 ;;
+        *) echo "to-utf8: --from must be windows-1252 or latin1" >&2; exit 2 ;;
+      esac
+      shift 2
+      ;;
+    --in-place) IN_PLACE=1; shift ;;
+    --dry-run) DRY_RUN=1; shift ;;
+    *) echo "Usage: $0 [--in-place [--dry-run]] [--from windows-1252|latin1] [directory | file...]" >&2; exit 2 ;;
+  esac
+done
+if [[ -n ${DRY_RUN} && -z ${IN_PLACE} ]]; then
+  echo "to-utf8: --dry-run only applies with --in-place" >&2
+  exit 2
+fi
+
+# Print the encoding to convert a file from, or nothing to leave it alone:
+# empty, binary, or UTF-8 already (ASCII included)
+# yupsh: classify(data, c.fallback)
+classify() {
+  local bom
+  bom=$(head -c 2 "$1" | od -An -tx1 | tr -d ' \n')
+  if [[ ! -s $1 ]]; then
+    return
+  elif [[ ${bom} == fffe ]]; then
+    echo UTF-16LE
+  elif [[ ${bom} == feff ]]; then
+    echo UTF-16BE
+  # Control bytes other than \t \n \v \f \r and ESC
+  # yupsh: (c < 0x20 && !isTextControl(c)) || c == 0x7F
+  elif LC_ALL=C grep -q '[\x00-\x08\x0E-\x1A\x1C-\x1F\x7F]' -P "$1"; then
+    return
+  elif iconv -f UTF-8 -t UTF-8 "$1" > /dev/null 2>&1; then
+    return
+  else
+    echo "${FROM}"
+  fi
+}
+
+# The name of an encoding in the report
+# yupsh: source.name
+label() {
+  case $1 in
+    WINDOWS-1252) echo Windows-1252 ;;
+    LATIN1) echo Latin-1 ;;
+    *) echo "$1" ;;
+  esac
+}
+
+# The name to give iconv: plain UTF-16 reads the byte order from the BOM
+# and drops it, where UTF-16LE would keep it as a U+FEFF character
+# yupsh: unicode.UTF16(..., unicode.ExpectBOM)
+iconv_name() {
+  echo "${1/#UTF-16??/UTF-16}"
+}
+
+files=0
+converted=0
+failed=0
+
+if [[ -n ${IN_PLACE} ]]; then
+  # Walk each directory, converting the files that need it
+  # yupsh: find.Find(...), sort.Sort(), While(c.file, input.WholeLine)
+  (($# > 0)) || set -- .
+  while IFS= read -r file; do
+    files=$((files + 1))
+    if [[ ! -r ${file} ]]; then
+      echo "to-utf8: ${file}: Permission denied" >&2
+      failed=$((failed + 1))
+      continue
+    fi
+    from=$(classify "${file}")
+    [[ -n ${from} ]] || continue
+
+    # yupsh: rewrite(path, data, src.enc)
+    if [[ -z ${DRY_RUN} ]]; then
+      tmp=$(mktemp "$(dirname "${file}")/.$(basename "${file}").XXXXXX")
+      if iconv -f "$(iconv_name "${from}")" -t UTF-8 "${file}" > "${tmp}"; then
+        chmod --reference="${file}" "${tmp}"
+        mv "${tmp}" "${file}"
+      else
+        rm -f "${tmp}"
+        failed=$((failed + 1))
+        continue
+      fi
+    fi
+    converted=$((converted + 1))
+    echo "${DRY_RUN:+[dry-run] }${file}: $(label "${from}") -> UTF-8"
+  done < <(find "$@" -type f | sort)
+else
+  # Print each file, or stdin, converted or as it is
+  # yupsh: c.print(path)
+  (($# > 0)) || set -- -
+  for file in "$@"; do
+    if [[ ${file} == - ]]; then
+      # classify needs a file to read twice
+      stdin=$(mktemp)
+      trap 'rm -f "${stdin}"' EXIT
+      cat > "${stdin}"
+      path=${stdin}
+    elif [[ -r ${file} ]]; then
+      path=${file}
+    else
+      echo "to-utf8: ${file}: cannot read" >&2
+      failed=$((failed + 1))
+      continue
+    fi
+    files=$((files + 1))
+
+    from=$(classify "${path}")
+    if [[ -z ${from} ]]; then
+      cat "${path}"
+      continue
+    fi
+    iconv -f "$(iconv_name "${from}")" -t UTF-8 "${path}"
+    converted=$((converted + 1))
+    echo "to-utf8: ${file}: $(label "${from}") -> UTF-8" >&2
+  done
+fi
+
+echo "to-utf8: ${DRY_RUN:+[dry-run] }converted ${converted} of ${files} file(s)" >&2
+((failed == 0)) || exit 1
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	grep `github.com/yupsh/grep`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	sort `github.com/yupsh/sort`
+	. `github.com/yupsh/while`
+)
+
+// markers are the words looked for, in the order they are reported
+var markers = []string{"TODO", "FIXME", "XXX"}
+
+// sourceExts are the extensions of the files searched by default
+const sourceExts = "go,c,h,cc,cpp,hpp,rs,java,kt,scala,swift,cs,py,rb,pl,php,lua,js,jsx,ts,tsx,sh,bash,sql"
+
+// skipDirs are directories of code that isn't the project's own
+const skipDirs = `(^|/)(\.git|\.hg|node_modules|vendor|third_party)/`
+
+// Find the TODO, FIXME and XXX comments in a source tree, and report them
+// grouped by marker, with where each one is
+// Shell equivalent: See todos.sh
+//
+//   todos src
+//   TODO (4)
+//     src/app.py:4  cache the results
+//     src/main.go:6  (ada): parse the flags
+//     ...
+//   FIXME (2)
+//     src/main.go:8  exits 0 even when run fails
+//     ...
+//
+//   7 marker(s): 4 TODO, 2 FIXME, 1 XXX
+//
+// Pattern: find -> grep -> grep -v -> sort -> While(s.file) -> s.report().
+// find lists the files, grep keeps the source files by extension (--ext),
+// grep -v drops the ones under .git, node_modules, vendor and third_party,
+// and sort puts them in order, so the report is too.
+//
+// A marker is the word in capitals, so TODOS and todo don't count; the rest
+// of the line after it, less a colon, is its text. Only the first marker on
+// a line is reported. With --author, an attribution in parentheses straight
+// after the marker, as in TODO(ada), is taken out of the text, shown in
+// brackets, and counted in a line of its own at the end.
+//
+// As with grep, the exit status is 0 when a marker was found, 1 when none
+// was and 2 on an error (see internal/status), so a check can fail a build
+// with `! todos src`.
+//
+// Usage: todos [--ext go,py,...] [--author] [directory]
+func main() {
+	opts := flags.New("todos", "[directory]")
+	exts := opts.String("ext", sourceExts, "search the files with these comma-separated `extensions`")
+	author := opts.Bool("author", false, "show and count the (name) after a marker")
+	opts.Parse()
+	if opts.NArg() > 1 {
+		opts.Fail("at most one directory")
+	}
+	extPattern, err := extensions(*exts)
+	if err != nil {
+		opts.Fail("--ext: %v", err)
+	}
+	dir := opts.ArgOr(0, ".")
+
+	// find only warns about a missing directory, so check it up front
+	if err := input.Dir(dir); err != nil {
+		status.Result{Err: err}.Exit("todos")
+	}
+
+	s := newScanner(*author)
+	err = gloo.Run(pipe.Pipeline(
+		// Shell: find "${DIR}" -type f
+		find.Find(find.Dir(dir), find.FileType),
+
+		// Keep the source files
+		// Shell: grep -E '\.(go|py|...)$'
+		grep.Grep(grep.Pattern(extPattern)),
+
+		// Leave out other people's code
+		// Shell: grep -v -E '/(\.git|node_modules|...)/'
+		grep.Grep(grep.Pattern(skipDirs), grep.Invert),
+
+		// Shell: LC_ALL=C sort
+		sort.Sort(),
+
+		// Collect each file's markers
+		// Shell: while read -r file; do grep -n -w -E 'TODO|FIXME|XXX' "$file"; done
+		While(s.file, input.WholeLine),
+
+		// Print them by marker
+		// Shell: awk '{items[$2] = items[$2] "\n  " $1} END {...}'
+		s.report(),
+	))
+	if err == nil {
+		err = s.err
+	}
+	status.Result{Count: s.total, Err: err}.Exit("todos")
+}
+
+// extensions turns a comma-separated list of extensions into a pattern
+// matching the paths that end in one of them
+func extensions(list string) (string, error) {
+	var quoted []string
+	for _, ext := range strings.Split(list, ",") {
+		ext = strings.TrimPrefix(strings.TrimSpace(ext), ".")
+		if ext == "" || strings.ContainsRune(ext, '/') {
+			return "", fmt.Errorf("%q is not an extension", ext)
+		}
+		quoted = append(quoted, regexp.QuoteMeta(ext))
+	}
+	return `\.(` + strings.Join(quoted, "|") + `)$`, nil
+}
+
+// item is one marker found in a file
+type item struct {
+	where  string // path:line
+	author string // with --author: the name in parentheses, if any
+	text   string
+}
+
+// scanner collects the markers of every file it is given
+type scanner struct {
+	re     *regexp.Regexp
+	author bool              // take out attributions (--author)
+	items  map[string][]item // by marker
+	total  int
+	err    error // set once a file couldn't be read
+}
+
+func newScanner(author bool) *scanner {
+	return &scanner{
+		// A marker is a whole word: \b, as grep -w
+		re:     regexp.MustCompile(`\b(` + strings.Join(markers, "|") + `)\b`),
+		author: author,
+		items:  map[string][]item{},
+	}
+}
+
+// file is the While() callback: it collects the markers of one file
+//
+// Shell equivalent:
+//   grep -n -w -E 'TODO|FIXME|XXX' "${file}"
+func (s *scanner) file(args ...any) gloo.Command {
+	path := args[0].(string)
+	if err := s.collect(path); err != nil {
+		fmt.Fprintf(os.Stderr, "todos: %v\n", err)
+		s.err = fmt.Errorf("not every file could be read")
+	}
+	return nil
+}
+
+// collect adds the markers of the named file
+func (s *scanner) collect(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	lines := bufio.NewScanner(f)
+	lines.Buffer(nil, 1<<30) // minified files can have very long lines
+	for n := 1; lines.Scan(); n++ {
+		line := lines.Text()
+		loc := s.re.FindStringSubmatchIndex(line)
+		if loc == nil {
+			continue
+		}
+		marker := line[loc[2]:loc[3]]
+		it := item{where: fmt.Sprintf("%s:%d", path, n)}
+		it.author, it.text = s.split(line[loc[1]:])
+		s.items[marker] = append(s.items[marker], it)
+		s.total++
+	}
+	if err := lines.Err(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// split returns the attribution and the text of what follows a marker:
+// "(ada): parse the flags" is "ada" and "parse the flags" with --author,
+// and no one and all of it without. The end of a block comment is dropped.
+//
+// Shell equivalent:
+//   sub(/^\([^)]*\)/, ""); sub(/^:?[ \t]*/, ""); sub(/[ \t]*(\*\/|-->)[ \t]*$/, "")
+func (s *scanner) split(rest string) (author, text string) {
+	if s.author && strings.HasPrefix(rest, "(") {
+		if name, after, ok := strings.Cut(rest[1:], ")"); ok {
+			author, rest = strings.TrimSpace(name), after
+		}
+	}
+	text = strings.TrimLeft(strings.TrimPrefix(rest, ":"), " \t")
+	text = strings.TrimRight(text, " \t")
+	for _, end := range []string{"*/", "-->"} {
+		text = strings.TrimRight(strings.TrimSuffix(text, end), " \t")
+	}
+	return author, text
+}
+
+// report returns the command that prints the markers found, grouped in the
+// order of markers, then the count of each and, with --author, of each
+// author
+func (s *scanner) report() gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		// The callback prints nothing; its output ends when every file has
+		// been read
+		if _, err := io.Copy(io.Discard, stdin); err != nil {
+			return err
+		}
+		if s.total == 0 {
+			return nil
+		}
+
+		out := bufio.NewWriter(stdout)
+		var counts []string
+		authors := map[string]int{}
+		for _, marker := range markers {
+			items := s.items[marker]
+			if len(items) == 0 {
+				continue
+			}
+			counts = append(counts, fmt.Sprintf("%d %s", len(items), marker))
+
+			fmt.Fprintf(out, "%s (%d)\n", marker, len(items))
+			for _, it := range items {
+				text := it.text
+				if s.author {
+					name := it.author
+					if name == "" {
+						name = "unassigned"
+					} else {
+						text = "[" + name + "] " + text
+					}
+					authors[name]++
+				}
+				fmt.Fprintf(out, "  %s  %s\n", it.where, text)
+			}
+		}
+
+		fmt.Fprintf(out, "\n%d marker(s): %s\n", s.total, strings.Join(counts, ", "))
+		if s.author {
+			fmt.Fprintf(out, "by author: %s\n", byCount(authors))
+		}
+		return out.Flush()
+	})
+}
+
+// byCount formats the authors' counts as "name N" pairs, the most first,
+// then by name; the unassigned markers come last
+//
+// Shell equivalent:
+//   sort -k2,2nr -k1,1 | paste -sd,
+func byCount(authors map[string]int) string {
+	names := slices.Collect(maps.Keys(authors))
+	slices.SortFunc(names, func(a, b string) int {
+		if (a == "unassigned") != (b == "unassigned") {
+			if a == "unassigned" {
+				return 1
+			}
+			return -1
+		}
+		if c := cmp.Compare(authors[b], authors[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf("%s %d", name, authors[name])
+	}
+	return strings.Join(pairs, ", ")
+}
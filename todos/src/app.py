@@ -0,0 +1,7 @@
+import json
+
+def load(path):
+    # TODO: cache the results
+    # todo: lowercase markers aren't counted
+    with open(path) as f:
+        return json.load(f)
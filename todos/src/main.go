@@ -0,0 +1,14 @@
+package main
+
+import "os"
+
+func main() {
+	// TODO(ada): parse the flags
+	args := os.Args[1:]
+	// FIXME: exits 0 even when run fails
+	run(args)
+}
+
+func run(args []string) error {
+	return nil
+}
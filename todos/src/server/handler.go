@@ -0,0 +1,12 @@
+package server
+
+import "sync"
+
+// TODO: timeouts
+var mu sync.Mutex
+
+func Handle() {
+	mu.Lock() // XXX(bob): held across the write, far too long
+	defer mu.Unlock()
+	/* FIXME(carol) leaks a goroutine per request */
+}
@@ -0,0 +1,6 @@
+const TODOS = [];
+
+// TODO(ada) handle a double submit
+export function submit(item) {
+  TODOS.push(item);
+}
@@ -0,0 +1,110 @@
+#!/bin/bash
+
+# Find the TODO, FIXME and XXX comments in a source tree, and report them
+# grouped by marker, with where each one is
+# yupsh equivalent: See main.go
+
+# Parse flags (--ext LIST, --author), then the directory
+# yupsh: exts := opts.String("ext", sourceExts, ...); author := opts.Bool("author", ...)
+EXTS=go,c,h,cc,cpp,hpp,rs,java,kt,scala,swift,cs,py,rb,pl,php,lua,js,jsx,ts,tsx,sh,bash,sql
+AUTHOR=0
+usage() {
+  echo "Usage: $0 [--ext go,py,...] [--author] [directory]" >&2
+  exit 2
+}
+while [[ $1 == --* ]]; do
+  case $1 in
+    --ext) EXTS=$2; shift 2 ;;
+    --author) AUTHOR=1; shift ;;
+    *) usage ;;
+  esac
+done
+(( $# <= 1 )) || usage
+DIR=${1:-.}
+if [[ ! -e ${DIR} ]]; then
+  echo "todos: stat ${DIR}: no such file or directory" >&2
+  exit 2
+elif [[ ! -d ${DIR} ]]; then
+  echo "todos: ${DIR}: not a directory" >&2
+  exit 2
+fi
+
+# go,py -> \.(go|py)$
+# yupsh: extensions(*exts)
+EXT_PATTERN="\\.($(sed -e 's/[.[\*^$+?(){}|]/\\&/g' -e 's/\(^\|,\)\\\./\1/g' -e 's/,/|/g' <<< "${EXTS}"))\$"
+
+# Source files, not under another project's directories, in order
+# yupsh: find.Find(...) | grep.Grep(extPattern) | grep.Grep(skipDirs, grep.Invert) | sort.Sort()
+find "${DIR}" -type f |
+  grep -E "${EXT_PATTERN}" |
+  grep -v -E '(^|/)(\.git|\.hg|node_modules|vendor|third_party)/' |
+  LC_ALL=C sort |
+  awk -v author="${AUTHOR}" '
+    BEGIN {
+      nmarkers = split("TODO FIXME XXX", markers, " ")
+      # A marker as a whole word, as grep -w and \b match
+      word = "(^|[^A-Za-z0-9_])(TODO|FIXME|XXX)([^A-Za-z0-9_]|$)"
+    }
+
+    # yupsh: s.file(), reading each file and collecting its markers
+    {
+      file = $0
+      n = 0
+      while ((getline line < file) > 0) {
+        n++
+        if (!match(line, word)) continue
+        # The match may start with the character before the marker
+        start = RSTART
+        if (substr(line, start, 1) !~ /[A-Z]/) start++
+        marker = substr(line, start)
+        sub(/[^A-Z].*/, "", marker)
+        rest = substr(line, start + length(marker))
+
+        # yupsh: s.split(rest)
+        name = ""
+        if (author && rest ~ /^\([^)]*\)/) {
+          name = substr(rest, 2, index(rest, ")") - 2)
+          gsub(/^[ \t]+|[ \t]+$/, "", name)
+          rest = substr(rest, index(rest, ")") + 1)
+        }
+        sub(/^:/, "", rest); sub(/^[ \t]+/, "", rest); sub(/[ \t]+$/, "", rest)
+        sub(/\*\/$/, "", rest); sub(/[ \t]+$/, "", rest)
+        sub(/-->$/, "", rest); sub(/[ \t]+$/, "", rest)
+
+        if (author) {
+          if (name == "") name = "unassigned"
+          else rest = "[" name "] " rest
+          authors[name]++
+        }
+        items[marker] = items[marker] sprintf("  %s:%d  %s\n", file, n, rest)
+        count[marker]++
+        total++
+      }
+      if (n == 0 && (getline line < file) < 0) {
+        printf "todos: open %s: permission denied\n", file > "/dev/stderr"
+        failed = 1
+      }
+      close(file)
+    }
+
+    # yupsh: s.report()
+    END {
+      if (total == 0) exit failed ? 2 : 1
+      for (i = 1; i <= nmarkers; i++) {
+        m = markers[i]
+        if (!count[m]) continue
+        printf "%s (%d)\n%s", m, count[m], items[m]
+        summary = summary (summary == "" ? "" : ", ") count[m] " " m
+      }
+      printf "\n%d marker(s): %s\n", total, summary
+      if (author) {
+        # yupsh: byCount(authors), the most first, unassigned last
+        cmd = "LC_ALL=C sort -k1,1n -k2,2 | cut -f2,3 | tr \"\\t\" \" \" | paste -sd, - | sed \"s/,/, /g\""
+        printf "by author: "
+        for (name in authors) {
+          printf "%d\t%s\t%d\n", (name == "unassigned" ? 1 : -authors[name]), name, authors[name] | cmd
+        }
+        close(cmd)
+      }
+      exit failed ? 2 : 0
+    }'
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	head `github.com/yupsh/head`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	sort `github.com/yupsh/sort`
+)
+
+// Keep the K lines with the largest numeric value, in O(K) memory
+// Shell equivalent: See topk.sh
+//
+// Reads "key<TAB>value" lines and prints the top K, largest first:
+//   topk --k 2 sizes.tsv
+//   db.sql	4120
+//   demo.mp4	1180
+//
+// `sort -nr | head -n K` gives the same answer, but sort.Sort() (like GNU
+// sort) must buffer every line before head sees the first one. A bounded
+// min-heap only ever holds K lines: each new line either replaces the
+// smallest of the current top K or is dropped.
+//
+// Lines whose value field is not a number are skipped with a warning.
+// --sort runs the sort.Sort() | head.Head() pipeline instead, to compare the
+// two (see the README's benchmark).
+//
+// Usage: topk [--k 10] [--field 2] [--sort] [file...]
+func main() {
+	opts := flags.New("topk", "[file...]")
+	k := opts.Int("k", flags.DefaultTop, "keep the `N` largest lines")
+	field := opts.Int("field", 2, "tab-separated `field` holding the value")
+	useSort := opts.Bool("sort", false, "use sort | head instead of the heap, for comparison")
+	opts.Parse()
+	if *k < 1 {
+		opts.Fail("--k must be at least 1")
+	}
+	if *field < 1 {
+		opts.Fail("--field must be at least 1")
+	}
+
+	selectTop := topK(*k, *field)
+	if *useSort {
+		// Shell: sort -t$'\t' -k2,2 -nr | head -n 10
+		selectTop = viaSort(*k, *field)
+	}
+
+	// Shell: cat "$@" | topk
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		selectTop,
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "topk: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// topK prints the k lines of stdin with the largest value in field, largest
+// first; equal values keep their input order
+func topK(k, field int) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		h := &minHeap{}
+		scanner := bufio.NewScanner(stdin)
+		for n := 1; scanner.Scan(); n++ {
+			line := scanner.Text()
+			value, err := valueOf(line, field)
+			if err != nil {
+				fmt.Fprintf(stderr, "topk: line %d: %v\n", n, err)
+				continue
+			}
+
+			entry := entry{line: line, value: value, seq: n}
+			switch {
+			case h.Len() < k:
+				heap.Push(h, entry)
+			case h.less(h.entries[0], entry):
+				// Replace the smallest of the current top K
+				h.entries[0] = entry
+				heap.Fix(h, 0)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+
+		// Popping yields smallest first, so fill the result from the end
+		top := make([]string, h.Len())
+		for i := len(top) - 1; i >= 0; i-- {
+			top[i] = heap.Pop(h).(entry).line
+		}
+		for _, line := range top {
+			if _, err := fmt.Fprintln(stdout, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// viaSort is the pipeline --sort runs in place of topK(): the same lines,
+// after sorting all of them
+//
+// Shell equivalent:
+//   sort -t$'\t' -k2,2 -nr | head -n K
+func viaSort(k, field int) gloo.Command {
+	return pipe.Pipeline(
+		sort.Sort(sort.Field(field), sort.Delimiter("\t"), sort.Numeric, sort.Reverse),
+		head.Head(head.LineCount(k)),
+	)
+}
+
+// valueOf parses the numeric value in a tab-separated field of line
+// Shell: cut -f2
+func valueOf(line string, field int) (float64, error) {
+	fields := strings.Split(line, "\t")
+	if field > len(fields) {
+		return 0, fmt.Errorf("no field %d", field)
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(fields[field-1]), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", fields[field-1])
+	}
+	return value, nil
+}
+
+// entry is one candidate line; seq is its line number, used to rank ties
+type entry struct {
+	line  string
+	value float64
+	seq   int
+}
+
+// minHeap is a container/heap whose root is the lowest-ranked entry
+type minHeap struct {
+	entries []entry
+}
+
+// less reports whether a ranks below b: a smaller value, or an equal value
+// seen later
+func (h *minHeap) less(a, b entry) bool {
+	if a.value != b.value {
+		return a.value < b.value
+	}
+	return a.seq > b.seq
+}
+
+func (h *minHeap) Len() int { return len(h.entries) }
+
+func (h *minHeap) Less(i, j int) bool { return h.less(h.entries[i], h.entries[j]) }
+
+func (h *minHeap) Swap(i, j int) { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+
+func (h *minHeap) Push(x any) { h.entries = append(h.entries, x.(entry)) }
+
+func (h *minHeap) Pop() any {
+	last := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	return last
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	gloo `github.com/gloo-foo/framework`
+)
+
+// keyValues returns n random "keyN<TAB>value" lines, as the README's
+// benchmark makes with awk; the values are distinct, so every way of
+// picking the top K agrees on the order
+func keyValues(n int) string {
+	rng := rand.New(rand.NewPCG(606, 1))
+	var b strings.Builder
+	for i, v := range rng.Perm(n) {
+		fmt.Fprintf(&b, "key%d\t%d\n", i, v*1000+rng.IntN(1000))
+	}
+	return b.String()
+}
+
+// run runs cmd over stdin and returns what it wrote to stdout
+func run(t testing.TB, cmd gloo.Command, stdin string) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	if err := cmd.Executor()(context.Background(), strings.NewReader(stdin), &stdout, &stderr); err != nil {
+		t.Fatalf("run: %v\n%s", err, stderr.String())
+	}
+	return stdout.String()
+}
+
+// TestTopKMatchesSort checks that the heap prints the same lines as
+// sort | head
+func TestTopKMatchesSort(t *testing.T) {
+	data := keyValues(5000)
+	for _, k := range []int{1, 10, 100, 5000, 6000} {
+		want := run(t, viaSort(k, 2), data)
+		if got := run(t, topK(k, 2), data); got != want {
+			t.Errorf("k=%d: heap and sort | head differ:\n%s\nwant:\n%s", k, got, want)
+		}
+	}
+}
+
+// TestTopKTies checks that equal values keep their input order, and that a
+// line without a numeric value is skipped with a warning
+func TestTopKTies(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	in := "a\t1\nb\t3\nc\tx\nd\t3\ne\t2\nf\n"
+	if err := topK(3, 2).Executor()(context.Background(), strings.NewReader(in), &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if want := "b\t3\nd\t3\ne\t2\n"; stdout.String() != want {
+		t.Errorf("top 3 = %q, want %q", stdout.String(), want)
+	}
+	if want := "topk: line 3: invalid value \"x\"\ntopk: line 6: no field 2\n"; stderr.String() != want {
+		t.Errorf("warnings = %q, want %q", stderr.String(), want)
+	}
+}
+
+// BenchmarkTopK compares the bounded heap with sort | head, the README's
+// benchmark on a smaller input:
+//
+//   go test -bench TopK -benchmem
+//
+// The heap's memory stays at K lines, sort's grows with the input.
+func BenchmarkTopK(b *testing.B) {
+	for _, lines := range []int{10_000, 200_000} {
+		data := keyValues(lines)
+		for _, bm := range []struct {
+			name string
+			cmd  func(k, field int) gloo.Command
+		}{
+			{"heap", topK},
+			{"sort", viaSort},
+		} {
+			b.Run(fmt.Sprintf("%s/lines=%d", bm.name, lines), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(len(data)))
+				for b.Loop() {
+					var stderr bytes.Buffer
+					err := bm.cmd(10, 2).Executor()(context.Background(), strings.NewReader(data), io.Discard, &stderr)
+					if err != nil {
+						b.Fatalf("%v\n%s", err, stderr.String())
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,24 @@
+#!/bin/bash
+set -e
+
+# Keep the K lines with the largest numeric value
+# yupsh equivalent: See main.go
+
+# Parse flags (--k N, --field N), then the files
+# yupsh: k := opts.Int("k", flags.DefaultTop, ...); field := opts.Int("field", 2, ...)
+K=10
+FIELD=2
+while [[ $1 == --* ]]; do
+  case $1 in
+    --k) K=$2; shift 2 ;;
+    --field) FIELD=$2; shift 2 ;;
+    --sort) shift ;;  # the shell version always uses sort | head
+    *) echo "Usage: $0 [--k 10] [--field 2] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Sort everything by the value, largest first, and keep the first K
+# yupsh: topK(*k, *field) - a bounded min-heap instead of a full sort
+cat "$@" \
+| sort -t$'\t' -s -k"${FIELD},${FIELD}" -nr \
+| head -n "${K}"
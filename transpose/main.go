@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Transpose a grid of delimited values: rows become columns
+// Shell equivalent: See transpose.sh
+//
+//   a<TAB>b<TAB>c          a<TAB>1
+//   1<TAB>2<TAB>3    ->    b<TAB>2
+//                          c<TAB>3
+//
+// Ragged input is padded: a row shorter than the longest gets --fill
+// (empty by default) in its missing cells, so every output row has as many
+// cells as there were input rows.
+//
+// The first output row needs the first cell of *every* input row, so the
+// whole grid is buffered with gloo.AccumulateAndOutput() (as in pivot),
+// then written out column by column.
+//
+// Usage: transpose [--delim TAB] [--fill VALUE] [file...]
+func main() {
+	opts := flags.New("transpose", "[file...]")
+	delim := opts.String("delim", "\t", "cell `separator`, for input and output")
+	fill := opts.String("fill", "", "`value` for the missing cells of short rows")
+	opts.Parse()
+	if *delim == "" {
+		opts.Fail("--delim must not be empty")
+	}
+
+	// Shell: cat "$@" | awk -F'\t' '{for (i = 1; i <= NF; i++) cell[NR, i] = $i} END {...}'
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		transpose(*delim, *fill),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "transpose: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// transpose buffers every row, then prints column i of the input as row i
+func transpose(delim, fill string) gloo.Command {
+	return gloo.AccumulateAndOutput(func(lines []string, stdout io.Writer) error {
+		// Pass 1: split the rows and find the widest
+		// Shell: {for (i = 1; i <= NF; i++) cell[NR, i] = $i; if (NF > max) max = NF}
+		rows := make([][]string, len(lines))
+		width := 0
+		for i, line := range lines {
+			rows[i] = strings.Split(line, delim)
+			width = max(width, len(rows[i]))
+		}
+
+		// Pass 2: output row c is input column c
+		// Shell: END {for (c = 1; c <= max; c++) {for (r = 1; r <= NR; r++) ...}}
+		out := make([]string, len(rows))
+		for c := range width {
+			for r, row := range rows {
+				if c < len(row) {
+					out[r] = row[c]
+				} else {
+					out[r] = fill
+				}
+			}
+			if _, err := fmt.Fprintln(stdout, strings.Join(out, delim)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
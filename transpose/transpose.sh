@@ -0,0 +1,41 @@
+#!/bin/bash
+set -e
+
+# Transpose a grid of delimited values: rows become columns
+# yupsh equivalent: See main.go
+
+# Parse flags (--delim SEP, --fill VALUE), then the files
+# yupsh: delim := opts.String("delim", "\t", ...); fill := opts.String("fill", "", ...)
+DELIM=$'\t'
+FILL=
+while [[ $1 == --* ]]; do
+  case $1 in
+    --delim) DELIM=$2; shift 2 ;;
+    --fill) FILL=$2; shift 2 ;;
+    *) echo "Usage: $0 [--delim TAB] [--fill VALUE] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Buffer every cell, then print column by column in END
+# yupsh: transpose(*delim, *fill) built on gloo.AccumulateAndOutput()
+cat "$@" | awk -v delim="${DELIM}" -v fill="${FILL}" '
+  # Pass 1: split the rows and find the widest
+  # yupsh: rows[i] = strings.Split(line, delim); width = max(width, len(rows[i]))
+  {
+    n = split($0, f, delim)
+    if (n == 0) n = 1
+    for (i = 1; i <= n; i++) cell[NR, i] = f[i]
+    len[NR] = n
+    if (n > width) width = n
+  }
+
+  # Pass 2: output row c is input column c
+  # yupsh: for c := range width { ... strings.Join(out, delim) }
+  END {
+    for (c = 1; c <= width; c++) {
+      line = ""
+      for (r = 1; r <= NR; r++)
+        line = line (r > 1 ? delim : "") (c <= len[r] ? cell[r, c] : fill)
+      print line
+    }
+  }'
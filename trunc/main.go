@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	term `github.com/yupsh/script-examples/internal/term`
+	. `github.com/yupsh/while`
+	textwidth `golang.org/x/text/width`
+)
+
+// Clip each line to the terminal width, marking clipped lines with "…"
+// Shell equivalent: See trunc.sh
+//
+//   kubectl get pods -o wide | trunc --width 40
+//   NAME                   READY   STATUS  …
+//   api-7d9f8c6b5-x2x9q    1/1     Running…
+//
+// The width is --width when given, else the width of the terminal on stdout,
+// else $COLUMNS, else 80 (see internal/term). Widths are display columns:
+// tabs advance to the next multiple of 8, East Asian wide characters (CJK,
+// fullwidth forms, most emoji) take two columns, and combining marks none,
+// as a terminal displays them. Sequences a terminal draws as one glyph, such
+// as emoji joined with U+200D, are counted a character at a time, so they
+// may be clipped early.
+//
+// Usage: trunc [--width N] [file...]
+func main() {
+	opts := flags.New("trunc", "[file...]")
+	width := opts.Int("width", 0, "clip lines to `N` columns (default terminal width)")
+	opts.Parse()
+	if *width < 0 {
+		opts.Fail("--width must not be negative")
+	}
+	if *width == 0 {
+		// Shell: WIDTH=$(tput cols)
+		*width = term.Width()
+	}
+
+	// Shell: cut -c1-"${WIDTH}"
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		While(clipTo(*width), input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "trunc: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// ellipsis replaces the last column of a clipped line
+const ellipsis = "…"
+
+// clipTo returns the While() callback clipping each line to width columns
+func clipTo(width int) Body {
+	return func(args ...any) gloo.Command {
+		return echo.Echo(clip(args[0].(string), width))
+	}
+}
+
+// clip shortens line to width columns, ending in the ellipsis, if it is
+// wider than that
+func clip(line string, width int) string {
+	if columns(line) <= width {
+		return line
+	}
+
+	// Keep what fits in width-1 columns, leaving one for the ellipsis
+	var b strings.Builder
+	column := 0
+	for _, c := range line {
+		next := advance(column, c)
+		if next > width-1 {
+			break
+		}
+		b.WriteRune(c)
+		column = next
+	}
+	return b.String() + ellipsis
+}
+
+// columns returns the display width of s
+func columns(s string) int {
+	column := 0
+	for _, c := range s {
+		column = advance(column, c)
+	}
+	return column
+}
+
+// advance returns the column after printing c at column: a tab moves to
+// the next tab stop, a wide character takes two columns, and a combining
+// mark or an invisible format character, such as U+200D, takes none
+// Shell: wcwidth(3)
+func advance(column int, c rune) int {
+	switch {
+	case c == '\t':
+		return column + 8 - column%8
+	case unicode.In(c, unicode.Mn, unicode.Me, unicode.Cf):
+		return column
+	}
+	switch textwidth.LookupRune(c).Kind() {
+	case textwidth.EastAsianWide, textwidth.EastAsianFullwidth:
+		return column + 2
+	}
+	return column + 1
+}
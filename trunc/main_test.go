@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+// TestClip checks that lines are clipped to display columns: tabs to the
+// next stop, wide characters two columns, combining marks none
+func TestClip(t *testing.T) {
+	tests := []struct {
+		line  string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"one too long", 11, "one too lo…"},
+		{"a\tb", 9, "a\tb"},
+		{"a\tbcd", 10, "a\tb…"},
+		{"日本語のテキスト", 16, "日本語のテキスト"},
+		{"日本語のテキスト", 11, "日本語のテ…"},
+		{"日本語のテキスト", 10, "日本語の…"},
+		{"ＦＵＬＬ width", 8, "ＦＵＬ…"},
+		{"e\u0301e\u0301e\u0301e\u0301e\u0301", 5, "e\u0301e\u0301e\u0301e\u0301e\u0301"},
+		{"e\u0301e\u0301e\u0301e\u0301e\u0301", 4, "e\u0301e\u0301e\u0301…"},
+	}
+	for _, tt := range tests {
+		got := clip(tt.line, tt.width)
+		if got != tt.want {
+			t.Errorf("clip(%q, %d) = %q, want %q", tt.line, tt.width, got, tt.want)
+		}
+		if n := columns(got); n > tt.width {
+			t.Errorf("clip(%q, %d) = %q is %d columns wide", tt.line, tt.width, got, n)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+#!/bin/bash
+set -e
+
+# Clip each line to the terminal width, marking clipped lines with "…"
+# yupsh equivalent: See main.go
+
+# Parse flags (--width N), then the files
+# yupsh: width := opts.Int("width", 0, ...)
+WIDTH=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --width) WIDTH=$2; shift 2 ;;
+    *) echo "Usage: $0 [--width N] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Terminal width on stdout, else $COLUMNS, else 80
+# yupsh: term.Width()
+if (( WIDTH == 0 )); then
+  if [[ -t 1 ]]; then
+    WIDTH=$(tput cols)
+  else
+    WIDTH=${COLUMNS:-80}
+  fi
+fi
+
+# Clip every line (expand turns tabs into the spaces a terminal shows)
+# yupsh: While(clipTo(*width), input.WholeLine)
+cat "$@" | expand | while IFS= read -r line; do
+  if (( ${#line} > WIDTH )); then
+    line="${line:0:WIDTH-1}…"
+  fi
+  printf '%s\n' "${line}"
+done
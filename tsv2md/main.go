@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	awk `github.com/yupsh/awk`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Turn tab-separated rows into a GitHub-flavored Markdown table
+// Shell equivalent: See tsv2md.sh
+//
+//   printf 'name\tsize\nmain.go\t4120\nREADME.md\t880\n' | tsv2md
+//   | name | size |
+//   | --- | --- |
+//   | main.go | 4120 |
+//   | README.md | 880 |
+//
+// The first row is the header, and the separator row under it is generated.
+// Every row gets the header's number of cells: short rows are padded with
+// empty cells, and the extra cells of long ones are dropped with a warning,
+// as GitHub would hide them anyway. "|" in a cell is escaped as "\|", and
+// empty lines are skipped, since one would end the table.
+//
+// By default each row is printed as it is read. --align buffers the whole
+// table instead, to pad every column to its widest cell, so the Markdown
+// source lines up too, and to right-align the columns that hold only
+// numbers, both in the source and, with a "--:" separator, when rendered:
+//
+//   tsv2md --align
+//   | name      | size |
+//   | --------- | ---: |
+//   | main.go   | 4120 |
+//   | README.md |  880 |
+//
+// Usage: tsv2md [--align] [--delim TAB] [file...]
+func main() {
+	opts := flags.New("tsv2md", "[file...]")
+	align := opts.Bool("align", false, "pad columns to line up, and right-align numeric ones")
+	delim := opts.Delim("\t")
+	opts.Parse()
+
+	// Shell: awk -F'\t' '...' "$@"
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		awk.Awk(&markdownProgram{align: *align}, awk.FieldSeparator(*delim)),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tsv2md: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// markdownProgram is a custom awk program that prints rows as a Markdown
+// table, as they are read or, with align, all at once at the end
+//
+// Shell awk pattern:
+//   NR == 1 {print row($0); print sep()}   - Action: the header, then "---"s
+//   {print row($0)}                        - Action: every other row
+//   END {...}                              - End: the table, with --align
+type markdownProgram struct {
+	awk.SimpleProgram
+	align   bool
+	columns int        // cells in the header; 0 until it is read
+	rows    [][]string // the header and rows, buffered for --align
+}
+
+// Condition skips empty lines, which would end the table, and with
+// --delim " " lines of only blanks, which have no fields
+// Shell: NF > 0
+func (p *markdownProgram) Condition(ctx *awk.Context) bool {
+	return ctx.Field(0) != "" && ctx.NF > 0
+}
+
+// Action escapes the row's cells, fits them to the header's columns, and
+// prints the row, or keeps it for End() with --align
+// Shell: {print row($0)}
+func (p *markdownProgram) Action(ctx *awk.Context) (string, bool) {
+	// ctx.Fields[0] is the whole line ($0); the cells follow it
+	cells := make([]string, 0, ctx.NF)
+	for _, cell := range ctx.Fields[1:] {
+		// Shell: gsub(/\|/, "\\|", cell)
+		cells = append(cells, strings.ReplaceAll(cell, "|", `\|`))
+	}
+
+	header := p.columns == 0
+	if header {
+		p.columns = len(cells)
+	}
+	if len(cells) > p.columns {
+		fmt.Fprintf(os.Stderr, "tsv2md: line %d: %d cells, but the header has %d; extra cells dropped\n",
+			ctx.NR, len(cells), p.columns)
+		cells = cells[:p.columns]
+	}
+	for len(cells) < p.columns {
+		cells = append(cells, "")
+	}
+
+	if p.align {
+		p.rows = append(p.rows, cells)
+		return "", false
+	}
+
+	// Shell: NR == 1 {print row($0); print sep()}
+	if header {
+		dashes := make([]string, p.columns)
+		for i := range dashes {
+			dashes[i] = "---"
+		}
+		return row(cells) + "\n" + row(dashes), true
+	}
+	return row(cells), true
+}
+
+// End prints the buffered table with --align: every column padded to its
+// widest cell, and numeric columns right-aligned
+// Shell: END {for (i = 1; i <= NR; i++) printf "| %-*s |", width[1], ...}
+func (p *markdownProgram) End(ctx *awk.Context) (string, error) {
+	if !p.align || len(p.rows) == 0 {
+		return "", nil
+	}
+
+	// Shell: width[i] = max(width[i], length(cell))
+	widths := make([]int, p.columns)
+	for i := range widths {
+		widths[i] = 3 // room for the separator's "---"
+	}
+	for _, cells := range p.rows {
+		for i, cell := range cells {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	// The header, then the separator, with "--:" under numeric columns
+	numeric := numericColumns(p.rows[1:], p.columns)
+	lines := []string{row(pad(p.rows[0], widths, numeric))}
+	sep := make([]string, p.columns)
+	for i, width := range widths {
+		if numeric[i] {
+			sep[i] = strings.Repeat("-", width-1) + ":"
+		} else {
+			sep[i] = strings.Repeat("-", width)
+		}
+	}
+	lines = append(lines, row(sep))
+
+	for _, cells := range p.rows[1:] {
+		lines = append(lines, row(pad(cells, widths, numeric)))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// row formats cells as one line of a Markdown table
+// Shell: "| " cell[1] " | " cell[2] ... " |"
+func row(cells []string) string {
+	return "| " + strings.Join(cells, " | ") + " |"
+}
+
+// pad returns cells padded to widths, on the left in numeric columns so the
+// numbers line up on their last digit, and on the right elsewhere
+// Shell: printf "%*s" for numbers, "%-*s" for text
+func pad(cells []string, widths []int, numeric []bool) []string {
+	padded := make([]string, len(cells))
+	for i, cell := range cells {
+		spaces := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		if numeric[i] {
+			padded[i] = spaces + cell
+		} else {
+			padded[i] = cell + spaces
+		}
+	}
+	return padded
+}
+
+// numericColumns reports, for each column, whether every non-empty cell of
+// rows is a number, and at least one is
+func numericColumns(rows [][]string, columns int) []bool {
+	numeric := make([]bool, columns)
+	for i := range numeric {
+		for _, cells := range rows {
+			if cells[i] == "" {
+				continue
+			}
+			if _, err := strconv.ParseFloat(cells[i], 64); err != nil {
+				numeric[i] = false
+				break
+			}
+			numeric[i] = true
+		}
+	}
+	return numeric
+}
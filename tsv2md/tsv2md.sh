@@ -0,0 +1,90 @@
+#!/bin/bash
+set -e
+
+# Turn tab-separated rows into a GitHub-flavored Markdown table
+# yupsh equivalent: See main.go
+
+# Parse flags (--align, --delim SEP), then the files
+# yupsh: align := opts.Bool("align", ...); delim := opts.Delim("\t")
+ALIGN=0
+DELIM=$'\t'
+while [[ $1 == --* ]]; do
+  case $1 in
+    --align) ALIGN=1; shift ;;
+    --delim) DELIM=$2; shift 2 ;;
+    *) echo "Usage: $0 [--align] [--delim TAB] [file...]" >&2; exit 2 ;;
+  esac
+done
+# --delim '\t' means a tab, as in the yupsh version
+[[ ${DELIM} == '\t' ]] && DELIM=$'\t'
+
+# Print each row as it's read, the generated separator after the header; or
+# with --align, buffer them and print the padded table at the end
+# (widths count characters in gawk, but bytes in mawk)
+# yupsh: awk.Awk(&markdownProgram{align: *align}, awk.FieldSeparator(*delim))
+cat "$@" | awk -F"${DELIM}" -v align="${ALIGN}" '
+  # yupsh: row(cells)
+  function row(r,   s, i) {
+    s = "|"
+    for (i = 1; i <= cols; i++) s = s " " cell[r, i] " |"
+    return s
+  }
+
+  # yupsh: Condition(): ctx.Field(0) != "" && ctx.NF > 0
+  $0 == "" || NF == 0 { next }
+
+  # yupsh: Action()
+  {
+    n++
+    if (n == 1) cols = NF
+    if (NF > cols) {
+      printf "tsv2md: line %d: %d cells, but the header has %d; extra cells dropped\n", NR, NF, cols > "/dev/stderr"
+    }
+    for (i = 1; i <= cols; i++) {
+      c = $i
+      gsub(/\|/, "\\|", c)
+      cell[n, i] = c
+      if (length(c) > width[i]) width[i] = length(c)
+      # yupsh: numericColumns()
+      if (n > 1 && c != "") {
+        if (c ~ /^[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?$/) num[i] = 1
+        else text[i] = 1
+      }
+    }
+    if (!align) {
+      print row(n)
+      if (n == 1) {
+        s = "|"
+        for (i = 1; i <= cols; i++) s = s " --- |"
+        print s
+      }
+      for (i = 1; i <= cols; i++) delete cell[n, i]
+    }
+  }
+
+  # yupsh: End()
+  END {
+    if (!align || n == 0) exit
+    for (i = 1; i <= cols; i++) {
+      if (width[i] < 3) width[i] = 3
+      numeric[i] = (i in num) && !(i in text)
+    }
+    for (r = 1; r <= n; r++) {
+      # yupsh: pad(cells, widths, numeric)
+      for (i = 1; i <= cols; i++) {
+        cell[r, i] = sprintf(numeric[i] ? "%*s" : "%-*s", width[i], cell[r, i])
+      }
+      print row(r)
+      if (r == 1) {
+        s = "|"
+        for (i = 1; i <= cols; i++) {
+          dashes = sprintf("%*s", width[i], "")
+          gsub(/ /, "-", dashes)
+          if (numeric[i]) dashes = substr(dashes, 2) ":"
+          s = s " " dashes " |"
+        }
+        print s
+      }
+    }
+  }
+'
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	digest `github.com/yupsh/script-examples/internal/digest`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Verify files against a checksum manifest
+// Shell equivalent: See verify.sh
+//
+// The other half of the integrity workflow started by hashfiles: read a
+// manifest of "digest  path" lines, recompute each file's digest and report
+//   path: OK        digest matches
+//   path: FAILED    digest differs
+//   path: MISSING   file no longer exists (or can't be read)
+// The exit status is 1 if any entry failed or was missing, as with
+// `sha256sum -c`, so it can gate a script: verify manifest.sha256 && deploy
+//
+// The algorithm is detected per line from the digest length, so md5, sha1
+// and sha256 manifests are all accepted.
+//
+// Usage: verify [manifest...]
+func main() {
+	opts := flags.New("verify", "[manifest...]")
+	opts.Parse()
+
+	// Tally results across all callback invocations
+	// Shell: failed=0; missing=0
+	var results tally
+
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+
+		// Read the manifests, or stdin when there are none
+		// Shell: cat "$@"
+		input.Source(opts.Args()...),
+
+		// Split each line on the two-space separator used by sha256sum
+		// Shell: while read -r sum path; do ...; done
+		// yupsh: args[0] = digest, args[1:] = path (rejoined if it contains "  ")
+		While(results.check, FieldSeparator("  ")),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "verify: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+
+	// Summarize problems on stderr, like sha256sum -c
+	if results.missing > 0 {
+		fmt.Fprintf(os.Stderr, "verify: WARNING: %d listed file(s) could not be read\n", results.missing)
+	}
+	if results.failed > 0 {
+		fmt.Fprintf(os.Stderr, "verify: WARNING: %d computed checksum(s) did NOT match\n", results.failed)
+	}
+	if results.failed+results.missing > 0 {
+		os.Exit(1)
+	}
+}
+
+// tally counts problem entries while the manifest is checked
+type tally struct {
+	failed  int // Digest mismatches (or unrecognized digests)
+	missing int // Files that could not be opened or read
+}
+
+// check recomputes one manifest entry and reports its status
+//
+// Shell equivalent:
+//   if [ ! -r "$path" ]; then echo "$path: MISSING"
+//   elif [ "$(sha256sum "$path" | cut -d' ' -f1)" = "$sum" ]; then echo "$path: OK"
+//   else echo "$path: FAILED"; fi
+//
+// It is a method so the While() loop can update the tally that main() reads
+// after the pipeline finishes.
+func (t *tally) check(args ...any) gloo.Command {
+	if len(args) < 2 || args[0].(string) == "" {
+		return nil // Skip blank or malformed lines
+	}
+
+	want := strings.ToLower(args[0].(string))
+	fields := make([]string, len(args)-1)
+	for i, arg := range args[1:] {
+		fields[i] = arg.(string)
+	}
+	// "*" marks binary mode in sha256sum output; the path follows it
+	path := strings.TrimPrefix(strings.Join(fields, "  "), "*")
+
+	algo, err := digest.Detect(want)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "verify: %s: %v\n", path, err)
+		t.failed++
+		return echo.Echo(path + ": FAILED")
+	}
+
+	got, err := digest.File(path, algo)
+	switch {
+	case err != nil:
+		t.missing++
+		return echo.Echo(path + ": MISSING")
+	case got != want:
+		t.failed++
+		return echo.Echo(path + ": FAILED")
+	}
+	return echo.Echo(path + ": OK")
+}
@@ -0,0 +1,45 @@
+#!/bin/bash
+set -o pipefail
+
+# Verify files against a checksum manifest
+# yupsh equivalent: See main.go
+
+failed=0
+missing=0
+
+# Read "digest  path" lines from the manifests (stdin when none)
+# yupsh: input.Source(opts.Args()...), While(results.check, FieldSeparator("  "))
+while IFS= read -r line; do
+  [[ -z ${line} ]] && continue
+  sum=${line%%  *}
+  path=${line#*  }
+  path=${path#\*}
+
+  # Pick the algorithm from the digest length
+  # yupsh: digest.Detect(want)
+  case ${#sum} in
+    64) algo=sha256 ;;
+    40) algo=sha1 ;;
+    32) algo=md5 ;;
+    *) echo "${path}: FAILED"; failed=$((failed + 1)); continue ;;
+  esac
+
+  # yupsh: digest.File(path, algo)
+  if ! got=$({ "${algo}sum" < "${path}"; } 2>/dev/null); then
+    echo "${path}: MISSING"
+    missing=$((missing + 1))
+  elif [[ ${got%% *} == "${sum,,}" ]]; then
+    echo "${path}: OK"
+  else
+    echo "${path}: FAILED"
+    failed=$((failed + 1))
+  fi
+done < <(cat "$@")
+
+if [[ ${missing} -gt 0 ]]; then
+  echo "verify: WARNING: ${missing} listed file(s) could not be read" >&2
+fi
+if [[ ${failed} -gt 0 ]]; then
+  echo "verify: WARNING: ${failed} computed checksum(s) did NOT match" >&2
+fi
+[[ $((failed + missing)) -eq 0 ]]
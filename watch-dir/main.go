@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	find `github.com/yupsh/find`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Watch a directory tree and print files as they are created, modified or
+// deleted
+// Shell equivalent: See watch-dir.sh
+//
+//	watch-dir --pattern '*.go' ./src
+//	created  src/util.go
+//	modified src/main.go
+//	deleted  src/old.go
+//
+// Changes are found by polling: every --interval the tree is listed with
+// find and each file stat'ed, and the listing is compared with the previous
+// one. A new path is "created", a missing one "deleted", and one whose size
+// or modification time changed "modified". The first listing is the
+// baseline, so files that already exist are not reported. Runs until
+// interrupted with Ctrl-C (exit status 0).
+//
+// Polling needs no dependency and works on any filesystem, at the cost of
+// latency (up to one interval) and a full walk per interval; a file created
+// and deleted within one interval is never seen.
+//
+// Usage: watch-dir [--interval 1s] [--pattern GLOB] [directory]
+func main() {
+	opts := flags.New("watch-dir", "[directory]")
+	interval := opts.Duration("interval", time.Second, "how often to look for changes")
+	pattern := opts.String("pattern", "", "watch only files whose name matches `glob`, e.g. '*.log'")
+	opts.Parse()
+	dir := opts.ArgOr(0, ".")
+
+	if *interval <= 0 {
+		opts.Fail("--interval must be positive")
+	}
+	if *pattern != "" {
+		if _, err := filepath.Match(*pattern, ""); err != nil {
+			opts.Fail("--pattern: %v", err)
+		}
+	}
+	// find only warns about a missing directory, so check it up front
+	if err := input.Dir(dir); err != nil {
+		status.Result{Err: err}.Exit("watch-dir")
+	}
+
+	// Shell: trap 'exit 0' INT TERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := watch(ctx, dir, *pattern, *interval); err != nil {
+		fmt.Fprintf(os.Stderr, "watch-dir: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// watch takes a snapshot every interval and prints how each differs from
+// the one before, until ctx is cancelled
+func watch(ctx context.Context, dir, pattern string, interval time.Duration) error {
+	prev, err := take(ctx, dir, pattern)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil // Ctrl-C: the normal way to stop watching
+		case <-ticker.C:
+		}
+
+		cur, err := take(ctx, dir, pattern)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for _, ev := range changes(prev, cur) {
+			fmt.Printf("%-8s %s\n", ev.kind, ev.path)
+		}
+		prev = cur
+	}
+}
+
+// fileState is what a snapshot records about a file to notice changes
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// snapshot maps each watched path to its state
+type snapshot map[string]fileState
+
+// take lists the files under dir and records their size and modification time
+//
+// Shell equivalent:
+//
+//	find "$DIR" -type f -name "$PATTERN" -exec stat -c '%s %Y %n' {} +
+func take(ctx context.Context, dir, pattern string) (snapshot, error) {
+	s := make(snapshot)
+	findOpts := []any{find.Dir(dir), find.FileType}
+	if pattern != "" {
+		findOpts = append(findOpts, find.Name(pattern))
+	}
+
+	err := gloo.RunWithContext(ctx, pipe.Pipeline(
+		find.Find(findOpts...),
+		While(s.add, input.WholeLine),
+	))
+	return s, err
+}
+
+// add is the While() callback: it records one file's state
+// Shell: stat -c '%s %Y %n' "$file"
+func (s snapshot) add(args ...any) gloo.Command {
+	path := args[0].(string)
+
+	// A file deleted between find and stat is simply not in this snapshot
+	if info, err := os.Stat(path); err == nil {
+		s[path] = fileState{size: info.Size(), modTime: info.ModTime()}
+	}
+	return nil
+}
+
+// event is one change between two snapshots
+type event struct {
+	kind string // "created", "modified" or "deleted"
+	path string
+}
+
+// changes compares two snapshots, returning the events ordered by path
+//
+// Shell equivalent:
+//
+//	comm -3 <(sort prev) <(sort cur)
+func changes(prev, cur snapshot) []event {
+	var events []event
+	for path, state := range cur {
+		old, existed := prev[path]
+		switch {
+		case !existed:
+			events = append(events, event{"created", path})
+		case old != state:
+			events = append(events, event{"modified", path})
+		}
+	}
+	for path := range prev {
+		if _, exists := cur[path]; !exists {
+			events = append(events, event{"deleted", path})
+		}
+	}
+
+	slices.SortFunc(events, func(a, b event) int { return strings.Compare(a.path, b.path) })
+	return events
+}
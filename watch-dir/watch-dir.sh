@@ -0,0 +1,47 @@
+#!/bin/bash
+set -e
+
+# Watch a directory tree and print files as they are created, modified or
+# deleted
+# yupsh equivalent: See main.go
+
+# Parse flags (--interval SECONDS, --pattern GLOB), then the directory
+# yupsh: interval := opts.Duration("interval", time.Second, ...); pattern := ...
+INTERVAL=1
+PATTERN='*'
+while [[ $1 == --* ]]; do
+  case $1 in
+    --interval) INTERVAL=${2%s}; shift 2 ;;
+    --pattern) PATTERN=$2; shift 2 ;;
+    *) echo "Usage: $0 [--interval 1s] [--pattern GLOB] [directory]" >&2; exit 2 ;;
+  esac
+done
+DIR=${1:-.}
+if [[ ! -d ${DIR} ]]; then
+  echo "watch-dir: ${DIR}: not a directory" >&2
+  exit 2
+fi
+
+# yupsh: signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+PREV=$(mktemp)
+CUR=$(mktemp)
+trap 'rm -f "${PREV}" "${CUR}"; exit 0' INT TERM
+trap 'rm -f "${PREV}" "${CUR}"' EXIT
+
+# One "path<TAB>size mtime" line per file, sorted by path
+# yupsh: take(ctx, dir, pattern) = find | While(s.add)
+snapshot() {
+  find "${DIR}" -type f -name "${PATTERN}" -printf '%p\t%s %T@\n' 2>/dev/null | LC_ALL=C sort
+}
+
+snapshot > "${PREV}"
+while sleep "${INTERVAL}"; do
+  snapshot > "${CUR}"
+  # yupsh: changes(prev, cur), ordered by path
+  LC_ALL=C join -t $'\t' -a 1 -a 2 -e '' -o 0,1.2,2.2 "${PREV}" "${CUR}" |
+    awk -F'\t' '
+      $2 == "" { printf "%-8s %s\n", "created", $1; next }
+      $3 == "" { printf "%-8s %s\n", "deleted", $1; next }
+      $2 != $3 { printf "%-8s %s\n", "modified", $1 }'
+  mv "${CUR}" "${PREV}"
+done
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Pick K random lines, each with a chance proportional to the weight in one
+// of its fields
+// Shell equivalent: See weighted-sample.sh
+//
+//   weighted-sample --count 2 --weight-field 2 --seed 7 servers.txt
+//   web-1 20
+//   db-2 5
+//
+// This is the A-Res algorithm of Efraimidis and Spirakis, a weighted
+// reservoir sample: each line gets the key u^(1/w), for a uniform random u
+// in (0, 1) and its weight w, and the K lines with the largest keys are the
+// sample. A heavier line's key is more likely to be large, so with K = 1 a
+// line is picked with probability w / (sum of weights), and with larger K
+// the lines are picked one after another in that way, without replacement.
+//
+// The input is read once and only the K best lines so far are kept, in a
+// min-heap on the key, so it can be any length, even a stream with no end
+// in sight. The keys are compared as log(u) / w, the logarithm of u^(1/w):
+// the same order, without u^(1/w) rounding to 1 when w is large.
+//
+// The sample is printed in input order. Lines whose weight is 0 are never
+// picked; lines where it isn't a non-negative number are skipped with a
+// warning. --seed makes the sample reproducible; without it, every run
+// picks anew.
+//
+// Usage: weighted-sample [--count K] [--weight-field N] [--delim SEP] [--seed S] [file...]
+func main() {
+	opts := flags.New("weighted-sample", "[file...]")
+	count := opts.Int("count", 1, "number of lines `K` to pick")
+	field := opts.Int("weight-field", 2, "`column` holding each line's weight")
+	delim := opts.Delim(" ")
+	seed := opts.Uint64("seed", 0, "random `seed`, for a reproducible sample (default: random)")
+	opts.Parse()
+	if *count < 1 {
+		opts.Fail("--count must be at least 1")
+	}
+	if *field < 1 {
+		opts.Fail("--weight-field must be at least 1")
+	}
+
+	src := rand.NewPCG(rand.Uint64(), rand.Uint64())
+	if *seed != 0 {
+		src = rand.NewPCG(*seed, *seed)
+	}
+	s := &sampler{k: *count, field: *field, delim: *delim, rng: rand.New(src)}
+
+	// Shell: awk '{print log(1 - rand()) / $2 "\t" NR "\t" $0}' | sort -gr | head -n K | sort -n
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		s.sample(),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "weighted-sample: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// entry is a line in the reservoir
+type entry struct {
+	key  float64 // log(u) / w: the largest keys win
+	n    int     // its line number, to print the sample in input order
+	line string
+}
+
+// reservoir is a min-heap of entries on their keys: the root is the entry
+// the next better line replaces
+type reservoir []entry
+
+func (r reservoir) Len() int           { return len(r) }
+func (r reservoir) Less(i, j int) bool { return r[i].key < r[j].key }
+func (r reservoir) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r *reservoir) Push(x any)        { *r = append(*r, x.(entry)) }
+func (r *reservoir) Pop() any {
+	old := *r
+	e := old[len(old)-1]
+	*r = old[:len(old)-1]
+	return e
+}
+
+// sampler holds the sample of the lines seen so far
+type sampler struct {
+	k     int
+	field int
+	delim string
+	rng   *rand.Rand
+	best  reservoir
+}
+
+// offer gives line n, of weight w, its key, and keeps it if the key is
+// among the k largest so far
+//
+// Shell equivalent:
+//   print log(rand()) / w "\t" NR "\t" $0
+func (s *sampler) offer(n int, line string, w float64) {
+	u := 1 - s.rng.Float64() // in (0, 1], so log(u) is never -Inf
+	key := math.Log(u) / w
+	switch {
+	case len(s.best) < s.k:
+		heap.Push(&s.best, entry{key, n, line})
+	case key > s.best[0].key:
+		s.best[0] = entry{key, n, line}
+		heap.Fix(&s.best, 0)
+	}
+}
+
+// sample returns the command that reads the weighted lines from stdin and
+// prints the sample, in input order, once the input ends
+func (s *sampler) sample() gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		scanner := bufio.NewScanner(stdin)
+		for n := 1; scanner.Scan(); n++ {
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+
+			w, err := strconv.ParseFloat(strings.TrimSpace(column(line, s.field, s.delim)), 64)
+			if err != nil || !(w >= 0) || math.IsInf(w, 1) {
+				fmt.Fprintf(stderr, "weighted-sample: line %d: column %d is not a weight\n", n, s.field)
+				continue
+			}
+			if w > 0 {
+				// A weight of 0 could only be picked with a key of -Inf
+				s.offer(n, line, w)
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+
+		// Shell: sort -t$'\t' -k2,2n | cut -f3-
+		slices.SortFunc(s.best, func(a, b entry) int { return a.n - b.n })
+		out := bufio.NewWriter(stdout)
+		for _, e := range s.best {
+			fmt.Fprintln(out, e.line)
+		}
+		return out.Flush()
+	})
+}
+
+// column returns field n of line, split on delim as --delim describes, or
+// "" if the line is too short
+func column(line string, n int, delim string) string {
+	var fields []string
+	if delim == " " {
+		fields = strings.Fields(line)
+	} else {
+		fields = strings.Split(line, delim)
+	}
+	if n > len(fields) {
+		return ""
+	}
+	return fields[n-1]
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// servers is four lines weighing 1, 2, 3 and 4, and one of weight 0
+const servers = "a 1\nb 2\nzero 0\nc 3\nd 4\n"
+
+// runs is how many seeded samples each statistical test draws
+const runs = 20000
+
+// sample draws k lines from lines with the given seed, as --seed does, and
+// returns them in the order printed
+func sample(t *testing.T, k int, seed uint64, lines string) ([]string, string) {
+	t.Helper()
+	s := &sampler{k: k, field: 2, delim: " ", rng: rand.New(rand.NewPCG(seed, seed))}
+	var stdout, stderr bytes.Buffer
+	if err := s.sample().Executor()(context.Background(), strings.NewReader(lines), &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, line := range strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n") {
+		if line != "" {
+			names = append(names, strings.Fields(line)[0])
+		}
+	}
+	return names, stderr.String()
+}
+
+// chiSquare returns Pearson's statistic for observed counts against the
+// expected probabilities, out of n draws
+func chiSquare(observed map[string]int, expected map[string]float64, n int) float64 {
+	var x2 float64
+	for key, p := range expected {
+		e := p * float64(n)
+		d := float64(observed[key]) - e
+		x2 += d * d / e
+	}
+	return x2
+}
+
+// TestSingleProportional checks that with --count 1 each line is picked in
+// proportion to its weight, over many seeded runs
+func TestSingleProportional(t *testing.T) {
+	observed := map[string]int{}
+	for seed := uint64(1); seed <= runs; seed++ {
+		names, _ := sample(t, 1, seed, servers)
+		if len(names) != 1 {
+			t.Fatalf("seed %d: picked %q, want one line", seed, names)
+		}
+		observed[names[0]]++
+	}
+
+	if observed["zero"] != 0 {
+		t.Errorf("the line of weight 0 was picked %d times", observed["zero"])
+	}
+	expected := map[string]float64{"a": 0.1, "b": 0.2, "c": 0.3, "d": 0.4}
+
+	// 3 degrees of freedom: above 16.27 happens by chance 1 time in 1000
+	if x2 := chiSquare(observed, expected, runs); x2 > 16.27 {
+		t.Errorf("picks %v are not in proportion to the weights 1:2:3:4 (chi-square %.1f)", observed, x2)
+	}
+}
+
+// TestPairsWithoutReplacement checks that with --count 2 each pair is
+// picked as often as drawing one line by weight, then another by weight
+// from the rest
+func TestPairsWithoutReplacement(t *testing.T) {
+	weights := map[string]float64{"a": 1, "b": 2, "c": 3, "d": 4}
+	order := "abcd"
+
+	// P{x, y} = wx/W * wy/(W-wx) + wy/W * wx/(W-wy)
+	const total = 10.0
+	expected := map[string]float64{}
+	for i := range order {
+		for j := i + 1; j < len(order); j++ {
+			x, y := weights[order[i:i+1]], weights[order[j:j+1]]
+			expected[order[i:i+1]+order[j:j+1]] = x/total*y/(total-x) + y/total*x/(total-y)
+		}
+	}
+
+	observed := map[string]int{}
+	for seed := uint64(1); seed <= runs; seed++ {
+		names, _ := sample(t, 2, seed, servers)
+		if len(names) != 2 || names[0] >= names[1] {
+			t.Fatalf("seed %d: picked %q, want two lines in input order", seed, names)
+		}
+		observed[names[0]+names[1]]++
+	}
+
+	// 5 degrees of freedom: above 20.52 happens by chance 1 time in 1000
+	if x2 := chiSquare(observed, expected, runs); x2 > 20.52 {
+		t.Errorf("pairs %v are not as weighted sampling without replacement picks them (chi-square %.1f)", observed, x2)
+	}
+}
+
+// TestSeedReproducible checks that a seed always picks the same sample
+func TestSeedReproducible(t *testing.T) {
+	for seed := uint64(1); seed <= 20; seed++ {
+		first, _ := sample(t, 2, seed, servers)
+		again, _ := sample(t, 2, seed, servers)
+		if fmt.Sprint(first) != fmt.Sprint(again) {
+			t.Errorf("seed %d picked %q, then %q", seed, first, again)
+		}
+	}
+}
+
+// TestWeights checks the lines that can't be picked: weight 0, and the
+// ones skipped with a warning; and that asking for more lines than can be
+// picked gives all of them
+func TestWeights(t *testing.T) {
+	in := "a 1\nb -1\nc x\nd\n\ne 0\nf NaN\ng +Inf\nh 2\n"
+	names, stderr := sample(t, 10, 1, in)
+	if want := "[a h]"; fmt.Sprint(names) != want {
+		t.Errorf("picked %q, want %s", names, want)
+	}
+	for _, n := range []int{2, 3, 4, 7, 8} {
+		if warning := fmt.Sprintf("line %d: column 2 is not a weight", n); !strings.Contains(stderr, warning) {
+			t.Errorf("no warning %q in %q", warning, stderr)
+		}
+	}
+	if strings.Contains(stderr, "line 6:") {
+		t.Errorf("weight 0 warned about: %q", stderr)
+	}
+}
@@ -0,0 +1,60 @@
+#!/bin/bash
+set -o pipefail
+
+# Pick K random lines, each with a chance proportional to the weight in one
+# of its fields
+# yupsh equivalent: See main.go
+#
+# awk's rand() is not Go's, so the same --seed picks a different sample in
+# each version; the chances of each line are the same.
+
+# Parse flags (--count K, --weight-field N, --delim SEP, --seed S), then the files
+# yupsh: count := opts.Int("count", 1, ...); field := opts.Int("weight-field", 2, ...); ...
+COUNT=1
+FIELD=2
+DELIM=" "
+SEED=0
+usage() {
+  echo "Usage: $0 [--count K] [--weight-field N] [--delim SEP] [--seed S] [file...]" >&2
+  exit 2
+}
+while [[ $1 == --* ]]; do
+  case $1 in
+    --count) COUNT=$2; shift 2 ;;
+    --weight-field) FIELD=$2; shift 2 ;;
+    --delim) DELIM=$2; shift 2 ;;
+    --seed) SEED=$2; shift 2 ;;
+    *) usage ;;
+  esac
+done
+if (( COUNT < 1 || FIELD < 1 )); then
+  usage
+fi
+if [[ ${DELIM} == '\t' ]]; then
+  DELIM=$'\t'
+fi
+
+# Give every weighted line the key log(u) / w, keep the K largest, and put
+# them back in input order
+# yupsh: s.sample(), with s.offer() keeping the K best in a min-heap
+cat "$@" | awk -v f="${FIELD}" -v d="${DELIM}" -v seed="${SEED}" '
+  BEGIN {
+    if (d != " ") FS = d
+    # yupsh: rand.NewPCG(*seed, *seed), or a random seed
+    if (seed) srand(seed); else srand()
+  }
+  /^[ \t]*$/ { next }
+  {
+    w = $f
+    sub(/^[ \t]+/, "", w); sub(/[ \t]+$/, "", w)
+    if (w !~ /^(\+?[0-9]+\.?[0-9]*|\+?\.[0-9]+)([eE][-+]?[0-9]+)?$/) {
+      printf "weighted-sample: line %d: column %d is not a weight\n", NR, f > "/dev/stderr"
+      next
+    }
+    # yupsh: key := math.Log(1 - s.rng.Float64()) / w
+    if (w > 0) printf "%.17g\t%d\t%s\n", log(1 - rand()) / w, NR, $0
+  }' |
+  # yupsh: key > s.best[0].key, keeping s.k of them
+  sort -t$'\t' -k1,1gr | head -n "${COUNT}" |
+  # yupsh: slices.SortFunc(s.best, ...by line number)
+  sort -t$'\t' -k2,2n | cut -f3-
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Count, for each timestamped event, the events in the time window ending
+// at it
+// Shell equivalent: See window-count.sh
+//
+//   window-count --window 1m --min 5 access.log
+//   5	2026-10-14T09:03:10Z 10.0.0.7 GET /login 401
+//   6	2026-10-14T09:03:12Z 10.0.0.7 GET /login 401
+//   7	2026-10-14T09:03:15Z 10.0.0.7 GET /login 200
+//
+// Each line is printed after the number of events in the --window up to
+// and including it: an event at 09:03:12 with --window 1m counts the events
+// after 09:02:12. A count that jumps is a spike, and --min prints only the
+// lines where the count has reached N, so it works as an alert:
+//   window-count --window 5m --min 100 access.log | head -1
+//
+// The events must be in time order, as a log is. The window is a queue of
+// the timestamps inside it: each event is added at the back, and the events
+// that have fallen out of the window are dropped from the front, so memory
+// holds one window's worth of timestamps however long the stream. A line
+// without a timestamp, or with one earlier than the line before, is
+// skipped with a warning.
+//
+// The timestamp is the first words of the line, as many as --layout has,
+// parsed with time.Parse and the Go reference time (Mon Jan 2 15:04:05 MST
+// 2006):
+//   --layout 2006-01-02T15:04:05Z07:00   2026-10-14T09:00:01Z (the default)
+//   --layout '2006-01-02 15:04:05'       2026-10-14 09:00:01
+//   --layout 'Jan _2 15:04:05'           Oct 14 09:00:01 (syslog)
+//
+// Usage: window-count [--window DURATION] [--min N] [--layout LAYOUT] [file...]
+func main() {
+	opts := flags.New("window-count", "[file...]")
+	window := opts.Duration("window", 5*time.Minute, "count the events in the last `duration`, such as 30s, 5m, or 1h")
+	minCount := opts.Int("min", 0, "print only the events with a count of at least `N`")
+	layout := opts.String("layout", time.RFC3339, "Go time `layout` of the leading timestamp")
+	opts.Parse()
+	if *window <= 0 {
+		opts.Fail("--window must be positive")
+	}
+
+	s := stamps{layout: *layout, words: len(strings.Fields(*layout))}
+	if s.words == 0 {
+		opts.Fail("--layout must not be empty")
+	}
+
+	// Shell: awk '{q[tail++] = t; while (q[head] <= t - w) head++; print tail - head "\t" $0}'
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		windowCount(s, *window, *minCount),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "window-count: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// stamps reads the leading timestamps of log lines
+type stamps struct {
+	layout string
+	words  int // the number of words in layout, and so in a timestamp
+}
+
+// parse returns the time at the start of line, and whether there is one
+func (s stamps) parse(line string) (time.Time, bool) {
+	words := strings.Fields(line)
+	if len(words) < s.words {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(s.layout, strings.Join(words[:s.words], " "))
+	return t, err == nil
+}
+
+// windowCount prints each event with the number of events in the window
+// ending at it, if that is at least atLeast
+func windowCount(s stamps, window time.Duration, atLeast int) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		q := &queue{}
+		w := bufio.NewWriter(stdout)
+
+		scanner := bufio.NewScanner(stdin)
+		for lineNum := 1; scanner.Scan(); lineNum++ {
+			line := scanner.Text()
+			t, ok := s.parse(line)
+			if !ok {
+				fmt.Fprintf(stderr, "window-count: line %d: no timestamp: %q\n", lineNum, line)
+				continue
+			}
+			if last, ok := q.back(); ok && t.Before(last) {
+				fmt.Fprintf(stderr, "window-count: line %d: out of order: %q\n", lineNum, line)
+				continue
+			}
+
+			// The window is (t-window, t]: an event exactly one window
+			// earlier has just left it
+			q.push(t)
+			q.dropUntil(t.Add(-window))
+
+			// Shell: count >= min {print count "\t" $0}
+			if n := q.len(); n >= atLeast {
+				if _, err := fmt.Fprintf(w, "%d\t%s\n", n, line); err != nil {
+					return err
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return w.Flush()
+	})
+}
+
+// queue holds the timestamps of the events in the window, oldest first
+type queue struct {
+	times []time.Time
+	head  int // index of the oldest timestamp still in the window
+}
+
+// push adds t, the newest timestamp, at the back
+func (q *queue) push(t time.Time) {
+	// Reuse the space of the dropped timestamps once they are the larger
+	// part, so the slice stays about one window long rather than growing
+	// with the stream
+	if q.head > len(q.times)/2 {
+		q.times = q.times[:copy(q.times, q.times[q.head:])]
+		q.head = 0
+	}
+	q.times = append(q.times, t)
+}
+
+// dropUntil drops the timestamps at or before cutoff from the front
+func (q *queue) dropUntil(cutoff time.Time) {
+	for q.head < len(q.times) && !q.times[q.head].After(cutoff) {
+		q.head++
+	}
+}
+
+// back returns the newest timestamp, and whether there is one
+func (q *queue) back() (time.Time, bool) {
+	if q.len() == 0 {
+		return time.Time{}, false
+	}
+	return q.times[len(q.times)-1], true
+}
+
+// len returns the number of timestamps in the window
+func (q *queue) len() int {
+	return len(q.times) - q.head
+}
@@ -0,0 +1,84 @@
+#!/bin/bash
+set -eo pipefail
+
+# Count, for each timestamped event, the events in the time window ending
+# at it
+# yupsh equivalent: See main.go
+#
+# awk has no time parsing (gawk's mktime only reads local time), so this
+# version reads one format only, RFC 3339 as in 2026-10-14T09:00:01Z or
+# 2026-10-14T11:00:01+02:00, and converts it to seconds itself. The Go
+# version parses any --layout with time.Parse.
+
+# Parse flags (--window DURATION, --min N), then the files
+# yupsh: window := opts.Duration("window", 5*time.Minute, ...); minCount := ...
+WINDOW=5m
+MIN=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --window) WINDOW=$2; shift 2 ;;
+    --min) MIN=$2; shift 2 ;;
+    *) echo "Usage: $0 [--window DURATION] [--min N] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# The window in seconds: a number with an s, m, or h
+# yupsh: time.ParseDuration, as the flag is parsed
+case ${WINDOW} in
+  *s) SECONDS_=${WINDOW%s} ;;
+  *m) SECONDS_=$(( ${WINDOW%m} * 60 )) ;;
+  *h) SECONDS_=$(( ${WINDOW%h} * 3600 )) ;;
+  *) SECONDS_= ;;
+esac
+if [[ ! ${SECONDS_} =~ ^[0-9]+$ ]] || (( SECONDS_ == 0 )); then
+  echo "window-count: --window must be a positive number of s, m, or h" >&2
+  exit 2
+fi
+
+# Keep the timestamps of the window in an array used as a queue: push at
+# tail, drop from head
+# yupsh: windowCount(s, *window, *minCount) with a queue struct
+cat "$@" | awk -v window="${SECONDS_}" -v min="${MIN}" '
+  BEGIN { head = tail = 0 }
+
+  # Seconds since 1970 of a date in the proleptic Gregorian calendar
+  # yupsh: time.Parse(s.layout, ...)
+  function epoch(y, m, d, hh, mm, ss,    era, yoe, doy, doe) {
+    y -= (m <= 2)
+    era = int((y >= 0 ? y : y - 399) / 400)
+    yoe = y - era * 400
+    doy = int((153 * (m + (m > 2 ? -3 : 9)) + 2) / 5) + d - 1
+    doe = yoe * 365 + int(yoe / 4) - int(yoe / 100) + doy
+    return ((era * 146097 + doe - 719468) * 24 + hh) * 3600 + mm * 60 + ss
+  }
+
+  # yupsh: s.parse(line) fails -> warn and skip
+  $1 !~ /^[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]T[0-9][0-9]:[0-9][0-9]:[0-9][0-9](\.[0-9]+)?(Z|[-+][0-9][0-9]:[0-9][0-9])$/ {
+    printf "window-count: line %d: no timestamp: \"%s\"\n", NR, $0 > "/dev/stderr"
+    next
+  }
+
+  {
+    t = epoch(substr($1, 1, 4), substr($1, 6, 2), substr($1, 9, 2),
+              substr($1, 12, 2), substr($1, 15, 2), substr($1, 18))
+    zone = substr($1, length($1) - 5)
+    if (zone ~ /^[-+]/) {
+      offset = substr(zone, 2, 2) * 3600 + substr(zone, 5, 2) * 60
+      t -= (zone ~ /^-/ ? -offset : offset)
+    }
+  }
+
+  # yupsh: t.Before(last) -> warn and skip
+  tail > head && t < q[tail - 1] {
+    printf "window-count: line %d: out of order: \"%s\"\n", NR, $0 > "/dev/stderr"
+    next
+  }
+
+  # yupsh: q.push(t); q.dropUntil(t.Add(-window))
+  {
+    q[tail++] = t
+    while (head < tail && q[head] <= t - window) delete q[head++]
+  }
+
+  # yupsh: if n := q.len(); n >= atLeast
+  tail - head >= min { print tail - head "\t" $0 }'
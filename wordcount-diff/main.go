@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	head `github.com/yupsh/head`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// word matches one token: a run of letters, digits and apostrophes
+var word = regexp.MustCompile(`[\p{L}\p{N}']+`)
+
+// Compare how often each word occurs in two texts, such as two versions of a
+// document, and print the changes, largest first
+// Shell equivalent: See wordcount-diff.sh
+//
+//   wordcount-diff --top 4 old.txt new.txt
+//   +2 the
+//   +1 and
+//   +1 banks
+//   +1 busy
+//
+// Each line is the change in a word's count from the old text to the new:
+// "+N word" if it occurs N more times, "-N word" if N fewer. Words whose
+// counts are the same are left out. Changes are sorted by size, and equal
+// ones by word.
+//
+// Words are found as ngrams finds them: lowercased runs of letters, digits
+// and apostrophes, with punctuation separating them.
+//
+// As with diff, the exit status is 0 if every count is the same (and nothing
+// is printed), 1 if some differ and 2 on trouble.
+//
+// Usage: wordcount-diff [--top N] old new
+func main() {
+	opts := flags.New("wordcount-diff", "old new")
+	top := opts.Top()
+	opts.Parse()
+	if opts.NArg() != 2 {
+		opts.Fail("want two files to compare")
+	}
+
+	// Shell: tokens < "$1" | sort | uniq -c (and the same for "$2")
+	oldWords, err := countWords(opts.Arg(0))
+	if err != nil {
+		fail(err)
+	}
+	newWords, err := countWords(opts.Arg(1))
+	if err != nil {
+		fail(err)
+	}
+
+	changes := compare(oldWords, newWords)
+	err = gloo.Run(pipe.Pipeline(
+		// One "+N word" or "-N word" line per change, largest first
+		// Shell: awk '{d[$2] += $1} END {...}' | sort -t$'\t' -k1,1nr -k2,2
+		report(changes),
+
+		// Shell: head -n "${TOP}"
+		head.Head(head.LineCount(*top)),
+	))
+	if err != nil {
+		fail(err)
+	}
+	if len(changes) > 0 {
+		os.Exit(1)
+	}
+}
+
+// fail reports err and exits 2, diff's status for trouble
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "wordcount-diff: %s\n", status.Message(err))
+	os.Exit(2)
+}
+
+// counts maps each word of a text to the number of times it occurs
+type counts map[string]int
+
+// countWords reads the named file ("-" for stdin) and counts its words
+func countWords(name string) (counts, error) {
+	c := counts{}
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+
+		// Shell: cat "${file}"
+		input.Source(name),
+
+		// Shell: tr '[:upper:]' '[:lower:]' | tr -cs "[:alnum:]'" '\n' | sort | uniq -c
+		While(c.line, input.WholeLine),
+	))
+	return c, err
+}
+
+// line is the While() callback that counts the words of one line; it prints
+// nothing, as the counts are only compared once both texts are read
+func (c counts) line(args ...any) gloo.Command {
+	for _, w := range word.FindAllString(strings.ToLower(args[0].(string)), -1) {
+		c[w]++
+	}
+	return nil
+}
+
+// change is the difference in one word's count, new minus old
+type change struct {
+	word string
+	n    int
+}
+
+// compare returns the words whose counts differ between oldWords and
+// newWords, largest change first, and equal changes in byte order of the word
+func compare(oldWords, newWords counts) []change {
+	var changes []change
+	for w, n := range newWords {
+		if d := n - oldWords[w]; d != 0 {
+			changes = append(changes, change{w, d})
+		}
+	}
+	for w, n := range oldWords {
+		if _, ok := newWords[w]; !ok {
+			changes = append(changes, change{w, -n})
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b change) int {
+		if c := cmp.Compare(abs(b.n), abs(a.n)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.word, b.word)
+	})
+	return changes
+}
+
+// abs returns the magnitude of a change
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// report returns the command that prints each change as "+N word" or
+// "-N word"
+//
+// Shell equivalent:
+//   awk -F'\t' '{print $3 " " $2}'
+func report(changes []change) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		out := bufio.NewWriter(stdout)
+		for _, c := range changes {
+			fmt.Fprintf(out, "%+d %s\n", c.n, c.word)
+		}
+		return out.Flush()
+	})
+}
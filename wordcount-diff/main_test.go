@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// diff writes the old and new texts to files, counts their words as main()
+// does, and returns the report of the changes
+func diff(t *testing.T, oldText, newText string) string {
+	t.Helper()
+	dir := t.TempDir()
+	var words [2]counts
+	for i, text := range []string{oldText, newText} {
+		path := filepath.Join(dir, []string{"old", "new"}[i])
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		var err error
+		if words[i], err = countWords(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := report(compare(words[0], words[1])).Executor()(context.Background(), strings.NewReader(""), &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stdout.String()
+}
+
+func TestWordcountDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{
+			// Case and punctuation don't make a word different; equal
+			// changes are in byte order of the word
+			"cats and dogs",
+			"The cat sat.\nThe cat ran!\n",
+			"the dog sat; the CAT sat.\n",
+			"-1 cat\n+1 dog\n-1 ran\n+1 sat\n",
+		},
+		{
+			// Apostrophes stay in words, letters needn't be ASCII, and
+			// larger changes come first
+			"apostrophes, accents and digits",
+			"don't stop, Café 42\n",
+			"Don't don't DON'T\ncafé café 7",
+			"+2 don't\n-1 42\n+1 7\n+1 café\n-1 stop\n",
+		},
+		{"same words, other order", "a b, b c\n", "c b b a\n", ""},
+		{"both empty", "", "", ""},
+		{"all removed", "gone gone\n", "", "-2 gone\n"},
+		{"all added", "", "\n\nnew\n", "+1 new\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diff(t, tt.old, tt.new); got != tt.want {
+				t.Errorf("report:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExampleFiles checks the report on the example's old.txt and new.txt,
+// whose first lines the doc comment shows
+func TestExampleFiles(t *testing.T) {
+	oldWords, err := countWords("old.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newWords, err := countWords("new.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range compare(oldWords, newWords) {
+		got = append(got, c.word)
+		if c.word == "town" && c.n != 1 {
+			t.Errorf("town changed by %d, want +1", c.n)
+		}
+	}
+	want := "the and banks busy fast flooded grew old quiet river's slow town twice"
+	if strings.Join(got, " ") != want {
+		t.Errorf("changed words %q, want %q", strings.Join(got, " "), want)
+	}
+}
@@ -0,0 +1,52 @@
+#!/bin/bash
+set -e
+
+# Compare how often each word occurs in two texts, and print the changes,
+# largest first
+# yupsh equivalent: See main.go
+
+# Parse flags (--top N), then the two files
+# yupsh: top := opts.Top(); if opts.NArg() != 2 { opts.Fail(...) }
+TOP=10
+while [[ $1 == --* ]]; do
+  case $1 in
+    --top) TOP=$2; shift 2 ;;
+    *) echo "Usage: $0 [--top N] old new" >&2; exit 2 ;;
+  esac
+done
+if [[ $# -ne 2 ]]; then
+  echo "Usage: $0 [--top N] old new" >&2
+  exit 2
+fi
+for file in "$@"; do
+  if [[ ${file} != - && ! -r ${file} ]]; then
+    echo "wordcount-diff: ${file}: cannot read" >&2
+    exit 2
+  fi
+done
+
+# One lowercased word per line
+# yupsh: While(c.line, input.WholeLine), with word.FindAllString(strings.ToLower(line))
+tokens() {
+  cat "$1" | tr '[:upper:]' '[:lower:]' | tr -cs "[:alnum:]'" '\n' | grep .
+}
+
+# Each word of the old text counts -1, of the new +1; the sums are the
+# changes. Sort them by size, then word, as "size TAB word TAB change"
+# yupsh: changes := compare(oldWords, newWords)
+CHANGES=$(
+  { tokens "$1" | sed 's/^/-1 /'; tokens "$2" | sed 's/^/1 /'; } |
+    awk '{d[$2] += $1} END {
+      for (w in d) if (d[w]) printf "%d\t%s\t%+d\n", d[w] < 0 ? -d[w] : d[w], w, d[w]
+    }' |
+    LC_ALL=C sort -t$'\t' -k1,1nr -k2,2
+)
+
+# Nothing changed: print nothing, and exit 0
+# yupsh: if len(changes) > 0 { os.Exit(1) }
+[[ -n ${CHANGES} ]] || exit 0
+
+# Print "+N word" or "-N word", largest first
+# yupsh: report(changes) | head.Head(head.LineCount(*top))
+printf '%s\n' "${CHANGES}" | head -n "${TOP}" | awk -F'\t' '{print $3 " " $2}'
+exit 1
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	echo `github.com/yupsh/echo`
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	. `github.com/yupsh/while`
+)
+
+// Add a prefix and/or suffix to every line
+// Shell equivalent: See wrap-lines.sh
+//
+//   wrap-lines --prefix '> '                 quote an email reply
+//   wrap-lines --prefix '    '               indent a code block
+//   wrap-lines --prefix '- '                 build a Markdown list
+//   wrap-lines --prefix '"' --suffix '",'    build a string array
+//
+// The prefix and suffix are literal text: sed metacharacters such as & and /
+// need no escaping. A line that already starts with the prefix still gets it
+// again; the transform is applied to every line exactly once. Empty input
+// produces empty output.
+//
+// Usage: wrap-lines [--prefix TEXT] [--suffix TEXT] [--skip-blank] [file...]
+func main() {
+	opts := flags.New("wrap-lines", "[file...]")
+	prefix := opts.String("prefix", "", "`text` to add before each line")
+	suffix := opts.String("suffix", "", "`text` to add after each line")
+	skipBlank := opts.Bool("skip-blank", false, "leave empty lines empty")
+	opts.Parse()
+
+	// Shell: sed 's/.*/PREFIX&SUFFIX/' "$@"
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		While(wrapLine(*prefix, *suffix, *skipBlank), input.WholeLine),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wrap-lines: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// wrapLine returns the While() callback adding prefix and suffix to a line
+// Shell: sed '/^$/!s/.*/PREFIX&SUFFIX/'   (with --skip-blank)
+func wrapLine(prefix, suffix string, skipBlank bool) Body {
+	return func(args ...any) gloo.Command {
+		line := args[0].(string)
+		if skipBlank && line == "" {
+			return echo.Echo(line)
+		}
+		return echo.Echo(prefix + line + suffix)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	input `github.com/yupsh/script-examples/internal/input`
+	. `github.com/yupsh/while`
+)
+
+// wrap runs the pipeline main() runs over text and returns its output
+func wrap(t *testing.T, prefix, suffix string, skipBlank bool, text string) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	err := While(wrapLine(prefix, suffix, skipBlank), input.WholeLine).Executor()(
+		context.Background(), strings.NewReader(text), &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("wrap-lines: %v\n%s", err, stderr.String())
+	}
+	return stdout.String()
+}
+
+func TestWrapLines(t *testing.T) {
+	tests := []struct {
+		name           string
+		prefix, suffix string
+		skipBlank      bool
+		in, want       string
+	}{
+		{"empty input", "> ", "", false, "", ""},
+		{"empty input, skip blank", "> ", ";", true, "", ""},
+		{"quote a reply", "> ", "", false, "hi\nthere\n", "> hi\n> there\n"},
+		{"already quoted gets it again", "> ", "", false, "> hi\n>> deeper\nplain\n", "> > hi\n> >> deeper\n> plain\n"},
+		{"already suffixed gets it again", `"`, `",`, false, "\"a\",\nb\n", "\"\"a\",\",\n\"b\",\n"},
+		{"prefix is the whole line", "- ", "", false, "- \n", "- - \n"},
+		{"blank line wrapped", "- ", "", false, "a\n\nb\n", "- a\n- \n- b\n"},
+		{"blank line kept blank", "- ", "", true, "a\n\nb\n", "- a\n\n- b\n"},
+		{"spaces are not blank", "[", "]", true, "  \n", "[  ]\n"},
+		{"only blank lines", "> ", "", true, "\n\n", "\n\n"},
+		{"no prefix or suffix", "", "", false, "a b\n\tc\n", "a b\n\tc\n"},
+		{"sed metacharacters are literal", `&\1/`, `/&`, false, "x\n", "&\\1/x/&\n"},
+		{"spacing inside lines kept", "| ", " |", false, "  two  spaces\tand tab  \n", "|   two  spaces\tand tab   |\n"},
+		{"last line without a newline", "> ", "", false, "a\nb", "> a\n> b\n"},
+		{"CRLF input", "> ", "", false, "a\r\nb\r\n", "> a\n> b\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrap(t, tt.prefix, tt.suffix, tt.skipBlank, tt.in); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
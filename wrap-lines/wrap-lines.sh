@@ -0,0 +1,26 @@
+#!/bin/bash
+set -e
+
+# Add a prefix and/or suffix to every line
+# yupsh equivalent: See main.go
+
+# Parse flags (--prefix TEXT, --suffix TEXT, --skip-blank), then the files
+# yupsh: prefix := opts.String("prefix", "", ...); suffix := ...
+PREFIX=
+SUFFIX=
+SKIP_BLANK=0
+while [[ $1 == --* ]]; do
+  case $1 in
+    --prefix) PREFIX=$2; shift 2 ;;
+    --suffix) SUFFIX=$2; shift 2 ;;
+    --skip-blank) SKIP_BLANK=1; shift ;;
+    *) echo "Usage: $0 [--prefix TEXT] [--suffix TEXT] [--skip-blank] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# awk reads the texts from the environment, so & / and \ stay literal
+# (sed 's/.*/PREFIX&SUFFIX/' would need them escaped)
+# yupsh: While(wrapLine(*prefix, *suffix, *skipBlank), input.WholeLine)
+cat "$@" | PREFIX="${PREFIX}" SUFFIX="${SUFFIX}" awk -v skip="${SKIP_BLANK}" '
+  skip && $0 == "" { print; next }
+  { print ENVIRON["PREFIX"] $0 ENVIRON["SUFFIX"] }'
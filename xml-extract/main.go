@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+)
+
+// Print the text of every occurrence of an XML element, one per line
+// Shell equivalent: See xml-extract.sh
+//
+//   xml-extract --element price catalog.xml
+//   12.50
+//   8.99
+//
+// One value per line is what the line-oriented examples read, so the output
+// feeds straight into percentile, histogram, sort or awk.
+//
+// The document is read a token at a time with xml.Decoder, and only the text
+// of the element being read is held in memory, so documents of any size
+// stream through. An element's text is all the character data inside it,
+// including its children's, with whitespace runs collapsed to one space and
+// the ends trimmed (XPath's normalize-space()); an empty element prints an
+// empty line. --element is compared with the local name, so it matches
+// <price> and <g:price> alike. An element nested in another of the same name
+// is part of the outer one's text, not a line of its own.
+//
+// Input follows the examples' convention (files, or stdin).
+//
+// Usage: xml-extract --element NAME [file...]
+func main() {
+	opts := flags.New("xml-extract", "--element NAME [file...]")
+	element := opts.String("element", "", "print the text of each element with this local `name`")
+	opts.Parse()
+
+	if *element == "" {
+		opts.Fail("--element is required")
+	}
+
+	err := gloo.Run(pipe.Pipeline(
+		// Report a missing file instead of silently producing nothing
+		// Shell: set -o pipefail
+		pipe.PipeFail,
+
+		// Read the named files, or stdin when there are none
+		// Shell: cat "$@"
+		input.Source(opts.Args()...),
+
+		// The text of each matching element, one per line
+		// Shell: xmlstarlet sel -t -m "//*[local-name()='price']" -v 'normalize-space(.)' -n
+		extract(*element),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "xml-extract: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// extract returns the command that reads an XML document from stdin and
+// writes the text of each element named element to stdout, one per line
+//
+// Shell equivalent:
+//   xmlstarlet sel -t -m "//*[local-name()='NAME']" -v 'normalize-space(.)' -n
+//
+// Output is buffered, and flushed even when a syntax error stops the run, so
+// every value before the error is written.
+func extract(element string) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		out := bufio.NewWriter(stdout)
+		err := extractText(ctx, xml.NewDecoder(stdin), element, out)
+		if flushErr := out.Flush(); err == nil {
+			err = flushErr
+		}
+		return err
+	})
+}
+
+// extractText writes the text of each element named element that dec reads
+// to out, one per line
+//
+// dec.Token() returns the document as a stream of start tags, text and end
+// tags. depth counts how far inside a matching element the stream is, 0
+// when outside one: a matching start tag sets it to 1, any start tag inside
+// adds one and every end tag takes one away, so the end tag that brings it
+// back to 0 is the matching element's own.
+func extractText(ctx context.Context, dec *xml.Decoder, element string, out io.Writer) error {
+	var text strings.Builder
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			// Shell: -m "//*[local-name()='NAME']"
+			if depth > 0 {
+				depth++
+			} else if tok.Name.Local == element {
+				depth = 1
+				text.Reset()
+			}
+
+		case xml.CharData:
+			// Text and CDATA sections alike; entities are already decoded
+			if depth > 0 {
+				text.Write(tok)
+			}
+
+		case xml.EndElement:
+			if depth == 0 {
+				break
+			}
+			depth--
+			if depth > 0 {
+				break
+			}
+
+			// The matching element is complete
+			// Shell: -v 'normalize-space(.)' -n
+			line := strings.Join(strings.Fields(text.String()), " ")
+			if _, err := fmt.Fprintln(out, line); err != nil {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+	}
+}
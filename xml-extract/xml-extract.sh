@@ -0,0 +1,31 @@
+#!/bin/bash
+
+# Print the text of every occurrence of an XML element, one per line
+# yupsh equivalent: See main.go
+#
+# Needs xmlstarlet. Its XPath "//" also matches an element nested in another
+# of the same name, printing its text a second time on its own line; the Go
+# version prints only the outer one.
+
+usage() {
+  echo "Usage: $0 --element NAME [file...]" >&2
+  exit 2
+}
+
+# Parse flags (--element NAME), then the files
+# yupsh: element := opts.String("element", "", ...)
+ELEMENT=
+while [[ $1 == --* ]]; do
+  case $1 in
+    --element) ELEMENT=$2; shift 2 ;;
+    *) usage ;;
+  esac
+done
+[[ -n ${ELEMENT} ]] || usage
+
+# Every element with that local name, whatever its namespace, as its
+# whitespace-normalized text, one per line; stdin ("-") without files
+# yupsh: input.Source(opts.Args()...) | extract(*element)
+xmlstarlet sel -t \
+  -m "//*[local-name()='${ELEMENT}']" -v 'normalize-space(.)' -n \
+  "${@:--}"
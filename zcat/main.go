@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	status `github.com/yupsh/script-examples/internal/status`
+	xz `github.com/ulikunitz/xz`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+)
+
+// Print files decompressed, whether they are gzip, bzip2, xz, or plain text
+// Shell equivalent: See zcat.sh
+//
+//   zcat access.log access.log.1.gz access.log.2.bz2 | grep ' 500 '
+//   curl -s https://example.com/dump.xz | zcat | head
+//
+// The format of each input is sniffed from its first bytes, the magic
+// number every compressed format starts with, so a file is decompressed
+// whatever its name, and stdin works too. A file whose extension names a
+// format its contents aren't in is an error, as it is for gzip -d, rather
+// than being passed through compressed. Anything else is printed as it is,
+// like zcat -f, so a directory of rotated logs, some compressed and some
+// not, reads as one stream:
+//   zcat /var/log/syslog* | grep sshd
+//
+// As the first command of a pipeline, it lets any example read compressed
+// input:
+//   gloo.Run(pipe.Pipeline(decompress(files...), grep.Grep("ERROR")))
+//
+// Usage: zcat [file...]
+func main() {
+	opts := flags.New("zcat", "[file...]")
+	opts.Parse()
+
+	err := gloo.Run(pipe.Pipeline(
+		// Report a missing or damaged file instead of stopping quietly
+		// Shell: set -o pipefail
+		pipe.PipeFail,
+
+		// Decompress each file, or stdin when there are none
+		// Shell: for f in "$@"; do case $(magic "$f") in ...; done
+		decompress(opts.Args()...),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "zcat: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// format is a compressed file format zcat can read
+type format struct {
+	name  string
+	ext   string // the file name extension, such as ".gz"
+	magic []byte // the bytes every file in the format starts with
+	open  func(r io.Reader) (io.Reader, error)
+}
+
+// formats are the compressed formats, by their magic numbers. A plain file
+// matches none of them.
+//
+// Shell equivalent:
+//   file --mime-type -b "$f"    # application/gzip, application/x-bzip2, ...
+var formats = []format{
+	{"gzip", ".gz", []byte{0x1f, 0x8b}, func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r) // reads concatenated members, as gzip -d does
+	}},
+	{"bzip2", ".bz2", []byte("BZh"), func(r io.Reader) (io.Reader, error) {
+		return bzip2.NewReader(r), nil
+	}},
+	{"xz", ".xz", []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, func(r io.Reader) (io.Reader, error) {
+		return xz.NewReader(r)
+	}},
+}
+
+// sniff returns the format r is in, from its first bytes, without
+// consuming them, or nil for a plain file
+func sniff(r *bufio.Reader) *format {
+	for i, f := range formats {
+		// Peek returns fewer bytes, and an error, for a shorter file
+		if head, _ := r.Peek(len(f.magic)); bytes.Equal(head, f.magic) {
+			return &formats[i]
+		}
+	}
+	return nil
+}
+
+// byExt returns the format a file name's extension names, or nil
+func byExt(name string) *format {
+	ext := filepath.Ext(name)
+	for i, f := range formats {
+		if f.ext == ext {
+			return &formats[i]
+		}
+	}
+	return nil
+}
+
+// decompress returns the command that writes each file decompressed to
+// stdout, or stdin when there are no files, where input.Stdin ("-") stands
+// for stdin
+//
+// Shell equivalent:
+//   zcat -f "$@"    # gzip only, with bzcat and xzcat for the others
+func decompress(files ...string) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		if len(files) == 0 {
+			files = []string{input.Stdin}
+		}
+
+		for _, name := range files {
+			if err := decompressFile(name, stdin, stdout); err != nil {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+		return nil
+	})
+}
+
+// decompressFile writes the contents of name (or stdin for "-") to stdout,
+// decompressed if it is in one of the formats
+func decompressFile(name string, stdin io.Reader, stdout io.Writer) error {
+	r := stdin
+	if name != input.Stdin {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	// Sniffing reads the first bytes into the buffer, where the
+	// decompressor, or the copy of a plain file, reads them again
+	buffered := bufio.NewReader(r)
+	found := sniff(buffered)
+
+	// Shell: gzip: notes.txt.gz: not in gzip format
+	if want := byExt(name); want != nil && found != want {
+		return fmt.Errorf("%s: not in %s format", name, want.name)
+	}
+
+	var contents io.Reader = buffered
+	if found != nil {
+		var err error
+		if contents, err = found.open(buffered); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	if _, err := io.Copy(stdout, contents); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
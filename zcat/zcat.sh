@@ -0,0 +1,54 @@
+#!/bin/bash
+set -eo pipefail
+
+# Print files decompressed, whether they are gzip, bzip2, xz, or plain text
+# yupsh equivalent: See main.go
+#
+# Each format has its own tool (gzip -dc, bzip2 -dc, xz -dc), so the format
+# is sniffed from the first bytes first. A pipe can't be read twice, so
+# stdin is saved to a temporary file to be sniffed, where the Go version
+# peeks at its first bytes in a buffer.
+
+if (( $# == 0 )); then
+  set -- -
+fi
+
+TMP=$(mktemp)
+trap 'rm -f "${TMP}"' EXIT
+
+# The magic number: the first bytes of the file, in hex
+# yupsh: sniff(), bufio.Reader.Peek
+magic() {
+  head -c 6 "$1" | od -An -tx1 | tr -d ' \n'
+}
+
+for file in "$@"; do
+  if [[ ${file} == - ]]; then
+    cat > "${TMP}"
+    file=${TMP}
+  fi
+
+  # yupsh: formats, each with its magic number and its decompressor
+  case $(magic "${file}") in
+    1f8b*)          format=gzip;  tool=(gzip -dc) ;;
+    425a68*)        format=bzip2; tool=(bzip2 -dc) ;;
+    fd377a585a00)   format=xz;    tool=(xz -dc) ;;
+    *)              format=;      tool=(cat) ;;
+  esac
+
+  # A .gz, .bz2, or .xz name that isn't in its format is an error
+  # yupsh: byExt(name), "not in %s format"
+  case ${file} in
+    *.gz) want=gzip ;;
+    *.bz2) want=bzip2 ;;
+    *.xz) want=xz ;;
+    *) want=${format} ;;
+  esac
+  if [[ ${want} != "${format}" ]]; then
+    echo "zcat: ${file}: not in ${want} format" >&2
+    exit 1
+  fi
+
+  # yupsh: found.open(buffered), then io.Copy(stdout, contents)
+  "${tool[@]}" "${file}"
+done
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	gloo `github.com/gloo-foo/framework`
+	pipe `github.com/gloo-foo/pipe`
+	flags `github.com/yupsh/script-examples/internal/flags`
+	input `github.com/yupsh/script-examples/internal/input`
+	status `github.com/yupsh/script-examples/internal/status`
+	welford `github.com/yupsh/script-examples/internal/welford`
+)
+
+// Flag the numbers of a stream that lie far from the numbers before them
+// Shell equivalent: See zscore.sh
+//
+//   zscore latency.txt
+//   ANOMALY 410 44.54
+//   ANOMALY 45 -13.01
+//
+// Each number is scored against the mean and standard deviation of the
+// numbers before it: its z-score is how many standard deviations it lies
+// from the mean, and a number whose z-score is beyond --threshold, either
+// way, is printed as "ANOMALY value zscore". The statistics are kept with
+// Welford's method, by the internal/welford package stats uses too, so the
+// stream is read once, in constant memory, and each anomaly is printed as
+// soon as it arrives:
+//   tail -f latency.log | zscore --threshold 4
+//
+// An anomaly is left out of the statistics, so one spike doesn't widen the
+// standard deviation enough to hide the next. The first --warmup numbers
+// are only learned from, never scored, since a mean of a handful of numbers
+// says little. Nothing is scored while every number so far has been the
+// same, as the standard deviation is 0.
+//
+// Lines that are not numbers are skipped with a warning.
+//
+// Usage: zscore [--threshold Z] [--warmup N] [file...]
+func main() {
+	opts := flags.New("zscore", "[file...]")
+	threshold := opts.Float64("threshold", 3, "flag numbers more than `Z` standard deviations from the mean")
+	warmup := opts.Int("warmup", 10, "learn from the first `N` numbers before scoring any")
+	opts.Parse()
+	if *threshold <= 0 {
+		opts.Fail("--threshold must be positive")
+	}
+	if *warmup < 2 {
+		opts.Fail("--warmup must be at least 2")
+	}
+
+	// Shell: awk 'n >= w && (z = ($1 - mean) / sd) > t {print "ANOMALY", $1, z; next} {...}'
+	err := gloo.Run(pipe.Pipeline(
+		pipe.PipeFail,
+		input.Source(opts.Args()...),
+		anomalies(*threshold, *warmup),
+	))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "zscore: %s\n", status.Message(err))
+		os.Exit(1)
+	}
+}
+
+// zscore returns how many standard deviations v lies from the mean of the
+// numbers in r, and whether that is defined: there must be two numbers, not
+// all the same
+func zscore(r *welford.Stats, v float64) (float64, bool) {
+	if r.N() < 2 {
+		return 0, false
+	}
+	sd := r.StdDev()
+	if sd == 0 {
+		return 0, false
+	}
+	return (v - r.Mean()) / sd, true
+}
+
+// anomalies returns the command that prints each number of stdin whose
+// z-score, after the first warmup numbers, is beyond threshold
+func anomalies(threshold float64, warmup int) gloo.Command {
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		var r welford.Stats
+
+		scanner := bufio.NewScanner(stdin)
+		for lineNum := 1; scanner.Scan(); lineNum++ {
+			text := strings.TrimSpace(scanner.Text())
+			v, err := strconv.ParseFloat(text, 64)
+			if err != nil || math.IsNaN(v) {
+				fmt.Fprintf(stderr, "zscore: line %d: not a number: %q\n", lineNum, scanner.Text())
+				continue
+			}
+
+			// Score against the numbers before this one, and keep an
+			// anomaly out of them
+			// Shell: n >= w && (z > t || z < -t) {print "ANOMALY", $1, z; next}
+			if z, ok := zscore(&r, v); ok && r.N() >= warmup && math.Abs(z) > threshold {
+				// Written straight to stdout, unbuffered: on a live stream,
+				// an anomaly is worth knowing about at once
+				if _, err := fmt.Fprintf(stdout, "ANOMALY %s %.2f\n", text, z); err != nil {
+					return err
+				}
+				continue
+			}
+			r.Add(v)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+		return scanner.Err()
+	})
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// zscores runs anomalies() over the numbers and returns what it printed
+func zscores(t *testing.T, threshold float64, warmup int, numbers []string) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	in := strings.NewReader(strings.Join(numbers, "\n") + "\n")
+	if err := anomalies(threshold, warmup).Executor()(context.Background(), in, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	return stdout.String()
+}
+
+// normal returns n seeded readings around 100, with a standard deviation
+// of 5
+func normal(n int) []string {
+	rng := rand.New(rand.NewPCG(688, 1))
+	numbers := make([]string, n)
+	for i := range numbers {
+		numbers[i] = fmt.Sprintf("%.1f", 100+5*rng.NormFloat64())
+	}
+	return numbers
+}
+
+// TestInjectedOutliers checks that outliers injected into a steady stream,
+// one high and one low, are flagged, and nothing else is
+func TestInjectedOutliers(t *testing.T) {
+	numbers := normal(1000)
+	numbers[500] = "200"
+	numbers[700] = "0"
+
+	// At a threshold of 5, a reading of the steady stream is flagged about
+	// once in two million
+	out := zscores(t, 5, 10, numbers)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ANOMALY 200 ") || !strings.HasPrefix(lines[1], "ANOMALY 0 ") {
+		t.Fatalf("flagged:\n%s\nwant the readings 200 and 0", out)
+	}
+
+	var high, low float64
+	fmt.Sscanf(lines[0], "ANOMALY 200 %g", &high)
+	fmt.Sscanf(lines[1], "ANOMALY 0 %g", &low)
+	if high < 15 || high > 25 || low > -15 || low < -25 {
+		t.Errorf("z-scores %v and %v, want about +20 and -20", high, low)
+	}
+}
+
+// TestOutlierLeftOut checks that an outlier doesn't enter the statistics:
+// a second, like it, straight after, is flagged with the same z-score
+func TestOutlierLeftOut(t *testing.T) {
+	numbers := append(normal(200), "200", "200")
+	out := zscores(t, 5, 10, numbers)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 2 || lines[0] != lines[1] {
+		t.Errorf("flagged:\n%s\nwant the two 200s, with the same z-score", out)
+	}
+}
+
+// TestKnownZScore checks the z-score against the sample standard deviation
+// of the numbers before it: 1 to 10 have a mean of 5.5 and a standard
+// deviation of 3.0277, so 100 is 31.21 of them away
+func TestKnownZScore(t *testing.T) {
+	numbers := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "100"}
+	if got, want := zscores(t, 3, 10, numbers), "ANOMALY 100 31.21\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestNotScored checks the numbers that are never flagged, however far
+// out: those in the warm-up, and any while the numbers so far are all the
+// same
+func TestNotScored(t *testing.T) {
+	tests := []struct {
+		name    string
+		warmup  int
+		numbers []string
+	}{
+		{"outlier in the warm-up", 10, []string{"1", "2", "1", "2", "1000", "1", "2", "1", "2", "1"}},
+		{"every number the same", 3, []string{"5", "5", "5", "5", "1000"}},
+		{"too few numbers", 2, []string{"5"}},
+	}
+	for _, tt := range tests {
+		if out := zscores(t, 3, tt.warmup, tt.numbers); out != "" {
+			t.Errorf("%s: flagged %q", tt.name, out)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+#!/bin/bash
+set -e
+
+# Flag the numbers of a stream that lie far from the numbers before them
+# yupsh equivalent: See main.go
+
+# Parse flags (--threshold Z, --warmup N), then the files
+# yupsh: threshold := opts.Float64("threshold", 3, ...); warmup := opts.Int("warmup", 10, ...)
+THRESHOLD=3
+WARMUP=10
+while [[ $1 == --* ]]; do
+  case $1 in
+    --threshold) THRESHOLD=$2; shift 2 ;;
+    --warmup) WARMUP=$2; shift 2 ;;
+    *) echo "Usage: $0 [--threshold Z] [--warmup N] [file...]" >&2; exit 2 ;;
+  esac
+done
+
+# Score each number against the running statistics of the ones before it,
+# then add it to them unless it was an anomaly
+# yupsh: anomalies(*threshold, *warmup) with a running struct
+cat "$@" | awk -v t="${THRESHOLD}" -v w="${WARMUP}" '
+  # yupsh: strconv.ParseFloat(...) fails -> warn and skip
+  $0 !~ /^[[:space:]]*[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?[[:space:]]*$/ {
+    printf "zscore: line %d: not a number: \"%s\"\n", NR, $0 > "/dev/stderr"
+    next
+  }
+
+  # yupsh: r.zscore(v), defined with two numbers not all the same
+  n >= w && n >= 2 && m2 > 0 {
+    z = ($1 - mean) / sqrt(m2 / (n - 1))
+    if (z > t || z < -t) {
+      # fflush: print it now, as on a live stream
+      # yupsh: fmt.Fprintf(stdout, "ANOMALY %s %.2f\n", text, z); continue
+      printf "ANOMALY %s %.2f\n", $1, z
+      fflush()
+      next
+    }
+  }
+
+  # yupsh: r.Add(v), with Welford updates of mean and m2
+  {
+    n++; d = $1 - mean; mean += d / n; m2 += d * ($1 - mean)
+  }'